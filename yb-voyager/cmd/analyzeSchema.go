@@ -24,15 +24,20 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
+	"unicode/utf8"
 
+	pg_query "github.com/pganalyze/pg_query_go/v5"
 	"github.com/samber/lo"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"golang.org/x/exp/slices"
+	"google.golang.org/protobuf/proto"
 
 	"github.com/yugabyte/yb-voyager/yb-voyager/src/callhome"
 	"github.com/yugabyte/yb-voyager/yb-voyager/src/cp"
 	"github.com/yugabyte/yb-voyager/yb-voyager/src/metadb"
+	"github.com/yugabyte/yb-voyager/yb-voyager/src/queryparser"
 	"github.com/yugabyte/yb-voyager/yb-voyager/src/utils"
 )
 
@@ -45,23 +50,46 @@ type summaryInfo struct {
 
 type sqlInfo struct {
 	objName string
-	// SQL statement after removing all new-lines from it. Simplifies analyze-schema regex matching.
+	// SQL statement after removing all new-lines from it.
 	stmt string
 	// Formatted SQL statement with new-lines and tabs
 	formattedStmt string
+	// Line number in the file of each line of formattedStmt
+	lineNumbers []int
+	// Statements parsed from formattedStmt. Not set if formattedStmt could not be parsed.
+	parseTree []*pg_query.RawStmt
+	parseErr  error
+}
+
+// Position of an issue in the schema file
+type issuePosition struct {
+	line   int
+	column int
+}
+
+// Returns the position in the file of the given offset in formattedStmt
+func (s *sqlInfo) position(offset int) issuePosition {
+	if len(s.lineNumbers) == 0 {
+		return issuePosition{}
+	}
+	offset = lo.Clamp(offset, 0, len(s.formattedStmt))
+	before := s.formattedStmt[:offset]
+	lineIdx := lo.Min([]int{strings.Count(before, "\n"), len(s.lineNumbers) - 1})
+	lineStart := strings.LastIndex(before, "\n") + 1
+	return issuePosition{line: s.lineNumbers[lineIdx], column: utf8.RuneCountInString(before[lineStart:]) + 1}
+}
+
+func (s *sqlInfo) startPosition() issuePosition {
+	return s.position(len(s.formattedStmt) - len(strings.TrimLeft(s.formattedStmt, " \t\n")))
 }
 
 var (
-	anything                     = `.*`
-	ws                           = `[\s\n\t]+`
-	optionalWS                   = `[\s\n\t]*` //optional white spaces
-	ident                        = `[a-zA-Z0-9_."]+`
-	ifExists                     = opt("IF", "EXISTS")
-	ifNotExists                  = opt("IF", "NOT", "EXISTS")
-	optionalCommaSeperatedTokens = `[^,]+(?:,[^,]+){0,}`
-	commaSeperatedTokens         = `[^,]+(?:,[^,]+){1,}`
-	unqualifiedIdent             = `[a-zA-Z0-9_]+`
-	supportedExtensionsOnYB      = []string{
+	anything                = `.*`
+	ws                      = `[\s\n\t]+`
+	optionalWS              = `[\s\n\t]*` //optional white spaces
+	ident                   = `[a-zA-Z0-9_."]+`
+	ifNotExists             = opt("IF", "NOT", "EXISTS")
+	supportedExtensionsOnYB = []string{
 		"adminpack", "amcheck", "autoinc", "bloom", "btree_gin", "btree_gist", "citext", "cube",
 		"dblink", "dict_int", "dict_xsyn", "earthdistance", "file_fdw", "fuzzystrmatch", "hll", "hstore",
 		"hypopg", "insert_username", "intagg", "intarray", "isn", "lo", "ltree", "moddatetime",
@@ -72,6 +100,16 @@ var (
 	}
 )
 
+// Templates of the reasons of the issues which name a part of the object, in the format of fmt.Sprintf with
+// a single %s. The reports which group the issues, like the rules of the SARIF report, use the templates.
+const (
+	STORED_GENERATED_COLUMN_REASON = "Stored generated column is not supported. Column is: %s"
+	UNSUPPORTED_DATATYPE_REASON    = "%s datatype doesn't have a mapping in YugabyteDB"
+	UNPARSED_STMT_REASON           = "Unable to parse the SQL statement: %s"
+)
+
+var issueReasonTemplates = []string{STORED_GENERATED_COLUMN_REASON, UNSUPPORTED_DATATYPE_REASON, UNPARSED_STMT_REASON}
+
 func cat(tokens ...string) string {
 	str := ""
 	for idx, token := range tokens {
@@ -107,10 +145,6 @@ func re(tokens ...string) *regexp.Regexp {
 	return regexp.MustCompile(s)
 }
 
-func parenth(s string) string {
-	return `\(` + s + `\)`
-}
-
 var (
 	outputFormat  string
 	sourceObjList []string
@@ -119,92 +153,34 @@ var (
 	// key is partitioned table, value is filename where the ADD PRIMARY KEY statement resides
 	primaryCons      = make(map[string]string)
 	summaryMap       = make(map[string]*summaryInfo)
-	multiRegex       = regexp.MustCompile(`([a-zA-Z0-9_\.]+[,|;])`)
 	dollarQuoteRegex = regexp.MustCompile(`(\$.*\$)`)
-	//TODO: optional but replace every possible space or new line char with [\s\n]+ in all regexs
-	createConvRegex       = re("CREATE", opt("DEFAULT"), optionalWS, "CONVERSION", capture(ident))
-	alterConvRegex        = re("ALTER", "CONVERSION", capture(ident))
-	gistRegex             = re("CREATE", "INDEX", ifNotExists, capture(ident), "ON", capture(ident), anything, "USING", "GIST")
-	brinRegex             = re("CREATE", "INDEX", ifNotExists, capture(ident), "ON", capture(ident), anything, "USING", "brin")
-	spgistRegex           = re("CREATE", "INDEX", ifNotExists, capture(ident), "ON", capture(ident), anything, "USING", "spgist")
-	rtreeRegex            = re("CREATE", "INDEX", ifNotExists, capture(ident), "ON", capture(ident), anything, "USING", "rtree")
-	ginRegex              = re("CREATE", "INDEX", ifNotExists, capture(ident), "ON", capture(ident), anything, "USING", "GIN", capture(optionalCommaSeperatedTokens))
-	viewWithCheckRegex    = re("VIEW", capture(ident), anything, "WITH", "CHECK", "OPTION")
-	rangeRegex            = re("PRECEDING", "and", anything, ":float")
-	fetchRegex            = re("FETCH", anything, "FROM")
-	fetchRelativeRegex    = re("FETCH", "RELATIVE")
-	backwardRegex         = re("MOVE", "BACKWARD")
-	fetchAbsRegex         = re("FETCH", "ABSOLUTE")
-	alterAggRegex         = re("ALTER", "AGGREGATE", capture(ident))
-	dropCollRegex         = re("DROP", "COLLATION", ifExists, capture(commaSeperatedTokens))
-	dropIdxRegex          = re("DROP", "INDEX", ifExists, capture(commaSeperatedTokens))
-	dropViewRegex         = re("DROP", "VIEW", ifExists, capture(commaSeperatedTokens))
-	dropSeqRegex          = re("DROP", "SEQUENCE", ifExists, capture(commaSeperatedTokens))
-	dropForeignRegex      = re("DROP", "FOREIGN", "TABLE", ifExists, capture(commaSeperatedTokens))
-	dropIdxConcurRegex    = re("DROP", "INDEX", "CONCURRENTLY", ifExists, capture(ident))
-	trigRefRegex          = re("CREATE", "TRIGGER", capture(ident), anything, "REFERENCING")
-	constrTrgRegex        = re("CREATE", "CONSTRAINT", "TRIGGER", capture(ident))
-	currentOfRegex        = re("WHERE", "CURRENT", "OF")
-	amRegex               = re("CREATE", "ACCESS", "METHOD", capture(ident))
-	idxConcRegex          = re("REINDEX", anything, capture(ident))
-	storedRegex           = re(capture(unqualifiedIdent), capture(unqualifiedIdent), "GENERATED", "ALWAYS", anything, "STORED")
-	partitionColumnsRegex = re("CREATE", "TABLE", ifNotExists, capture(ident), parenth(capture(optionalCommaSeperatedTokens)), "PARTITION BY", capture("[A-Za-z]+"), parenth(capture(optionalCommaSeperatedTokens)))
-	likeAllRegex          = re("CREATE", "TABLE", ifNotExists, capture(ident), anything, "LIKE", anything, "INCLUDING ALL")
-	likeRegex             = re("CREATE", "TABLE", ifNotExists, capture(ident), anything, `\(LIKE`)
-	inheritRegex          = re("CREATE", opt(capture(unqualifiedIdent)), "TABLE", ifNotExists, capture(ident), anything, "INHERITS", "[ |(]")
-	withOidsRegex         = re("CREATE", "TABLE", ifNotExists, capture(ident), anything, "WITH", anything, "OIDS")
-	intvlRegex            = re("CREATE", "TABLE", ifNotExists, capture(ident)+`\(`, anything, "interval", "PRIMARY")
-	anydataRegex          = re("CREATE", "TABLE", ifNotExists, capture(ident), anything, "AnyData", anything)
-	anydatasetRegex       = re("CREATE", "TABLE", ifNotExists, capture(ident), anything, "AnyDataSet", anything)
-	anyTypeRegex          = re("CREATE", "TABLE", ifNotExists, capture(ident), anything, "AnyType", anything)
-	uriTypeRegex          = re("CREATE", "TABLE", ifNotExists, capture(ident), anything, "URIType", anything)
-	//super user role required, language c is errored as unsafe
-	cLangRegex = re("CREATE", opt("OR REPLACE"), "FUNCTION", capture(ident), anything, "language c")
-
-	alterOfRegex                    = re("ALTER", "TABLE", opt("ONLY"), ifExists, capture(ident), anything, "OF", anything)
-	alterSchemaRegex                = re("ALTER", "TABLE", opt("ONLY"), ifExists, capture(ident), anything, "SET SCHEMA")
-	createSchemaRegex               = re("CREATE", "SCHEMA", anything, "CREATE", "TABLE")
-	alterNotOfRegex                 = re("ALTER", "TABLE", opt("ONLY"), ifExists, capture(ident), anything, "NOT OF")
-	alterColumnStatsRegex           = re("ALTER", "TABLE", opt("ONLY"), ifExists, capture(ident), anything, "ALTER", "COLUMN", capture(ident), anything, "SET STATISTICS")
-	alterColumnStorageRegex         = re("ALTER", "TABLE", opt("ONLY"), ifExists, capture(ident), anything, "ALTER", "COLUMN", capture(ident), anything, "SET STORAGE")
-	alterColumnSetAttributesRegex   = re("ALTER", "TABLE", opt("ONLY"), ifExists, capture(ident), anything, "ALTER", "COLUMN", capture(ident), anything, "SET", `\(`)
-	alterColumnResetAttributesRegex = re("ALTER", "TABLE", opt("ONLY"), ifExists, capture(ident), anything, "ALTER", "COLUMN", capture(ident), anything, "RESET", anything)
-	alterConstrRegex                = re("ALTER", opt(capture(unqualifiedIdent)), ifExists, "TABLE", capture(ident), anything, "ALTER", "CONSTRAINT")
-	setOidsRegex                    = re("ALTER", opt(capture(unqualifiedIdent)), "TABLE", ifExists, capture(ident), anything, "SET WITH OIDS")
-	clusterRegex                    = re("ALTER", "TABLE", opt("ONLY"), ifExists, capture(ident), anything, "CLUSTER")
-	withoutClusterRegex             = re("ALTER", "TABLE", opt("ONLY"), ifExists, capture(ident), anything, "SET WITHOUT CLUSTER")
-	alterSetRegex                   = re("ALTER", "TABLE", opt("ONLY"), ifExists, capture(ident), "SET")
-	alterIdxRegex                   = re("ALTER", "INDEX", capture(ident), "SET")
-	alterResetRegex                 = re("ALTER", "TABLE", opt("ONLY"), ifExists, capture(ident), "RESET")
-	alterOptionsRegex               = re("ALTER", opt(capture(unqualifiedIdent)), "TABLE", ifExists, capture(ident), "OPTIONS")
-	alterInhRegex                   = re("ALTER", opt(capture(unqualifiedIdent)), "TABLE", ifExists, capture(ident), "INHERIT")
-	valConstrRegex                  = re("ALTER", opt(capture(unqualifiedIdent)), "TABLE", ifExists, capture(ident), "VALIDATE CONSTRAINT")
-	deferRegex                      = re("ALTER", opt(capture(unqualifiedIdent)), "TABLE", ifExists, capture(ident), anything, "UNIQUE", anything, "deferrable")
-	alterViewRegex                  = re("ALTER", "VIEW", capture(ident))
-	dropAttrRegex                   = re("ALTER", "TYPE", capture(ident), "DROP ATTRIBUTE")
-	alterTypeRegex                  = re("ALTER", "TYPE", capture(ident))
-	alterTblSpcRegex                = re("ALTER", "TABLESPACE", capture(ident), "SET")
-
-	// table partition. partitioned table is the key in tblParts map
-	tblPartitionRegex = re("CREATE", "TABLE", ifNotExists, capture(ident), anything, "PARTITION", "OF", capture(ident))
-	addPrimaryRegex   = re("ALTER", "TABLE", opt("ONLY"), ifExists, capture(ident), anything, "ADD PRIMARY KEY")
-	primRegex         = re("CREATE", "FOREIGN", "TABLE", capture(ident)+`\(`, anything, "PRIMARY KEY")
-	foreignKeyRegex   = re("CREATE", "FOREIGN", "TABLE", capture(ident)+`\(`, anything, "REFERENCES", anything)
-
-	// unsupported SQLs exported by ora2pg
-	compoundTrigRegex          = re("CREATE", opt("OR REPLACE"), "TRIGGER", capture(ident), anything, "COMPOUND", anything)
+
+	// comments added by ora2pg for the SQLs it could not convert
 	unsupportedCommentRegex1   = re("--", anything, "(unsupported)")
 	packageSupportCommentRegex = re("--", anything, "Oracle package ", "'"+capture(ident)+"'", anything, "please edit to match PostgreSQL syntax")
 	unsupportedCommentRegex2   = re("--", anything, "please edit to match PostgreSQL syntax")
 	typeUnsupportedRegex       = re("Inherited types are not supported", anything, "replacing with inherited table")
-	bulkCollectRegex           = re("BULK COLLECT") // ora2pg unable to convert this oracle feature into a PostgreSQL compatible syntax
-	jsonFuncRegex              = re("CREATE", opt("OR REPLACE"), capture(unqualifiedIdent), capture(ident), anything, "JSON_ARRAYAGG")
+
+	// datatypes exported by ora2pg as is, keyed by their lower-cased names
+	unsupportedDatatypes = map[string]string{
+		"anydata":    "AnyData",
+		"anydataset": "AnyDataSet",
+		"anytype":    "AnyType",
+		"uritype":    "URIType",
+	}
 )
 
 // Reports one case in JSON
 func reportCase(filePath string, reason string, ghIssue string, suggestion string, objType string, objName string, sqlStmt string) {
+	reportCaseAt(issuePosition{}, filePath, reason, ghIssue, suggestion, objType, objName, sqlStmt)
+}
+
+// Reports one case in JSON along with its position in the file
+func reportCaseAt(pos issuePosition, filePath string, reason string, ghIssue string, suggestion string, objType string, objName string, sqlStmt string) {
 	var issue utils.Issue
 	issue.FilePath = filePath
+	issue.Line = pos.line
+	issue.Column = pos.column
 	issue.Reason = reason
 	issue.GH = ghIssue
 	issue.Suggestion = suggestion
@@ -215,8 +191,8 @@ func reportCase(filePath string, reason string, ghIssue string, suggestion strin
 	reportStruct.Issues = append(reportStruct.Issues, issue)
 }
 
-func reportAddingPrimaryKey(fpath string, tbl string, line string) {
-	reportCase(fpath, "Adding primary key to a partitioned table is not yet implemented.",
+func reportAddingPrimaryKey(pos issuePosition, fpath string, tbl string, line string) {
+	reportCaseAt(pos, fpath, "Adding primary key to a partitioned table is not yet implemented.",
 		"https://github.com/yugabyte/yugabyte-db/issues/10074", "", "", tbl, line)
 }
 
@@ -294,375 +270,792 @@ func addSummaryDetailsForIndexes() {
 	}
 }
 
-// Checks Whether there is a GIN index
+// stmtChecker checks one statement parsed from a schema file. The statements embedded in function bodies
+// are checked by a stmtChecker of their own, which attributes the issues to the function.
+type stmtChecker struct {
+	fpath   string
+	sqlInfo *sqlInfo
+	// Type of the objects in the schema file
+	objType string
+	// Offset in sqlInfo.formattedStmt of the text the statement was parsed from
+	textOffset int
+	// To be subtracted from the locations in the parse tree, for text which was prefixed before parsing
+	shift int
+	// Offset in sqlInfo.formattedStmt where the statement starts
+	stmtStart int
+	// Function whose body contains the statement, if any
+	funcType string
+	funcName string
+}
+
+// Returns the offset in sqlInfo.formattedStmt of a location in the parse tree
+func (c *stmtChecker) offset(location int) int {
+	if location < 0 {
+		return c.stmtStart
+	}
+	return c.textOffset + location - c.shift
+}
+
+func (c *stmtChecker) report(location int, reason string, ghIssue string, suggestion string, objType string, objName string) {
+	if c.funcName != "" {
+		objType, objName = c.funcType, c.funcName
+	}
+	reportCaseAt(c.sqlInfo.position(c.offset(location)), c.fpath, reason, ghIssue, suggestion, objType, objName, c.sqlInfo.formattedStmt)
+}
+
+// Marks the object being created by the statement, or the function containing it, as invalid in the summary
+func (c *stmtChecker) markInvalid(objType string) {
+	objName := c.sqlInfo.objName
+	if c.funcName != "" {
+		objType, objName = c.funcType, c.funcName
+	}
+	if summaryMap[objType] != nil {
+		summaryMap[objType].invalidCount[objName] = true
+	}
+}
+
+func (c *stmtChecker) check(stmt *pg_query.Node) {
+	checkViews(c, stmt)
+	checkSql(c, stmt)
+	checkGist(c, stmt)
+	checkGin(c, stmt)
+	checkDDL(c, stmt)
+	checkForeign(c, stmt)
+}
+
+// Checks whether there is a GIN index
 /*
 Following type of SQL queries are being taken care of by this function -
 	1. CREATE INDEX index_name ON table_name USING gin(column1, column2 ...)
-	2. CREATE INDEX index_name ON table_name USING gin(column1 [ASC/DESC/HASH])
-	3. CREATE EXTENSION btree_gin;
+	2. CREATE INDEX index_name ON table_name USING gin(column1 [ASC/DESC])
 */
-func checkGin(sqlInfoArr []sqlInfo, fpath string) {
-	for _, sqlInfo := range sqlInfoArr {
-		matchGin := ginRegex.FindStringSubmatch(sqlInfo.stmt)
-		if matchGin != nil {
-			columnsFromGin := strings.Trim(matchGin[4], `()`)
-			columnList := strings.Split(columnsFromGin, ",")
-			if len(columnList) > 1 {
-				reportCase(fpath, "Schema contains gin index on multi column which is not supported.",
-					"https://github.com/yugabyte/yugabyte-db/issues/7850", "", "INDEX", matchGin[2], sqlInfo.formattedStmt)
-			} else {
-				if strings.Contains(strings.ToUpper(columnList[0]), "ASC") || strings.Contains(strings.ToUpper(columnList[0]), "DESC") || strings.Contains(strings.ToUpper(columnList[0]), "HASH") {
-					reportCase(fpath, "Schema contains gin index on column with ASC/DESC/HASH Clause which is not supported.",
-						"https://github.com/yugabyte/yugabyte-db/issues/7850", "", "INDEX", matchGin[2], sqlInfo.formattedStmt)
-				}
-			}
-		}
-		if strings.Contains(strings.ToLower(sqlInfo.stmt), "using gin") {
-			summaryMap["INDEX"].details["There are some gin indexes present in the schema, but gin indexes are partially supported in YugabyteDB as mentioned in (https://github.com/yugabyte/yugabyte-db/issues/7850) so take a look and modify them if not supported."] = true
-		}
+func checkGin(c *stmtChecker, stmt *pg_query.Node) {
+	index := stmt.GetIndexStmt()
+	if index == nil || !strings.EqualFold(index.AccessMethod, "gin") {
+		return
+	}
+	if len(index.IndexParams) > 1 {
+		c.report(-1, "Schema contains gin index on multi column which is not supported.",
+			"https://github.com/yugabyte/yugabyte-db/issues/7850", "", "INDEX", queryparser.QuoteIdentifier(index.Idxname))
+	} else if len(index.IndexParams) == 1 && index.IndexParams[0].GetIndexElem().GetOrdering() != pg_query.SortByDir_SORTBY_DEFAULT {
+		c.report(-1, "Schema contains gin index on column with ASC/DESC/HASH Clause which is not supported.",
+			"https://github.com/yugabyte/yugabyte-db/issues/7850", "", "INDEX", queryparser.QuoteIdentifier(index.Idxname))
+	}
+	if summaryMap["INDEX"] != nil {
+		summaryMap["INDEX"].details["There are some gin indexes present in the schema, but gin indexes are partially supported in YugabyteDB as mentioned in (https://github.com/yugabyte/yugabyte-db/issues/7850) so take a look and modify them if not supported."] = true
 	}
 }
 
 // Checks whether there is gist index
-func checkGist(sqlInfoArr []sqlInfo, fpath string) {
-	for _, sqlInfo := range sqlInfoArr {
-		if idx := gistRegex.FindStringSubmatch(sqlInfo.stmt); idx != nil {
-			reportCase(fpath, "Schema contains gist index which is not supported.",
-				"https://github.com/YugaByte/yugabyte-db/issues/1337", "", "INDEX", idx[2], sqlInfo.formattedStmt)
-		} else if idx := brinRegex.FindStringSubmatch(sqlInfo.stmt); idx != nil {
-			reportCase(fpath, "index method 'brin' not supported yet.",
-				"https://github.com/YugaByte/yugabyte-db/issues/1337", "", "INDEX", idx[2], sqlInfo.formattedStmt)
-		} else if idx := spgistRegex.FindStringSubmatch(sqlInfo.stmt); idx != nil {
-			reportCase(fpath, "index method 'spgist' not supported yet.",
-				"https://github.com/YugaByte/yugabyte-db/issues/1337", "", "INDEX", idx[2], sqlInfo.formattedStmt)
-		} else if idx := rtreeRegex.FindStringSubmatch(sqlInfo.stmt); idx != nil {
-			reportCase(fpath, "index method 'rtree' is superceded by 'gist' which is not supported yet.",
-				"https://github.com/YugaByte/yugabyte-db/issues/1337", "", "INDEX", idx[2], sqlInfo.formattedStmt)
-		}
+func checkGist(c *stmtChecker, stmt *pg_query.Node) {
+	index := stmt.GetIndexStmt()
+	if index == nil {
+		return
+	}
+	var reason string
+	switch strings.ToLower(index.AccessMethod) {
+	case "gist":
+		reason = "Schema contains gist index which is not supported."
+	case "brin":
+		reason = "index method 'brin' not supported yet."
+	case "spgist":
+		reason = "index method 'spgist' not supported yet."
+	case "rtree":
+		reason = "index method 'rtree' is superceded by 'gist' which is not supported yet."
+	default:
+		return
 	}
+	c.report(-1, reason, "https://github.com/YugaByte/yugabyte-db/issues/1337", "", "INDEX", queryparser.QuoteIdentifier(index.Idxname))
 }
 
 // Checks compatibility of views
-func checkViews(sqlInfoArr []sqlInfo, fpath string) {
-	for _, sqlInfo := range sqlInfoArr {
-		/*if dropMatViewRegex.MatchString(sqlInfo.stmt) {
-			reportCase(fpath, "DROP MATERIALIZED VIEW not supported yet.",a
-				"https://github.com/YugaByte/yugabyte-db/issues/10102", "")
-		} else if view := matViewRegex.FindStringSubmatch(sqlInfo.stmt); view != nil {
-			reportCase(fpath, "Schema contains materialized view which is not supported. The view is: "+view[1],
-				"https://github.com/yugabyte/yugabyte-db/issues/10102", "")
-		} else */
-		if view := viewWithCheckRegex.FindStringSubmatch(sqlInfo.stmt); view != nil {
-			reportCase(fpath, "Schema containing VIEW WITH CHECK OPTION is not supported yet.", "", "", "VIEW", view[1], sqlInfo.formattedStmt)
+func checkViews(c *stmtChecker, stmt *pg_query.Node) {
+	view := stmt.GetViewStmt()
+	if view == nil {
+		return
+	}
+	if view.WithCheckOption == pg_query.ViewCheckOption_LOCAL_CHECK_OPTION || view.WithCheckOption == pg_query.ViewCheckOption_CASCADED_CHECK_OPTION {
+		c.report(-1, "Schema containing VIEW WITH CHECK OPTION is not supported yet.", "", "", "VIEW", queryparser.RangeVarName(view.View))
+	}
+}
+
+// Separates the DROP statement of multiple objects into multiple statements which are accepted by YB.
+func separateMultiObj(dropStmt *pg_query.DropStmt) string {
+	var stmts []string
+	for _, object := range dropStmt.Objects {
+		singleObjDropStmt := proto.Clone(dropStmt).(*pg_query.DropStmt)
+		singleObjDropStmt.Objects = []*pg_query.Node{object}
+		stmt, err := pg_query.Deparse(&pg_query.ParseResult{Stmts: []*pg_query.RawStmt{
+			{Stmt: &pg_query.Node{Node: &pg_query.Node_DropStmt{DropStmt: singleObjDropStmt}}},
+		}})
+		if err != nil {
+			log.Warnf("deparse DROP statement: %v", err)
+			return ""
 		}
+		stmts = append(stmts, stmt+";")
 	}
+	return strings.Join(stmts, " ")
 }
 
-// Separates the input line into multiple statements which are accepted by YB.
-func separateMultiObj(objType string, line string) string {
-	indexes := multiRegex.FindAllStringSubmatchIndex(line, -1)
-	suggestion := ""
-	for _, match := range indexes {
-		start := match[2]
-		end := match[3]
-		obj := strings.Replace(line[start:end], ",", ";", -1)
-		suggestion += objType + " " + obj
+// Window frame option bits from PostgreSQL's nodes/parsenodes.h
+const (
+	FRAMEOPTION_RANGE                  = 0x00002
+	FRAMEOPTION_START_OFFSET_PRECEDING = 0x00800
+	FRAMEOPTION_END_OFFSET_PRECEDING   = 0x01000
+	FRAMEOPTION_START_OFFSET_FOLLOWING = 0x02000
+	FRAMEOPTION_END_OFFSET_FOLLOWING   = 0x04000
+	FRAMEOPTION_OFFSET                 = FRAMEOPTION_START_OFFSET_PRECEDING | FRAMEOPTION_END_OFFSET_PRECEDING | FRAMEOPTION_START_OFFSET_FOLLOWING | FRAMEOPTION_END_OFFSET_FOLLOWING
+)
+
+// Returns true for RANGE window frames with an offset of type double precision
+func isRangeWithFloatOffset(window *pg_query.WindowDef) bool {
+	if window.FrameOptions&FRAMEOPTION_RANGE == 0 || window.FrameOptions&FRAMEOPTION_OFFSET == 0 {
+		return false
+	}
+	for _, offset := range []*pg_query.Node{window.StartOffset, window.EndOffset} {
+		if typeCast := offset.GetTypeCast(); typeCast != nil && slices.Contains([]string{"float4", "float8"}, queryparser.TypeName(typeCast.TypeName)) {
+			return true
+		}
 	}
-	return suggestion
+	return false
+}
+
+// Returns the type and name of the object altered by statements like ALTER ... RENAME, ALTER ... OWNER TO and
+// ALTER ... SET SCHEMA which are common to many object types
+func getAlteredObject(stmt *pg_query.Node) (pg_query.ObjectType, string, bool) {
+	switch {
+	case stmt.GetRenameStmt() != nil:
+		rename := stmt.GetRenameStmt()
+		objType := rename.RenameType
+		if objType == pg_query.ObjectType_OBJECT_COLUMN || objType == pg_query.ObjectType_OBJECT_ATTRIBUTE || objType == pg_query.ObjectType_OBJECT_TABCONSTRAINT {
+			objType = rename.RelationType
+		}
+		if rename.Relation != nil {
+			return objType, queryparser.RangeVarName(rename.Relation), true
+		}
+		return objType, queryparser.ObjectName(rename.Object), true
+	case stmt.GetAlterOwnerStmt() != nil:
+		alterOwner := stmt.GetAlterOwnerStmt()
+		if alterOwner.Relation != nil {
+			return alterOwner.ObjectType, queryparser.RangeVarName(alterOwner.Relation), true
+		}
+		return alterOwner.ObjectType, queryparser.ObjectName(alterOwner.Object), true
+	case stmt.GetAlterObjectSchemaStmt() != nil:
+		alterSchema := stmt.GetAlterObjectSchemaStmt()
+		if alterSchema.Relation != nil {
+			return alterSchema.ObjectType, queryparser.RangeVarName(alterSchema.Relation), true
+		}
+		return alterSchema.ObjectType, queryparser.ObjectName(alterSchema.Object), true
+	}
+	return pg_query.ObjectType_OBJECT_TYPE_UNDEFINED, "", false
 }
 
 // Checks compatibility of SQL statements
-func checkSql(sqlInfoArr []sqlInfo, fpath string) {
-	for _, sqlInfo := range sqlInfoArr {
-		if rangeRegex.MatchString(sqlInfo.stmt) {
-			reportCase(fpath,
+func checkSql(c *stmtChecker, stmt *pg_query.Node) {
+	if objType, objName, ok := getAlteredObject(stmt); ok {
+		switch objType {
+		case pg_query.ObjectType_OBJECT_CONVERSION:
+			c.report(-1, "ALTER CONVERSION not supported yet", "https://github.com/YugaByte/yugabyte-db/issues/10866", "", "CONVERSION", objName)
+		case pg_query.ObjectType_OBJECT_AGGREGATE:
+			c.report(-1, "ALTER AGGREGATE not supported yet.",
+				"https://github.com/YugaByte/yugabyte-db/issues/2717", "", "AGGREGATE", objName)
+		}
+	}
+
+	switch {
+	case stmt.GetCreateConversionStmt() != nil:
+		c.report(-1, "CREATE CONVERSION not supported yet", "https://github.com/YugaByte/yugabyte-db/issues/10866", "", "CONVERSION",
+			queryparser.NameFromList(stmt.GetCreateConversionStmt().ConversionName))
+	case stmt.GetFetchStmt() != nil:
+		fetch := stmt.GetFetchStmt()
+		switch {
+		case fetch.Direction == pg_query.FetchDirection_FETCH_ABSOLUTE:
+			c.report(-1, "FETCH ABSOLUTE not supported yet", "https://github.com/YugaByte/yugabyte-db/issues/6514", "", "CURSOR", "")
+		case fetch.Direction == pg_query.FetchDirection_FETCH_RELATIVE:
+			c.report(-1, "FETCH RELATIVE not supported yet", "https://github.com/YugaByte/yugabyte-db/issues/6514", "", "CURSOR", "")
+		case fetch.Ismove && fetch.Direction == pg_query.FetchDirection_FETCH_BACKWARD:
+			c.report(-1, "FETCH BACKWARD not supported yet", "https://github.com/YugaByte/yugabyte-db/issues/6514", "", "CURSOR", "")
+		case !fetch.Ismove:
+			c.report(-1, "FETCH - not supported yet", "https://github.com/YugaByte/yugabyte-db/issues/6514", "", "CURSOR", "")
+		}
+	case stmt.GetDropStmt() != nil:
+		drop := stmt.GetDropStmt()
+		objTypes := map[pg_query.ObjectType]string{
+			pg_query.ObjectType_OBJECT_COLLATION:     "COLLATION",
+			pg_query.ObjectType_OBJECT_INDEX:         "INDEX",
+			pg_query.ObjectType_OBJECT_VIEW:          "VIEW",
+			pg_query.ObjectType_OBJECT_SEQUENCE:      "SEQUENCE",
+			pg_query.ObjectType_OBJECT_FOREIGN_TABLE: "FOREIGN TABLE",
+		}
+		if objType, ok := objTypes[drop.RemoveType]; ok && len(drop.Objects) > 1 {
+			c.report(-1, "DROP multiple objects not supported yet.",
+				"https://github.com/YugaByte/yugabyte-db/issues/880", separateMultiObj(drop), objType, "")
+		} else if drop.RemoveType == pg_query.ObjectType_OBJECT_INDEX && drop.Concurrent {
+			c.report(-1, "DROP INDEX CONCURRENTLY not supported yet",
+				"", "", "INDEX", queryparser.ObjectName(drop.Objects[0]))
+		}
+	case stmt.GetCreateTrigStmt() != nil:
+		trigger := stmt.GetCreateTrigStmt()
+		if len(trigger.TransitionRels) > 0 {
+			c.report(-1, "REFERENCING clause (transition tables) not supported yet.",
+				"https://github.com/YugaByte/yugabyte-db/issues/1668", "", "TRIGGER", queryparser.QuoteIdentifier(trigger.Trigname))
+		}
+		if trigger.Isconstraint {
+			c.report(-1, "CREATE CONSTRAINT TRIGGER not supported yet.",
+				"https://github.com/YugaByte/yugabyte-db/issues/1709", "", "TRIGGER", queryparser.QuoteIdentifier(trigger.Trigname))
+		}
+	}
+
+	queryparser.Walk(stmt, func(node *pg_query.Node) bool {
+		switch {
+		case node.GetCurrentOfExpr() != nil:
+			c.report(-1, "WHERE CURRENT OF not supported yet", "https://github.com/YugaByte/yugabyte-db/issues/737", "", "CURSOR", "")
+		case node.GetWindowDef() != nil && isRangeWithFloatOffset(node.GetWindowDef()):
+			c.report(int(node.GetWindowDef().Location),
 				"RANGE with offset PRECEDING/FOLLOWING is not supported for column type numeric and offset type double precision",
-				"https://github.com/yugabyte/yugabyte-db/issues/10692", "", "TABLE", "", sqlInfo.formattedStmt)
-		} else if stmt := createConvRegex.FindStringSubmatch(sqlInfo.stmt); stmt != nil {
-			reportCase(fpath, "CREATE CONVERSION not supported yet", "https://github.com/YugaByte/yugabyte-db/issues/10866", "", "CONVERSION", stmt[2], sqlInfo.formattedStmt)
-		} else if stmt := alterConvRegex.FindStringSubmatch(sqlInfo.stmt); stmt != nil {
-			reportCase(fpath, "ALTER CONVERSION not supported yet", "https://github.com/YugaByte/yugabyte-db/issues/10866", "", "CONVERSION", stmt[1], sqlInfo.formattedStmt)
-		} else if fetchAbsRegex.MatchString(sqlInfo.stmt) {
-			reportCase(fpath, "FETCH ABSOLUTE not supported yet", "https://github.com/YugaByte/yugabyte-db/issues/6514", "", "CURSOR", "", sqlInfo.formattedStmt)
-		} else if fetchRelativeRegex.MatchString(sqlInfo.stmt) {
-			reportCase(fpath, "FETCH RELATIVE not supported yet", "https://github.com/YugaByte/yugabyte-db/issues/6514", "", "CURSOR", "", sqlInfo.formattedStmt)
-		} else if fetchRegex.MatchString(sqlInfo.stmt) {
-			reportCase(fpath, "FETCH - not supported yet", "https://github.com/YugaByte/yugabyte-db/issues/6514", "", "CURSOR", "", sqlInfo.formattedStmt)
-		} else if backwardRegex.MatchString(sqlInfo.stmt) {
-			reportCase(fpath, "FETCH BACKWARD not supported yet", "https://github.com/YugaByte/yugabyte-db/issues/6514", "", "CURSOR", "", sqlInfo.formattedStmt)
-		} else if stmt := alterAggRegex.FindStringSubmatch(sqlInfo.stmt); stmt != nil {
-			reportCase(fpath, "ALTER AGGREGATE not supported yet.",
-				"https://github.com/YugaByte/yugabyte-db/issues/2717", "", "AGGREGATE", stmt[1], sqlInfo.formattedStmt)
-		} else if dropCollRegex.MatchString(sqlInfo.stmt) {
-			reportCase(fpath, "DROP multiple objects not supported yet.",
-				"https://github.com/YugaByte/yugabyte-db/issues/880", separateMultiObj("DROP COLLATION", sqlInfo.formattedStmt), "COLLATION", "", sqlInfo.formattedStmt)
-		} else if dropIdxRegex.MatchString(sqlInfo.stmt) {
-			reportCase(fpath, "DROP multiple objects not supported yet.",
-				"https://github.com/YugaByte/yugabyte-db/issues/880", separateMultiObj("DROP INDEX", sqlInfo.formattedStmt), "INDEX", "", sqlInfo.formattedStmt)
-		} else if dropViewRegex.MatchString(sqlInfo.stmt) {
-			reportCase(fpath, "DROP multiple objects not supported yet.",
-				"https://github.com/YugaByte/yugabyte-db/issues/880", separateMultiObj("DROP VIEW", sqlInfo.formattedStmt), "VIEW", "", sqlInfo.formattedStmt)
-		} else if dropSeqRegex.MatchString(sqlInfo.stmt) {
-			reportCase(fpath, "DROP multiple objects not supported yet.",
-				"https://github.com/YugaByte/yugabyte-db/issues/880", separateMultiObj("DROP SEQUENCE", sqlInfo.formattedStmt), "SEQUENCE", "", sqlInfo.formattedStmt)
-		} else if dropForeignRegex.MatchString(sqlInfo.stmt) {
-			reportCase(fpath, "DROP multiple objects not supported yet.",
-				"https://github.com/YugaByte/yugabyte-db/issues/880", separateMultiObj("DROP FOREIGN TABLE", sqlInfo.formattedStmt), "FOREIGN TABLE", "", sqlInfo.formattedStmt)
-		} else if idx := dropIdxConcurRegex.FindStringSubmatch(sqlInfo.stmt); idx != nil {
-			reportCase(fpath, "DROP INDEX CONCURRENTLY not supported yet",
-				"", "", "INDEX", idx[2], sqlInfo.formattedStmt)
-		} else if trig := trigRefRegex.FindStringSubmatch(sqlInfo.stmt); trig != nil {
-			reportCase(fpath, "REFERENCING clause (transition tables) not supported yet.",
-				"https://github.com/YugaByte/yugabyte-db/issues/1668", "", "TRIGGER", trig[1], sqlInfo.formattedStmt)
-		} else if trig := constrTrgRegex.FindStringSubmatch(sqlInfo.stmt); trig != nil {
-			reportCase(fpath, "CREATE CONSTRAINT TRIGGER not supported yet.",
-				"https://github.com/YugaByte/yugabyte-db/issues/1709", "", "TRIGGER", trig[1], sqlInfo.formattedStmt)
-		} else if currentOfRegex.MatchString(sqlInfo.stmt) {
-			reportCase(fpath, "WHERE CURRENT OF not supported yet", "https://github.com/YugaByte/yugabyte-db/issues/737", "", "CURSOR", "", sqlInfo.formattedStmt)
-		} else if bulkCollectRegex.MatchString(sqlInfo.stmt) {
-			reportCase(fpath, "BULK COLLECT keyword of oracle is not converted into PostgreSQL compatible syntax", "", "", "", "", sqlInfo.formattedStmt)
+				"https://github.com/yugabyte/yugabyte-db/issues/10692", "", "TABLE", c.sqlInfo.objName)
+		case node.GetJsonArrayAgg() != nil:
+			c.report(int(node.GetJsonArrayAgg().GetConstructor().GetLocation()), "JSON_ARRAYAGG() function is not available in YugabyteDB", "",
+				`Rename the function to YugabyteDB's equivalent JSON_AGG()`, c.objType, c.sqlInfo.objName)
 		}
+		return true
+	})
+}
+
+// Returns true if the boolean option, like oids in WITH (oids = true), is enabled
+func isOptionEnabled(option *pg_query.DefElem) bool {
+	switch {
+	case option.Arg == nil:
+		return true
+	case option.Arg.GetString_() != nil:
+		return slices.Contains([]string{"true", "on", "yes", "1"}, strings.ToLower(option.Arg.GetString_().Sval))
+	case option.Arg.GetInteger() != nil:
+		return option.Arg.GetInteger().Ival != 0
+	case option.Arg.GetBoolean() != nil:
+		return option.Arg.GetBoolean().Boolval
 	}
+	return false
 }
 
-// Checks unsupported DDL statements
-func checkDDL(sqlInfoArr []sqlInfo, fpath string) {
+// Returns the columns of the primary key of the table being created along with the location of its definition
+func getPrimaryKeyColumns(createTable *pg_query.CreateStmt) ([]string, int) {
+	for _, element := range createTable.TableElts {
+		if column := element.GetColumnDef(); column != nil {
+			for _, constraint := range column.Constraints {
+				if constraint.GetConstraint().GetContype() == pg_query.ConstrType_CONSTR_PRIMARY {
+					return []string{column.Colname}, int(constraint.GetConstraint().Location)
+				}
+			}
+		} else if constraint := element.GetConstraint(); constraint != nil && constraint.Contype == pg_query.ConstrType_CONSTR_PRIMARY {
+			var columns []string
+			for _, key := range constraint.Keys {
+				columns = append(columns, key.GetString_().GetSval())
+			}
+			return columns, int(constraint.Location)
+		}
+	}
+	return nil, -1
+}
 
-	for _, sqlInfo := range sqlInfoArr {
-		if am := amRegex.FindStringSubmatch(sqlInfo.stmt); am != nil {
-			reportCase(fpath, "CREATE ACCESS METHOD is not supported.",
-				"https://github.com/yugabyte/yugabyte-db/issues/10693", "", "ACCESS METHOD", am[1], sqlInfo.formattedStmt)
-		} else if tbl := idxConcRegex.FindStringSubmatch(sqlInfo.stmt); tbl != nil {
-			reportCase(fpath, "REINDEX is not supported.",
-				"https://github.com/yugabyte/yugabyte-db/issues/10267", "", "TABLE", tbl[1], sqlInfo.formattedStmt)
-		} else if col := storedRegex.FindStringSubmatch(sqlInfo.stmt); col != nil {
-			reportCase(fpath, "Stored generated column is not supported. Column is: "+col[1],
-				"https://github.com/yugabyte/yugabyte-db/issues/10695", "", "TABLE", "", sqlInfo.formattedStmt)
-		} else if tbl := likeAllRegex.FindStringSubmatch(sqlInfo.stmt); tbl != nil {
-			summaryMap["TABLE"].invalidCount[sqlInfo.objName] = true
-			reportCase(fpath, "LIKE ALL is not supported yet.",
-				"https://github.com/yugabyte/yugabyte-db/issues/10697", "", "TABLE", tbl[2], sqlInfo.formattedStmt)
-		} else if tbl := likeRegex.FindStringSubmatch(sqlInfo.stmt); tbl != nil {
-			summaryMap["TABLE"].invalidCount[sqlInfo.objName] = true
-			reportCase(fpath, "LIKE clause not supported yet.",
-				"https://github.com/YugaByte/yugabyte-db/issues/1129", "", "TABLE", tbl[2], sqlInfo.formattedStmt)
-		} else if tbl := tblPartitionRegex.FindStringSubmatch(sqlInfo.stmt); tbl != nil {
-			tblParts[tbl[2]] = tbl[3]
-			if filename, ok := primaryCons[tbl[2]]; ok {
-				reportAddingPrimaryKey(filename, tbl[2], sqlInfo.formattedStmt)
+func hasUniqueConstraint(createTable *pg_query.CreateStmt) bool {
+	isUnique := func(constraint *pg_query.Node) bool {
+		return constraint.GetConstraint().GetContype() == pg_query.ConstrType_CONSTR_UNIQUE
+	}
+	for _, element := range createTable.TableElts {
+		if isUnique(element) || (element.GetColumnDef() != nil && lo.SomeBy(element.GetColumnDef().Constraints, isUnique)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Checks the definition of a column in CREATE TABLE or ALTER TABLE ADD COLUMN
+func checkColumnDef(c *stmtChecker, tableName string, column *pg_query.ColumnDef) {
+	for _, node := range column.Constraints {
+		constraint := node.GetConstraint()
+		if constraint.GetContype() == pg_query.ConstrType_CONSTR_GENERATED {
+			c.report(int(constraint.Location), fmt.Sprintf(STORED_GENERATED_COLUMN_REASON, column.Colname),
+				"https://github.com/yugabyte/yugabyte-db/issues/10695", "", "TABLE", tableName)
+		}
+		checkConstraint(c, tableName, constraint)
+	}
+	if datatype, ok := unsupportedDatatypes[queryparser.TypeName(column.TypeName)]; ok {
+		c.report(int(column.TypeName.Location), fmt.Sprintf(UNSUPPORTED_DATATYPE_REASON, datatype), "",
+			fmt.Sprintf("Remove the column with %s datatype or change it to a relevant supported datatype", datatype), "TABLE", tableName)
+	}
+}
+
+// Checks a constraint defined in CREATE TABLE or ALTER TABLE
+func checkConstraint(c *stmtChecker, tableName string, constraint *pg_query.Constraint) {
+	if constraint.GetContype() == pg_query.ConstrType_CONSTR_UNIQUE && constraint.Deferrable {
+		c.report(int(constraint.Location), "DEFERRABLE unique constraints are not supported yet.",
+			"https://github.com/YugaByte/yugabyte-db/issues/1129", "", "TABLE", tableName)
+	}
+}
+
+func checkCreateTable(c *stmtChecker, createTable *pg_query.CreateStmt) {
+	tableName := queryparser.RangeVarName(createTable.Relation)
+	columnTypes := make(map[string]string)
+	for _, element := range createTable.TableElts {
+		switch {
+		case element.GetColumnDef() != nil:
+			column := element.GetColumnDef()
+			columnTypes[column.Colname] = queryparser.TypeName(column.TypeName)
+			checkColumnDef(c, tableName, column)
+		case element.GetConstraint() != nil:
+			checkConstraint(c, tableName, element.GetConstraint())
+		case element.GetTableLikeClause() != nil:
+			like := element.GetTableLikeClause()
+			c.markInvalid("TABLE")
+			if like.Options&uint32(CREATE_TABLE_LIKE_ALL) == CREATE_TABLE_LIKE_ALL {
+				c.report(int(like.Relation.GetLocation()), "LIKE ALL is not supported yet.",
+					"https://github.com/yugabyte/yugabyte-db/issues/10697", "", "TABLE", tableName)
+			} else {
+				c.report(int(like.Relation.GetLocation()), "LIKE clause not supported yet.",
+					"https://github.com/YugaByte/yugabyte-db/issues/1129", "", "TABLE", tableName)
 			}
-		} else if tbl := addPrimaryRegex.FindStringSubmatch(sqlInfo.stmt); tbl != nil {
-			if _, ok := tblParts[tbl[3]]; ok {
-				reportAddingPrimaryKey(fpath, tbl[2], sqlInfo.formattedStmt)
+		}
+	}
+
+	if createTable.Partbound != nil && len(createTable.InhRelations) > 0 {
+		tblParts[tableName] = queryparser.RangeVarName(createTable.InhRelations[0].GetRangeVar())
+		if filename, ok := primaryCons[tableName]; ok {
+			reportAddingPrimaryKey(issuePosition{}, filename, tableName, c.sqlInfo.formattedStmt)
+		}
+	} else if len(createTable.InhRelations) > 0 {
+		c.markInvalid("TABLE")
+		c.report(int(createTable.InhRelations[0].GetRangeVar().GetLocation()), "INHERITS not supported yet.",
+			"https://github.com/YugaByte/yugabyte-db/issues/1129", "", "TABLE", tableName)
+	}
+
+	for _, option := range createTable.Options {
+		if defElem := option.GetDefElem(); defElem != nil && strings.EqualFold(defElem.Defname, "oids") && isOptionEnabled(defElem) {
+			c.markInvalid("TABLE")
+			c.report(int(defElem.Location), "OIDs are not supported for user tables.",
+				"https://github.com/yugabyte/yugabyte-db/issues/10273", "", "TABLE", tableName)
+		}
+	}
+
+	primaryKeyColumns, primaryKeyLocation := getPrimaryKeyColumns(createTable)
+	if lo.SomeBy(primaryKeyColumns, func(column string) bool { return columnTypes[column] == "interval" }) {
+		c.markInvalid("TABLE")
+		c.report(primaryKeyLocation, "PRIMARY KEY containing column of type 'INTERVAL' not yet supported.",
+			"https://github.com/YugaByte/yugabyte-db/issues/1397", "", "TABLE", tableName)
+	}
+
+	if createTable.Partspec != nil {
+		checkPartitionColumns(c, createTable, tableName, primaryKeyColumns)
+	}
+}
+
+// example1 - CREATE TABLE example1( 	id numeric NOT NULL, 	country_code varchar(3), 	record_type varchar(5), PRIMARY KEY (id,country_code) ) PARTITION BY RANGE (country_code, record_type) ;
+// example2 - CREATE TABLE example2 ( 	id numeric NOT NULL PRIMARY KEY, 	country_code varchar(3), 	record_type varchar(5) ) PARTITION BY RANGE (country_code, record_type) ;
+func checkPartitionColumns(c *stmtChecker, createTable *pg_query.CreateStmt, tableName string, primaryKeyColumns []string) {
+	partitionSpec := createTable.Partspec
+	var partitionColumns []string
+	for _, param := range partitionSpec.PartParams {
+		partitionElem := param.GetPartitionElem()
+		if partitionElem.GetExpr() != nil {
+			if len(primaryKeyColumns) > 0 || hasUniqueConstraint(createTable) {
+				c.markInvalid("TABLE")
+				c.report(int(partitionElem.Location), "Issue with Partition using Expression on a table which cannot contain Primary Key / Unique Key on any column",
+					"https://github.com/yugabyte/yb-voyager/issues/698", "Remove the Constriant from the table definition", "TABLE", tableName)
 			}
-			primaryCons[tbl[2]] = fpath
-		} else if tbl := inheritRegex.FindStringSubmatch(sqlInfo.stmt); tbl != nil {
-			summaryMap["TABLE"].invalidCount[sqlInfo.objName] = true
-			reportCase(fpath, "INHERITS not supported yet.",
-				"https://github.com/YugaByte/yugabyte-db/issues/1129", "", "TABLE", tbl[3], sqlInfo.formattedStmt)
-		} else if tbl := withOidsRegex.FindStringSubmatch(sqlInfo.stmt); tbl != nil {
-			summaryMap["TABLE"].invalidCount[sqlInfo.objName] = true
-			reportCase(fpath, "OIDs are not supported for user tables.",
-				"https://github.com/yugabyte/yugabyte-db/issues/10273", "", "TABLE", tbl[2], sqlInfo.formattedStmt)
-		} else if tbl := intvlRegex.FindStringSubmatch(sqlInfo.stmt); tbl != nil {
-			summaryMap["TABLE"].invalidCount[sqlInfo.objName] = true
-			reportCase(fpath, "PRIMARY KEY containing column of type 'INTERVAL' not yet supported.",
-				"https://github.com/YugaByte/yugabyte-db/issues/1397", "", "TABLE", tbl[2], sqlInfo.formattedStmt)
-		} else if tbl := alterOfRegex.FindStringSubmatch(sqlInfo.stmt); tbl != nil {
-			reportCase(fpath, "ALTER TABLE OF not supported yet.",
-				"https://github.com/YugaByte/yugabyte-db/issues/1124", "", "TABLE", tbl[3], sqlInfo.formattedStmt)
-		} else if tbl := alterSchemaRegex.FindStringSubmatch(sqlInfo.stmt); tbl != nil {
-			reportCase(fpath, "ALTER TABLE SET SCHEMA not supported yet.",
-				"https://github.com/YugaByte/yugabyte-db/issues/3947", "", "TABLE", tbl[3], sqlInfo.formattedStmt)
-		} else if createSchemaRegex.MatchString(sqlInfo.stmt) {
-			reportCase(fpath, "CREATE SCHEMA with elements not supported yet.",
-				"https://github.com/YugaByte/yugabyte-db/issues/10865", "", "SCHEMA", "", sqlInfo.formattedStmt)
-		} else if tbl := alterNotOfRegex.FindStringSubmatch(sqlInfo.stmt); tbl != nil {
-			reportCase(fpath, "ALTER TABLE NOT OF not supported yet.",
-				"https://github.com/YugaByte/yugabyte-db/issues/1124", "", "TABLE", tbl[3], sqlInfo.formattedStmt)
-		} else if tbl := alterColumnStatsRegex.FindStringSubmatch(sqlInfo.stmt); tbl != nil {
-			reportCase(fpath, "ALTER TABLE ALTER column SET STATISTICS not supported yet.",
-				"https://github.com/YugaByte/yugabyte-db/issues/1124", "", "TABLE", tbl[3], sqlInfo.formattedStmt)
-		} else if tbl := alterColumnStorageRegex.FindStringSubmatch(sqlInfo.stmt); tbl != nil {
-			reportCase(fpath, "ALTER TABLE ALTER column SET STORAGE not supported yet.",
-				"https://github.com/YugaByte/yugabyte-db/issues/1124", "", "TABLE", tbl[3], sqlInfo.formattedStmt)
-		} else if tbl := alterColumnSetAttributesRegex.FindStringSubmatch(sqlInfo.stmt); tbl != nil {
-			reportCase(fpath, "ALTER TABLE ALTER column SET (attribute = value) not supported yet.",
-				"https://github.com/YugaByte/yugabyte-db/issues/1124", "", "TABLE", tbl[3], sqlInfo.formattedStmt)
-		} else if tbl := alterColumnResetAttributesRegex.FindStringSubmatch(sqlInfo.stmt); tbl != nil {
-			reportCase(fpath, "ALTER TABLE ALTER column RESET (attribute) not supported yet.",
-				"https://github.com/YugaByte/yugabyte-db/issues/1124", "", "TABLE", tbl[3], sqlInfo.formattedStmt)
-		} else if tbl := alterConstrRegex.FindStringSubmatch(sqlInfo.stmt); tbl != nil {
-			reportCase(fpath, "ALTER TABLE ALTER CONSTRAINT not supported yet.",
-				"https://github.com/YugaByte/yugabyte-db/issues/1124", "", "TABLE", tbl[3], sqlInfo.formattedStmt)
-		} else if tbl := setOidsRegex.FindStringSubmatch(sqlInfo.stmt); tbl != nil {
-			reportCase(fpath, "ALTER TABLE SET WITH OIDS not supported yet.",
-				"https://github.com/YugaByte/yugabyte-db/issues/1124", "", "TABLE", tbl[4], sqlInfo.formattedStmt)
-		} else if tbl := withoutClusterRegex.FindStringSubmatch(sqlInfo.stmt); tbl != nil {
-			reportCase(fpath, "ALTER TABLE SET WITHOUT CLUSTER not supported yet.",
-				"https://github.com/YugaByte/yugabyte-db/issues/1124", "", "TABLE", tbl[2], sqlInfo.formattedStmt)
-		} else if tbl := clusterRegex.FindStringSubmatch(sqlInfo.stmt); tbl != nil {
-			reportCase(fpath, "ALTER TABLE CLUSTER not supported yet.",
-				"https://github.com/YugaByte/yugabyte-db/issues/1124", "", "TABLE", tbl[2], sqlInfo.formattedStmt)
-		} else if tbl := alterSetRegex.FindStringSubmatch(sqlInfo.stmt); tbl != nil {
-			reportCase(fpath, "ALTER TABLE SET not supported yet.",
-				"https://github.com/YugaByte/yugabyte-db/issues/1124", "", "TABLE", tbl[2], sqlInfo.formattedStmt)
-		} else if tbl := alterIdxRegex.FindStringSubmatch(sqlInfo.stmt); tbl != nil {
-			reportCase(fpath, "ALTER INDEX SET not supported yet.",
-				"https://github.com/YugaByte/yugabyte-db/issues/1124", "", "INDEX", tbl[1], sqlInfo.formattedStmt)
-		} else if tbl := alterResetRegex.FindStringSubmatch(sqlInfo.stmt); tbl != nil {
-			reportCase(fpath, "ALTER TABLE RESET not supported yet.",
-				"https://github.com/YugaByte/yugabyte-db/issues/1124", "", "TABLE", tbl[2], sqlInfo.formattedStmt)
-		} else if tbl := alterOptionsRegex.FindStringSubmatch(sqlInfo.stmt); tbl != nil {
-			reportCase(fpath, "ALTER TABLE not supported yet.",
-				"https://github.com/YugaByte/yugabyte-db/issues/1124", "", "TABLE", tbl[3], sqlInfo.formattedStmt)
-		} else if typ := dropAttrRegex.FindStringSubmatch(sqlInfo.stmt); typ != nil {
-			reportCase(fpath, "ALTER TYPE DROP ATTRIBUTE not supported yet.",
-				"https://github.com/YugaByte/yugabyte-db/issues/1893", "", "TYPE", typ[1], sqlInfo.formattedStmt)
-		} else if typ := alterTypeRegex.FindStringSubmatch(sqlInfo.stmt); typ != nil {
-			reportCase(fpath, "ALTER TYPE not supported yet.",
-				"https://github.com/YugaByte/yugabyte-db/issues/1893", "", "TYPE", typ[1], sqlInfo.formattedStmt)
-		} else if tbl := alterInhRegex.FindStringSubmatch(sqlInfo.stmt); tbl != nil {
-			reportCase(fpath, "ALTER TABLE INHERIT not supported yet.",
-				"https://github.com/YugaByte/yugabyte-db/issues/1124", "", "TABLE", tbl[3], sqlInfo.formattedStmt)
-		} else if tbl := valConstrRegex.FindStringSubmatch(sqlInfo.stmt); tbl != nil {
-			reportCase(fpath, "ALTER TABLE VALIDATE CONSTRAINT not supported yet.",
-				"https://github.com/YugaByte/yugabyte-db/issues/1124", "", "TABLE", tbl[3], sqlInfo.formattedStmt)
-		} else if tbl := deferRegex.FindStringSubmatch(sqlInfo.stmt); tbl != nil {
-			reportCase(fpath, "DEFERRABLE unique constraints are not supported yet.",
-				"https://github.com/YugaByte/yugabyte-db/issues/1129", "", "TABLE", tbl[4], sqlInfo.formattedStmt)
-		} else if spc := alterTblSpcRegex.FindStringSubmatch(sqlInfo.stmt); spc != nil {
-			reportCase(fpath, "ALTER TABLESPACE not supported yet.",
-				"https://github.com/YugaByte/yugabyte-db/issues/1153", "", "TABLESPACE", spc[1], sqlInfo.formattedStmt)
-		} else if spc := alterViewRegex.FindStringSubmatch(sqlInfo.stmt); spc != nil {
-			reportCase(fpath, "ALTER VIEW not supported yet.",
-				"https://github.com/YugaByte/yugabyte-db/issues/1131", "", "VIEW", spc[1], sqlInfo.formattedStmt)
-		} else if tbl := cLangRegex.FindStringSubmatch(sqlInfo.stmt); tbl != nil {
-			reportCase(fpath, "LANGUAGE C not supported yet.",
-				"", "", "FUNCTION", tbl[2], sqlInfo.formattedStmt)
-			summaryMap["FUNCTION"].invalidCount[sqlInfo.objName] = true
-		} else if regMatch := partitionColumnsRegex.FindStringSubmatch(sqlInfo.stmt); regMatch != nil {
-			// example1 - CREATE TABLE example1( 	id numeric NOT NULL, 	country_code varchar(3), 	record_type varchar(5), PRIMARY KEY (id,country_code) ) PARTITION BY RANGE (country_code, record_type) ;
-			// example2 - CREATE TABLE example2 ( 	id numeric NOT NULL PRIMARY KEY, 	country_code varchar(3), 	record_type varchar(5) ) PARTITION BY RANGE (country_code, record_type) ;
-			columnList := utils.CsvStringToSlice(strings.Trim(regMatch[3], " "))
-			// example1 - columnList: [id numeric NOT NULL country_code varchar(3) record_type varchar(5) PRIMARY KEY (id country_code)]
-			// example2 - columnList: [id numeric NOT NULL PRIMARY KEY country_code varchar(3) record_type varchar(5]
-			openBracketSplits := strings.Split(strings.Trim(regMatch[3], " "), "(")
-			// example1 -  openBracketSplits: [	id numeric NOT NULL, 	country_code varchar 3), 	record_type varchar 5), 	descriptions varchar 50), 	PRIMARY KEY  id,country_code)]
-			stringbeforeLastOpenBracket := ""
-			if len(openBracketSplits) > 1 {
-				stringbeforeLastOpenBracket = strings.Join(strings.Fields(openBracketSplits[len(openBracketSplits)-2]), " ") //without extra spaces to easily check suffix
+			return
+		}
+		partitionColumns = append(partitionColumns, partitionElem.GetName())
+	}
+	if partitionSpec.Strategy == pg_query.PartitionStrategy_PARTITION_STRATEGY_LIST && len(partitionColumns) > 1 {
+		c.markInvalid("TABLE")
+		c.report(int(partitionSpec.Location), `cannot use "list" partition strategy with more than one column`,
+			"https://github.com/yugabyte/yb-voyager/issues/699", "Make it a single column partition by list or choose other supported Partitioning methods", "TABLE", tableName)
+		return
+	}
+	if len(primaryKeyColumns) == 0 { // if non-PK table, then no need to report
+		return
+	}
+	for _, partitionColumn := range partitionColumns {
+		if !slices.Contains(primaryKeyColumns, partitionColumn) { //partition key not in PK
+			c.markInvalid("TABLE")
+			c.report(int(partitionSpec.Location), "insufficient columns in the PRIMARY KEY constraint definition in CREATE TABLE",
+				"https://github.com/yugabyte/yb-voyager/issues/578", "Add all Partition columns to Primary Key", "TABLE", tableName)
+			break
+		}
+	}
+}
+
+// CREATE_TABLE_LIKE_ALL from PostgreSQL's nodes/parsenodes.h, set in the options of LIKE ... INCLUDING ALL
+const CREATE_TABLE_LIKE_ALL = 0x7FFFFFFF
+
+var unsupportedAlterTableCmds = map[pg_query.AlterTableType]string{
+	pg_query.AlterTableType_AT_AddOf:              "ALTER TABLE OF not supported yet.",
+	pg_query.AlterTableType_AT_DropOf:             "ALTER TABLE NOT OF not supported yet.",
+	pg_query.AlterTableType_AT_SetStatistics:      "ALTER TABLE ALTER column SET STATISTICS not supported yet.",
+	pg_query.AlterTableType_AT_SetStorage:         "ALTER TABLE ALTER column SET STORAGE not supported yet.",
+	pg_query.AlterTableType_AT_SetOptions:         "ALTER TABLE ALTER column SET (attribute = value) not supported yet.",
+	pg_query.AlterTableType_AT_ResetOptions:       "ALTER TABLE ALTER column RESET (attribute) not supported yet.",
+	pg_query.AlterTableType_AT_AlterConstraint:    "ALTER TABLE ALTER CONSTRAINT not supported yet.",
+	pg_query.AlterTableType_AT_DropCluster:        "ALTER TABLE SET WITHOUT CLUSTER not supported yet.",
+	pg_query.AlterTableType_AT_ClusterOn:          "ALTER TABLE CLUSTER not supported yet.",
+	pg_query.AlterTableType_AT_SetRelOptions:      "ALTER TABLE SET not supported yet.",
+	pg_query.AlterTableType_AT_SetLogged:          "ALTER TABLE SET not supported yet.",
+	pg_query.AlterTableType_AT_SetUnLogged:        "ALTER TABLE SET not supported yet.",
+	pg_query.AlterTableType_AT_SetTableSpace:      "ALTER TABLE SET not supported yet.",
+	pg_query.AlterTableType_AT_SetAccessMethod:    "ALTER TABLE SET not supported yet.",
+	pg_query.AlterTableType_AT_DropOids:           "ALTER TABLE SET not supported yet.",
+	pg_query.AlterTableType_AT_ResetRelOptions:    "ALTER TABLE RESET not supported yet.",
+	pg_query.AlterTableType_AT_GenericOptions:     "ALTER TABLE not supported yet.",
+	pg_query.AlterTableType_AT_AddInherit:         "ALTER TABLE INHERIT not supported yet.",
+	pg_query.AlterTableType_AT_ValidateConstraint: "ALTER TABLE VALIDATE CONSTRAINT not supported yet.",
+}
+
+func checkAlterTable(c *stmtChecker, alterTable *pg_query.AlterTableStmt) {
+	name := queryparser.RangeVarName(alterTable.Relation)
+	location := int(alterTable.Relation.GetLocation())
+	switch alterTable.Objtype {
+	case pg_query.ObjectType_OBJECT_TABLE, pg_query.ObjectType_OBJECT_FOREIGN_TABLE:
+		for _, node := range alterTable.Cmds {
+			cmd := node.GetAlterTableCmd()
+			if reason, ok := unsupportedAlterTableCmds[cmd.GetSubtype()]; ok {
+				c.report(location, reason, "https://github.com/YugaByte/yugabyte-db/issues/1124", "", "TABLE", name)
 			}
-			// example1 - stringbeforeLastBracket: 50), PRIMARY KEY
-			// example2 - stringbeforeLastBracket: 5), descriptions varchar
-			var primaryKeyColumnsList []string
-			if strings.HasSuffix(strings.ToLower(stringbeforeLastOpenBracket), ", primary key") { //false for example2
-				primaryKeyColumns := strings.Trim(openBracketSplits[len(openBracketSplits)-1], ") ")
-				primaryKeyColumnsList = utils.CsvStringToSlice(primaryKeyColumns)
-			} else {
-				//this case can come by manual intervention
-				for _, columnDefinition := range columnList {
-					if strings.Contains(strings.ToLower(columnDefinition), "primary key") {
-						partsOfColumnDefinition := strings.Split(columnDefinition, " ")
-						columnName := partsOfColumnDefinition[0]
-						primaryKeyColumnsList = append(primaryKeyColumnsList, columnName)
-						break
+			switch {
+			case cmd.GetSubtype() == pg_query.AlterTableType_AT_AddColumn && cmd.Def.GetColumnDef() != nil:
+				checkColumnDef(c, name, cmd.Def.GetColumnDef())
+			case cmd.GetSubtype() == pg_query.AlterTableType_AT_AddConstraint && cmd.Def.GetConstraint() != nil:
+				constraint := cmd.Def.GetConstraint()
+				checkConstraint(c, name, constraint)
+				if constraint.Contype == pg_query.ConstrType_CONSTR_PRIMARY {
+					if _, ok := tblParts[name]; ok {
+						reportAddingPrimaryKey(c.sqlInfo.position(c.offset(int(constraint.Location))), c.fpath, name, c.sqlInfo.formattedStmt)
 					}
+					primaryCons[name] = c.fpath
 				}
 			}
-			partitionColumns := strings.Trim(regMatch[5], `()`)
-			partitionColumnsList := utils.CsvStringToSlice(partitionColumns)
-			if len(partitionColumnsList) == 1 {
-				expressionChk := partitionColumnsList[0]
-				if strings.ContainsAny(expressionChk, "()[]{}|/!@$#%^&*-+=") {
-					summaryMap["TABLE"].invalidCount[sqlInfo.objName] = true
-					reportCase(fpath, "Issue with Partition using Expression on a table which cannot contain Primary Key / Unique Key on any column",
-						"https://github.com/yugabyte/yb-voyager/issues/698", "Remove the Constriant from the table definition", "TABLE", regMatch[2], sqlInfo.formattedStmt)
-					continue
-				}
+		}
+	case pg_query.ObjectType_OBJECT_INDEX:
+		for _, node := range alterTable.Cmds {
+			switch node.GetAlterTableCmd().GetSubtype() {
+			case pg_query.AlterTableType_AT_SetRelOptions, pg_query.AlterTableType_AT_SetTableSpace, pg_query.AlterTableType_AT_SetStatistics:
+				c.report(location, "ALTER INDEX SET not supported yet.",
+					"https://github.com/YugaByte/yugabyte-db/issues/1124", "", "INDEX", name)
+			}
+		}
+	case pg_query.ObjectType_OBJECT_TYPE:
+		alterType := false
+		for _, node := range alterTable.Cmds {
+			if node.GetAlterTableCmd().GetSubtype() == pg_query.AlterTableType_AT_DropColumn {
+				c.report(location, "ALTER TYPE DROP ATTRIBUTE not supported yet.",
+					"https://github.com/YugaByte/yugabyte-db/issues/1893", "", "TYPE", name)
+			} else {
+				alterType = true
 			}
-			if strings.ToLower(regMatch[4]) == "list" && len(partitionColumnsList) > 1 {
-				summaryMap["TABLE"].invalidCount[sqlInfo.objName] = true
-				reportCase(fpath, `cannot use "list" partition strategy with more than one column`,
-					"https://github.com/yugabyte/yb-voyager/issues/699", "Make it a single column partition by list or choose other supported Partitioning methods", "TABLE", regMatch[2], sqlInfo.formattedStmt)
+		}
+		if alterType {
+			c.report(location, "ALTER TYPE not supported yet.",
+				"https://github.com/YugaByte/yugabyte-db/issues/1893", "", "TYPE", name)
+		}
+	case pg_query.ObjectType_OBJECT_VIEW:
+		c.report(location, "ALTER VIEW not supported yet.",
+			"https://github.com/YugaByte/yugabyte-db/issues/1131", "", "VIEW", name)
+	}
+}
+
+// Checks unsupported DDL statements
+func checkDDL(c *stmtChecker, stmt *pg_query.Node) {
+	if objType, objName, ok := getAlteredObject(stmt); ok {
+		switch {
+		case objType == pg_query.ObjectType_OBJECT_TABLE && stmt.GetAlterObjectSchemaStmt() != nil:
+			c.report(int(stmt.GetAlterObjectSchemaStmt().Relation.GetLocation()), "ALTER TABLE SET SCHEMA not supported yet.",
+				"https://github.com/YugaByte/yugabyte-db/issues/3947", "", "TABLE", objName)
+		case objType == pg_query.ObjectType_OBJECT_TYPE:
+			c.report(-1, "ALTER TYPE not supported yet.",
+				"https://github.com/YugaByte/yugabyte-db/issues/1893", "", "TYPE", objName)
+		case objType == pg_query.ObjectType_OBJECT_VIEW:
+			c.report(-1, "ALTER VIEW not supported yet.",
+				"https://github.com/YugaByte/yugabyte-db/issues/1131", "", "VIEW", objName)
+		}
+	}
+
+	switch {
+	case stmt.GetCreateAmStmt() != nil:
+		c.report(-1, "CREATE ACCESS METHOD is not supported.",
+			"https://github.com/yugabyte/yugabyte-db/issues/10693", "", "ACCESS METHOD", queryparser.QuoteIdentifier(stmt.GetCreateAmStmt().Amname))
+	case stmt.GetReindexStmt() != nil:
+		reindex := stmt.GetReindexStmt()
+		name := queryparser.RangeVarName(reindex.Relation)
+		if name == "" {
+			name = queryparser.QuoteIdentifier(reindex.Name)
+		}
+		c.report(-1, "REINDEX is not supported.",
+			"https://github.com/yugabyte/yugabyte-db/issues/10267", "", "TABLE", name)
+	case stmt.GetCreateStmt() != nil:
+		checkCreateTable(c, stmt.GetCreateStmt())
+	case stmt.GetAlterTableStmt() != nil:
+		checkAlterTable(c, stmt.GetAlterTableStmt())
+	case stmt.GetCreateSchemaStmt() != nil && len(stmt.GetCreateSchemaStmt().SchemaElts) > 0:
+		c.report(-1, "CREATE SCHEMA with elements not supported yet.",
+			"https://github.com/YugaByte/yugabyte-db/issues/10865", "", "SCHEMA", queryparser.QuoteIdentifier(stmt.GetCreateSchemaStmt().Schemaname))
+	case stmt.GetAlterEnumStmt() != nil:
+		c.report(-1, "ALTER TYPE not supported yet.",
+			"https://github.com/YugaByte/yugabyte-db/issues/1893", "", "TYPE", queryparser.NameFromList(stmt.GetAlterEnumStmt().TypeName))
+	case stmt.GetAlterTypeStmt() != nil:
+		c.report(-1, "ALTER TYPE not supported yet.",
+			"https://github.com/YugaByte/yugabyte-db/issues/1893", "", "TYPE", queryparser.NameFromList(stmt.GetAlterTypeStmt().TypeName))
+	case stmt.GetAlterTableSpaceOptionsStmt() != nil && !stmt.GetAlterTableSpaceOptionsStmt().IsReset:
+		c.report(-1, "ALTER TABLESPACE not supported yet.",
+			"https://github.com/YugaByte/yugabyte-db/issues/1153", "", "TABLESPACE", queryparser.QuoteIdentifier(stmt.GetAlterTableSpaceOptionsStmt().Tablespacename))
+	case stmt.GetCreateFunctionStmt() != nil:
+		function := stmt.GetCreateFunctionStmt()
+		if language, location := getFunctionLanguage(function); language == "c" {
+			c.report(location, "LANGUAGE C not supported yet.", "", "", "FUNCTION", queryparser.NameFromList(function.Funcname))
+			c.markInvalid("FUNCTION")
+		}
+	}
+}
+
+// Returns the lower-cased language of the function along with the location of the LANGUAGE clause
+func getFunctionLanguage(function *pg_query.CreateFunctionStmt) (string, int) {
+	for _, option := range function.Options {
+		if defElem := option.GetDefElem(); defElem != nil && defElem.Defname == "language" {
+			return strings.ToLower(defElem.Arg.GetString_().GetSval()), int(defElem.Location)
+		}
+	}
+	return "sql", -1
+}
+
+// check foreign table
+func checkForeign(c *stmtChecker, stmt *pg_query.Node) {
+	foreignTable := stmt.GetCreateForeignTableStmt()
+	if foreignTable == nil || foreignTable.BaseStmt == nil {
+		return
+	}
+	tableName := queryparser.RangeVarName(foreignTable.BaseStmt.Relation)
+	var constraints []*pg_query.Constraint
+	for _, element := range foreignTable.BaseStmt.TableElts {
+		if column := element.GetColumnDef(); column != nil {
+			for _, constraint := range column.Constraints {
+				constraints = append(constraints, constraint.GetConstraint())
+			}
+		} else if constraint := element.GetConstraint(); constraint != nil {
+			constraints = append(constraints, constraint)
+		}
+	}
+	if constraint, ok := lo.Find(constraints, func(constraint *pg_query.Constraint) bool {
+		return constraint.GetContype() == pg_query.ConstrType_CONSTR_PRIMARY
+	}); ok {
+		c.report(int(constraint.Location), "Primary key constraints are not supported on foreign tables.",
+			"https://github.com/yugabyte/yugabyte-db/issues/10698", "", "TABLE", tableName)
+	}
+	if constraint, ok := lo.Find(constraints, func(constraint *pg_query.Constraint) bool {
+		return constraint.GetContype() == pg_query.ConstrType_CONSTR_FOREIGN
+	}); ok {
+		c.report(int(constraint.Location), "Foreign key constraints are not supported on foreign tables.",
+			"https://github.com/yugabyte/yugabyte-db/issues/10699", "", "TABLE", tableName)
+	}
+}
+
+// Checks the statements in the body of a function or procedure, attributing the issues to it. PL/pgSQL bodies are
+// parsed by the PL/pgSQL parser, and the SQL statements and expressions in them are then checked like any other statement.
+func checkFunctionBody(c *stmtChecker, rawStmt *pg_query.RawStmt) {
+	function := rawStmt.Stmt.GetCreateFunctionStmt()
+	if function == nil {
+		return
+	}
+	body, bodyOffset, ok := queryparser.FunctionBody(c.sqlInfo.formattedStmt, function)
+	if !ok {
+		return
+	}
+	bodyChecker := func(textOffset int, shift int, stmtStart int) *stmtChecker {
+		funcType := lo.Ternary(function.IsProcedure, "PROCEDURE", "FUNCTION")
+		return &stmtChecker{fpath: c.fpath, sqlInfo: c.sqlInfo, objType: c.objType, textOffset: textOffset, shift: shift,
+			stmtStart: stmtStart, funcType: funcType, funcName: queryparser.NameFromList(function.Funcname)}
+	}
+
+	switch language, _ := getFunctionLanguage(function); language {
+	case "sql":
+		stmts, err := queryparser.Parse(body)
+		if err != nil {
+			log.Infof("parse body of function %s in %q: %v", queryparser.NameFromList(function.Funcname), c.fpath, err)
+			return
+		}
+		for _, stmt := range stmts {
+			bodyChecker(bodyOffset, 0, bodyOffset+queryparser.StmtStart(body, stmt)).check(stmt.Stmt)
+		}
+	case "plpgsql":
+		exprs, err := queryparser.ParsePlPgSQL(queryparser.StmtText(c.sqlInfo.formattedStmt, rawStmt))
+		if err != nil {
+			// The PL/pgSQL parser rejects, for instance, every FETCH as it does not know the types of the
+			// variables. Such bodies are checked based on their tokens.
+			log.Infof("parse PL/pgSQL body of function %s in %q: %v", queryparser.NameFromList(function.Funcname), c.fpath, err)
+			checkTokens(bodyChecker(bodyOffset, 0, bodyOffset), body)
+			return
+		}
+		for _, expr := range exprs {
+			sql, shift, ok := expr.SQL()
+			if !ok {
 				continue
 			}
-			if len(primaryKeyColumnsList) == 0 { // if non-PK table, then no need to report
+			stmts, err := queryparser.Parse(sql)
+			if err != nil {
+				log.Infof("parse %q in PL/pgSQL body of function %s in %q: %v", expr.Query, queryparser.NameFromList(function.Funcname), c.fpath, err)
 				continue
 			}
-			for _, partitionColumn := range partitionColumnsList {
-				if !slices.Contains(primaryKeyColumnsList, partitionColumn) { //partition key not in PK
-					summaryMap["TABLE"].invalidCount[sqlInfo.objName] = true
-					reportCase(fpath, "insufficient columns in the PRIMARY KEY constraint definition in CREATE TABLE",
-						"https://github.com/yugabyte/yb-voyager/issues/578", "Add all Partition columns to Primary Key", "TABLE", regMatch[2], sqlInfo.formattedStmt)
-					break
-				}
+			exprOffset := bodyOffset + findPlPgSQLExpr(body, expr)
+			for _, stmt := range stmts {
+				bodyChecker(exprOffset, shift, exprOffset).check(stmt.Stmt)
 			}
-		} else if strings.Contains(strings.ToLower(sqlInfo.stmt), "drop temporary table") {
-			filePath := strings.Split(fpath, "/")
-			fileName := filePath[len(filePath)-1]
-			objType := strings.ToUpper(strings.Split(fileName, ".")[0])
-			reportCase(fpath, `temporary table is not a supported clause for drop`,
-				"https://github.com/yugabyte/yb-voyager/issues/705", `remove "temporary" and change it to "drop table"`, objType, sqlInfo.objName, sqlInfo.formattedStmt)
-		} else if regMatch := anydataRegex.FindStringSubmatch(sqlInfo.stmt); regMatch != nil {
-			reportCase(fpath, "AnyData datatype doesn't have a mapping in YugabyteDB", "", `Remove the column with AnyData datatype or change it to a relevant supported datatype`, "TABLE", regMatch[2], sqlInfo.formattedStmt)
-		} else if regMatch := anydatasetRegex.FindStringSubmatch(sqlInfo.stmt); regMatch != nil {
-			reportCase(fpath, "AnyDataSet datatype doesn't have a mapping in YugabyteDB", "", `Remove the column with AnyDataSet datatype or change it to a relevant supported datatype`, "TABLE", regMatch[2], sqlInfo.formattedStmt)
-		} else if regMatch := anyTypeRegex.FindStringSubmatch(sqlInfo.stmt); regMatch != nil {
-			reportCase(fpath, "AnyType datatype doesn't have a mapping in YugabyteDB", "", `Remove the column with AnyType datatype or change it to a relevant supported datatype`, "TABLE", regMatch[2], sqlInfo.formattedStmt)
-		} else if regMatch := uriTypeRegex.FindStringSubmatch(sqlInfo.stmt); regMatch != nil {
-			reportCase(fpath, "URIType datatype doesn't have a mapping in YugabyteDB", "", `Remove the column with URIType datatype or change it to a relevant supported datatype`, "TABLE", regMatch[2], sqlInfo.formattedStmt)
-		} else if regMatch := jsonFuncRegex.FindStringSubmatch(sqlInfo.stmt); regMatch != nil {
-			reportCase(fpath, "JSON_ARRAYAGG() function is not available in YugabyteDB", "", `Rename the function to YugabyteDB's equivalent JSON_AGG()`, regMatch[2], regMatch[3], sqlInfo.formattedStmt)
 		}
-
 	}
 }
 
-// check foreign table
-func checkForeign(sqlInfoArr []sqlInfo, fpath string) {
-	for _, sqlInfo := range sqlInfoArr {
-		if tbl := primRegex.FindStringSubmatch(sqlInfo.stmt); tbl != nil {
-			reportCase(fpath, "Primary key constraints are not supported on foreign tables.",
-				"https://github.com/yugabyte/yugabyte-db/issues/10698", "", "TABLE", tbl[1], sqlInfo.formattedStmt)
-		} else if tbl := foreignKeyRegex.FindStringSubmatch(sqlInfo.stmt); tbl != nil {
-			reportCase(fpath, "Foreign key constraints are not supported on foreign tables.",
-				"https://github.com/yugabyte/yugabyte-db/issues/10699", "", "TABLE", tbl[1], sqlInfo.formattedStmt)
+// Returns the offset of the expression in the function body. The PL/pgSQL parser blanks out the INTO clause of
+// the queries and replaces PERFORM with SELECT, so such queries are located by their prefix or by their line.
+func findPlPgSQLExpr(body string, expr queryparser.PlPgSQLExpr) int {
+	lineStart := 0
+	for line := 1; line < expr.LineNo; line++ {
+		idx := strings.Index(body[lineStart:], "\n")
+		if idx < 0 {
+			break
 		}
+		lineStart += idx + 1
 	}
+	prefix := expr.Query
+	if idx := strings.Index(prefix, "  "); idx > 0 {
+		prefix = prefix[:idx]
+	}
+	if idx := strings.Index(body[lineStart:], prefix); idx >= 0 {
+		return lineStart + idx
+	}
+	return lineStart + len(body[lineStart:]) - len(strings.TrimLeft(body[lineStart:], " \t\n"))
 }
 
-// all other cases to check
-func checkRemaining(sqlInfoArr []sqlInfo, fpath string) {
-	for _, sqlInfo := range sqlInfoArr {
-		if trig := compoundTrigRegex.FindStringSubmatch(sqlInfo.stmt); trig != nil {
-			reportCase(fpath, "Compound Triggers are not supported in YugabyteDB.",
-				"", "", "TRIGGER", trig[2], sqlInfo.formattedStmt)
-			summaryMap["TRIGGER"].invalidCount[sqlInfo.objName] = true
+// Checks the statements which are not accepted by the PostgreSQL grammar, based on their tokens.
+// These are mostly in the syntax of the source database, left as is by the export.
+func checkUnparsedStmt(sqlInfo *sqlInfo, fpath string, objType string) {
+	c := &stmtChecker{fpath: fpath, sqlInfo: sqlInfo, objType: objType,
+		stmtStart: len(sqlInfo.formattedStmt) - len(strings.TrimLeft(sqlInfo.formattedStmt, " \t\n"))}
+	if !checkTokens(c, sqlInfo.formattedStmt) {
+		c.report(queryparser.ErrorOffset(sqlInfo.formattedStmt, sqlInfo.parseErr), fmt.Sprintf(UNPARSED_STMT_REASON, sqlInfo.parseErr), "",
+			"Edit the statement to match PostgreSQL syntax", objType, sqlInfo.objName)
+	}
+}
+
+// Checks the tokens of sql for the unsupported constructs which the PostgreSQL grammar does not accept either.
+// Returns true if any issue is reported.
+func checkTokens(c *stmtChecker, sql string) bool {
+	tokens, err := queryparser.Tokens(sql)
+	if err != nil {
+		log.Infof("scan %q: %v", sql, err)
+		return false
+	}
+	text := func(i int) string {
+		if i < 0 || i >= len(tokens) {
+			return ""
 		}
+		return tokens[i].Text
+	}
+	nameAfter := func(keyword string) string {
+		name, _ := getNameAfterKeyword(sql, tokens, keyword)
+		return name
 	}
 
+	reported := make(map[string]bool)
+	report := func(i int, reason string, ghIssue string, suggestion string, objType string, objName string) {
+		if !reported[reason] {
+			reported[reason] = true
+			c.report(tokens[i].Start, reason, ghIssue, suggestion, objType, objName)
+		}
+	}
+	for i := range tokens {
+		switch {
+		case text(i) == "WITH" && text(i+1) == "OIDS" && text(i-1) == "SET":
+			report(i-1, "ALTER TABLE SET WITH OIDS not supported yet.",
+				"https://github.com/YugaByte/yugabyte-db/issues/1124", "", "TABLE", nameAfter("TABLE"))
+		case text(i) == "WITH" && text(i+1) == "OIDS":
+			c.markInvalid("TABLE")
+			report(i, "OIDs are not supported for user tables.",
+				"https://github.com/yugabyte/yugabyte-db/issues/10273", "", "TABLE", nameAfter("TABLE"))
+		case text(i) == "COMPOUND" && text(i+1) == "TRIGGER":
+			c.markInvalid("TRIGGER")
+			report(i, "Compound Triggers are not supported in YugabyteDB.", "", "", "TRIGGER", nameAfter("TRIGGER"))
+		case text(i) == "DROP" && text(i+1) == "TEMPORARY" && text(i+2) == "TABLE":
+			objType := strings.ToUpper(strings.Split(filepath.Base(c.fpath), ".")[0])
+			report(i+1, `temporary table is not a supported clause for drop`,
+				"https://github.com/yugabyte/yb-voyager/issues/705", `remove "temporary" and change it to "drop table"`, objType, c.sqlInfo.objName)
+		case text(i) == "BULK" && text(i+1) == "COLLECT":
+			report(i, "BULK COLLECT keyword of oracle is not converted into PostgreSQL compatible syntax", "", "", "", "")
+		case text(i) == "FETCH" && text(i+1) == "ABSOLUTE":
+			report(i, "FETCH ABSOLUTE not supported yet", "https://github.com/YugaByte/yugabyte-db/issues/6514", "", "CURSOR", "")
+		case text(i) == "FETCH" && text(i+1) == "RELATIVE":
+			report(i, "FETCH RELATIVE not supported yet", "https://github.com/YugaByte/yugabyte-db/issues/6514", "", "CURSOR", "")
+		case text(i) == "MOVE" && text(i+1) == "BACKWARD":
+			report(i, "FETCH BACKWARD not supported yet", "https://github.com/YugaByte/yugabyte-db/issues/6514", "", "CURSOR", "")
+		case text(i) == "FETCH" && !isFetchLimitClause(tokens[i:]):
+			report(i, "FETCH - not supported yet", "https://github.com/YugaByte/yugabyte-db/issues/6514", "", "CURSOR", "")
+		case text(i) == "WHERE" && text(i+1) == "CURRENT" && text(i+2) == "OF":
+			report(i, "WHERE CURRENT OF not supported yet", "https://github.com/YugaByte/yugabyte-db/issues/737", "", "CURSOR", "")
+		case text(i) == "JSON_ARRAYAGG" && text(i+1) == "(":
+			report(i, "JSON_ARRAYAGG() function is not available in YugabyteDB", "",
+				`Rename the function to YugabyteDB's equivalent JSON_AGG()`, c.objType, c.sqlInfo.objName)
+		}
+	}
+	return len(reported) > 0
+}
+
+// Returns the name of the object following the first occurrence of the keyword in the tokens of sql, e.g. the table name
+// in ALTER TABLE [ONLY] [IF EXISTS] name, along with the index of the token after the name.
+func getNameAfterKeyword(sql string, tokens []queryparser.Token, keyword string) (string, int) {
+	idx := slices.IndexFunc(tokens, func(token queryparser.Token) bool { return token.Text == keyword })
+	if idx < 0 {
+		return "", -1
+	}
+	name := ""
+	for idx++; idx < len(tokens) && slices.Contains([]string{"ONLY", "IF", "NOT", "EXISTS"}, tokens[idx].Text); idx++ {
+	}
+	for ; idx < len(tokens) && (name == "" || strings.HasSuffix(name, ".") || tokens[idx].Text == "."); idx++ {
+		name += sql[tokens[idx].Start:tokens[idx].End]
+	}
+	return name, idx
+}
+
+// Returns true for the FETCH { FIRST | NEXT } n ROWS { ONLY | WITH TIES } clause of queries, which starts the tokens
+func isFetchLimitClause(tokens []queryparser.Token) bool {
+	for i := 1; i < len(tokens) && i <= 5; i++ {
+		if tokens[i].Text == "ONLY" || tokens[i].Text == "TIES" {
+			return true
+		}
+		if tokens[i].Text == ";" {
+			break
+		}
+	}
+	return false
 }
 
 // Checks whether the script, fpath, can be migrated to YB
-func checker(sqlInfoArr []sqlInfo, fpath string) {
+func checker(sqlInfoArr []sqlInfo, fpath string, objType string) {
 	if !utils.FileOrFolderExists(fpath) {
 		return
 	}
-	checkViews(sqlInfoArr, fpath)
-	checkSql(sqlInfoArr, fpath)
-	checkGist(sqlInfoArr, fpath)
-	checkGin(sqlInfoArr, fpath)
-	checkDDL(sqlInfoArr, fpath)
-	checkForeign(sqlInfoArr, fpath)
-	checkRemaining(sqlInfoArr, fpath)
+	for i := range sqlInfoArr {
+		sqlInfo := &sqlInfoArr[i]
+		if sqlInfo.parseErr != nil {
+			checkUnparsedStmt(sqlInfo, fpath, objType)
+			continue
+		}
+		for _, rawStmt := range sqlInfo.parseTree {
+			c := &stmtChecker{fpath: fpath, sqlInfo: sqlInfo, objType: objType, stmtStart: queryparser.StmtStart(sqlInfo.formattedStmt, rawStmt)}
+			c.check(rawStmt.Stmt)
+			checkFunctionBody(c, rawStmt)
+		}
+	}
 }
 
 func checkExtensions(sqlInfoArr []sqlInfo, fpath string) {
 	for _, sqlInfo := range sqlInfoArr {
 		if sqlInfo.objName != "" && !slices.Contains(supportedExtensionsOnYB, sqlInfo.objName) {
 			summaryMap["EXTENSION"].invalidCount[sqlInfo.objName] = true
-			reportCase(fpath, "This extension is not supported in YugabyteDB.", "", "", "EXTENSION",
+			reportCaseAt(sqlInfo.startPosition(), fpath, "This extension is not supported in YugabyteDB.", "", "", "EXTENSION",
 				sqlInfo.objName, sqlInfo.formattedStmt)
 		}
 		if strings.ToLower(sqlInfo.objName) == "hll" {
@@ -713,18 +1106,96 @@ func getCreateObjRegex(objType string) (*regexp.Regexp, int) {
 	return createObjRegex, objNameIndex
 }
 
-func processCollectedSql(fpath string, stmt string, formattedStmt string, objType string, reportNextSql *int) sqlInfo {
-	createObjRegex, objNameIndex := getCreateObjRegex(objType)
+// Returns the name of the object of type objType created by the first of the statements creating one
+func getCreateObjName(stmts []*pg_query.RawStmt, objType string) (string, bool) {
+	for _, rawStmt := range stmts {
+		stmt := rawStmt.Stmt
+		switch objType {
+		case "TABLE":
+			if createTable := stmt.GetCreateStmt(); createTable != nil {
+				return queryparser.RangeVarName(createTable.Relation), true
+			}
+		case "INDEX", "PARTITION_INDEX", "FTS_INDEX":
+			if index := stmt.GetIndexStmt(); index != nil {
+				return queryparser.QuoteIdentifier(index.Idxname), true
+			}
+		case "VIEW", "SYNONYM":
+			if view := stmt.GetViewStmt(); view != nil {
+				return queryparser.RangeVarName(view.View), true
+			}
+		case "MVIEW":
+			if mview := stmt.GetCreateTableAsStmt(); mview != nil && mview.Objtype == pg_query.ObjectType_OBJECT_MATVIEW {
+				return queryparser.RangeVarName(mview.Into.GetRel()), true
+			}
+		case "SCHEMA", "PACKAGE":
+			if schema := stmt.GetCreateSchemaStmt(); schema != nil {
+				return queryparser.QuoteIdentifier(schema.Schemaname), true
+			}
+		case "SEQUENCE":
+			if sequence := stmt.GetCreateSeqStmt(); sequence != nil {
+				return queryparser.RangeVarName(sequence.Sequence), true
+			}
+		case "FUNCTION", "PROCEDURE":
+			if function := stmt.GetCreateFunctionStmt(); function != nil && function.IsProcedure == (objType == "PROCEDURE") {
+				return queryparser.NameFromList(function.Funcname), true
+			}
+		case "TRIGGER":
+			if trigger := stmt.GetCreateTrigStmt(); trigger != nil {
+				return queryparser.QuoteIdentifier(trigger.Trigname), true
+			}
+		case "TYPE":
+			switch {
+			case stmt.GetCompositeTypeStmt() != nil:
+				return queryparser.RangeVarName(stmt.GetCompositeTypeStmt().Typevar), true
+			case stmt.GetCreateEnumStmt() != nil:
+				return queryparser.NameFromList(stmt.GetCreateEnumStmt().TypeName), true
+			case stmt.GetCreateRangeStmt() != nil:
+				return queryparser.NameFromList(stmt.GetCreateRangeStmt().TypeName), true
+			case stmt.GetDefineStmt() != nil && stmt.GetDefineStmt().Kind == pg_query.ObjectType_OBJECT_TYPE:
+				return queryparser.NameFromList(stmt.GetDefineStmt().Defnames), true
+			}
+		case "DOMAIN":
+			if domain := stmt.GetCreateDomainStmt(); domain != nil {
+				return queryparser.NameFromList(domain.Domainname), true
+			}
+		case "EXTENSION":
+			if extension := stmt.GetCreateExtensionStmt(); extension != nil {
+				return extension.Extname, true
+			}
+		case "COLLATION", "AGGREGATE":
+			kind := lo.Ternary(objType == "COLLATION", pg_query.ObjectType_OBJECT_COLLATION, pg_query.ObjectType_OBJECT_AGGREGATE)
+			if define := stmt.GetDefineStmt(); define != nil && define.Kind == kind {
+				return queryparser.NameFromList(define.Defnames), true
+			}
+		case "RULE":
+			if rule := stmt.GetRuleStmt(); rule != nil {
+				return queryparser.QuoteIdentifier(rule.Rulename), true
+			}
+		}
+	}
+	return "", false
+}
+
+func processCollectedSql(fpath string, stmt string, formattedStmt string, lineNumbers []int, objType string, reportNextSql *int) sqlInfo {
+	formattedStmt = strings.TrimRight(formattedStmt, "\n") //removing new line from end
+	parseTree, parseErr := queryparser.Parse(formattedStmt)
+
 	var objName = "" // to extract from sql statement
+	var isCreateObjStmt bool
+	if parseErr == nil {
+		objName, isCreateObjStmt = getCreateObjName(parseTree, objType)
+	} else {
+		// statements not accepted by the PostgreSQL grammar, e.g. in the syntax of the source database
+		createObjRegex, objNameIndex := getCreateObjRegex(objType)
+		if createObjStmt := createObjRegex.FindStringSubmatch(formattedStmt); createObjStmt != nil {
+			objName, isCreateObjStmt = createObjStmt[objNameIndex], true
+		}
+	}
 
 	//update about sqlStmt in the summary variable for the report generation part
-	createObjStmt := createObjRegex.FindStringSubmatch(formattedStmt)
-	if createObjStmt != nil {
-		objName = createObjStmt[objNameIndex]
-		if summaryMap != nil && summaryMap[objType] != nil { //when just createSqlStrArray() is called from someother file, then no summaryMap exists
-			summaryMap[objType].totalCount += 1
-			summaryMap[objType].objSet[objName] = true
-		}
+	if isCreateObjStmt && summaryMap != nil && summaryMap[objType] != nil { //when just createSqlStrArray() is called from someother file, then no summaryMap exists
+		summaryMap[objType].totalCount += 1
+		summaryMap[objType].objSet[objName] = true
 	}
 
 	if *reportNextSql > 0 && (summaryMap != nil && summaryMap[objType] != nil) {
@@ -732,12 +1203,13 @@ func processCollectedSql(fpath string, stmt string, formattedStmt string, objTyp
 		*reportNextSql = 0 //reset flag
 	}
 
-	formattedStmt = strings.TrimRight(formattedStmt, "\n") //removing new line from end
-
 	sqlInfo := sqlInfo{
 		objName:       objName,
 		stmt:          stmt,
 		formattedStmt: formattedStmt,
+		lineNumbers:   lineNumbers,
+		parseTree:     parseTree,
+		parseErr:      parseErr,
 	}
 	return sqlInfo
 }
@@ -767,12 +1239,13 @@ func createSqlStrInfoArray(path string, objType string) []sqlInfo {
 		}
 
 		var stmt, formattedStmt string
+		var lineNumbers []int
 		if isStartOfCodeBlockSqlStmt(currLine) {
-			stmt, formattedStmt = collectSqlStmtContainingCode(lines, &i)
+			stmt, formattedStmt, lineNumbers = collectSqlStmtContainingCode(lines, &i)
 		} else {
-			stmt, formattedStmt = collectSqlStmt(lines, &i)
+			stmt, formattedStmt, lineNumbers = collectSqlStmt(lines, &i)
 		}
-		sqlInfo := processCollectedSql(path, stmt, formattedStmt, objType, &reportNextSql)
+		sqlInfo := processCollectedSql(path, stmt, formattedStmt, lineNumbers, objType, &reportNextSql)
 		sqlInfoArr = append(sqlInfoArr, sqlInfo)
 	}
 
@@ -794,13 +1267,14 @@ const (
 	CODE_BLOCK_COMPLETED   = 2
 )
 
-func collectSqlStmtContainingCode(lines []string, i *int) (string, string) {
+func collectSqlStmtContainingCode(lines []string, i *int) (string, string, []int) {
 	dollarQuoteFlag := CODE_BLOCK_NOT_STARTED
 	// Delimiter to outermost Code Block if nested Code Blocks present
 	codeBlockDelimiter := ""
 
 	stmt := ""
 	formattedStmt := ""
+	var lineNumbers []int
 
 sqlParsingLoop:
 	for ; *i < len(lines); *i++ {
@@ -811,6 +1285,7 @@ sqlParsingLoop:
 
 		stmt += currLine + " "
 		formattedStmt += currLine + "\n"
+		lineNumbers = append(lineNumbers, *i+1)
 
 		// Assuming that both the dollar quote strings will not be in same line
 		switch dollarQuoteFlag {
@@ -835,12 +1310,13 @@ sqlParsingLoop:
 		}
 	}
 
-	return stmt, formattedStmt
+	return stmt, formattedStmt, lineNumbers
 }
 
-func collectSqlStmt(lines []string, i *int) (string, string) {
+func collectSqlStmt(lines []string, i *int) (string, string, []int) {
 	stmt := ""
 	formattedStmt := ""
+	var lineNumbers []int
 	for ; *i < len(lines); *i++ {
 		currLine := strings.TrimRight(lines[*i], " ")
 		if len(currLine) == 0 {
@@ -849,12 +1325,13 @@ func collectSqlStmt(lines []string, i *int) (string, string) {
 
 		stmt += currLine + " "
 		formattedStmt += currLine + "\n"
+		lineNumbers = append(lineNumbers, *i+1)
 
 		if isEndOfSqlStmt(currLine) {
 			break
 		}
 	}
-	return stmt, formattedStmt
+	return stmt, formattedStmt, lineNumbers
 }
 
 func isEndOfSqlStmt(line string) bool {
@@ -889,6 +1366,43 @@ func initializeSummaryMap() {
 
 }
 
+// Returns the position of the issue in its file as ":line:column", to be appended to the file path
+func getIssuePositionSuffix(issue utils.Issue) string {
+	if issue.Line == 0 {
+		return ""
+	}
+	return fmt.Sprintf(":%d:%d", issue.Line, issue.Column)
+}
+
+// Returns the HTML list item of the issue followed by its details in an unclosed list
+func getIssueHTML(issue utils.Issue) string {
+	var htmlstring string
+	if issue.ObjectType != "" {
+		htmlstring += "<li>Issue in Object " + issue.ObjectType + ":</li><ul>"
+	} else {
+		htmlstring += "<li>Issue " + issue.ObjectType + ":</li><ul>"
+	}
+	if issue.ObjectName != "" {
+		htmlstring += "<li>Object Name: " + issue.ObjectName + "</li>"
+	}
+	if issue.Reason != "" {
+		htmlstring += "<li>Reason: " + issue.Reason + "</li>"
+	}
+	if issue.SqlStatement != "" {
+		htmlstring += "<li>SQL Statement: " + issue.SqlStatement + "</li>"
+	}
+	if issue.FilePath != "" {
+		htmlstring += "<li>File Path: " + issue.FilePath + getIssuePositionSuffix(issue) + "<a href='" + issue.FilePath + "'> [Preview]</a></li>"
+	}
+	if issue.Suggestion != "" {
+		htmlstring += "<li>Suggestion: " + issue.Suggestion + "</li>"
+	}
+	if issue.GH != "" {
+		htmlstring += "<li><a href='" + issue.GH + "'>Github Issue Link</a></li>"
+	}
+	return htmlstring
+}
+
 func generateHTMLReport(Report utils.Report) string {
 	//appending to doc line by line for better readability
 
@@ -916,32 +1430,34 @@ func generateHTMLReport(Report utils.Report) string {
 	//Issues/Error messages
 	htmlstring += "<ul list-style-type='disc'>"
 	for i := 0; i < len(Report.Issues); i++ {
-		if Report.Issues[i].ObjectType != "" {
-			htmlstring += "<li>Issue in Object " + Report.Issues[i].ObjectType + ":</li><ul>"
-		} else {
-			htmlstring += "<li>Issue " + Report.Issues[i].ObjectType + ":</li><ul>"
-		}
-		if Report.Issues[i].ObjectName != "" {
-			htmlstring += "<li>Object Name: " + Report.Issues[i].ObjectName + "</li>"
-		}
-		if Report.Issues[i].Reason != "" {
-			htmlstring += "<li>Reason: " + Report.Issues[i].Reason + "</li>"
-		}
-		if Report.Issues[i].SqlStatement != "" {
-			htmlstring += "<li>SQL Statement: " + Report.Issues[i].SqlStatement + "</li>"
-		}
-		if Report.Issues[i].FilePath != "" {
-			htmlstring += "<li>File Path: " + Report.Issues[i].FilePath + "<a href='" + Report.Issues[i].FilePath + "'> [Preview]</a></li>"
-		}
-		if Report.Issues[i].Suggestion != "" {
-			htmlstring += "<li>Suggestion: " + Report.Issues[i].Suggestion + "</li>"
-		}
-		if Report.Issues[i].GH != "" {
-			htmlstring += "<li><a href='" + Report.Issues[i].GH + "'>Github Issue Link</a></li>"
-		}
+		htmlstring += getIssueHTML(Report.Issues[i])
 		htmlstring += "</ul>"
 	}
 	htmlstring += "</ul>"
+	if len(Report.WaivedIssues) > 0 {
+		htmlstring += "<h3>Waived Issues</h3>"
+		htmlstring += "<ul list-style-type='disc'>"
+		for i := 0; i < len(Report.WaivedIssues); i++ {
+			htmlstring += getIssueHTML(Report.WaivedIssues[i].Issue)
+			if Report.WaivedIssues[i].Justification != "" {
+				htmlstring += "<li>Justification: " + Report.WaivedIssues[i].Justification + "</li>"
+			}
+			if Report.WaivedIssues[i].Expiry != "" {
+				htmlstring += "<li>Waiver Expiry: " + Report.WaivedIssues[i].Expiry + "</li>"
+			}
+			htmlstring += "</ul>"
+		}
+		htmlstring += "</ul>"
+	}
+	if len(Report.AppliedFixes) > 0 {
+		htmlstring += "<h3>Applied Fixes</h3>"
+		htmlstring += "<table width='100%' table-layout='fixed'><tr><th>Object</th><th>Object Name</th><th>Fix</th><th>File Path</th><th>Backup File Path</th></tr>"
+		for _, fix := range Report.AppliedFixes {
+			htmlstring += "<tr><td>" + fix.ObjectType + "</td><td>" + fix.ObjectName + "</td><td>" + fix.Description + "</td><td>" +
+				fix.FilePath + ":" + strconv.Itoa(fix.Line) + "</td><td>" + fix.BackupFilePath + "</td></tr>"
+		}
+		htmlstring += "</table>"
+	}
 	if len(Report.Summary.Notes) > 0 {
 		htmlstring += "<h3>Notes</h3>"
 		htmlstring += "<ul list-style-type='disc'>"
@@ -985,7 +1501,7 @@ func generateTxtReport(Report utils.Report) string {
 		txtstring += "-Object Name: " + Report.Issues[i].ObjectName + "\n"
 		txtstring += "-Reason: " + Report.Issues[i].Reason + "\n"
 		txtstring += "-SQL Statement: " + Report.Issues[i].SqlStatement + "\n"
-		txtstring += "-File Path: " + Report.Issues[i].FilePath + "\n"
+		txtstring += "-File Path: " + Report.Issues[i].FilePath + getIssuePositionSuffix(Report.Issues[i]) + "\n"
 		if Report.Issues[i].Suggestion != "" {
 			txtstring += "-Suggestion: " + Report.Issues[i].Suggestion + "\n"
 		}
@@ -994,6 +1510,32 @@ func generateTxtReport(Report utils.Report) string {
 		}
 		txtstring += "\n"
 	}
+	if len(Report.WaivedIssues) != 0 {
+		txtstring += "Waived Issues:\n\n"
+	}
+	for _, waivedIssue := range Report.WaivedIssues {
+		txtstring += "Waived in Object " + waivedIssue.ObjectType + ":\n"
+		txtstring += "-Object Name: " + waivedIssue.ObjectName + "\n"
+		txtstring += "-Reason: " + waivedIssue.Reason + "\n"
+		txtstring += "-File Path: " + waivedIssue.FilePath + getIssuePositionSuffix(waivedIssue.Issue) + "\n"
+		if waivedIssue.Justification != "" {
+			txtstring += "-Justification: " + waivedIssue.Justification + "\n"
+		}
+		if waivedIssue.Expiry != "" {
+			txtstring += "-Waiver Expiry: " + waivedIssue.Expiry + "\n"
+		}
+		txtstring += "\n"
+	}
+	if len(Report.AppliedFixes) != 0 {
+		txtstring += "Applied Fixes:\n\n"
+	}
+	for _, fix := range Report.AppliedFixes {
+		txtstring += "Fixed Object " + fix.ObjectType + ":\n"
+		txtstring += "-Object Name: " + fix.ObjectName + "\n"
+		txtstring += "-Fix: " + fix.Description + "\n"
+		txtstring += "-File Path: " + fix.FilePath + ":" + strconv.Itoa(fix.Line) + "\n"
+		txtstring += "-Backup File Path: " + fix.BackupFilePath + "\n\n"
+	}
 	if len(Report.Summary.Notes) > 0 {
 		txtstring += "Notes:\n\n"
 		for i := 0; i < len(Report.Summary.Notes); i++ {
@@ -1013,6 +1555,9 @@ func analyzeSchemaInternal() utils.Report {
 	reportStruct = utils.Report{}
 	schemaDir := filepath.Join(exportDir, "schema")
 	sourceObjList = utils.GetSchemaObjectList(msr.SourceDBConf.DBType)
+	if applyFixes {
+		applySchemaFixes(schemaDir)
+	}
 	initializeSummaryMap()
 	for _, objType := range sourceObjList {
 		var sqlInfoArr []sqlInfo
@@ -1029,9 +1574,11 @@ func analyzeSchemaInternal() utils.Report {
 		if objType == "EXTENSION" {
 			checkExtensions(sqlInfoArr, filePath)
 		}
-		checker(sqlInfoArr, filePath)
+		checker(sqlInfoArr, filePath, objType)
+		checkUserRules(sqlInfoArr, filePath, objType)
 	}
 
+	applyIssueWaivers(time.Now())
 	reportSummary()
 	return reportStruct
 }
@@ -1041,7 +1588,7 @@ func analyzeSchema() {
 	if err != nil {
 		utils.ErrExit("failed to get migration UUID: %w", err)
 	}
-	reportFile := "schema_analysis_report." + outputFormat
+	reportFile := getReportFileName(outputFormat)
 
 	schemaAnalysisStartedEvent := createSchemaAnalysisStartedEvent()
 	controlPlane.SchemaAnalysisStarted(&schemaAnalysisStartedEvent)
@@ -1072,6 +1619,10 @@ func analyzeSchema() {
 	case "xml":
 		byteReport, _ := xml.MarshalIndent(reportStruct, "", "\t")
 		finalReport = string(byteReport)
+	case "sarif":
+		finalReport = generateSarifReport(reportStruct)
+	case "junit":
+		finalReport = generateJUnitReport(reportStruct)
 	default:
 		panic(fmt.Sprintf("invalid report format: %q", outputFormat))
 	}
@@ -1125,6 +1676,8 @@ var analyzeSchemaCmd = &cobra.Command{
 	Long: ``,
 	PreRun: func(cmd *cobra.Command, args []string) {
 		validateReportOutputFormat()
+		loadUserRules()
+		loadIssueWaivers()
 	},
 
 	Run: func(cmd *cobra.Command, args []string) {
@@ -1136,11 +1689,16 @@ func init() {
 	rootCmd.AddCommand(analyzeSchemaCmd)
 	registerCommonGlobalFlags(analyzeSchemaCmd)
 	analyzeSchemaCmd.PersistentFlags().StringVar(&outputFormat, "output-format", "txt",
-		"format in which report will be generated: (html, txt, json, xml)")
+		"format in which report will be generated: (html, txt, json, xml, sarif, junit)")
+	registerRulesFileFlag(analyzeSchemaCmd)
+	registerWaiversFileFlag(analyzeSchemaCmd)
+	BoolVar(analyzeSchemaCmd.Flags(), &applyFixes, "apply-fixes", false,
+		"fix the issues which have mechanical fixes(WITH OIDS, stored generated columns, SET STORAGE, CLUSTER ON and SET STATISTICS) "+
+			"by rewriting the schema files in the export directory. The original files are backed up with the .bak extension and the fixes are listed in the report")
 }
 
 func validateReportOutputFormat() {
-	allowedOutputFormats := []string{"html", "json", "txt", "xml"}
+	allowedOutputFormats := []string{"html", "json", "txt", "xml", "sarif", "junit"}
 	outputFormat = strings.ToLower(outputFormat)
 
 	for i := 0; i < len(allowedOutputFormats); i++ {
@@ -0,0 +1,498 @@
+/*
+Copyright (c) YugabyteDB, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	pg_query "github.com/pganalyze/pg_query_go/v5"
+	"github.com/samber/lo"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/exp/slices"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/yugabyte/yb-voyager/yb-voyager/src/queryparser"
+	"github.com/yugabyte/yb-voyager/yb-voyager/src/utils"
+)
+
+var applyFixes utils.BoolStr
+
+const FIX_COMMENT_PREFIX = "-- yb-voyager analyze-schema --apply-fixes: "
+
+// schemaFix is a mechanical fix of an issue in a statement of a schema file, replacing the text between start and end.
+type schemaFix struct {
+	// offsets in sqlInfo.formattedStmt of the text to be replaced
+	start       int
+	end         int
+	replacement string
+	// empty for the fixes which are a part of another fix
+	description string
+	objType     string
+	objName     string
+	// if not empty, the replacement is a statement added to the end of the schema file of this object type instead
+	appendToObjType string
+}
+
+// Descriptions of the fixes of the ALTER TABLE commands which are removed from the schema
+var removableAlterTableCmds = map[pg_query.AlterTableType]string{
+	pg_query.AlterTableType_AT_SetStorage:    "Removed ALTER TABLE ALTER column SET STORAGE",
+	pg_query.AlterTableType_AT_ClusterOn:     "Removed ALTER TABLE CLUSTER ON",
+	pg_query.AlterTableType_AT_DropOids:      "Removed ALTER TABLE SET WITHOUT OIDS",
+	pg_query.AlterTableType_AT_SetStatistics: "Commented out ALTER column SET STATISTICS",
+}
+
+// Applies the fixes of the issues which can be fixed mechanically to the schema files, after backing them up,
+// and records the fixes in reportStruct.AppliedFixes.
+func applySchemaFixes(schemaDir string) {
+	backupSuffix := "." + time.Now().Format("20060102T150405") + ".bak"
+	objTypes := append(slices.Clone(sourceObjList), "PARTITION_INDEX", "FTS_INDEX")
+	fileFixes := make(map[string][]indexedSchemaFix)
+	fileSqlInfoArrs := make(map[string][]sqlInfo)
+	appendedStmts := make(map[string][]string)
+	for _, objType := range objTypes {
+		filePath := utils.GetObjectFilePath(schemaDir, objType)
+		if !utils.FileOrFolderExists(filePath) {
+			continue
+		}
+		fixes, sqlInfoArr := getSchemaFixes(filePath, objType)
+		for _, fix := range fixes {
+			if fix.appendToObjType != "" {
+				appendToFilePath := utils.GetObjectFilePath(schemaDir, fix.appendToObjType)
+				appendedStmts[appendToFilePath] = append(appendedStmts[appendToFilePath], fix.replacement)
+			} else {
+				fileFixes[filePath] = append(fileFixes[filePath], fix)
+			}
+		}
+		fileSqlInfoArrs[filePath] = sqlInfoArr
+	}
+	// the statements are added to the files after all the files are analyzed for fixes
+	for _, objType := range objTypes {
+		filePath := utils.GetObjectFilePath(schemaDir, objType)
+		if len(fileFixes[filePath]) == 0 && len(appendedStmts[filePath]) == 0 {
+			continue
+		}
+		backupFilePath := filePath + backupSuffix
+		applied, err := applyFixesToFile(filePath, backupFilePath, fileSqlInfoArrs[filePath], fileFixes[filePath], appendedStmts[filePath])
+		if err != nil {
+			utils.ErrExit("apply fixes to %q: %v", filePath, err)
+		}
+		if len(applied) > 0 {
+			utils.PrintAndLog("applied %d fix(es) to %q, the original file is backed up at %q", len(applied), filePath, backupFilePath)
+		} else {
+			utils.PrintAndLog("added %d statement(s) of the fixes to %q", len(appendedStmts[filePath]), filePath)
+		}
+		reportStruct.AppliedFixes = append(reportStruct.AppliedFixes, applied...)
+	}
+	// the statements collected for fixing are not to be counted in the summary of the analysis
+	summaryMap = make(map[string]*summaryInfo)
+}
+
+// Returns the fixes for the statements in the schema file, each with the index of its statement in the returned sqlInfoArr
+func getSchemaFixes(filePath string, objType string) ([]indexedSchemaFix, []sqlInfo) {
+	sqlInfoArr := createSqlStrInfoArray(filePath, objType)
+	var fixes []indexedSchemaFix
+	for i := range sqlInfoArr {
+		var stmtFixes []schemaFix
+		if sqlInfoArr[i].parseErr != nil {
+			stmtFixes = getUnparsedStmtFixes(&sqlInfoArr[i])
+		} else {
+			for _, rawStmt := range sqlInfoArr[i].parseTree {
+				stmtFixes = append(stmtFixes, getStmtFixes(&sqlInfoArr[i], rawStmt)...)
+			}
+		}
+		for _, fix := range stmtFixes {
+			fixes = append(fixes, indexedSchemaFix{schemaFix: fix, sqlInfoIdx: i})
+		}
+	}
+	return fixes, sqlInfoArr
+}
+
+type indexedSchemaFix struct {
+	schemaFix
+	sqlInfoIdx int
+}
+
+// Returns the offset in the file of the offset in sqlInfo.formattedStmt. lineStarts are the offsets of the lines in the file.
+// The lines of formattedStmt are the lines of the file with the trailing spaces removed.
+func getFileOffset(sqlInfo *sqlInfo, offset int, lineStarts []int) int {
+	before := sqlInfo.formattedStmt[:offset]
+	lineIdx := lo.Min([]int{strings.Count(before, "\n"), len(sqlInfo.lineNumbers) - 1})
+	return lineStarts[sqlInfo.lineNumbers[lineIdx]-1] + len(before) - (strings.LastIndex(before, "\n") + 1)
+}
+
+// Rewrites the file with the fixes and the statements appended, after copying the original file to backupFilePath.
+// The file is created if it does not exist, for the appended statements.
+func applyFixesToFile(filePath string, backupFilePath string, sqlInfoArr []sqlInfo, fixes []indexedSchemaFix, appendedStmts []string) ([]utils.AppliedFix, error) {
+	var content []byte
+	fileExists := utils.FileOrFolderExists(filePath)
+	if fileExists {
+		var err error
+		content, err = os.ReadFile(filePath)
+		if err != nil {
+			return nil, fmt.Errorf("read file: %w", err)
+		}
+	}
+	fixed, applied := getFixedText(string(content), filePath, backupFilePath, sqlInfoArr, fixes)
+	for _, stmt := range appendedStmts {
+		if fixed != "" {
+			fixed = strings.TrimRight(fixed, "\n") + "\n\n"
+		}
+		fixed += stmt + "\n"
+	}
+
+	if fileExists {
+		err := os.WriteFile(backupFilePath, content, 0644)
+		if err != nil {
+			return nil, fmt.Errorf("backup file: %w", err)
+		}
+	} else {
+		err := os.MkdirAll(filepath.Dir(filePath), 0755)
+		if err != nil {
+			return nil, fmt.Errorf("create directory: %w", err)
+		}
+	}
+	err := os.WriteFile(filePath, []byte(fixed), 0644)
+	if err != nil {
+		return nil, fmt.Errorf("write file: %w", err)
+	}
+	return applied, nil
+}
+
+// Returns the text of the file filePath with the fixes applied, and the fixes applied
+func getFixedText(text string, filePath string, backupFilePath string, sqlInfoArr []sqlInfo, fixes []indexedSchemaFix) (string, []utils.AppliedFix) {
+	lineStarts := []int{0}
+	for i, ch := range text {
+		if ch == '\n' {
+			lineStarts = append(lineStarts, i+1)
+		}
+	}
+
+	type fileEdit struct {
+		start, end  int
+		replacement string
+		fix         indexedSchemaFix
+	}
+	var edits []fileEdit
+	for _, fix := range fixes {
+		sqlInfo := &sqlInfoArr[fix.sqlInfoIdx]
+		edit := fileEdit{
+			start:       getFileOffset(sqlInfo, fix.start, lineStarts),
+			end:         getFileOffset(sqlInfo, fix.end, lineStarts),
+			replacement: fix.replacement,
+			fix:         fix,
+		}
+		// removal of whole lines includes the line break
+		lineEnd := edit.end + strings.IndexByte(text[edit.end:]+"\n", '\n')
+		if edit.replacement == "" && (edit.start == 0 || text[edit.start-1] == '\n') && strings.TrimRight(text[edit.end:lineEnd], " ") == "" {
+			edit.end = lo.Min([]int{lineEnd + 1, len(text)})
+		}
+		edits = append(edits, edit)
+	}
+	sort.SliceStable(edits, func(i, j int) bool { return edits[i].start < edits[j].start })
+
+	var applied []utils.AppliedFix
+	var fixed strings.Builder
+	last := 0
+	for _, edit := range edits {
+		fix := edit.fix
+		if edit.start < last {
+			log.Warnf("skipping the fix %q of %s %s in %q overlapping with another fix", fix.description, fix.objType, fix.objName, filePath)
+			continue
+		}
+		fixed.WriteString(text[last:edit.start])
+		fixed.WriteString(edit.replacement)
+		last = edit.end
+		if fix.description == "" {
+			continue
+		}
+		applied = append(applied, utils.AppliedFix{
+			ObjectType:     fix.objType,
+			ObjectName:     fix.objName,
+			Description:    fix.description,
+			FilePath:       filePath,
+			Line:           sqlInfoArr[fix.sqlInfoIdx].position(fix.start).line,
+			BackupFilePath: backupFilePath,
+		})
+	}
+	fixed.WriteString(text[last:])
+	return fixed.String(), applied
+}
+
+// Returns the end offset of the statement in sqlInfo.formattedStmt, including the terminating semicolon
+func getStmtEnd(sqlInfo *sqlInfo, rawStmt *pg_query.RawStmt) int {
+	end := len(sqlInfo.formattedStmt)
+	if rawStmt.StmtLen > 0 {
+		end = int(rawStmt.StmtLocation + rawStmt.StmtLen)
+	}
+	if rest := strings.TrimLeft(sqlInfo.formattedStmt[end:], " \t\n"); strings.HasPrefix(rest, ";") {
+		end = len(sqlInfo.formattedStmt) - len(rest) + 1
+	}
+	return end
+}
+
+// Returns the text commenting out the lines of the statement, following a comment with the reason
+func commentOut(stmt string, reason string) string {
+	lines := strings.Split(stmt, "\n")
+	for i := range lines {
+		lines[i] = "-- " + lines[i]
+	}
+	return FIX_COMMENT_PREFIX + reason + "\n" + strings.Join(lines, "\n")
+}
+
+func getStmtFixes(sqlInfo *sqlInfo, rawStmt *pg_query.RawStmt) []schemaFix {
+	tokens, err := queryparser.Tokens(sqlInfo.formattedStmt)
+	if err != nil {
+		log.Infof("scan %q: %v", sqlInfo.formattedStmt, err)
+		return nil
+	}
+	var fixes []schemaFix
+	switch {
+	case rawStmt.Stmt.GetCreateStmt() != nil:
+		createTable := rawStmt.Stmt.GetCreateStmt()
+		tableName := queryparser.RangeVarName(createTable.Relation)
+		for _, element := range createTable.TableElts {
+			if column := element.GetColumnDef(); column != nil {
+				fixes = append(fixes, getGeneratedColumnFixes(sqlInfo, rawStmt, tokens, createTable.Relation, column)...)
+			}
+		}
+		for _, option := range createTable.Options {
+			if option.GetDefElem().GetDefname() == "oids" && isOptionEnabled(option.GetDefElem()) {
+				if fix, ok := getRemoveOptionFix(tokens, int(option.GetDefElem().Location)); ok {
+					fix.description, fix.objType, fix.objName = "Removed WITH (OIDS = true)", "TABLE", tableName
+					fixes = append(fixes, fix)
+				}
+			}
+		}
+	case rawStmt.Stmt.GetAlterTableStmt() != nil:
+		alterTable := rawStmt.Stmt.GetAlterTableStmt()
+		for _, node := range alterTable.Cmds {
+			cmd := node.GetAlterTableCmd()
+			if cmd.GetSubtype() == pg_query.AlterTableType_AT_AddColumn && cmd.Def.GetColumnDef() != nil {
+				fixes = append(fixes, getGeneratedColumnFixes(sqlInfo, rawStmt, tokens, alterTable.Relation, cmd.Def.GetColumnDef())...)
+			}
+		}
+		if len(fixes) == 0 {
+			fixes = getAlterTableFixes(sqlInfo, rawStmt, alterTable)
+		}
+	}
+	return fixes
+}
+
+// Returns the fix removing the option at the location from its WITH ( ... ) clause, or the whole clause if it is the only option
+func getRemoveOptionFix(tokens []queryparser.Token, location int) (schemaFix, bool) {
+	idx := slices.IndexFunc(tokens, func(token queryparser.Token) bool { return token.Start == location })
+	if idx <= 1 {
+		return schemaFix{}, false
+	}
+	end := idx
+	for depth := 0; end+1 < len(tokens); end++ {
+		next := tokens[end+1].Text
+		if depth == 0 && (next == "," || next == ")") {
+			break
+		}
+		if next == "(" {
+			depth++
+		} else if next == ")" {
+			depth--
+		}
+	}
+	if end+1 >= len(tokens) {
+		return schemaFix{}, false
+	}
+	prev, next := tokens[idx-1], tokens[end+1]
+	switch {
+	case prev.Text == "(" && next.Text == ")" && idx >= 3 && tokens[idx-2].Text == "WITH":
+		// the only option, remove WITH ( ... ) along with the preceding white spaces
+		return schemaFix{start: tokens[idx-3].End, end: next.End}, true
+	case next.Text == ",":
+		if end+2 >= len(tokens) {
+			return schemaFix{}, false
+		}
+		return schemaFix{start: tokens[idx].Start, end: tokens[end+2].Start}, true
+	case prev.Text == ",":
+		return schemaFix{start: prev.Start, end: tokens[end].End}, true
+	}
+	return schemaFix{}, false
+}
+
+// Returns the fixes replacing the stored generated column with a column set by a trigger:
+// GENERATED ALWAYS AS (expr) STORED is removed from the column, and a trigger setting the column to expr
+// on INSERT and UPDATE is added to the triggers, with its function added to the functions.
+func getGeneratedColumnFixes(sqlInfo *sqlInfo, rawStmt *pg_query.RawStmt, tokens []queryparser.Token, table *pg_query.RangeVar, column *pg_query.ColumnDef) []schemaFix {
+	var constraint *pg_query.Constraint
+	for _, node := range column.Constraints {
+		if node.GetConstraint().GetContype() == pg_query.ConstrType_CONSTR_GENERATED {
+			constraint = node.GetConstraint()
+		}
+	}
+	if constraint == nil {
+		return nil
+	}
+	// GENERATED ALWAYS AS ( expr ) STORED
+	idx := slices.IndexFunc(tokens, func(token queryparser.Token) bool { return token.Start == int(constraint.Location) })
+	if idx < 1 || idx+4 >= len(tokens) || tokens[idx+2].Text != "AS" || tokens[idx+3].Text != "(" {
+		return nil
+	}
+	exprEnd := idx + 4
+	for depth := 0; exprEnd < len(tokens) && (depth > 0 || tokens[exprEnd].Text != ")"); exprEnd++ {
+		if tokens[exprEnd].Text == "(" {
+			depth++
+		} else if tokens[exprEnd].Text == ")" {
+			depth--
+		}
+	}
+	if exprEnd+1 >= len(tokens) || tokens[exprEnd+1].Text != "STORED" {
+		return nil
+	}
+
+	// the columns in the expression are referred to as the fields of the NEW row in the trigger
+	exprStart := tokens[idx+4].Start
+	var columnLocations []int
+	queryparser.Walk(constraint.RawExpr, func(node *pg_query.Node) bool {
+		if columnRef := node.GetColumnRef(); columnRef != nil && len(columnRef.Fields) == 1 && columnRef.Fields[0].GetString_() != nil {
+			columnLocations = append(columnLocations, int(columnRef.Location))
+		}
+		return true
+	})
+	sort.Ints(columnLocations)
+	expr := ""
+	last := exprStart
+	for _, location := range columnLocations {
+		expr += sqlInfo.formattedStmt[last:location] + "NEW."
+		last = location
+	}
+	expr += sqlInfo.formattedStmt[last:tokens[exprEnd].Start]
+
+	tableName := queryparser.RangeVarName(table)
+	name := queryparser.QuoteIdentifier(fmt.Sprintf("%s_%s_generated", table.Relname, column.Colname))
+	functionName := name
+	if table.Schemaname != "" {
+		functionName = queryparser.QuoteIdentifier(table.Schemaname) + "." + name
+	}
+	function := fmt.Sprintf(`CREATE FUNCTION %s() RETURNS trigger
+    LANGUAGE plpgsql
+    AS $$
+BEGIN
+    NEW.%s := %s;
+    RETURN NEW;
+END;
+$$;`,
+		functionName, queryparser.QuoteIdentifier(column.Colname), strings.TrimSpace(expr))
+	trigger := fmt.Sprintf(`CREATE TRIGGER %s BEFORE INSERT OR UPDATE ON %s FOR EACH ROW EXECUTE FUNCTION %s();`,
+		name, tableName, functionName)
+
+	description := fmt.Sprintf("Replaced the stored generated column %s with the trigger %s", column.Colname, name)
+	return []schemaFix{
+		{start: tokens[idx-1].End, end: tokens[exprEnd+1].End, description: description, objType: "TABLE", objName: tableName},
+		// recorded in the report as a part of the previous fix
+		{replacement: function, objType: "TABLE", objName: tableName, appendToObjType: "FUNCTION"},
+		{replacement: trigger, objType: "TABLE", objName: tableName, appendToObjType: "TRIGGER"},
+	}
+}
+
+// Returns the fix removing the unsupported commands of ALTER TABLE/INDEX which do not change the data or the constraints.
+// The statement is removed if all of its commands are removed, and rewritten with the rest of the commands otherwise.
+// SET STATISTICS is commented out instead, to keep it for reference.
+func getAlterTableFixes(sqlInfo *sqlInfo, rawStmt *pg_query.RawStmt, alterTable *pg_query.AlterTableStmt) []schemaFix {
+	if !slices.Contains([]pg_query.ObjectType{pg_query.ObjectType_OBJECT_TABLE, pg_query.ObjectType_OBJECT_FOREIGN_TABLE,
+		pg_query.ObjectType_OBJECT_INDEX, pg_query.ObjectType_OBJECT_MATVIEW}, alterTable.Objtype) {
+		return nil
+	}
+	var descriptions []string
+	var keptCmds []*pg_query.Node
+	commentOutStmt := false
+	for _, node := range alterTable.Cmds {
+		subtype := node.GetAlterTableCmd().GetSubtype()
+		if description, ok := removableAlterTableCmds[subtype]; ok {
+			descriptions = append(descriptions, description)
+			commentOutStmt = commentOutStmt || subtype == pg_query.AlterTableType_AT_SetStatistics
+		} else {
+			keptCmds = append(keptCmds, node)
+		}
+	}
+	if len(descriptions) == 0 {
+		return nil
+	}
+	objType := lo.Ternary(alterTable.Objtype == pg_query.ObjectType_OBJECT_INDEX, "INDEX", "TABLE")
+	fix := schemaFix{
+		start:       queryparser.StmtStart(sqlInfo.formattedStmt, rawStmt),
+		end:         getStmtEnd(sqlInfo, rawStmt),
+		description: strings.Join(lo.Uniq(descriptions), ", "),
+		objType:     objType,
+		objName:     queryparser.RangeVarName(alterTable.Relation),
+	}
+	stmt := sqlInfo.formattedStmt[fix.start:fix.end]
+	if commentOutStmt {
+		fix.replacement = commentOut(stmt, fix.description)
+	}
+	if len(keptCmds) > 0 {
+		modified := proto.Clone(rawStmt).(*pg_query.RawStmt)
+		modified.Stmt.GetAlterTableStmt().Cmds = keptCmds
+		deparsed, err := pg_query.Deparse(&pg_query.ParseResult{Stmts: []*pg_query.RawStmt{modified}})
+		if err != nil {
+			log.Infof("deparse %q without the unsupported commands: %v", stmt, err)
+			return nil
+		}
+		if fix.replacement == "" {
+			fix.replacement = commentOut(stmt, fix.description)
+		}
+		fix.replacement += "\n" + deparsed + ";"
+	}
+	return []schemaFix{fix}
+}
+
+// Returns the fixes for the statements not accepted by the PostgreSQL grammar, based on their tokens
+func getUnparsedStmtFixes(sqlInfo *sqlInfo) []schemaFix {
+	sql := sqlInfo.formattedStmt
+	tokens, err := queryparser.Tokens(sql)
+	if err != nil {
+		log.Infof("scan %q: %v", sql, err)
+		return nil
+	}
+	text := func(i int) string {
+		if i < 0 || i >= len(tokens) {
+			return ""
+		}
+		return tokens[i].Text
+	}
+	tableName, nameEnd := getNameAfterKeyword(sql, tokens, "TABLE")
+	var fixes []schemaFix
+	for i := range tokens {
+		switch {
+		case text(i) == "SET" && text(i+1) == "WITH" && text(i+2) == "OIDS" && text(0) == "ALTER":
+			// ALTER TABLE name SET WITH OIDS [;]
+			if nameEnd == i && (i+3 == len(tokens) || (text(i+3) == ";" && i+4 == len(tokens))) {
+				fixes = append(fixes, schemaFix{start: tokens[0].Start, end: tokens[len(tokens)-1].End,
+					description: "Removed ALTER TABLE SET WITH OIDS", objType: "TABLE", objName: tableName})
+			} else if text(i+3) == "," {
+				fixes = append(fixes, schemaFix{start: tokens[i].Start, end: tokens[i+4].Start,
+					description: "Removed ALTER TABLE SET WITH OIDS", objType: "TABLE", objName: tableName})
+			} else if text(i-1) == "," {
+				fixes = append(fixes, schemaFix{start: tokens[i-1].Start, end: tokens[i+2].End,
+					description: "Removed ALTER TABLE SET WITH OIDS", objType: "TABLE", objName: tableName})
+			}
+		case text(i) == "WITH" && text(i+1) == "OIDS" && text(i-1) != "SET" && text(0) == "CREATE":
+			fixes = append(fixes, schemaFix{start: tokens[i-1].End, end: tokens[i+1].End,
+				description: "Removed WITH OIDS", objType: "TABLE", objName: lo.Ternary(sqlInfo.objName != "", sqlInfo.objName, tableName)})
+		}
+	}
+	return fixes
+}
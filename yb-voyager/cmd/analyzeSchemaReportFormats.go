@@ -0,0 +1,356 @@
+/*
+Copyright (c) YugabyteDB, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/samber/lo"
+
+	"github.com/yugabyte/yb-voyager/yb-voyager/src/utils"
+)
+
+// Extensions of the report file per output format, for formats where it differs from the format name.
+var reportFileExtensions = map[string]string{
+	"sarif": "sarif",
+	"junit": "junit.xml",
+}
+
+func getReportFileName(outputFormat string) string {
+	ext, ok := reportFileExtensions[outputFormat]
+	if !ok {
+		ext = outputFormat
+	}
+	return "schema_analysis_report." + ext
+}
+
+// ============================ SARIF ============================
+
+const (
+	SARIF_VERSION      = "2.1.0"
+	SARIF_SCHEMA       = "https://json.schemastore.org/sarif-2.1.0.json"
+	SARIF_URI_BASE_ID  = "EXPORT_DIR"
+	VOYAGER_INFO_URI   = "https://docs.yugabyte.com/preview/yugabyte-voyager/"
+	VOYAGER_TOOL_NAME  = "yb-voyager"
+	SARIF_RULE_ID_SIZE = 64
+)
+
+type SarifReport struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []SarifRun `json:"runs"`
+}
+
+type SarifRun struct {
+	Tool               SarifTool                        `json:"tool"`
+	OriginalUriBaseIds map[string]SarifArtifactLocation `json:"originalUriBaseIds,omitempty"`
+	Results            []SarifResult                    `json:"results"`
+}
+
+type SarifTool struct {
+	Driver SarifDriver `json:"driver"`
+}
+
+type SarifDriver struct {
+	Name           string      `json:"name"`
+	InformationUri string      `json:"informationUri"`
+	Version        string      `json:"version"`
+	Rules          []SarifRule `json:"rules"`
+}
+
+type SarifRule struct {
+	ID               string        `json:"id"`
+	ShortDescription SarifMessage  `json:"shortDescription"`
+	HelpUri          string        `json:"helpUri,omitempty"`
+	Help             *SarifMessage `json:"help,omitempty"`
+}
+
+type SarifMessage struct {
+	Text string `json:"text"`
+}
+
+type SarifResult struct {
+	RuleID       string             `json:"ruleId"`
+	RuleIndex    int                `json:"ruleIndex"`
+	Level        string             `json:"level"`
+	Message      SarifMessage       `json:"message"`
+	Locations    []SarifLocation    `json:"locations,omitempty"`
+	Suppressions []SarifSuppression `json:"suppressions,omitempty"`
+	Properties   map[string]string  `json:"properties,omitempty"`
+}
+
+// Suppression of a result which is waived in the waivers file
+type SarifSuppression struct {
+	Kind          string `json:"kind"`
+	Justification string `json:"justification,omitempty"`
+}
+
+type SarifLocation struct {
+	PhysicalLocation SarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type SarifPhysicalLocation struct {
+	ArtifactLocation SarifArtifactLocation `json:"artifactLocation"`
+	Region           *SarifRegion          `json:"region,omitempty"`
+}
+
+type SarifArtifactLocation struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId,omitempty"`
+}
+
+type SarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+var nonAlphaNumericRegex = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Returns the template of issueReasonTemplates the reason is made from, with its variable part left out, or the
+// reason itself if it has no variable part.
+func getIssueReasonKey(reason string) string {
+	for _, template := range issueReasonTemplates {
+		prefix, suffix, _ := strings.Cut(template, "%s")
+		if len(reason) >= len(prefix)+len(suffix) && strings.HasPrefix(reason, prefix) && strings.HasSuffix(reason, suffix) {
+			return strings.Replace(template, "%s", "...", 1)
+		}
+	}
+	return reason
+}
+
+// Rule ids are derived from the fixed part of the reason so that the same issue gets the same id across runs
+// of analyze-schema, and across the objects it is reported for.
+func getSarifRuleID(reason string) string {
+	reason = getIssueReasonKey(reason)
+	id := strings.Trim(nonAlphaNumericRegex.ReplaceAllString(strings.ToLower(reason), "-"), "-")
+	if len(id) > SARIF_RULE_ID_SIZE {
+		id = strings.TrimRight(id[:SARIF_RULE_ID_SIZE], "-")
+	}
+	if id == "" {
+		id = "unsupported-construct"
+	}
+	return id
+}
+
+// Returns the location of the issue's file relative to the export directory, so that code scanning tools
+// can map it to the schema files checked in along with the rest of the repository.
+func getSarifArtifactLocation(filePath string) SarifArtifactLocation {
+	relPath, err := filepath.Rel(exportDir, filePath)
+	if err != nil || strings.HasPrefix(relPath, "..") {
+		return SarifArtifactLocation{URI: filepath.ToSlash(filePath)}
+	}
+	return SarifArtifactLocation{URI: filepath.ToSlash(relPath), URIBaseID: SARIF_URI_BASE_ID}
+}
+
+func generateSarifReport(report utils.Report) string {
+	driver := SarifDriver{
+		Name:           VOYAGER_TOOL_NAME,
+		InformationUri: VOYAGER_INFO_URI,
+		Version:        utils.YB_VOYAGER_VERSION,
+		Rules:          []SarifRule{},
+	}
+	ruleIndex := make(map[string]int)
+	results := []SarifResult{}
+	addResult := func(issue utils.Issue, suppressions []SarifSuppression) {
+		ruleID := getSarifRuleID(issue.Reason)
+		index, ok := ruleIndex[ruleID]
+		if !ok {
+			index = len(driver.Rules)
+			ruleIndex[ruleID] = index
+			rule := SarifRule{
+				ID:               ruleID,
+				ShortDescription: SarifMessage{Text: getIssueReasonKey(issue.Reason)},
+				HelpUri:          issue.GH,
+			}
+			if issue.Suggestion != "" {
+				rule.Help = &SarifMessage{Text: issue.Suggestion}
+			}
+			driver.Rules = append(driver.Rules, rule)
+		}
+
+		message := issue.Reason
+		if issue.ObjectName != "" {
+			message = fmt.Sprintf("%s %s: %s", issue.ObjectType, issue.ObjectName, issue.Reason)
+		}
+		if issue.Suggestion != "" {
+			message += "\nSuggestion: " + issue.Suggestion
+		}
+		result := SarifResult{
+			RuleID:       ruleID,
+			RuleIndex:    index,
+			Level:        "error",
+			Message:      SarifMessage{Text: message},
+			Suppressions: suppressions,
+			Properties: map[string]string{
+				"objectType": issue.ObjectType,
+				"objectName": issue.ObjectName,
+			},
+		}
+		if issue.FilePath != "" {
+			location := SarifPhysicalLocation{ArtifactLocation: getSarifArtifactLocation(issue.FilePath)}
+			if issue.Line != 0 {
+				location.Region = &SarifRegion{StartLine: issue.Line, StartColumn: issue.Column}
+			}
+			result.Locations = []SarifLocation{{PhysicalLocation: location}}
+		}
+		results = append(results, result)
+	}
+	for _, issue := range report.Issues {
+		addResult(issue, nil)
+	}
+	for _, waivedIssue := range report.WaivedIssues {
+		addResult(waivedIssue.Issue, []SarifSuppression{{Kind: "external", Justification: waivedIssue.Justification}})
+	}
+
+	sarifReport := SarifReport{
+		Schema:  SARIF_SCHEMA,
+		Version: SARIF_VERSION,
+		Runs: []SarifRun{{
+			Tool: SarifTool{Driver: driver},
+			OriginalUriBaseIds: map[string]SarifArtifactLocation{
+				SARIF_URI_BASE_ID: {URI: "file://" + filepath.ToSlash(exportDir) + "/"},
+			},
+			Results: results,
+		}},
+	}
+	jsonBytes, err := json.MarshalIndent(sarifReport, "", "    ")
+	if err != nil {
+		panic(err)
+	}
+	return string(jsonBytes)
+}
+
+// ============================ JUnit XML ============================
+
+type JUnitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Suites   []JUnitTestSuite `xml:"testsuite"`
+}
+
+type JUnitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	TestCases []JUnitTestCase `xml:"testcase"`
+}
+
+type JUnitTestCase struct {
+	ClassName string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	File      string        `xml:"file,attr,omitempty"`
+	Line      int           `xml:"line,attr,omitempty"`
+	Failure   *JUnitFailure `xml:"failure,omitempty"`
+}
+
+type JUnitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// Returns the name of the test case for an issue. Issues not tied to a single object(e.g. DROP of multiple objects)
+// are named after their position in the schema file.
+func getJUnitTestCaseName(issue utils.Issue) string {
+	if issue.ObjectName != "" {
+		return issue.ObjectName
+	}
+	return filepath.Base(issue.FilePath) + getIssuePositionSuffix(issue)
+}
+
+func getJUnitFailureText(issues []utils.Issue) string {
+	var details []string
+	for _, issue := range issues {
+		text := "Reason: " + issue.Reason + "\n"
+		if issue.SqlStatement != "" {
+			text += "SQL Statement: " + issue.SqlStatement + "\n"
+		}
+		if issue.FilePath != "" {
+			text += "File Path: " + issue.FilePath + getIssuePositionSuffix(issue) + "\n"
+		}
+		if issue.Suggestion != "" {
+			text += "Suggestion: " + issue.Suggestion + "\n"
+		}
+		if issue.GH != "" {
+			text += "Github Issue Link: " + issue.GH + "\n"
+		}
+		details = append(details, text)
+	}
+	return strings.Join(details, "\n")
+}
+
+// Every object analyzed becomes a test case in the test suite of its object type,
+// and the objects with issues are reported as failed test cases.
+func generateJUnitReport(report utils.Report) string {
+	var objectTypes []string
+	objectNames := make(map[string][]string)
+	for _, dbObject := range report.Summary.DBObjects {
+		objectTypes = append(objectTypes, dbObject.ObjectType)
+		if dbObject.ObjectNames != "" {
+			objectNames[dbObject.ObjectType] = strings.Split(dbObject.ObjectNames, ", ")
+		}
+	}
+	issuesByTestCase := make(map[string]map[string][]utils.Issue)
+	for _, issue := range report.Issues {
+		if !lo.Contains(objectTypes, issue.ObjectType) {
+			objectTypes = append(objectTypes, issue.ObjectType)
+		}
+		if issuesByTestCase[issue.ObjectType] == nil {
+			issuesByTestCase[issue.ObjectType] = make(map[string][]utils.Issue)
+		}
+		name := getJUnitTestCaseName(issue)
+		issuesByTestCase[issue.ObjectType][name] = append(issuesByTestCase[issue.ObjectType][name], issue)
+	}
+
+	testSuites := JUnitTestSuites{Name: fmt.Sprintf("%s analyze-schema", VOYAGER_TOOL_NAME)}
+	for _, objType := range objectTypes {
+		names := lo.Uniq(append(objectNames[objType], lo.Keys(issuesByTestCase[objType])...))
+		sort.Strings(names)
+		testSuite := JUnitTestSuite{Name: objType}
+		for _, name := range names {
+			testCase := JUnitTestCase{ClassName: objType, Name: name}
+			if issues := issuesByTestCase[objType][name]; len(issues) > 0 {
+				testCase.File = issues[0].FilePath
+				testCase.Line = issues[0].Line
+				testCase.Failure = &JUnitFailure{
+					Message: strings.Join(lo.Uniq(lo.Map(issues, func(issue utils.Issue, _ int) string { return issue.Reason })), "; "),
+					Type:    "UnsupportedConstruct",
+					Text:    getJUnitFailureText(issues),
+				}
+				testSuite.Failures++
+			}
+			testSuite.TestCases = append(testSuite.TestCases, testCase)
+		}
+		testSuite.Tests = len(testSuite.TestCases)
+		testSuites.Tests += testSuite.Tests
+		testSuites.Failures += testSuite.Failures
+		testSuites.Suites = append(testSuites.Suites, testSuite)
+	}
+
+	byteReport, err := xml.MarshalIndent(testSuites, "", "\t")
+	if err != nil {
+		panic(err)
+	}
+	return xml.Header + string(byteReport)
+}
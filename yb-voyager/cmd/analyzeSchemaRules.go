@@ -0,0 +1,343 @@
+/*
+Copyright (c) YugabyteDB, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	pg_query "github.com/pganalyze/pg_query_go/v5"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+	"golang.org/x/exp/slices"
+	"gopkg.in/yaml.v3"
+
+	"github.com/yugabyte/yb-voyager/yb-voyager/src/queryparser"
+	"github.com/yugabyte/yb-voyager/yb-voyager/src/utils"
+)
+
+var rulesFilePath string
+
+// nil if no rules file is given.
+var userRules []*UserRule
+
+// UserRule is a check of the exported schema defined by the user in the rules file(YAML or JSON):
+//
+//	rules:
+//	  - name: no-serial
+//	    objectType: TABLE
+//	    match:
+//	      columnType: [serial, bigserial]
+//	    reason: SERIAL columns are not allowed
+//	    suggestion: Use an identity column instead
+//	    link: https://wiki.example.com/db-conventions#serial
+//	  - name: primary-key-required
+//	    objectType: TABLE
+//	    match:
+//	      missingPrimaryKey: true
+//	    reason: Every table must have a primary key
+//	  - name: banned-extensions
+//	    objectType: EXTENSION
+//	    match:
+//	      objectName: ^(postgis|timescaledb)$
+//	    reason: Extension is not approved
+//
+// The rule applies to the statements in the schema file of the object type, and matches the statements which
+// satisfy all the conditions given in `match`:
+//   - objectName: regular expression matched against the name of the object created by the statement
+//   - sql: regular expression matched case-insensitively against the statement
+//   - columnType: names of the data types, matched against the columns defined in CREATE TABLE, ALTER TABLE ADD COLUMN etc.
+//   - missingPrimaryKey: true to match the CREATE TABLE statements of the tables without a primary key in the schema file
+//
+// The column types are matched as written in the schema files, e.g. pg_dump exports the SERIAL columns as integer
+// columns with a default of nextval() which can be matched with `sql: nextval\(`.
+type UserRule struct {
+	Name       string        `json:"name" yaml:"name"`
+	ObjectType string        `json:"objectType" yaml:"objectType"`
+	Match      UserRuleMatch `json:"match" yaml:"match"`
+	Reason     string        `json:"reason" yaml:"reason"`
+	Suggestion string        `json:"suggestion" yaml:"suggestion"`
+	Link       string        `json:"link" yaml:"link"`
+
+	objectNameRe *regexp.Regexp
+	sqlRe        *regexp.Regexp
+	columnTypes  []string
+}
+
+type UserRuleMatch struct {
+	ObjectName        string   `json:"objectName" yaml:"objectName"`
+	SQL               string   `json:"sql" yaml:"sql"`
+	ColumnType        []string `json:"columnType" yaml:"columnType"`
+	MissingPrimaryKey bool     `json:"missingPrimaryKey" yaml:"missingPrimaryKey"`
+}
+
+type userRulesConfig struct {
+	Rules []*UserRule `json:"rules" yaml:"rules"`
+}
+
+func registerRulesFileFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&rulesFilePath, "rules-file", "",
+		"path of a YAML or JSON file with additional rules to check the schema against, reported along with the built-in checks. "+
+			"Each rule has an objectType, match conditions(objectName, sql, columnType, missingPrimaryKey) and a reason, suggestion and link. For example:\n"+
+			`{"rules": [{"objectType": "TABLE", "match": {"missingPrimaryKey": true}, "reason": "Every table must have a primary key"}]}`)
+}
+
+func loadUserRules() {
+	if rulesFilePath == "" {
+		return
+	}
+	var err error
+	userRules, err = readUserRules(rulesFilePath)
+	if err != nil {
+		utils.ErrExit("load rules from %q: %v", rulesFilePath, err)
+	}
+	utils.PrintAndLog("checking the schema against %d rule(s) from %q", len(userRules), rulesFilePath)
+}
+
+// Decodes the file into v, as JSON if the file has the .json extension and as YAML otherwise.
+// Unknown fields are not allowed, to catch the typos in the field names.
+func readYamlOrJsonFile(path string, v any) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read file: %w", err)
+	}
+	if strings.ToLower(filepath.Ext(path)) == ".json" {
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(v)
+	} else {
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		err = decoder.Decode(v)
+	}
+	if err != nil {
+		return fmt.Errorf("parse file: %w", err)
+	}
+	return nil
+}
+
+func readUserRules(path string) ([]*UserRule, error) {
+	var config userRulesConfig
+	err := readYamlOrJsonFile(path, &config)
+	if err != nil {
+		return nil, err
+	}
+	for i, rule := range config.Rules {
+		err = rule.init()
+		if err != nil {
+			return nil, fmt.Errorf("rule %s: %w", lo.Ternary(rule.Name != "", fmt.Sprintf("%q", rule.Name), fmt.Sprintf("#%d", i+1)), err)
+		}
+	}
+	return config.Rules, nil
+}
+
+// Validates the rule and prepares it for matching
+func (rule *UserRule) init() error {
+	rule.ObjectType = strings.ToUpper(strings.TrimSpace(rule.ObjectType))
+	var objectTypes []string
+	for _, dbType := range []string{POSTGRESQL, ORACLE, MYSQL} {
+		objectTypes = append(objectTypes, utils.GetSchemaObjectList(dbType)...)
+	}
+	objectTypes = lo.Uniq(objectTypes)
+	if !slices.Contains(objectTypes, rule.ObjectType) {
+		return fmt.Errorf("invalid objectType %q, supported object types are %v", rule.ObjectType, objectTypes)
+	}
+	if rule.Reason == "" {
+		return fmt.Errorf("reason is required")
+	}
+	match := rule.Match
+	if match.ObjectName == "" && match.SQL == "" && len(match.ColumnType) == 0 && !match.MissingPrimaryKey {
+		return fmt.Errorf("at least one of objectName, sql, columnType and missingPrimaryKey is required in match")
+	}
+	if match.MissingPrimaryKey && rule.ObjectType != "TABLE" {
+		return fmt.Errorf("missingPrimaryKey is applicable only to the TABLE objectType")
+	}
+	var err error
+	if match.ObjectName != "" {
+		rule.objectNameRe, err = regexp.Compile(match.ObjectName)
+		if err != nil {
+			return fmt.Errorf("invalid objectName regular expression: %w", err)
+		}
+	}
+	if match.SQL != "" {
+		rule.sqlRe, err = regexp.Compile("(?i)" + match.SQL)
+		if err != nil {
+			return fmt.Errorf("invalid sql regular expression: %w", err)
+		}
+	}
+	for _, columnType := range match.ColumnType {
+		typeName, err := normalizeTypeName(columnType)
+		if err != nil {
+			return fmt.Errorf("invalid columnType %q: %w", columnType, err)
+		}
+		rule.columnTypes = append(rule.columnTypes, typeName)
+	}
+	return nil
+}
+
+// Returns the name of the data type as in the parse tree, e.g. int4 for integer, so that it can be compared with the column types.
+func normalizeTypeName(typeName string) (string, error) {
+	stmts, err := queryparser.Parse(fmt.Sprintf("SELECT NULL::%s", typeName))
+	if err != nil {
+		return "", err
+	}
+	if len(stmts) != 1 {
+		return "", fmt.Errorf("not a data type")
+	}
+	var result string
+	queryparser.Walk(stmts[0].Stmt, func(node *pg_query.Node) bool {
+		if node.GetTypeCast() != nil {
+			result = queryparser.TypeName(node.GetTypeCast().TypeName)
+			return false
+		}
+		return true
+	})
+	if result == "" {
+		return "", fmt.Errorf("not a data type")
+	}
+	return result, nil
+}
+
+// Returns the name of the object created or altered by the statement
+func getStmtObjectName(sqlInfo *sqlInfo, stmt *pg_query.Node) string {
+	if sqlInfo.objName != "" {
+		return sqlInfo.objName
+	}
+	if alterTable := stmt.GetAlterTableStmt(); alterTable != nil {
+		return queryparser.RangeVarName(alterTable.Relation)
+	}
+	return ""
+}
+
+// Returns the locations in the statement of the columns whose data type is one of the given types
+func getColumnTypeLocations(stmt *pg_query.Node, columnTypes []string) []int {
+	var locations []int
+	queryparser.Walk(stmt, func(node *pg_query.Node) bool {
+		if column := node.GetColumnDef(); column != nil && column.TypeName != nil &&
+			slices.Contains(columnTypes, queryparser.TypeName(column.TypeName)) {
+			locations = append(locations, int(column.TypeName.Location))
+		}
+		return true
+	})
+	return locations
+}
+
+// Returns the names of the tables in the schema file which have a primary key, defined either in CREATE TABLE,
+// by ALTER TABLE ADD CONSTRAINT, or by the partitioned table for the partitions.
+func getTablesWithPrimaryKey(sqlInfoArr []sqlInfo) map[string]bool {
+	hasPrimaryKey := make(map[string]bool)
+	partitionParents := make(map[string]string)
+	for i := range sqlInfoArr {
+		for _, rawStmt := range sqlInfoArr[i].parseTree {
+			if createTable := rawStmt.Stmt.GetCreateStmt(); createTable != nil {
+				tableName := queryparser.RangeVarName(createTable.Relation)
+				if columns, _ := getPrimaryKeyColumns(createTable); len(columns) > 0 {
+					hasPrimaryKey[tableName] = true
+				}
+				if createTable.Partbound != nil && len(createTable.InhRelations) > 0 {
+					partitionParents[tableName] = queryparser.RangeVarName(createTable.InhRelations[0].GetRangeVar())
+				}
+			} else if alterTable := rawStmt.Stmt.GetAlterTableStmt(); alterTable != nil {
+				for _, cmd := range alterTable.Cmds {
+					constraint := cmd.GetAlterTableCmd().GetDef().GetConstraint()
+					if cmd.GetAlterTableCmd().GetSubtype() == pg_query.AlterTableType_AT_AddConstraint &&
+						constraint.GetContype() == pg_query.ConstrType_CONSTR_PRIMARY {
+						hasPrimaryKey[queryparser.RangeVarName(alterTable.Relation)] = true
+					}
+				}
+			}
+		}
+	}
+	// the partitions get the primary key of the partitioned table
+	for partition, parent := range partitionParents {
+		for !hasPrimaryKey[partition] && parent != "" {
+			hasPrimaryKey[partition] = hasPrimaryKey[parent]
+			parent = partitionParents[parent]
+		}
+	}
+	return hasPrimaryKey
+}
+
+// Checks the statements of the schema file against the rules given by the user for the object type
+func checkUserRules(sqlInfoArr []sqlInfo, fpath string, objType string) {
+	if !utils.FileOrFolderExists(fpath) {
+		return
+	}
+	rules := lo.Filter(userRules, func(rule *UserRule, _ int) bool { return rule.ObjectType == objType })
+	if len(rules) == 0 {
+		return
+	}
+	var hasPrimaryKey map[string]bool
+	if lo.SomeBy(rules, func(rule *UserRule) bool { return rule.Match.MissingPrimaryKey }) {
+		hasPrimaryKey = getTablesWithPrimaryKey(sqlInfoArr)
+	}
+	for i := range sqlInfoArr {
+		sqlInfo := &sqlInfoArr[i]
+		for _, rule := range rules {
+			if sqlInfo.parseErr != nil {
+				// only the text based conditions can be checked for the statements which could not be parsed
+				c := &stmtChecker{fpath: fpath, sqlInfo: sqlInfo, objType: objType,
+					stmtStart: len(sqlInfo.formattedStmt) - len(strings.TrimLeft(sqlInfo.formattedStmt, " \t\n"))}
+				if len(rule.columnTypes) == 0 && !rule.Match.MissingPrimaryKey {
+					checkUserRule(c, rule, nil, 0, sqlInfo.formattedStmt, sqlInfo.objName, nil)
+				}
+				continue
+			}
+			for _, rawStmt := range sqlInfo.parseTree {
+				c := &stmtChecker{fpath: fpath, sqlInfo: sqlInfo, objType: objType, stmtStart: queryparser.StmtStart(sqlInfo.formattedStmt, rawStmt)}
+				checkUserRule(c, rule, rawStmt.Stmt, int(rawStmt.StmtLocation), queryparser.StmtText(sqlInfo.formattedStmt, rawStmt),
+					getStmtObjectName(sqlInfo, rawStmt.Stmt), hasPrimaryKey)
+			}
+		}
+	}
+}
+
+// Reports the matches of the rule in the statement, which is at textOffset in sqlInfo.formattedStmt.
+// stmt is nil for the statements which could not be parsed.
+func checkUserRule(c *stmtChecker, rule *UserRule, stmt *pg_query.Node, textOffset int, stmtText string, objName string, hasPrimaryKey map[string]bool) {
+	if rule.objectNameRe != nil && (objName == "" || !rule.objectNameRe.MatchString(objName)) {
+		return
+	}
+	// offsets in sqlInfo.formattedStmt of the matches, by default the start of the statement
+	offsets := []int{c.stmtStart}
+	if rule.sqlRe != nil {
+		match := rule.sqlRe.FindStringIndex(stmtText)
+		if match == nil {
+			return
+		}
+		offsets = []int{textOffset + match[0]}
+	}
+	if rule.Match.MissingPrimaryKey {
+		createTable := stmt.GetCreateStmt()
+		if createTable == nil || hasPrimaryKey[queryparser.RangeVarName(createTable.Relation)] {
+			return
+		}
+	}
+	if len(rule.columnTypes) > 0 {
+		offsets = lo.Map(getColumnTypeLocations(stmt, rule.columnTypes), func(location int, _ int) int { return c.offset(location) })
+	}
+	for _, offset := range offsets {
+		reportCaseAt(c.sqlInfo.position(offset), c.fpath, rule.Reason, rule.Link, rule.Suggestion, rule.ObjectType, objName, c.sqlInfo.formattedStmt)
+		if objName != "" && summaryMap[rule.ObjectType] != nil {
+			summaryMap[rule.ObjectType].invalidCount[objName] = true
+		}
+	}
+}
@@ -0,0 +1,159 @@
+/*
+Copyright (c) YugabyteDB, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/samber/lo"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/yugabyte/yb-voyager/yb-voyager/src/utils"
+)
+
+const WAIVER_EXPIRY_FORMAT = "2006-01-02"
+
+var waiversFilePath string
+
+// nil if no waivers file is given.
+var issueWaivers []*IssueWaiver
+
+// IssueWaiver waives an issue reported by analyze-schema after it has been reviewed, as specified in the waivers file(YAML or JSON):
+//
+//	waivers:
+//	  - objectType: INDEX
+//	    objectName: idx_orders_tags
+//	    reason: Schema contains gin index on multi column which is not supported.
+//	    justification: The index is replaced by single column indexes after the migration
+//	    expiry: 2024-12-31
+//
+// The object type, object name and reason must be the same as in the report. The waived issues are reported
+// separately and the objects with only waived issues are not counted as invalid. The waiver is no longer
+// applied after the expiry date, if given.
+type IssueWaiver struct {
+	ObjectType    string `json:"objectType" yaml:"objectType"`
+	ObjectName    string `json:"objectName" yaml:"objectName"`
+	Reason        string `json:"reason" yaml:"reason"`
+	Justification string `json:"justification" yaml:"justification"`
+	Expiry        string `json:"expiry" yaml:"expiry"`
+
+	// zero if the waiver does not expire
+	expiry time.Time
+}
+
+type issueWaiversConfig struct {
+	Waivers []*IssueWaiver `json:"waivers" yaml:"waivers"`
+}
+
+func registerWaiversFileFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&waiversFilePath, "waivers-file", "",
+		"path of a YAML or JSON file with the reviewed issues to be waived, identified by objectType, objectName and reason as in the report. "+
+			"Each waiver can have a justification and an expiry date(YYYY-MM-DD). For example:\n"+
+			`{"waivers": [{"objectType": "INDEX", "objectName": "idx1", "reason": "Schema contains gist index which is not supported.", "justification": "not used", "expiry": "2024-12-31"}]}`)
+}
+
+func loadIssueWaivers() {
+	if waiversFilePath == "" {
+		return
+	}
+	var err error
+	issueWaivers, err = readIssueWaivers(waiversFilePath)
+	if err != nil {
+		utils.ErrExit("load waivers from %q: %v", waiversFilePath, err)
+	}
+	utils.PrintAndLog("applying %d waiver(s) from %q to the reported issues", len(issueWaivers), waiversFilePath)
+}
+
+func readIssueWaivers(path string) ([]*IssueWaiver, error) {
+	var config issueWaiversConfig
+	err := readYamlOrJsonFile(path, &config)
+	if err != nil {
+		return nil, err
+	}
+	for i, waiver := range config.Waivers {
+		waiver.ObjectType = strings.ToUpper(strings.TrimSpace(waiver.ObjectType))
+		if waiver.ObjectType == "" || waiver.Reason == "" {
+			return nil, fmt.Errorf("waiver #%d: objectType and reason are required", i+1)
+		}
+		if waiver.Expiry != "" {
+			waiver.expiry, err = time.ParseInLocation(WAIVER_EXPIRY_FORMAT, waiver.Expiry, time.Local)
+			if err != nil {
+				return nil, fmt.Errorf("waiver #%d: invalid expiry %q, expected a date in the YYYY-MM-DD format", i+1, waiver.Expiry)
+			}
+		}
+	}
+	return config.Waivers, nil
+}
+
+func (waiver *IssueWaiver) matches(issue utils.Issue) bool {
+	return waiver.ObjectType == strings.ToUpper(issue.ObjectType) && waiver.ObjectName == issue.ObjectName && waiver.Reason == issue.Reason
+}
+
+// The waiver applies till the end of the expiry date
+func (waiver *IssueWaiver) isExpired(now time.Time) bool {
+	return !waiver.expiry.IsZero() && !now.Before(waiver.expiry.AddDate(0, 0, 1))
+}
+
+// Moves the issues waived in the waivers file from reportStruct.Issues to reportStruct.WaivedIssues,
+// and unmarks the objects left with no issues as invalid.
+func applyIssueWaivers(now time.Time) {
+	if len(issueWaivers) == 0 {
+		return
+	}
+	var issues []utils.Issue
+	expiredWaivers := make(map[*IssueWaiver]bool)
+	usedWaivers := make(map[*IssueWaiver]bool)
+	for _, issue := range reportStruct.Issues {
+		waiver, found := lo.Find(issueWaivers, func(waiver *IssueWaiver) bool { return waiver.matches(issue) })
+		if !found {
+			issues = append(issues, issue)
+			continue
+		}
+		usedWaivers[waiver] = true
+		if waiver.isExpired(now) {
+			expiredWaivers[waiver] = true
+			issues = append(issues, issue)
+			continue
+		}
+		reportStruct.WaivedIssues = append(reportStruct.WaivedIssues, utils.WaivedIssue{
+			Issue:         issue,
+			Justification: waiver.Justification,
+			Expiry:        waiver.Expiry,
+		})
+	}
+	reportStruct.Issues = issues
+
+	for _, waivedIssue := range reportStruct.WaivedIssues {
+		hasIssues := lo.SomeBy(issues, func(issue utils.Issue) bool {
+			return issue.ObjectType == waivedIssue.ObjectType && issue.ObjectName == waivedIssue.ObjectName
+		})
+		if !hasIssues && summaryMap[waivedIssue.ObjectType] != nil {
+			delete(summaryMap[waivedIssue.ObjectType].invalidCount, waivedIssue.ObjectName)
+		}
+	}
+
+	for _, waiver := range issueWaivers {
+		if expiredWaivers[waiver] {
+			note := fmt.Sprintf("The waiver of the issue %q in %s %s expired on %s, review the issue again", waiver.Reason, waiver.ObjectType, waiver.ObjectName, waiver.Expiry)
+			reportStruct.Summary.Notes = append(reportStruct.Summary.Notes, note)
+		} else if !usedWaivers[waiver] {
+			log.Infof("waiver of the issue %q in %s %s did not match any issue", waiver.Reason, waiver.ObjectType, waiver.ObjectName)
+		}
+	}
+}
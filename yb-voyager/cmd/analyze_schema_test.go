@@ -0,0 +1,473 @@
+package cmd
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yugabyte/yb-voyager/yb-voyager/src/queryparser"
+	"github.com/yugabyte/yb-voyager/yb-voyager/src/utils"
+)
+
+func analyzeSchemaFile(t *testing.T, objType string, content string) []utils.Issue {
+	reportStruct = utils.Report{}
+	sourceObjList = utils.GetSchemaObjectList("postgresql")
+	summaryMap = make(map[string]*summaryInfo)
+	tblParts = make(map[string]string)
+	primaryCons = make(map[string]string)
+	initializeSummaryMap()
+
+	filePath := filepath.Join(t.TempDir(), "schema.sql")
+	assert.NoError(t, os.WriteFile(filePath, []byte(content), 0644))
+	sqlInfoArr := createSqlStrInfoArray(filePath, objType)
+	if objType == "EXTENSION" {
+		checkExtensions(sqlInfoArr, filePath)
+	}
+	checker(sqlInfoArr, filePath, objType)
+	checkUserRules(sqlInfoArr, filePath, objType)
+	return reportStruct.Issues
+}
+
+// Returns the issues as "line:column objectType objectName: reason" for concise assertions
+func summarizeIssues(issues []utils.Issue) []string {
+	var result []string
+	for _, issue := range issues {
+		result = append(result, fmt.Sprintf("%d:%d %s %s: %s", issue.Line, issue.Column, issue.ObjectType, issue.ObjectName, issue.Reason))
+	}
+	return result
+}
+
+func TestAnalyzeTables(t *testing.T) {
+	assert := assert.New(t)
+	issues := analyzeSchemaFile(t, "TABLE", `SET statement_timeout = 0;
+
+CREATE TABLE public.orders (
+    id integer NOT NULL,
+    -- total numeric GENERATED ALWAYS AS (price * qty) STORED,
+    total numeric GENERATED ALWAYS AS (price * qty)
+        STORED,
+    data ANYDATA
+);
+
+CREATE TABLE public.events (
+    id bigint,
+    duration interval,
+    PRIMARY KEY (duration)
+) WITH (oids = true);
+
+CREATE TABLE public.sales (
+    id integer PRIMARY KEY,
+    region text
+) PARTITION BY LIST (region, id);
+
+ALTER TABLE ONLY public.orders
+    ALTER COLUMN total SET STORAGE plain;
+ALTER TABLE ONLY public.orders ALTER COLUMN id SET DEFAULT 1;
+ALTER TABLE public.orders CLUSTER ON orders_pkey;
+`)
+	assert.Equal([]string{
+		"6:19 TABLE public.orders: Stored generated column is not supported. Column is: total",
+		"8:10 TABLE public.orders: AnyData datatype doesn't have a mapping in YugabyteDB",
+		"15:9 TABLE public.events: OIDs are not supported for user tables.",
+		"14:5 TABLE public.events: PRIMARY KEY containing column of type 'INTERVAL' not yet supported.",
+		"20:3 TABLE public.sales: cannot use \"list\" partition strategy with more than one column",
+		"22:18 TABLE public.orders: ALTER TABLE ALTER column SET STORAGE not supported yet.",
+		"25:13 TABLE public.orders: ALTER TABLE CLUSTER not supported yet.",
+	}, summarizeIssues(issues))
+	assert.Equal(map[string]bool{"public.events": true, "public.sales": true}, summaryMap["TABLE"].invalidCount)
+	assert.Equal(3, summaryMap["TABLE"].totalCount)
+}
+
+func TestAnalyzeIndexesAndViews(t *testing.T) {
+	assert := assert.New(t)
+	issues := analyzeSchemaFile(t, "INDEX", `CREATE INDEX idx_gist ON public.t USING gist (geom);
+CREATE INDEX idx_gin ON public.t USING gin (a, b);
+CREATE INDEX idx_btree ON public.t USING btree (a);
+DROP INDEX idx1, idx2;
+`)
+	assert.Equal([]string{
+		"1:1 INDEX idx_gist: Schema contains gist index which is not supported.",
+		"2:1 INDEX idx_gin: Schema contains gin index on multi column which is not supported.",
+		"4:1 INDEX : DROP multiple objects not supported yet.",
+	}, summarizeIssues(issues))
+	assert.Equal("DROP INDEX idx1; DROP INDEX idx2;", issues[2].Suggestion)
+
+	issues = analyzeSchemaFile(t, "VIEW", `CREATE VIEW public.v AS
+    SELECT * FROM public.t WHERE a > 0
+    WITH CASCADED CHECK OPTION;
+`)
+	assert.Equal([]string{"1:1 VIEW public.v: Schema containing VIEW WITH CHECK OPTION is not supported yet."}, summarizeIssues(issues))
+}
+
+func TestAnalyzeFunctionBodies(t *testing.T) {
+	assert := assert.New(t)
+	issues := analyzeSchemaFile(t, "FUNCTION", `CREATE FUNCTION public.total_sales() RETURNS json
+    LANGUAGE plpgsql
+    AS $$
+DECLARE
+    result json;
+BEGIN
+    -- ALTER TABLE public.sales SET WITH OIDS in a comment is not an issue
+    SELECT json_arrayagg(amount) INTO result FROM public.sales;
+    RETURN result;
+END;
+$$;
+
+CREATE FUNCTION public.move_back(c refcursor) RETURNS void
+    LANGUAGE plpgsql
+    AS $$
+BEGIN
+    MOVE BACKWARD FROM c;
+END;
+$$;
+
+CREATE FUNCTION public.plain() RETURNS text
+    LANGUAGE sql
+    AS $$ SELECT 'WHERE CURRENT OF c' $$;
+`)
+	assert.Equal([]string{
+		"8:12 FUNCTION public.total_sales: JSON_ARRAYAGG() function is not available in YugabyteDB",
+		"17:5 FUNCTION public.move_back: FETCH BACKWARD not supported yet",
+	}, summarizeIssues(issues))
+	assert.Equal(3, summaryMap["FUNCTION"].totalCount)
+}
+
+func TestAnalyzeUnparsedStatements(t *testing.T) {
+	assert := assert.New(t)
+	issues := analyzeSchemaFile(t, "TABLE", `\set ON_ERROR_STOP ON
+CREATE TABLE t1 (id int) WITH OIDS;
+ALTER TABLE t1 SET WITH OIDS;
+CREATE TABLE t2 (id int,);
+`)
+	assert.Equal([]string{
+		"2:26 TABLE t1: OIDs are not supported for user tables.",
+		"3:16 TABLE t1: ALTER TABLE SET WITH OIDS not supported yet.",
+		"4:25 TABLE t2: Unable to parse the SQL statement: syntax error at or near \")\"",
+	}, summarizeIssues(issues))
+	assert.Equal(2, summaryMap["TABLE"].totalCount)
+}
+
+func TestAnalyzeExtensions(t *testing.T) {
+	assert := assert.New(t)
+	issues := analyzeSchemaFile(t, "EXTENSION", `CREATE EXTENSION IF NOT EXISTS "uuid-ossp" WITH SCHEMA public;
+CREATE EXTENSION postgis;
+`)
+	assert.Equal([]string{"2:1 EXTENSION postgis: This extension is not supported in YugabyteDB."}, summarizeIssues(issues))
+}
+
+func TestSarifAndJUnitReports(t *testing.T) {
+	assert := assert.New(t)
+	exportDir = t.TempDir()
+	filePath := filepath.Join(exportDir, "schema", "tables", "table.sql")
+	report := utils.Report{
+		Summary: utils.Summary{DBObjects: []utils.DBObject{
+			{ObjectType: "TABLE", TotalCount: 2, InvalidCount: 1, ObjectNames: "public.orders, public.events"},
+		}},
+		Issues: []utils.Issue{
+			{ObjectType: "TABLE", ObjectName: "public.events", Reason: "OIDs are not supported for user tables.", FilePath: filePath, Line: 15, Column: 9, GH: "https://github.com/yugabyte/yugabyte-db/issues/10273"},
+			{ObjectType: "TABLE", ObjectName: "public.events", Reason: "ALTER TABLE CLUSTER not supported yet.", FilePath: filePath, Line: 25, Column: 13, Suggestion: "Remove it from the exported schema"},
+		},
+	}
+
+	var sarifReport SarifReport
+	assert.NoError(json.Unmarshal([]byte(generateSarifReport(report)), &sarifReport))
+	assert.Equal("2.1.0", sarifReport.Version)
+	run := sarifReport.Runs[0]
+	assert.Equal([]string{"oids-are-not-supported-for-user-tables", "alter-table-cluster-not-supported-yet"},
+		lo.Map(run.Tool.Driver.Rules, func(rule SarifRule, _ int) string { return rule.ID }))
+	assert.Len(run.Results, 2)
+	assert.Equal(1, run.Results[1].RuleIndex)
+	assert.Equal("TABLE public.events: ALTER TABLE CLUSTER not supported yet.\nSuggestion: Remove it from the exported schema", run.Results[1].Message.Text)
+	location := run.Results[0].Locations[0].PhysicalLocation
+	assert.Equal(SarifArtifactLocation{URI: "schema/tables/table.sql", URIBaseID: "EXPORT_DIR"}, location.ArtifactLocation)
+	assert.Equal(&SarifRegion{StartLine: 15, StartColumn: 9}, location.Region)
+
+	var testSuites JUnitTestSuites
+	assert.NoError(xml.Unmarshal([]byte(generateJUnitReport(report)), &testSuites))
+	assert.Equal(2, testSuites.Tests)
+	assert.Equal(1, testSuites.Failures)
+	testCases := testSuites.Suites[0].TestCases
+	assert.Equal("public.events", testCases[0].Name)
+	assert.Equal("OIDs are not supported for user tables.; ALTER TABLE CLUSTER not supported yet.", testCases[0].Failure.Message)
+	assert.Contains(testCases[0].Failure.Text, "File Path: "+filePath+":25:13")
+	assert.Equal("public.orders", testCases[1].Name)
+	assert.Nil(testCases[1].Failure)
+}
+
+func TestSarifRuleIDs(t *testing.T) {
+	assert := assert.New(t)
+	report := utils.Report{
+		Issues: []utils.Issue{
+			{ObjectType: "TABLE", ObjectName: "public.orders", Reason: fmt.Sprintf(STORED_GENERATED_COLUMN_REASON, "total")},
+			{ObjectType: "TABLE", ObjectName: "public.items", Reason: fmt.Sprintf(STORED_GENERATED_COLUMN_REASON, "amount")},
+			{ObjectType: "TABLE", ObjectName: "public.docs", Reason: fmt.Sprintf(UNSUPPORTED_DATATYPE_REASON, "AnyData")},
+			{ObjectType: "TABLE", ObjectName: "public.docs", Reason: fmt.Sprintf(UNPARSED_STMT_REASON, `syntax error at or near "NOLOGGING"`)},
+			{ObjectType: "TABLE", ObjectName: "public.events", Reason: "OIDs are not supported for user tables."},
+		},
+	}
+	var sarifReport SarifReport
+	assert.NoError(json.Unmarshal([]byte(generateSarifReport(report)), &sarifReport))
+	run := sarifReport.Runs[0]
+	// one rule per kind of issue, whatever the column, datatype or error it names
+	assert.Equal([]string{
+		"stored-generated-column-is-not-supported-column-is",
+		"datatype-doesn-t-have-a-mapping-in-yugabytedb",
+		"unable-to-parse-the-sql-statement",
+		"oids-are-not-supported-for-user-tables",
+	}, lo.Map(run.Tool.Driver.Rules, func(rule SarifRule, _ int) string { return rule.ID }))
+	assert.Equal("Stored generated column is not supported. Column is: ...", run.Tool.Driver.Rules[0].ShortDescription.Text)
+	assert.Equal([]int{0, 0, 1, 2, 3}, lo.Map(run.Results, func(result SarifResult, _ int) int { return result.RuleIndex }))
+	assert.Equal("TABLE public.items: Stored generated column is not supported. Column is: amount", run.Results[1].Message.Text)
+}
+
+func TestUserRules(t *testing.T) {
+	assert := assert.New(t)
+	rulesFile := filepath.Join(t.TempDir(), "rules.yaml")
+	assert.NoError(os.WriteFile(rulesFile, []byte(`rules:
+  - name: no-serial
+    objectType: table
+    match:
+      columnType: [serial, bigserial, integer]
+      objectName: ^public\.
+    reason: SERIAL columns are not allowed
+    suggestion: Use an identity column instead
+    link: https://wiki.example.com/serial
+  - name: primary-key-required
+    objectType: TABLE
+    match:
+      missingPrimaryKey: true
+    reason: Every table must have a primary key
+  - name: no-cascade
+    objectType: TABLE
+    match:
+      sql: on\s+delete\s+cascade
+    reason: Cascading deletes are not allowed
+`), 0644))
+	var err error
+	userRules, err = readUserRules(rulesFile)
+	assert.NoError(err)
+	defer func() { userRules = nil }()
+
+	issues := analyzeSchemaFile(t, "TABLE", `CREATE TABLE public.orders (
+    id serial,
+    customer_id int
+);
+CREATE TABLE audit.log (id bigserial PRIMARY KEY, note text);
+CREATE TABLE public.sales (id bigint, region text) PARTITION BY LIST (region);
+CREATE TABLE public.sales_in PARTITION OF public.sales FOR VALUES IN ('IN');
+ALTER TABLE ONLY public.sales
+    ADD CONSTRAINT sales_pkey PRIMARY KEY (id, region);
+ALTER TABLE ONLY public.orders
+    ADD CONSTRAINT orders_customer_fkey FOREIGN KEY (customer_id) REFERENCES public.customers(id) ON DELETE CASCADE;
+`)
+	assert.Equal([]string{
+		"2:8 TABLE public.orders: SERIAL columns are not allowed",
+		"3:17 TABLE public.orders: SERIAL columns are not allowed",
+		"1:1 TABLE public.orders: Every table must have a primary key",
+		"11:99 TABLE public.orders: Cascading deletes are not allowed",
+	}, summarizeIssues(issues))
+	assert.Equal("https://wiki.example.com/serial", issues[0].GH)
+	assert.Equal("Use an identity column instead", issues[0].Suggestion)
+	assert.Equal(map[string]bool{"public.orders": true}, summaryMap["TABLE"].invalidCount)
+
+	userRules, err = readUserRules(writeTempFile(t, "rules.json", `{"rules": [{"objectType": "EXTENSION", "match": {"objectName": "^postgis$"}, "reason": "Extension is not approved"}]}`))
+	assert.NoError(err)
+	issues = analyzeSchemaFile(t, "EXTENSION", "CREATE EXTENSION pgcrypto;\nCREATE EXTENSION postgis;\n")
+	assert.Equal([]string{
+		"2:1 EXTENSION postgis: This extension is not supported in YugabyteDB.",
+		"2:1 EXTENSION postgis: Extension is not approved",
+	}, summarizeIssues(issues))
+
+	for content, errMsg := range map[string]string{
+		`{"rules": [{"objectType": "TABLES", "match": {"sql": "x"}, "reason": "r"}]}`:                    `rule #1: invalid objectType "TABLES"`,
+		`{"rules": [{"name": "r1", "objectType": "TABLE", "reason": "r"}]}`:                              `rule "r1": at least one of`,
+		`{"rules": [{"objectType": "INDEX", "match": {"missingPrimaryKey": true}, "reason": "r"}]}`:      "missingPrimaryKey is applicable only to the TABLE objectType",
+		`{"rules": [{"objectType": "TABLE", "match": {"columnType": ["not a type"]}, "reason": "r"}]}`:   `invalid columnType "not a type"`,
+		`{"rules": [{"objectType": "TABLE", "match": {"sql": "("}, "reason": "r"}]}`:                     "invalid sql regular expression",
+		`{"rules": [{"objectType": "TABLE", "match": {"sql": "x"}, "reason": "r", "severity": "high"}]}`: `unknown field "severity"`,
+	} {
+		_, err = readUserRules(writeTempFile(t, "rules.json", content))
+		if assert.Error(err, content) {
+			assert.Contains(err.Error(), errMsg)
+		}
+	}
+}
+
+func writeTempFile(t *testing.T, name string, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+func TestIssueWaivers(t *testing.T) {
+	assert := assert.New(t)
+	var err error
+	issueWaivers, err = readIssueWaivers(writeTempFile(t, "waivers.yaml", `waivers:
+  - objectType: index
+    objectName: idx_gist
+    reason: Schema contains gist index which is not supported.
+    justification: Not used by the application
+  - objectType: INDEX
+    objectName: idx_gin
+    reason: Schema contains gin index on multi column which is not supported.
+    expiry: 2024-01-31
+`))
+	assert.NoError(err)
+	defer func() { issueWaivers = nil }()
+
+	analyzeSchemaFile(t, "INDEX", `CREATE INDEX idx_gist ON public.t USING gist (geom);
+CREATE INDEX idx_gin ON public.t USING gin (a, b);
+`)
+	summaryMap["INDEX"].invalidCount["idx_gist"] = true
+	summaryMap["INDEX"].invalidCount["idx_gin"] = true
+	// the waiver applies till the end of the expiry date
+	applyIssueWaivers(time.Date(2024, 1, 31, 23, 59, 0, 0, time.Local))
+	assert.Empty(reportStruct.Issues)
+	assert.Equal([]string{"1:1 INDEX idx_gist: Schema contains gist index which is not supported.", "2:1 INDEX idx_gin: Schema contains gin index on multi column which is not supported."},
+		summarizeIssues(lo.Map(reportStruct.WaivedIssues, func(waivedIssue utils.WaivedIssue, _ int) utils.Issue { return waivedIssue.Issue })))
+	assert.Equal("Not used by the application", reportStruct.WaivedIssues[0].Justification)
+	assert.Equal(map[string]bool{}, summaryMap["INDEX"].invalidCount)
+	assert.Contains(generateTxtReport(reportStruct), "Waived Issues:\n\nWaived in Object INDEX:\n-Object Name: idx_gist\n")
+
+	var sarifReport SarifReport
+	assert.NoError(json.Unmarshal([]byte(generateSarifReport(reportStruct)), &sarifReport))
+	assert.Equal([]SarifSuppression{{Kind: "external", Justification: "Not used by the application"}}, sarifReport.Runs[0].Results[0].Suppressions)
+
+	// the expired waiver is no longer applied
+	analyzeSchemaFile(t, "INDEX", `CREATE INDEX idx_gist ON public.t USING gist (geom);
+CREATE INDEX idx_gin ON public.t USING gin (a, b);
+`)
+	summaryMap["INDEX"].invalidCount["idx_gin"] = true
+	applyIssueWaivers(time.Date(2024, 2, 1, 0, 0, 0, 0, time.Local))
+	assert.Equal([]string{"2:1 INDEX idx_gin: Schema contains gin index on multi column which is not supported."}, summarizeIssues(reportStruct.Issues))
+	assert.Len(reportStruct.WaivedIssues, 1)
+	assert.Equal(map[string]bool{"idx_gin": true}, summaryMap["INDEX"].invalidCount)
+	assert.Equal([]string{`The waiver of the issue "Schema contains gin index on multi column which is not supported." in INDEX idx_gin expired on 2024-01-31, review the issue again`},
+		reportStruct.Summary.Notes)
+
+	_, err = readIssueWaivers(writeTempFile(t, "waivers.json", `{"waivers": [{"objectType": "INDEX", "objectName": "i", "reason": "r", "expiry": "31-01-2024"}]}`))
+	assert.ErrorContains(err, `waiver #1: invalid expiry "31-01-2024"`)
+}
+
+func TestApplySchemaFixes(t *testing.T) {
+	assert := assert.New(t)
+	schemaDir := t.TempDir()
+	tableFilePath := filepath.Join(schemaDir, "tables", "table.sql")
+	assert.NoError(os.MkdirAll(filepath.Dir(tableFilePath), 0755))
+	assert.NoError(os.WriteFile(tableFilePath, []byte(`SET statement_timeout = 0;
+
+CREATE TABLE public.orders (
+    id integer NOT NULL,
+    price numeric,
+    qty integer,
+    total numeric GENERATED ALWAYS AS (price * "qty") STORED
+) WITH (fillfactor = 70, oids = true);
+
+CREATE TABLE public.events (id bigint) WITH OIDS;
+
+ALTER TABLE public.events SET WITH OIDS;
+
+ALTER TABLE ONLY public.orders
+    ALTER COLUMN total SET STORAGE plain;
+
+ALTER TABLE ONLY public.orders ALTER COLUMN price SET STATISTICS 500, ALTER COLUMN id SET DEFAULT 1;
+
+ALTER TABLE public.orders CLUSTER ON orders_pkey;
+`), 0644))
+
+	functionFilePath := filepath.Join(schemaDir, "functions", "function.sql")
+	assert.NoError(os.MkdirAll(filepath.Dir(functionFilePath), 0755))
+	assert.NoError(os.WriteFile(functionFilePath, []byte("CREATE FUNCTION public.f() RETURNS integer LANGUAGE sql AS 'SELECT 1';\n\n"), 0644))
+
+	reportStruct = utils.Report{}
+	sourceObjList = utils.GetSchemaObjectList("postgresql")
+	summaryMap = make(map[string]*summaryInfo)
+	applySchemaFixes(schemaDir)
+
+	fixed, err := os.ReadFile(tableFilePath)
+	assert.NoError(err)
+	assert.Equal(`SET statement_timeout = 0;
+
+CREATE TABLE public.orders (
+    id integer NOT NULL,
+    price numeric,
+    qty integer,
+    total numeric
+) WITH (fillfactor = 70);
+
+CREATE TABLE public.events (id bigint);
+
+
+
+-- yb-voyager analyze-schema --apply-fixes: Commented out ALTER column SET STATISTICS
+-- ALTER TABLE ONLY public.orders ALTER COLUMN price SET STATISTICS 500, ALTER COLUMN id SET DEFAULT 1;
+ALTER TABLE ONLY public.orders ALTER COLUMN id SET DEFAULT 1;
+
+`, string(fixed))
+
+	// the function is added to the existing functions, and the trigger to the new file of the triggers
+	fixed, err = os.ReadFile(functionFilePath)
+	assert.NoError(err)
+	assert.Equal(`CREATE FUNCTION public.f() RETURNS integer LANGUAGE sql AS 'SELECT 1';
+
+CREATE FUNCTION public.orders_total_generated() RETURNS trigger
+    LANGUAGE plpgsql
+    AS $$
+BEGIN
+    NEW.total := NEW.price * NEW."qty";
+    RETURN NEW;
+END;
+$$;
+`, string(fixed))
+	fixed, err = os.ReadFile(filepath.Join(schemaDir, "triggers", "trigger.sql"))
+	assert.NoError(err)
+	assert.Equal(`CREATE TRIGGER orders_total_generated BEFORE INSERT OR UPDATE ON public.orders FOR EACH ROW EXECUTE FUNCTION public.orders_total_generated();
+`, string(fixed))
+	backupFiles, err := filepath.Glob(functionFilePath + ".*.bak")
+	assert.NoError(err)
+	assert.Len(backupFiles, 1)
+
+	backupFiles, err = filepath.Glob(tableFilePath + ".*.bak")
+	assert.NoError(err)
+	assert.Len(backupFiles, 1)
+	assert.Equal([]string{
+		"7 TABLE public.orders: Replaced the stored generated column total with the trigger orders_total_generated",
+		"8 TABLE public.orders: Removed WITH (OIDS = true)",
+		"10 TABLE public.events: Removed WITH OIDS",
+		"12 TABLE public.events: Removed ALTER TABLE SET WITH OIDS",
+		"14 TABLE public.orders: Removed ALTER TABLE ALTER column SET STORAGE",
+		"17 TABLE public.orders: Commented out ALTER column SET STATISTICS",
+		"19 TABLE public.orders: Removed ALTER TABLE CLUSTER ON",
+	}, lo.Map(reportStruct.AppliedFixes, func(fix utils.AppliedFix, _ int) string {
+		return fmt.Sprintf("%d %s %s: %s", fix.Line, fix.ObjectType, fix.ObjectName, fix.Description)
+	}))
+	assert.Equal(backupFiles[0], reportStruct.AppliedFixes[0].BackupFilePath)
+
+	// the fixed schema has no issues left
+	assert.Empty(analyzeSchemaFile(t, "TABLE", string(fixed)))
+}
+
+func TestGetRemoveOptionFix(t *testing.T) {
+	assert := assert.New(t)
+	sql := `CREATE TABLE t (id int) WITH (oids = true, fillfactor = 70)`
+	tokens, err := queryparser.Tokens(sql)
+	assert.NoError(err)
+	fix, ok := getRemoveOptionFix(tokens, strings.Index(sql, "oids"))
+	assert.True(ok)
+	assert.Equal("CREATE TABLE t (id int) WITH (fillfactor = 70)", sql[:fix.start]+fix.replacement+sql[fix.end:])
+
+	// the option followed by a comma ending the text
+	sql = `CREATE TABLE t (id int) WITH (oids = true,`
+	tokens, err = queryparser.Tokens(sql)
+	assert.NoError(err)
+	_, ok = getRemoveOptionFix(tokens, strings.Index(sql, "oids"))
+	assert.False(ok)
+}
@@ -25,6 +25,7 @@ import (
 
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+	"github.com/yugabyte/yb-voyager/yb-voyager/src/datastore"
 	"github.com/yugabyte/yb-voyager/yb-voyager/src/metadb"
 	"github.com/yugabyte/yb-voyager/yb-voyager/src/utils"
 )
@@ -189,12 +190,18 @@ func (d *EventSegmentDeleter) Run() error {
 
 type EventSegmentCopier struct {
 	Dest string
+	// Local directory or object store location (s3://, gs:// or https://) of Dest.
+	dataStore datastore.DataStore
 }
 
 func NewEventSegmentCopier(dest string) *EventSegmentCopier {
-	return &EventSegmentCopier{
+	copier := &EventSegmentCopier{
 		Dest: dest,
 	}
+	if dest != "" {
+		copier.dataStore = datastore.NewDataStore(dest)
+	}
+	return copier
 }
 
 func (m *EventSegmentCopier) getImportCount() (int, error) {
@@ -212,12 +219,9 @@ func (m *EventSegmentCopier) getImportCount() (int, error) {
 }
 
 func (m *EventSegmentCopier) ifExistsDeleteSegmentFileFromArchive(segmentNewPath string) error {
-	if utils.FileOrFolderExists(segmentNewPath) {
-		err := os.Remove(segmentNewPath)
-		if err != nil {
-			return fmt.Errorf("delete %s: %w", segmentNewPath, err)
-		}
-		log.Infof("Deleted existing file %s", segmentNewPath)
+	err := m.dataStore.Remove(segmentNewPath)
+	if err != nil {
+		return fmt.Errorf("delete %s: %w", segmentNewPath, err)
 	}
 	return nil
 }
@@ -228,15 +232,20 @@ func (m *EventSegmentCopier) copySegmentFile(segment utils.Segment, segmentNewPa
 		return fmt.Errorf("open segment file %s : %v", segment.FilePath, err)
 	}
 	defer sourceFile.Close()
-	destinationFile, err := os.Create(segmentNewPath)
+	destinationFile, err := m.dataStore.Create(segmentNewPath)
 	if err != nil {
 		return fmt.Errorf("create file %s : %v", segmentNewPath, err)
 	}
-	defer destinationFile.Close()
 	_, err = io.Copy(destinationFile, sourceFile)
 	if err != nil {
+		destinationFile.Close()
 		return fmt.Errorf("copy file %s : %v", segment.FilePath, err)
 	}
+	// For the object stores, the file is uploaded only on Close.
+	err = destinationFile.Close()
+	if err != nil {
+		return fmt.Errorf("close file %s : %v", segmentNewPath, err)
+	}
 	return nil
 }
 
@@ -273,7 +282,7 @@ func (m *EventSegmentCopier) Run() error {
 			var segmentNewPath string
 			if m.Dest != "" {
 				segmentFileName := filepath.Base(segment.FilePath)
-				segmentNewPath = fmt.Sprintf("%s/%s", m.Dest, segmentFileName)
+				segmentNewPath = datastore.JoinPath(m.Dest, segmentFileName)
 
 				err := m.ifExistsDeleteSegmentFileFromArchive(segmentNewPath)
 				if err != nil {
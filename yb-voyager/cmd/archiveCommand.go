@@ -19,8 +19,10 @@ package cmd
 import (
 	"fmt"
 	"path/filepath"
+	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/yugabyte/yb-voyager/yb-voyager/src/datastore"
 	"github.com/yugabyte/yb-voyager/yb-voyager/src/utils"
 )
 
@@ -43,6 +45,7 @@ func registerCommonArchiveFlags(cmd *cobra.Command) {
 
 	cmd.Flags().StringVar(&moveDestination, "move-to", "",
 		"Path to the directory where the imported change events are to be moved to. "+
+			"It can also be an object store location: s3://, gs:// or https:// (Azure blob storage). "+
 			"Note that, the changes are deleted from the export-dir only after the disk utilisation exceeds 70%.")
 
 	BoolVar(cmd.Flags(), &deleteSegments, "delete-changes-without-archiving", false,
@@ -58,7 +61,14 @@ func validateCommonArchiveFlags() {
 
 func validateMoveToFlag() {
 	if moveDestination != "" {
-		if !utils.FileOrFolderExists(moveDestination) {
+		if datastore.IsRemoteLocation(moveDestination) {
+			err := validateObjectStoreURL(moveDestination)
+			if err != nil {
+				utils.ErrExit("invalid move destination %q: %v\n", moveDestination, err)
+			}
+			moveDestination = strings.TrimSuffix(moveDestination, "/")
+			fmt.Printf("Note: Using %q as move destination\n", moveDestination)
+		} else if !utils.FileOrFolderExists(moveDestination) {
 			utils.ErrExit("move destination %q doesn't exists.\n", moveDestination)
 		} else {
 			var err error
@@ -0,0 +1,382 @@
+/*
+Copyright (c) YugabyteDB, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/yugabyte/yb-voyager/yb-voyager/src/utils"
+)
+
+var assessMigrationCmd = &cobra.Command{
+	Use: "migration",
+	Short: "Assess the migration from the source database to YugabyteDB.\n" +
+		"Gathers the sizes of the tables, the index types, the unsupported datatypes and the schema issues from the source database, " +
+		"without exporting any data, and recommends the size of the YugabyteDB cluster. The schema is to be exported by export schema first.",
+	Long: ``,
+
+	PreRun: func(cmd *cobra.Command, args []string) {
+		setExportFlagsDefaults()
+		err := validateExportFlags(cmd, SOURCE_DB_EXPORTER_ROLE)
+		if err != nil {
+			utils.ErrExit("Error: %s", err.Error())
+		}
+		markFlagsRequired(cmd)
+	},
+
+	Run: func(cmd *cobra.Command, args []string) {
+		assessMigration()
+	},
+}
+
+const MIGRATION_ASSESSMENT_REPORT_FILE_NAME = "migration_assessment_report"
+
+const (
+	ASSESSMENT_REPLICATION_FACTOR = 3
+	ASSESSMENT_MIN_NODES          = 3
+	// Replicated data that a node of each size is expected to hold comfortably.
+	ASSESSMENT_MAX_DATA_PER_8_VCPU_NODE  = int64(512) * 1024 * 1024 * 1024
+	ASSESSMENT_MAX_DATA_PER_16_VCPU_NODE = int64(2) * 1024 * 1024 * 1024 * 1024
+	// Tables and indexes that a node of 8 vCPUs is expected to serve, scaled with the vCPUs.
+	ASSESSMENT_MAX_OBJECTS_PER_8_VCPU_NODE = 1000
+	ASSESSMENT_MEMORY_PER_VCPU_GIB         = 4
+)
+
+const (
+	MIGRATION_COMPLEXITY_LOW    = "LOW"
+	MIGRATION_COMPLEXITY_MEDIUM = "MEDIUM"
+	MIGRATION_COMPLEXITY_HIGH   = "HIGH"
+)
+
+type MigrationAssessmentReport struct {
+	MigrationUUID       string              `json:"migration_uuid"`
+	GeneratedAt         string              `json:"generated_at"`
+	SourceDBType        string              `json:"source_db_type"`
+	SourceDBVersion     string              `json:"source_db_version"`
+	DBName              string              `json:"db_name"`
+	SchemaName          string              `json:"schema_name"`
+	TableCount          int                 `json:"table_count"`
+	TotalRowCount       int64               `json:"total_row_count"`
+	TotalSize           int64               `json:"total_size_in_bytes"`
+	Tables              []*AssessedTable    `json:"tables"`
+	IndexCount          int                 `json:"index_count"`
+	IndexTypes          map[string]int      `json:"index_types"`
+	UnsupportedColumns  []string            `json:"unsupported_columns"`
+	NonPKTables         []string            `json:"tables_without_primary_key"`
+	DBObjects           []utils.DBObject    `json:"database_objects"`
+	SchemaIssueCount    int                 `json:"schema_issue_count"`
+	SchemaIssues        []*SchemaIssueCount `json:"schema_issues"`
+	MigrationComplexity string              `json:"migration_complexity"`
+	Sizing              *ClusterSizing      `json:"sizing"`
+}
+
+type AssessedTable struct {
+	Name     string `json:"name"`
+	RowCount int64  `json:"row_count"`
+	// Size is -1 when the source database could not report the size of the table.
+	Size int64 `json:"size_in_bytes"`
+}
+
+type SchemaIssueCount struct {
+	ObjectType string `json:"object_type"`
+	Reason     string `json:"reason"`
+	Count      int    `json:"count"`
+}
+
+type ClusterSizing struct {
+	NumNodes          int `json:"num_nodes"`
+	VCPUsPerNode      int `json:"vcpus_per_node"`
+	MemoryPerNodeGiB  int `json:"memory_per_node_gib"`
+	ReplicationFactor int `json:"replication_factor"`
+	ParallelJobs      int `json:"parallel_jobs"`
+}
+
+func assessMigration() {
+	if !schemaIsExported() {
+		// The analysis of the schema needs the DDL files, exported along with the migration status by export schema.
+		utils.ErrExit("Error: schema is not exported yet. Run export schema with the same --export-dir before assessing the migration.")
+	}
+	err := retrieveMigrationUUID()
+	if err != nil {
+		utils.ErrExit("failed to get migration UUID: %w", err)
+	}
+
+	utils.PrintAndLog("analyzing the exported schema...")
+	analysisReport := analyzeSchemaInternal()
+
+	err = source.DB().Connect()
+	if err != nil {
+		utils.ErrExit("Failed to connect to the source db: %s", err)
+	}
+	defer source.DB().Disconnect()
+
+	report := &MigrationAssessmentReport{
+		MigrationUUID:   migrationUUID.String(),
+		GeneratedAt:     time.Now().Format(time.RFC3339),
+		SourceDBType:    source.DBType,
+		SourceDBVersion: source.DB().GetVersion(),
+		DBName:          source.DBName,
+		SchemaName:      source.Schema,
+		DBObjects:       analysisReport.Summary.DBObjects,
+	}
+	report.SchemaIssues = countSchemaIssues(analysisReport.Issues)
+	report.SchemaIssueCount = len(analysisReport.Issues)
+
+	utils.PrintAndLog("gathering the sizes of the tables...")
+	tableList := source.DB().GetAllTableNames()
+	for _, tableName := range tableList {
+		table := &AssessedTable{
+			Name:     tableName.Qualified.MinQuoted,
+			RowCount: source.DB().GetTableApproxRowCount(tableName),
+		}
+		table.Size, err = source.DB().GetTableSize(tableName)
+		if err != nil {
+			log.Warnf("get size of table %q: %v", tableName.Qualified.MinQuoted, err)
+			table.Size = -1
+		}
+		report.Tables = append(report.Tables, table)
+		report.TotalRowCount += table.RowCount
+		if table.Size > 0 {
+			report.TotalSize += table.Size
+		}
+	}
+	report.TableCount = len(report.Tables)
+
+	report.IndexTypes = getSourceIndexTypes()
+	for _, count := range report.IndexTypes {
+		report.IndexCount += count
+	}
+	_, report.UnsupportedColumns = source.DB().GetColumnsWithSupportedTypes(tableList, false, false)
+	report.NonPKTables, err = source.DB().GetNonPKTables()
+	if err != nil {
+		utils.ErrExit("get tables without primary key: %s", err)
+	}
+
+	report.MigrationComplexity = getMigrationComplexity(report.SchemaIssueCount, len(report.UnsupportedColumns))
+	report.Sizing = getClusterSizing(report.TotalSize, report.TableCount+report.IndexCount)
+	writeMigrationAssessmentReport(report)
+}
+
+// getSourceIndexTypes counts the indexes of the source database by their types. For the source databases not
+// reporting the indexes, the access methods of the exported CREATE INDEX statements are counted.
+func getSourceIndexTypes() map[string]int {
+	indexTypes := make(map[string]int)
+	indexesInfo := source.DB().GetIndexesInfo()
+	if indexesInfo != nil {
+		for _, indexInfo := range indexesInfo {
+			indexTypes[strings.ToUpper(indexInfo.IndexType)]++
+		}
+		return indexTypes
+	}
+
+	schemaDir := filepath.Join(exportDir, "schema")
+	for _, objType := range []string{"INDEX", "PARTITION_INDEX", "FTS_INDEX"} {
+		for _, sqlInfo := range createSqlStrInfoArray(utils.GetObjectFilePath(schemaDir, objType), objType) {
+			for _, rawStmt := range sqlInfo.parseTree {
+				indexStmt := rawStmt.Stmt.GetIndexStmt()
+				if indexStmt == nil {
+					continue
+				}
+				accessMethod := indexStmt.AccessMethod
+				if accessMethod == "" {
+					accessMethod = "btree"
+				}
+				indexTypes[strings.ToUpper(accessMethod)]++
+			}
+		}
+	}
+	return indexTypes
+}
+
+func countSchemaIssues(issues []utils.Issue) []*SchemaIssueCount {
+	var counts []*SchemaIssueCount
+	countByKey := make(map[string]*SchemaIssueCount)
+	for _, issue := range issues {
+		key := issue.ObjectType + "|" + issue.Reason
+		count, ok := countByKey[key]
+		if !ok {
+			count = &SchemaIssueCount{ObjectType: issue.ObjectType, Reason: issue.Reason}
+			countByKey[key] = count
+			counts = append(counts, count)
+		}
+		count.Count++
+	}
+	sort.SliceStable(counts, func(i, j int) bool {
+		return counts[i].Count > counts[j].Count
+	})
+	return counts
+}
+
+// getMigrationComplexity grades the manual effort of the migration from the number of constructs needing changes.
+func getMigrationComplexity(numSchemaIssues int, numUnsupportedColumns int) string {
+	switch numChanges := numSchemaIssues + numUnsupportedColumns; {
+	case numChanges == 0:
+		return MIGRATION_COMPLEXITY_LOW
+	case numChanges <= 20:
+		return MIGRATION_COMPLEXITY_MEDIUM
+	default:
+		return MIGRATION_COMPLEXITY_HIGH
+	}
+}
+
+// getClusterSizing recommends the YugabyteDB cluster for the data of the given size spread over the given
+// number of tables and indexes. The parallel jobs follow the default of import data: a job for every 4 cores.
+func getClusterSizing(dataSize int64, numObjects int) *ClusterSizing {
+	replicatedDataSize := dataSize * ASSESSMENT_REPLICATION_FACTOR
+	vCPUsPerNode, maxDataPerNode := 8, ASSESSMENT_MAX_DATA_PER_8_VCPU_NODE
+	if replicatedDataSize > ASSESSMENT_MIN_NODES*ASSESSMENT_MAX_DATA_PER_8_VCPU_NODE {
+		vCPUsPerNode, maxDataPerNode = 16, ASSESSMENT_MAX_DATA_PER_16_VCPU_NODE
+	}
+	maxObjectsPerNode := ASSESSMENT_MAX_OBJECTS_PER_8_VCPU_NODE * vCPUsPerNode / 8
+
+	numNodes := ASSESSMENT_MIN_NODES
+	nodesForData := int(math.Ceil(float64(replicatedDataSize) / float64(maxDataPerNode)))
+	if nodesForData > numNodes {
+		numNodes = nodesForData
+	}
+	nodesForObjects := int(math.Ceil(float64(numObjects*ASSESSMENT_REPLICATION_FACTOR) / float64(maxObjectsPerNode)))
+	if nodesForObjects > numNodes {
+		numNodes = nodesForObjects
+	}
+	return &ClusterSizing{
+		NumNodes:          numNodes,
+		VCPUsPerNode:      vCPUsPerNode,
+		MemoryPerNodeGiB:  vCPUsPerNode * ASSESSMENT_MEMORY_PER_VCPU_GIB,
+		ReplicationFactor: ASSESSMENT_REPLICATION_FACTOR,
+		ParallelJobs:      numNodes * vCPUsPerNode / 4,
+	}
+}
+
+func writeMigrationAssessmentReport(report *MigrationAssessmentReport) {
+	jsonBytes, err := json.Marshal(report)
+	if err != nil {
+		utils.ErrExit("marshal migration assessment report: %s", err)
+	}
+	reports := map[string]string{
+		"json": utils.PrettifyJsonString(string(jsonBytes)),
+		"html": utils.PrettifyHtmlString(generateMigrationAssessmentHTMLReport(report)),
+	}
+	for _, format := range []string{"html", "json"} {
+		reportPath := filepath.Join(exportDir, "reports", MIGRATION_ASSESSMENT_REPORT_FILE_NAME+"."+format)
+		err = os.WriteFile(reportPath, []byte(reports[format]), 0644)
+		if err != nil {
+			utils.ErrExit("failed to write report to %q: %s", reportPath, err)
+		}
+		fmt.Printf("-- find migration assessment report at: %s\n", reportPath)
+	}
+}
+
+func generateMigrationAssessmentHTMLReport(report *MigrationAssessmentReport) string {
+	sizeToString := func(size int64) string {
+		if size < 0 {
+			return "unknown"
+		}
+		return utils.HumanReadableByteCount(size)
+	}
+
+	htmlstring := "<html><body bgcolor='#EFEFEF'><h1>Migration Assessment Report</h1>"
+	htmlstring += "<table><tr><th>Database Name</th><td>" + report.DBName + "</td></tr>"
+	htmlstring += "<tr><th>Schema Name</th><td>" + report.SchemaName + "</td></tr>"
+	htmlstring += "<tr><th>" + strings.ToUpper(report.SourceDBType) + " Version</th><td>" + report.SourceDBVersion + "</td></tr>"
+	htmlstring += "<tr><th>Migration Complexity</th><td>" + report.MigrationComplexity + "</td></tr></table>"
+
+	htmlstring += "<h3>Recommended YugabyteDB Cluster</h3>"
+	htmlstring += "<table><tr><th>Nodes</th><td>" + strconv.Itoa(report.Sizing.NumNodes) + "</td></tr>"
+	htmlstring += "<tr><th>vCPUs per Node</th><td>" + strconv.Itoa(report.Sizing.VCPUsPerNode) + "</td></tr>"
+	htmlstring += "<tr><th>Memory per Node</th><td>" + strconv.Itoa(report.Sizing.MemoryPerNodeGiB) + " GiB</td></tr>"
+	htmlstring += "<tr><th>Replication Factor</th><td>" + strconv.Itoa(report.Sizing.ReplicationFactor) + "</td></tr>"
+	htmlstring += "<tr><th>--parallel-jobs</th><td>" + strconv.Itoa(report.Sizing.ParallelJobs) + "</td></tr></table>"
+
+	htmlstring += "<h3>Tables</h3>"
+	htmlstring += "<table><tr><th>Table Count</th><td>" + strconv.Itoa(report.TableCount) + "</td></tr>"
+	htmlstring += "<tr><th>Total Row Count</th><td>" + strconv.FormatInt(report.TotalRowCount, 10) + "</td></tr>"
+	htmlstring += "<tr><th>Total Size</th><td>" + sizeToString(report.TotalSize) + "</td></tr></table><br>"
+	htmlstring += "<table width='100%' table-layout='fixed'><tr><th>Table</th><th>Approx Row Count</th><th>Size</th></tr>"
+	for _, table := range report.Tables {
+		htmlstring += "<tr><td>" + table.Name + "</td><td style='text-align: center;'>" + strconv.FormatInt(table.RowCount, 10) +
+			"</td><td style='text-align: center;'>" + sizeToString(table.Size) + "</td></tr>"
+	}
+	htmlstring += "</table>"
+
+	htmlstring += "<h3>Indexes</h3>"
+	htmlstring += "<table><tr><th>Index Type</th><th>Count</th></tr>"
+	indexTypes := make([]string, 0, len(report.IndexTypes))
+	for indexType := range report.IndexTypes {
+		indexTypes = append(indexTypes, indexType)
+	}
+	sort.Strings(indexTypes)
+	for _, indexType := range indexTypes {
+		htmlstring += "<tr><td>" + indexType + "</td><td style='text-align: center;'>" + strconv.Itoa(report.IndexTypes[indexType]) + "</td></tr>"
+	}
+	htmlstring += "</table>"
+
+	if len(report.UnsupportedColumns) > 0 {
+		htmlstring += "<h3>Columns with Unsupported Datatypes</h3>"
+		htmlstring += "<ul list-style-type='disc'>"
+		for _, column := range report.UnsupportedColumns {
+			htmlstring += "<li>" + column + "</li>"
+		}
+		htmlstring += "</ul>"
+	}
+	if len(report.NonPKTables) > 0 {
+		htmlstring += "<h3>Tables without Primary Key</h3>"
+		htmlstring += "<ul list-style-type='disc'>"
+		for _, table := range report.NonPKTables {
+			htmlstring += "<li>" + table + "</li>"
+		}
+		htmlstring += "</ul>"
+	}
+
+	htmlstring += "<h3>Schema Analysis</h3>"
+	htmlstring += "<table width='100%' table-layout='fixed'><tr><th>Object</th><th>Total Count</th><th>Invalid Count</th></tr>"
+	for _, dbObject := range report.DBObjects {
+		if dbObject.TotalCount != 0 {
+			htmlstring += "<tr><th>" + dbObject.ObjectType + "</th><td style='text-align: center;'>" + strconv.Itoa(dbObject.TotalCount) +
+				"</td><td style='text-align: center;'>" + strconv.Itoa(dbObject.InvalidCount) + "</td></tr>"
+		}
+	}
+	htmlstring += "</table><br>"
+	if len(report.SchemaIssues) > 0 {
+		htmlstring += "<table width='100%' table-layout='fixed'><tr><th>Object</th><th width='70%'>Issue</th><th>Count</th></tr>"
+		for _, issue := range report.SchemaIssues {
+			htmlstring += "<tr><td>" + issue.ObjectType + "</td><td width='70%'>" + issue.Reason +
+				"</td><td style='text-align: center;'>" + strconv.Itoa(issue.Count) + "</td></tr>"
+		}
+		htmlstring += "</table>"
+	}
+	htmlstring += "<p>Run analyze-schema for the details of the issues.</p>"
+	htmlstring += "</body></html>"
+	return htmlstring
+}
+
+func init() {
+	assessCmd.AddCommand(assessMigrationCmd)
+	registerCommonGlobalFlags(assessMigrationCmd)
+	registerSourceDBConnFlags(assessMigrationCmd, false)
+	BoolVar(assessMigrationCmd.Flags(), &source.UseOrafce, "use-orafce", true,
+		"enable using orafce extension in the export of the schema to be assessed")
+}
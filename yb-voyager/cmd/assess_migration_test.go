@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yugabyte/yb-voyager/yb-voyager/src/utils"
+)
+
+func TestGetClusterSizing(t *testing.T) {
+	assert := assert.New(t)
+	const GiB = int64(1024 * 1024 * 1024)
+
+	sizing := getClusterSizing(10*GiB, 50)
+	assert.Equal(&ClusterSizing{NumNodes: 3, VCPUsPerNode: 8, MemoryPerNodeGiB: 32, ReplicationFactor: 3, ParallelJobs: 6}, sizing)
+
+	// 1 TiB replicated thrice does not fit in three nodes of 8 vCPUs.
+	sizing = getClusterSizing(1024*GiB, 50)
+	assert.Equal(&ClusterSizing{NumNodes: 3, VCPUsPerNode: 16, MemoryPerNodeGiB: 64, ReplicationFactor: 3, ParallelJobs: 12}, sizing)
+
+	sizing = getClusterSizing(4096*GiB, 50)
+	assert.Equal(6, sizing.NumNodes)
+	assert.Equal(24, sizing.ParallelJobs)
+
+	// Many small tables need more nodes than their data.
+	sizing = getClusterSizing(GiB, 2000)
+	assert.Equal(6, sizing.NumNodes)
+	assert.Equal(8, sizing.VCPUsPerNode)
+}
+
+func TestMigrationComplexityAndIssueCounts(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal(MIGRATION_COMPLEXITY_LOW, getMigrationComplexity(0, 0))
+	assert.Equal(MIGRATION_COMPLEXITY_MEDIUM, getMigrationComplexity(15, 5))
+	assert.Equal(MIGRATION_COMPLEXITY_HIGH, getMigrationComplexity(15, 6))
+
+	issues := []utils.Issue{
+		{ObjectType: "TABLE", ObjectName: "t1", Reason: "r1"},
+		{ObjectType: "TABLE", ObjectName: "t2", Reason: "r2"},
+		{ObjectType: "TABLE", ObjectName: "t3", Reason: "r2"},
+		{ObjectType: "INDEX", ObjectName: "i1", Reason: "r2"},
+	}
+	assert.Equal([]*SchemaIssueCount{
+		{ObjectType: "TABLE", Reason: "r2", Count: 2},
+		{ObjectType: "TABLE", Reason: "r1", Count: 1},
+		{ObjectType: "INDEX", Reason: "r2", Count: 1},
+	}, countSchemaIssues(issues))
+}
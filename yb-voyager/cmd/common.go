@@ -60,7 +60,10 @@ func updateFilePaths(source *srcdb.Source, exportDir string, tablesProgressMetad
 	// TODO: handle the case if table name has double quotes/case sensitive
 
 	sortedKeys := utils.GetSortedKeys(tablesProgressMetadata)
-	if source.DBType == "postgresql" {
+	if source.DBType == "postgresql" && source.UseNativeExport {
+		// Tables can be split into multiple data files, the progress is reported by the exporter itself.
+		log.Infof("data files of the tables will be recorded in the descriptor by the native export")
+	} else if source.DBType == "postgresql" {
 		requiredMap = getMappingForTableNameVsTableFileName(filepath.Join(exportDir, "data"), false)
 		for _, key := range sortedKeys {
 			tableName := tablesProgressMetadata[key].TableName
@@ -88,6 +91,12 @@ func updateFilePaths(source *srcdb.Source, exportDir string, tablesProgressMetad
 			tablesProgressMetadata[key].InProgressFilePath = filepath.Join(exportDir, "data", "tmp_"+targetTableName+"_data.sql")
 			tablesProgressMetadata[key].FinalFilePath = filepath.Join(exportDir, "data", targetTableName+"_data.sql")
 		}
+	} else if source.DBType == SQLITE {
+		for _, key := range sortedKeys {
+			targetTableName := srcdb.SQLiteTargetTableName(tablesProgressMetadata[key].TableName)
+			tablesProgressMetadata[key].InProgressFilePath = filepath.Join(exportDir, "data", "tmp_"+targetTableName+"_data.csv")
+			tablesProgressMetadata[key].FinalFilePath = filepath.Join(exportDir, "data", targetTableName+"_data.csv")
+		}
 	}
 
 	logMsg := "After updating data file paths, TablesProgressMetadata:"
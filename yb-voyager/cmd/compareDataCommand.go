@@ -0,0 +1,351 @@
+/*
+Copyright (c) YugabyteDB, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/gosuri/uitable"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/yugabyte/yb-voyager/yb-voyager/src/srcdb"
+	"github.com/yugabyte/yb-voyager/yb-voyager/src/tgtdb"
+	"github.com/yugabyte/yb-voyager/yb-voyager/src/utils"
+	"github.com/yugabyte/yb-voyager/yb-voyager/src/utils/jsonfile"
+	"github.com/yugabyte/yb-voyager/yb-voyager/src/utils/sqlname"
+)
+
+const (
+	DATA_COMPARISON_REPORT_FILE_NAME = "data_comparison_report.json"
+	COMPARISON_STATUS_PASS           = "PASS"
+	COMPARISON_STATUS_FAIL           = "FAIL"
+	// The row counts match, but the checksums cannot be computed for the source db type.
+	COMPARISON_STATUS_NOT_SUPPORTED = "NOT_SUPPORTED"
+)
+
+var skipChecksum utils.BoolStr
+var computeDataDiff utils.BoolStr
+
+var compareDataCmd = &cobra.Command{
+	Use:   "data",
+	Short: "Compare the row counts and checksums of the migrated tables between the source and target databases.",
+	Long: `Compare the exact row count and the content checksum of every table exported from the source database against the target database.
+A per-table PASS/FAIL/NOT_SUPPORTED report is saved in the export-dir/reports directory.
+Content checksums are computed only for PostgreSQL and YugabyteDB source databases, the tables of the other source databases with matching row counts are reported as NOT_SUPPORTED.
+With --diff, the keys of the missing, extra and changed rows of every mismatched table are listed in export-dir/reports/data_diff/<table>.ndjson.
+In case of live migration, run this command only after the writes on the source database are stopped and all the changes are imported to the target database.`,
+
+	PreRun: func(cmd *cobra.Command, args []string) {
+		validateMetaDBCreated()
+	},
+
+	Run: compareDataCommandFn,
+}
+
+type TableDataComparison struct {
+	SourceTableName string `json:"source_table_name"`
+	TargetTableName string `json:"target_table_name"`
+	SourceRowCount  int64  `json:"source_row_count"`
+	TargetRowCount  int64  `json:"target_row_count"`
+	SourceChecksum  string `json:"source_checksum,omitempty"`
+	TargetChecksum  string `json:"target_checksum,omitempty"`
+	Status          string `json:"status"`
+	Reason          string `json:"reason,omitempty"`
+	DiffFilePath    string `json:"diff_file_path,omitempty"`
+	NumMissingRows  int64  `json:"num_missing_rows,omitempty"`
+	NumExtraRows    int64  `json:"num_extra_rows,omitempty"`
+	NumChangedRows  int64  `json:"num_changed_rows,omitempty"`
+}
+
+type DataComparisonReport struct {
+	MigrationUUID string                 `json:"migration_uuid"`
+	GeneratedAt   string                 `json:"generated_at"`
+	SourceDBType  string                 `json:"source_db_type"`
+	Tables        []*TableDataComparison `json:"tables"`
+}
+
+func compareDataCommandFn(cmd *cobra.Command, args []string) {
+	msr, err := metaDB.GetMigrationStatusRecord()
+	if err != nil {
+		utils.ErrExit("get migration status record: %v", err)
+	} else if msr == nil {
+		utils.ErrExit("migration status record not found. Is the migration initialized?")
+	}
+	if msr.SourceDBConf == nil || len(msr.TableListExportedFromSource) == 0 {
+		utils.ErrExit("no tables to compare as export data is not started yet")
+	}
+	if msr.TargetDBConf == nil {
+		utils.ErrExit("no tables to compare as import data is not started yet")
+	}
+	retrieveMigrationUUID()
+
+	source = *msr.SourceDBConf
+	sqlname.SourceDBType = source.DBType
+	source.Password, err = getPassword(cmd, "source-db-password", "SOURCE_DB_PASSWORD")
+	if err != nil {
+		utils.ErrExit("error in getting source-db-password: %v", err)
+	}
+	err = source.DB().Connect()
+	if err != nil {
+		utils.ErrExit("connecting to source db: %v", err)
+	}
+	defer source.DB().Disconnect()
+
+	tconf = *msr.TargetDBConf
+	getTargetPassword(cmd)
+	tdb = tgtdb.NewTargetDB(&tconf)
+	err = tdb.Init()
+	if err != nil {
+		utils.ErrExit("initializing target db: %v", err)
+	}
+	defer tdb.Finalize()
+
+	report := &DataComparisonReport{
+		MigrationUUID: migrationUUID.String(),
+		GeneratedAt:   time.Now().Format(time.RFC3339),
+		SourceDBType:  source.DBType,
+	}
+	for _, table := range msr.TableListExportedFromSource {
+		sourceTable := sqlname.NewSourceNameFromQualifiedName(table)
+		utils.PrintAndLog("comparing data of table %s...", sourceTable.Qualified.MinQuoted)
+		result := compareTableData(sourceTable)
+		if computeDataDiff && result.Status == COMPARISON_STATUS_FAIL {
+			utils.PrintAndLog("computing data diff of table %s...", sourceTable.Qualified.MinQuoted)
+			computeTableDataDiff(result, sourceTable)
+		}
+		report.Tables = append(report.Tables, result)
+	}
+
+	reportFilePath := filepath.Join(exportDir, "reports", DATA_COMPARISON_REPORT_FILE_NAME)
+	err = jsonfile.NewJsonFile[DataComparisonReport](reportFilePath).Create(report)
+	if err != nil {
+		utils.ErrExit("writing data comparison report: %v", err)
+	}
+	numFailedTables := displayDataComparisonReport(report)
+	utils.PrintAndLog("data comparison report is saved at %q", reportFilePath)
+	if numFailedTables > 0 {
+		utils.ErrExit("data comparison failed for %d out of %d tables", numFailedTables, len(report.Tables))
+	}
+}
+
+func compareTableData(sourceTable *sqlname.SourceName) *TableDataComparison {
+	targetTable := getTargetTableNameForSourceTable(sourceTable)
+	result := &TableDataComparison{
+		SourceTableName: sourceTable.Qualified.MinQuoted,
+		TargetTableName: targetTable.Qualified.MinQuoted,
+	}
+
+	var err error
+	result.TargetRowCount, err = getTargetTableRowCount(targetTable)
+	if err != nil {
+		log.Errorf("get row count of target table %q: %v", targetTable, err)
+		return failTableDataComparison(result, fmt.Sprintf("failed to get row count of target table: %v", err))
+	}
+	result.SourceRowCount = source.DB().GetTableRowCount(sourceTable.Qualified.MinQuoted)
+	if result.SourceRowCount != result.TargetRowCount {
+		return failTableDataComparison(result, "row count mismatch")
+	}
+
+	if skipChecksum {
+		result.Status = COMPARISON_STATUS_PASS
+		result.Reason = "checksum comparison skipped"
+		return result
+	}
+	columns, err := getTargetTableColumns(targetTable)
+	if err != nil {
+		log.Errorf("get columns of target table %q: %v", targetTable, err)
+		return failTableDataComparison(result, fmt.Sprintf("failed to get columns of target table: %v", err))
+	}
+	_, result.SourceChecksum, err = source.DB().GetTableChecksum(sourceTable, columns, nil, nil)
+	if errors.Is(err, srcdb.ErrChecksumNotSupported) {
+		result.Status = COMPARISON_STATUS_NOT_SUPPORTED
+		result.Reason = fmt.Sprintf("row counts match, checksum comparison is not supported for source db type %q", source.DBType)
+		return result
+	} else if err != nil {
+		log.Errorf("get checksum of source table %q: %v", sourceTable, err)
+		return failTableDataComparison(result, fmt.Sprintf("failed to get checksum of source table: %v", err))
+	}
+	_, result.TargetChecksum, err = getTargetTableChecksum(targetTable, columns, nil, nil)
+	if err != nil {
+		log.Errorf("get checksum of target table %q: %v", targetTable, err)
+		return failTableDataComparison(result, fmt.Sprintf("failed to get checksum of target table: %v", err))
+	}
+	if result.SourceChecksum != result.TargetChecksum {
+		return failTableDataComparison(result, "checksum mismatch")
+	}
+	result.Status = COMPARISON_STATUS_PASS
+	return result
+}
+
+func computeTableDataDiff(result *TableDataComparison, sourceTable *sqlname.SourceName) {
+	targetTable := getTargetTableNameForSourceTable(sourceTable)
+	columns, err := getTargetTableColumns(targetTable)
+	if err == nil {
+		err = diffTableData(result, sourceTable, targetTable, columns)
+	}
+	if errors.Is(err, srcdb.ErrChecksumNotSupported) {
+		result.Reason += fmt.Sprintf("; data diff is not supported for source db type %q", source.DBType)
+	} else if err != nil {
+		log.Errorf("compute data diff of table %q: %v", sourceTable, err)
+		result.Reason += fmt.Sprintf("; failed to compute data diff: %v", err)
+	} else {
+		result.Reason += fmt.Sprintf("; %d missing, %d extra, %d changed rows", result.NumMissingRows, result.NumExtraRows, result.NumChangedRows)
+	}
+}
+
+func failTableDataComparison(result *TableDataComparison, reason string) *TableDataComparison {
+	result.Status = COMPARISON_STATUS_FAIL
+	result.Reason = reason
+	return result
+}
+
+func getTargetTableNameForSourceTable(sourceTable *sqlname.SourceName) *sqlname.TargetName {
+	schemaName := tconf.Schema
+	if source.DBType == POSTGRESQL || source.DBType == YUGABYTEDB {
+		// schema names remain the same on the target in case of PostgreSQL
+		schemaName = sourceTable.SchemaName.Unquoted
+	}
+	return sqlname.NewTargetName(schemaName, sourceTable.ObjectName.MinQuoted)
+}
+
+func getTargetTableRowCount(targetTable *sqlname.TargetName) (int64, error) {
+	query := fmt.Sprintf("SELECT COUNT(*) FROM %s", targetTable.Qualified.MinQuoted)
+	rows, err := tdb.Query(query)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	var rowCount int64
+	if !rows.Next() {
+		return 0, fmt.Errorf("no rows returned by query %q: %w", query, rows.Err())
+	}
+	err = rows.Scan(&rowCount)
+	if err != nil {
+		return 0, fmt.Errorf("scan row count from query %q: %w", query, err)
+	}
+	return rowCount, nil
+}
+
+// Returns the quoted column names of the target table in their ordinal order.
+// The same list is used to compute the checksums on both source and target.
+func getTargetTableColumns(targetTable *sqlname.TargetName) ([]string, error) {
+	query := fmt.Sprintf(`SELECT column_name FROM information_schema.columns
+		WHERE table_schema = '%s' AND table_name = '%s' ORDER BY ordinal_position`,
+		targetTable.SchemaName.Unquoted, targetTable.ObjectName.Unquoted)
+	rows, err := tdb.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var columns []string
+	for rows.Next() {
+		var column string
+		err = rows.Scan(&column)
+		if err != nil {
+			return nil, fmt.Errorf("scan column name from query %q: %w", query, err)
+		}
+		columns = append(columns, fmt.Sprintf(`"%s"`, column))
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("iterate over rows of query %q: %w", query, rows.Err())
+	}
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("table %s not found", targetTable.Qualified.MinQuoted)
+	}
+	return columns, nil
+}
+
+func getTargetTableChecksum(targetTable *sqlname.TargetName, columns []string, keyColumns []string, keyRange *srcdb.KeyRange) (int64, string, error) {
+	predicate := ""
+	if len(keyColumns) > 0 {
+		predicate = keyRange.PGPredicate(keyColumns)
+	}
+	query := srcdb.GetPGTableChecksumQuery(targetTable.Qualified.MinQuoted, columns, predicate)
+	rows, err := tdb.Query(query)
+	if err != nil {
+		return 0, "", err
+	}
+	defer rows.Close()
+
+	var rowCount int64
+	var checksum string
+	if !rows.Next() {
+		return 0, "", fmt.Errorf("no rows returned by query %q: %w", query, rows.Err())
+	}
+	err = rows.Scan(&rowCount, &checksum)
+	if err != nil {
+		return 0, "", fmt.Errorf("scan checksum from query %q: %w", query, err)
+	}
+	return rowCount, checksum, nil
+}
+
+// Returns the number of tables of each comparison status.
+func countTablesByStatus(report *DataComparisonReport) map[string]int {
+	numTablesByStatus := make(map[string]int)
+	for _, table := range report.Tables {
+		numTablesByStatus[table.Status]++
+	}
+	return numTablesByStatus
+}
+
+func displayDataComparisonReport(report *DataComparisonReport) int {
+	numTablesByStatus := countTablesByStatus(report)
+	uitbl := uitable.New()
+	uitbl.MaxColWidth = 50
+	addHeader(uitbl, "SOURCE TABLE", "TARGET TABLE", "SOURCE ROW COUNT", "TARGET ROW COUNT", "STATUS", "REASON")
+	for _, table := range report.Tables {
+		status := color.GreenString(table.Status)
+		switch table.Status {
+		case COMPARISON_STATUS_FAIL:
+			status = color.RedString(table.Status)
+		case COMPARISON_STATUS_NOT_SUPPORTED:
+			status = color.YellowString(table.Status)
+		}
+		uitbl.AddRow(table.SourceTableName, table.TargetTableName, table.SourceRowCount, table.TargetRowCount, status, table.Reason)
+	}
+	fmt.Print("\n")
+	fmt.Println(uitbl)
+	fmt.Print("\n")
+	utils.PrintAndLog("%d tables passed, %d failed, %d not supported for checksum comparison", numTablesByStatus[COMPARISON_STATUS_PASS],
+		numTablesByStatus[COMPARISON_STATUS_FAIL], numTablesByStatus[COMPARISON_STATUS_NOT_SUPPORTED])
+	return numTablesByStatus[COMPARISON_STATUS_FAIL]
+}
+
+func init() {
+	compareCmd.AddCommand(compareDataCmd)
+	registerCommonGlobalFlags(compareDataCmd)
+	compareDataCmd.Flags().MarkHidden("send-diagnostics")
+
+	compareDataCmd.Flags().StringVar(&sourceDbPassword, "source-db-password", "",
+		"source password to connect as the specified user. Alternatively, you can also specify the password by setting the environment variable SOURCE_DB_PASSWORD. If you don't provide a password via the CLI, yb-voyager will prompt you at runtime for a password. If the password contains special characters that are interpreted by the shell (for example, # and $), enclose the password in single quotes.")
+
+	compareDataCmd.Flags().StringVar(&targetDbPassword, "target-db-password", "",
+		"password with which to connect to the target YugabyteDB server. Alternatively, you can also specify the password by setting the environment variable TARGET_DB_PASSWORD. If you don't provide a password via the CLI, yb-voyager will prompt you at runtime for a password. If the password contains special characters that are interpreted by the shell (for example, # and $), enclose the password in single quotes.")
+
+	BoolVar(compareDataCmd.Flags(), &skipChecksum, "skip-checksum", false,
+		"compare only the row counts of the tables and skip the content checksum comparison (default false)")
+
+	BoolVar(compareDataCmd.Flags(), &computeDataDiff, "diff", false,
+		"list the keys of the missing, extra and changed rows of the tables failing the comparison. "+
+			"Supported only for PostgreSQL and YugabyteDB source databases and for tables having a primary key or a unique key (default false)")
+}
@@ -0,0 +1,350 @@
+/*
+Copyright (c) YugabyteDB, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/samber/lo"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/yugabyte/yb-voyager/yb-voyager/src/srcdb"
+	"github.com/yugabyte/yb-voyager/yb-voyager/src/utils/sqlname"
+)
+
+/*
+The data diff of a table is computed by recursively comparing the row count and the checksum of ranges of
+the key (primary key or unique key) space on both the source and the target databases:
+  - If a range matches on both sides, it is skipped.
+  - If a range has at most DATA_DIFF_LEAF_ROW_COUNT rows on both sides, the key and the hash of every row in the
+    range are fetched from both sides and compared to find out the missing, extra and changed rows.
+  - Otherwise, the range is split into (at most) DATA_DIFF_FANOUT sub-ranges using the keys of the side having
+    more rows in the range, and every sub-range is compared again.
+
+Limitations:
+  - Rows having NULL in any of the key columns are not compared.
+  - The key ranges are evaluated by both the databases according to their own collations. If the collations of the
+    textual key columns differ, the rows in a range may differ and will be reported as missing/extra rows.
+*/
+
+const (
+	DATA_DIFF_DIR_NAME       = "data_diff"
+	DATA_DIFF_FANOUT         = 16
+	DATA_DIFF_LEAF_ROW_COUNT = 1000
+
+	ROW_DIFF_TYPE_MISSING = "missing" // Row is present in the source but not in the target.
+	ROW_DIFF_TYPE_EXTRA   = "extra"   // Row is present in the target but not in the source.
+	ROW_DIFF_TYPE_CHANGED = "changed" // Row is present on both sides but the values differ.
+)
+
+type RowDiff struct {
+	Type string            `json:"type"`
+	Key  map[string]string `json:"key"`
+}
+
+type tableDiffSide interface {
+	getChecksum(keyRange *srcdb.KeyRange) (int64, string, error)
+	getKeyBoundaries(keyRange *srcdb.KeyRange, step int64) ([][]string, error)
+	getRowHashes(keyRange *srcdb.KeyRange) ([]*srcdb.RowHash, error)
+}
+
+//=============================================================
+
+type sourceTableDiffSide struct {
+	table      *sqlname.SourceName
+	columns    []string
+	keyColumns []string
+}
+
+func (s *sourceTableDiffSide) getChecksum(keyRange *srcdb.KeyRange) (int64, string, error) {
+	return source.DB().GetTableChecksum(s.table, s.columns, s.keyColumns, keyRange)
+}
+
+func (s *sourceTableDiffSide) getKeyBoundaries(keyRange *srcdb.KeyRange, step int64) ([][]string, error) {
+	return source.DB().GetTableKeyBoundaries(s.table, s.keyColumns, keyRange, step)
+}
+
+func (s *sourceTableDiffSide) getRowHashes(keyRange *srcdb.KeyRange) ([]*srcdb.RowHash, error) {
+	return source.DB().GetTableRowHashes(s.table, s.columns, s.keyColumns, keyRange)
+}
+
+type targetTableDiffSide struct {
+	table      *sqlname.TargetName
+	columns    []string
+	keyColumns []string
+}
+
+func (t *targetTableDiffSide) getChecksum(keyRange *srcdb.KeyRange) (int64, string, error) {
+	return getTargetTableChecksum(t.table, t.columns, t.keyColumns, keyRange)
+}
+
+func (t *targetTableDiffSide) getKeyBoundaries(keyRange *srcdb.KeyRange, step int64) ([][]string, error) {
+	query := srcdb.GetPGKeyBoundariesQuery(t.table.Qualified.MinQuoted, t.keyColumns, keyRange.PGPredicate(t.keyColumns), step)
+	rows, err := tdb.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return srcdb.ScanKeyBoundaries(rows, len(t.keyColumns))
+}
+
+func (t *targetTableDiffSide) getRowHashes(keyRange *srcdb.KeyRange) ([]*srcdb.RowHash, error) {
+	query := srcdb.GetPGRowHashesQuery(t.table.Qualified.MinQuoted, t.columns, t.keyColumns, keyRange.PGPredicate(t.keyColumns))
+	rows, err := tdb.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return srcdb.ScanRowHashes(rows, len(t.keyColumns))
+}
+
+//=============================================================
+
+type tableDataDiffer struct {
+	source       tableDiffSide
+	target       tableDiffSide
+	keyColumns   []string
+	fanout       int64
+	leafRowCount int64
+	onRowDiff    func(*RowDiff) error
+}
+
+func (d *tableDataDiffer) diff() error {
+	return d.diffRange(&srcdb.KeyRange{})
+}
+
+func (d *tableDataDiffer) diffRange(keyRange *srcdb.KeyRange) error {
+	sourceRowCount, sourceChecksum, err := d.source.getChecksum(keyRange)
+	if err != nil {
+		return fmt.Errorf("get checksum of source range %v: %w", keyRange, err)
+	}
+	targetRowCount, targetChecksum, err := d.target.getChecksum(keyRange)
+	if err != nil {
+		return fmt.Errorf("get checksum of target range %v: %w", keyRange, err)
+	}
+	if sourceRowCount == targetRowCount && sourceChecksum == targetChecksum {
+		return nil
+	}
+	maxRowCount := lo.Max([]int64{sourceRowCount, targetRowCount})
+	if maxRowCount <= d.leafRowCount {
+		return d.diffRows(keyRange)
+	}
+
+	// Split the range using the side having more rows, so that every sub-range has fewer rows on that side.
+	side, sideName := d.source, "source"
+	if targetRowCount > sourceRowCount {
+		side, sideName = d.target, "target"
+	}
+	step := (maxRowCount + d.fanout - 1) / d.fanout
+	boundaries, err := side.getKeyBoundaries(keyRange, step)
+	if err != nil {
+		return fmt.Errorf("get key boundaries of %s range %v: %w", sideName, keyRange, err)
+	}
+	if len(boundaries) == 0 {
+		return d.diffRows(keyRange)
+	}
+	lower := keyRange.Lower
+	for _, boundary := range boundaries {
+		err = d.diffRange(&srcdb.KeyRange{Lower: lower, Upper: boundary})
+		if err != nil {
+			return err
+		}
+		lower = boundary
+	}
+	return d.diffRange(&srcdb.KeyRange{Lower: lower, Upper: keyRange.Upper})
+}
+
+func (d *tableDataDiffer) diffRows(keyRange *srcdb.KeyRange) error {
+	sourceRowHashes, err := d.source.getRowHashes(keyRange)
+	if err != nil {
+		return fmt.Errorf("get row hashes of source range %v: %w", keyRange, err)
+	}
+	targetRowHashes, err := d.target.getRowHashes(keyRange)
+	if err != nil {
+		return fmt.Errorf("get row hashes of target range %v: %w", keyRange, err)
+	}
+	rowKey := func(rowHash *srcdb.RowHash) string { return strings.Join(rowHash.Key, "\x00") }
+	targetRowHashByKey := lo.KeyBy(targetRowHashes, rowKey)
+	sourceRowHashByKey := lo.KeyBy(sourceRowHashes, rowKey)
+
+	for _, sourceRowHash := range sourceRowHashes {
+		targetRowHash, ok := targetRowHashByKey[rowKey(sourceRowHash)]
+		if !ok {
+			err = d.reportRowDiff(ROW_DIFF_TYPE_MISSING, sourceRowHash.Key)
+		} else if targetRowHash.Hash != sourceRowHash.Hash {
+			err = d.reportRowDiff(ROW_DIFF_TYPE_CHANGED, sourceRowHash.Key)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	for _, targetRowHash := range targetRowHashes {
+		if _, ok := sourceRowHashByKey[rowKey(targetRowHash)]; !ok {
+			err = d.reportRowDiff(ROW_DIFF_TYPE_EXTRA, targetRowHash.Key)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (d *tableDataDiffer) reportRowDiff(diffType string, key []string) error {
+	rowDiff := &RowDiff{Type: diffType, Key: make(map[string]string)}
+	for i, col := range d.keyColumns {
+		rowDiff.Key[strings.Trim(col, `"`)] = key[i]
+	}
+	return d.onRowDiff(rowDiff)
+}
+
+//=============================================================
+
+// Computes the data diff of the table and records the mismatched rows in a NDJSON file in the export-dir/reports/data_diff directory.
+func diffTableData(result *TableDataComparison, sourceTable *sqlname.SourceName, targetTable *sqlname.TargetName, columns []string) error {
+	keyColumns, err := getTableKeyColumnsForDiff(targetTable)
+	if err != nil {
+		return err
+	}
+	diffFilePath := filepath.Join(exportDir, "reports", DATA_DIFF_DIR_NAME, fmt.Sprintf("%s.ndjson", targetTable.Qualified.Unquoted))
+	err = os.MkdirAll(filepath.Dir(diffFilePath), 0755)
+	if err != nil {
+		return fmt.Errorf("create directory %q: %w", filepath.Dir(diffFilePath), err)
+	}
+	file, err := os.Create(diffFilePath)
+	if err != nil {
+		return fmt.Errorf("create %q: %w", diffFilePath, err)
+	}
+	defer file.Close()
+	writer := bufio.NewWriter(file)
+	encoder := json.NewEncoder(writer)
+
+	differ := &tableDataDiffer{
+		source:       &sourceTableDiffSide{table: sourceTable, columns: columns, keyColumns: keyColumns},
+		target:       &targetTableDiffSide{table: targetTable, columns: columns, keyColumns: keyColumns},
+		keyColumns:   keyColumns,
+		fanout:       DATA_DIFF_FANOUT,
+		leafRowCount: DATA_DIFF_LEAF_ROW_COUNT,
+		onRowDiff: func(rowDiff *RowDiff) error {
+			switch rowDiff.Type {
+			case ROW_DIFF_TYPE_MISSING:
+				result.NumMissingRows++
+			case ROW_DIFF_TYPE_EXTRA:
+				result.NumExtraRows++
+			case ROW_DIFF_TYPE_CHANGED:
+				result.NumChangedRows++
+			}
+			return encoder.Encode(rowDiff)
+		},
+	}
+	log.Infof("computing data diff of table %q using key columns %v", sourceTable, keyColumns)
+	err = differ.diff()
+	if err != nil {
+		return err
+	}
+	err = writer.Flush()
+	if err != nil {
+		return fmt.Errorf("flush %q: %w", diffFilePath, err)
+	}
+	result.DiffFilePath = diffFilePath
+	return nil
+}
+
+// Returns the quoted primary key columns of the target table. If the table doesn't have a primary key,
+// the columns of a unique constraint are used, provided that they are all NOT NULL, so that every row
+// has a distinct key.
+func getTableKeyColumnsForDiff(targetTable *sqlname.TargetName) ([]string, error) {
+	query := fmt.Sprintf(`SELECT tc.constraint_type, tc.constraint_name, kcu.column_name, c.is_nullable
+		FROM information_schema.table_constraints tc
+		JOIN information_schema.key_column_usage kcu
+			ON tc.constraint_name = kcu.constraint_name
+			AND tc.table_schema = kcu.table_schema
+			AND tc.table_name = kcu.table_name
+		JOIN information_schema.columns c
+			ON c.table_schema = kcu.table_schema
+			AND c.table_name = kcu.table_name
+			AND c.column_name = kcu.column_name
+		WHERE tc.table_schema = '%s' AND tc.table_name = '%s' AND tc.constraint_type IN ('PRIMARY KEY', 'UNIQUE')
+		ORDER BY tc.constraint_name, kcu.ordinal_position`, targetTable.SchemaName.Unquoted, targetTable.ObjectName.Unquoted)
+	rows, err := tdb.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("query key columns of %q: %w", targetTable, err)
+	}
+	defer rows.Close()
+	var keyColumns []*tableKeyColumn
+	for rows.Next() {
+		var keyColumn tableKeyColumn
+		var isNullable string
+		err = rows.Scan(&keyColumn.constraintType, &keyColumn.constraintName, &keyColumn.column, &isNullable)
+		if err != nil {
+			return nil, fmt.Errorf("scan key column of %q: %w", targetTable, err)
+		}
+		keyColumn.nullable = isNullable == "YES"
+		keyColumns = append(keyColumns, &keyColumn)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("iterate over key columns of %q: %w", targetTable, rows.Err())
+	}
+
+	columns := pickKeyColumnsForDiff(keyColumns)
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("table has neither a primary key nor a unique key of NOT NULL columns")
+	}
+	log.Infof("key columns of %q for the data diff: %v", targetTable, columns)
+	return lo.Map(columns, func(column string, _ int) string {
+		return fmt.Sprintf(`"%s"`, column)
+	}), nil
+}
+
+type tableKeyColumn struct {
+	constraintType string
+	constraintName string
+	column         string
+	nullable       bool
+}
+
+// Picks the columns of the primary key, or else of the first unique constraint, in the order of the
+// names of the constraints, whose columns are all NOT NULL. The columns of a constraint are expected in
+// the order of their positions in it.
+func pickKeyColumnsForDiff(keyColumns []*tableKeyColumn) []string {
+	var primaryKeyColumns []string
+	uniqueKeyColumns := make(map[string][]string)
+	nullable := make(map[string]bool)
+	for _, keyColumn := range keyColumns {
+		if keyColumn.constraintType == "PRIMARY KEY" {
+			primaryKeyColumns = append(primaryKeyColumns, keyColumn.column)
+			continue
+		}
+		uniqueKeyColumns[keyColumn.constraintName] = append(uniqueKeyColumns[keyColumn.constraintName], keyColumn.column)
+		nullable[keyColumn.constraintName] = nullable[keyColumn.constraintName] || keyColumn.nullable
+	}
+	if len(primaryKeyColumns) > 0 {
+		return primaryKeyColumns
+	}
+	constraintNames := lo.Keys(uniqueKeyColumns)
+	sort.Strings(constraintNames)
+	for _, constraintName := range constraintNames {
+		if !nullable[constraintName] {
+			return uniqueKeyColumns[constraintName]
+		}
+	}
+	return nil
+}
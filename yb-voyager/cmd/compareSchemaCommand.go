@@ -0,0 +1,362 @@
+/*
+Copyright (c) YugabyteDB, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/gosuri/uitable"
+	"github.com/jackc/pgx/v4"
+	pg_query "github.com/pganalyze/pg_query_go/v5"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"golang.org/x/exp/slices"
+
+	"github.com/yugabyte/yb-voyager/yb-voyager/src/schemadiff"
+	"github.com/yugabyte/yb-voyager/yb-voyager/src/utils"
+)
+
+const SCHEMA_COMPARISON_REPORT_FILE_NAME = "schema_comparison_report"
+
+var compareSchemaOutputFormat string
+
+var compareSchemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Compare the schema exported from the source database with the schema present in the target database.",
+	Long: `Compare the tables, columns, indexes, constraints, sequences and views defined by the DDLs in the export-dir/schema directory with the ones present in the catalog of the target database.
+The objects missing in the target, the extra objects of the target and the objects different in the target (like the type, the default or the nullability of a column) are listed in a report saved in the export-dir/reports directory.
+Run this command after import schema, and after import schema --post-snapshot-import to compare the indexes too.`,
+
+	PreRun: func(cmd *cobra.Command, args []string) {
+		if !schemaIsExported() {
+			utils.ErrExit("Error: schema is not exported yet.")
+		}
+		if tconf.TargetDBType == "" {
+			tconf.TargetDBType = YUGABYTEDB
+		}
+		sourceDBType = GetSourceDBTypeFromMSR()
+		checkOrSetDefaultTargetSSLMode()
+		validateTargetPortRange()
+		validateTargetSchemaFlag()
+		getTargetPassword(cmd)
+		compareSchemaOutputFormat = strings.ToLower(compareSchemaOutputFormat)
+		if compareSchemaOutputFormat != "txt" && compareSchemaOutputFormat != "json" {
+			utils.ErrExit("Error: Invalid output format: %s. Supported formats are [txt json]", compareSchemaOutputFormat)
+		}
+	},
+
+	Run: compareSchemaCommandFn,
+}
+
+type SchemaComparisonReport struct {
+	MigrationUUID string             `json:"migration_uuid"`
+	GeneratedAt   string             `json:"generated_at"`
+	SourceDBType  string             `json:"source_db_type"`
+	Schemas       []string           `json:"schemas"`
+	NumMissing    int                `json:"num_missing"`
+	NumExtra      int                `json:"num_extra"`
+	NumDifferent  int                `json:"num_different"`
+	Diffs         []*schemadiff.Diff `json:"diffs"`
+}
+
+func compareSchemaCommandFn(cmd *cobra.Command, args []string) {
+	err := retrieveMigrationUUID()
+	if err != nil {
+		utils.ErrExit("failed to get migration UUID: %w", err)
+	}
+
+	expected := readExportedSchema()
+	schemas := expected.Schemas()
+	utils.PrintAndLog("comparing the exported schema with the schemas %v of the target database...", schemas)
+
+	conn, err := pgx.Connect(context.Background(), tconf.GetConnectionUri())
+	if err != nil {
+		utils.ErrExit("connect to target db: %s", err)
+	}
+	defer conn.Close(context.Background())
+	actual, err := readTargetSchema(conn, schemas)
+	if err != nil {
+		utils.ErrExit("read the schema of the target db: %s", err)
+	}
+
+	report := &SchemaComparisonReport{
+		MigrationUUID: migrationUUID.String(),
+		GeneratedAt:   time.Now().Format(time.RFC3339),
+		SourceDBType:  sourceDBType,
+		Schemas:       schemas,
+		Diffs:         schemadiff.Compare(expected, actual),
+	}
+	for _, diff := range report.Diffs {
+		switch diff.Status {
+		case schemadiff.MISSING:
+			report.NumMissing++
+		case schemadiff.EXTRA:
+			report.NumExtra++
+		case schemadiff.DIFFERENT:
+			report.NumDifferent++
+		}
+	}
+
+	txtReport := generateSchemaComparisonTxtReport(report)
+	fmt.Print("\n" + txtReport + "\n")
+	finalReport := txtReport
+	if compareSchemaOutputFormat == "json" {
+		jsonBytes, err := json.Marshal(report)
+		if err != nil {
+			panic(err)
+		}
+		finalReport = utils.PrettifyJsonString(string(jsonBytes))
+	}
+	reportPath := filepath.Join(exportDir, "reports", SCHEMA_COMPARISON_REPORT_FILE_NAME+"."+compareSchemaOutputFormat)
+	err = os.WriteFile(reportPath, []byte(finalReport), 0644)
+	if err != nil {
+		utils.ErrExit("failed to write report to %q: %s", reportPath, err)
+	}
+	utils.PrintAndLog("schema comparison report is saved at %q", reportPath)
+	if len(report.Diffs) > 0 {
+		utils.ErrExit("schema comparison found %d missing, %d extra and %d different objects", report.NumMissing, report.NumExtra, report.NumDifferent)
+	}
+}
+
+// readExportedSchema returns the objects defined by the DDLs of the schema files in the export dir.
+func readExportedSchema() *schemadiff.Schema {
+	defaultSchema := tconf.Schema
+	if sourceDBType == POSTGRESQL {
+		// The names are qualified by pg_dump.
+		defaultSchema = YUGABYTEDB_DEFAULT_SCHEMA
+	}
+	stmts, unparsedStmts := readExportedSchemaStmts()
+	for _, stmt := range unparsedStmts {
+		log.Warnf("not comparing the objects of the unparsable statement %s: %v", stmt, stmt.sqlInfo.parseErr)
+		color.Yellow("WARNING: not comparing the objects of the statement %s, which failed to parse: %s\n", stmt, stmt.sqlInfo.parseErr)
+	}
+	schema := schemadiff.NewSchema(defaultSchema)
+	schema.AddDDLs(stmts)
+	return schema
+}
+
+// readExportedSchemaStmts returns the statements of the schema files in the export-dir/schema directory,
+// and the statements which failed to parse.
+func readExportedSchemaStmts() ([]*pg_query.Node, []schemaDDLStmt) {
+	schemaDir := filepath.Join(exportDir, "schema")
+	var filePaths []string
+	var stmts []*pg_query.Node
+	var unparsedStmts []schemaDDLStmt
+	for _, objType := range append(utils.GetSchemaObjectList(sourceDBType), "FTS_INDEX", "PARTITION_INDEX") {
+		filePath := utils.GetObjectFilePath(schemaDir, objType)
+		if slices.Contains(filePaths, filePath) || !utils.FileOrFolderExists(filePath) {
+			continue
+		}
+		filePaths = append(filePaths, filePath)
+		for _, sqlInfo := range createSqlStrInfoArray(filePath, objType) {
+			if sqlInfo.parseErr != nil {
+				unparsedStmts = append(unparsedStmts, schemaDDLStmt{objType: objType, sqlInfo: sqlInfo})
+				continue
+			}
+			for _, rawStmt := range sqlInfo.parseTree {
+				stmts = append(stmts, rawStmt.Stmt)
+			}
+		}
+	}
+	return stmts, unparsedStmts
+}
+
+// readTargetSchema returns the objects of the schemas present in the catalog of the target database.
+func readTargetSchema(conn *pgx.Conn, schemas []string) (*schemadiff.Schema, error) {
+	// With an empty search_path, format_type() and pg_get_expr() qualify all the names not of pg_catalog,
+	// as pg_dump does.
+	_, err := conn.Exec(context.Background(), "SELECT pg_catalog.set_config('search_path', '', false)")
+	if err != nil {
+		return nil, fmt.Errorf("set search_path: %w", err)
+	}
+	schema := schemadiff.NewSchema("")
+
+	// The sequences of the identity columns and the objects of the extensions are not in the DDLs.
+	query := `SELECT n.nspname, c.relname, c.relkind, a.attname, pg_catalog.format_type(a.atttypid, a.atttypmod),
+		pg_catalog.pg_get_expr(d.adbin, d.adrelid), a.attnotnull
+	FROM pg_catalog.pg_class c
+		JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+		LEFT JOIN pg_catalog.pg_attribute a ON a.attrelid = c.oid AND a.attnum > 0 AND NOT a.attisdropped AND c.relkind IN ('r', 'p')
+		LEFT JOIN pg_catalog.pg_attrdef d ON d.adrelid = c.oid AND d.adnum = a.attnum
+	WHERE n.nspname::text = ANY($1) AND c.relkind IN ('r', 'p', 'v', 'm', 'S')
+		AND NOT EXISTS (SELECT 1 FROM pg_catalog.pg_depend dep WHERE dep.classid = 'pg_catalog.pg_class'::regclass
+			AND dep.objid = c.oid AND dep.deptype IN ('i', 'e'))
+	ORDER BY n.nspname, c.relname, a.attnum`
+	rows, err := conn.Query(context.Background(), query, schemas)
+	if err != nil {
+		return nil, fmt.Errorf("query tables: %w", err)
+	}
+	for rows.Next() {
+		var schemaName, relName, relKind string
+		var columnName, columnType, columnDefault *string
+		var notNull *bool
+		err = rows.Scan(&schemaName, &relName, &relKind, &columnName, &columnType, &columnDefault, &notNull)
+		if err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan table: %w", err)
+		}
+		name := schemadiff.QualifiedName(schemaName, relName)
+		switch relKind {
+		case "S":
+			schema.Sequences[name] = true
+		case "v":
+			schema.Views[name] = "VIEW"
+		case "m":
+			schema.Views[name] = "MATERIALIZED VIEW"
+		default:
+			table := schema.Tables[name]
+			if table == nil {
+				table = schema.AddTable(name)
+			}
+			if columnName == nil {
+				continue
+			}
+			column := &schemadiff.Column{Name: *columnName, Type: *columnType, NotNull: *notNull}
+			if columnDefault != nil {
+				column.Default, err = schemadiff.NormalizeExprText(*columnDefault)
+				if err != nil {
+					log.Warnf("normalize default %q of %s.%s: %v", *columnDefault, name, *columnName, err)
+					column.Default = *columnDefault
+				}
+			}
+			table.Columns = append(table.Columns, column)
+		}
+	}
+	rows.Close()
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("query tables: %w", rows.Err())
+	}
+
+	// The indexes of the primary key and unique constraints are compared as the constraints.
+	query = `SELECT n.nspname, ic.relname, tc.relname, i.indisunique,
+		ARRAY(SELECT pg_catalog.pg_get_indexdef(i.indexrelid, k, true) FROM pg_catalog.generate_series(1, i.indnatts) AS k ORDER BY k),
+		EXISTS (SELECT 1 FROM pg_catalog.pg_inherits inh WHERE inh.inhrelid = i.indexrelid)
+	FROM pg_catalog.pg_index i
+		JOIN pg_catalog.pg_class ic ON ic.oid = i.indexrelid
+		JOIN pg_catalog.pg_class tc ON tc.oid = i.indrelid
+		JOIN pg_catalog.pg_namespace n ON n.oid = ic.relnamespace
+	WHERE n.nspname::text = ANY($1)
+		AND NOT EXISTS (SELECT 1 FROM pg_catalog.pg_constraint con WHERE con.conindid = i.indexrelid AND con.contype IN ('p', 'u', 'x'))`
+	rows, err = conn.Query(context.Background(), query, schemas)
+	if err != nil {
+		return nil, fmt.Errorf("query indexes: %w", err)
+	}
+	for rows.Next() {
+		var schemaName, indexName, tableName string
+		index := &schemadiff.Index{}
+		var columns []string
+		err = rows.Scan(&schemaName, &indexName, &tableName, &index.Unique, &columns, &index.Inherited)
+		if err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan index: %w", err)
+		}
+		index.Name = schemadiff.QualifiedName(schemaName, indexName)
+		index.Table = schemadiff.QualifiedName(schemaName, tableName)
+		for _, column := range columns {
+			normalized, err := schemadiff.NormalizeExprText(column)
+			if err != nil {
+				log.Warnf("normalize column %q of index %s: %v", column, index.Name, err)
+				normalized = column
+			}
+			index.Columns = append(index.Columns, normalized)
+		}
+		schema.Indexes[index.Name] = index
+	}
+	rows.Close()
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("query indexes: %w", rows.Err())
+	}
+
+	query = `SELECT n.nspname, t.relname, con.conname, con.contype::text,
+		ARRAY(SELECT a.attname::text FROM pg_catalog.unnest(con.conkey) WITH ORDINALITY AS k(attnum, ord)
+			JOIN pg_catalog.pg_attribute a ON a.attrelid = con.conrelid AND a.attnum = k.attnum ORDER BY k.ord),
+		COALESCE(rn.nspname, ''), COALESCE(rt.relname, ''), NOT con.conislocal OR con.conparentid <> 0
+	FROM pg_catalog.pg_constraint con
+		JOIN pg_catalog.pg_class t ON t.oid = con.conrelid
+		JOIN pg_catalog.pg_namespace n ON n.oid = t.relnamespace
+		LEFT JOIN pg_catalog.pg_class rt ON rt.oid = con.confrelid
+		LEFT JOIN pg_catalog.pg_namespace rn ON rn.oid = rt.relnamespace
+	WHERE n.nspname::text = ANY($1) AND con.contype IN ('p', 'u', 'f', 'c')`
+	rows, err = conn.Query(context.Background(), query, schemas)
+	if err != nil {
+		return nil, fmt.Errorf("query constraints: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var schemaName, tableName, conType, refSchemaName, refTableName string
+		constraint := &schemadiff.Constraint{}
+		err = rows.Scan(&schemaName, &tableName, &constraint.Name, &conType, &constraint.Columns, &refSchemaName, &refTableName, &constraint.Inherited)
+		if err != nil {
+			return nil, fmt.Errorf("scan constraint: %w", err)
+		}
+		constraint.Table = schemadiff.QualifiedName(schemaName, tableName)
+		constraint.Type = map[string]string{
+			"p": schemadiff.PRIMARY_KEY,
+			"u": schemadiff.UNIQUE,
+			"f": schemadiff.FOREIGN_KEY,
+			"c": schemadiff.CHECK,
+		}[conType]
+		if constraint.Type == schemadiff.CHECK {
+			constraint.Columns = nil
+		}
+		if refTableName != "" {
+			constraint.RefTable = schemadiff.QualifiedName(refSchemaName, refTableName)
+		}
+		schema.AddConstraint(constraint)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("query constraints: %w", rows.Err())
+	}
+	return schema, nil
+}
+
+func generateSchemaComparisonTxtReport(report *SchemaComparisonReport) string {
+	var sb strings.Builder
+	sb.WriteString("Schema Comparison Report\n")
+	sb.WriteString(fmt.Sprintf("Migration UUID: %s\n", report.MigrationUUID))
+	sb.WriteString(fmt.Sprintf("Generated at: %s\n", report.GeneratedAt))
+	sb.WriteString(fmt.Sprintf("Schemas: %s\n", strings.Join(report.Schemas, ", ")))
+	sb.WriteString(fmt.Sprintf("Missing objects: %d, Extra objects: %d, Different objects: %d\n\n",
+		report.NumMissing, report.NumExtra, report.NumDifferent))
+	if len(report.Diffs) == 0 {
+		sb.WriteString("The schema of the target database matches the exported schema.\n")
+		return sb.String()
+	}
+	uitbl := uitable.New()
+	uitbl.MaxColWidth = 50
+	uitbl.AddRow("OBJECT TYPE", "OBJECT NAME", "STATUS", "ATTRIBUTE", "EXPECTED", "ACTUAL")
+	for _, diff := range report.Diffs {
+		uitbl.AddRow(diff.ObjectType, diff.ObjectName, diff.Status, diff.Attribute, diff.Expected, diff.Actual)
+	}
+	sb.WriteString(uitbl.String())
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+func init() {
+	compareCmd.AddCommand(compareSchemaCmd)
+	registerCommonGlobalFlags(compareSchemaCmd)
+	registerTargetDBConnFlags(compareSchemaCmd)
+	compareSchemaCmd.Flags().StringVar(&compareSchemaOutputFormat, "output-format", "txt",
+		"format in which the report will be saved: (txt, json)")
+}
@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yugabyte/yb-voyager/yb-voyager/src/srcdb"
+)
+
+// fakeTableDiffSide holds the rows of a table with a single (zero padded) text key column.
+type fakeTableDiffSide struct {
+	rows map[string]string
+}
+
+func (f *fakeTableDiffSide) keysInRange(keyRange *srcdb.KeyRange) []string {
+	var keys []string
+	for key := range f.rows {
+		if keyRange.Lower != nil && key <= keyRange.Lower[0] {
+			continue
+		}
+		if keyRange.Upper != nil && key > keyRange.Upper[0] {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (f *fakeTableDiffSide) getChecksum(keyRange *srcdb.KeyRange) (int64, string, error) {
+	keys := f.keysInRange(keyRange)
+	var values []string
+	for _, key := range keys {
+		values = append(values, key+"="+f.rows[key])
+	}
+	return int64(len(keys)), strings.Join(values, ","), nil
+}
+
+func (f *fakeTableDiffSide) getKeyBoundaries(keyRange *srcdb.KeyRange, step int64) ([][]string, error) {
+	var boundaries [][]string
+	for i, key := range f.keysInRange(keyRange) {
+		if int64(i+1)%step == 0 {
+			boundaries = append(boundaries, []string{key})
+		}
+	}
+	return boundaries, nil
+}
+
+func (f *fakeTableDiffSide) getRowHashes(keyRange *srcdb.KeyRange) ([]*srcdb.RowHash, error) {
+	var rowHashes []*srcdb.RowHash
+	for _, key := range f.keysInRange(keyRange) {
+		rowHashes = append(rowHashes, &srcdb.RowHash{Key: []string{key}, Hash: f.rows[key]})
+	}
+	return rowHashes, nil
+}
+
+func TestTableDataDiffer(t *testing.T) {
+	assert := assert.New(t)
+	sourceRows := make(map[string]string)
+	targetRows := make(map[string]string)
+	for i := 0; i < 10000; i++ {
+		key := fmt.Sprintf("%06d", i)
+		sourceRows[key] = fmt.Sprintf("value-%d", i)
+		targetRows[key] = fmt.Sprintf("value-%d", i)
+	}
+	delete(targetRows, "000017")
+	delete(targetRows, "009999")
+	targetRows["000500"] = "changed"
+	targetRows["020000"] = "extra"
+
+	var rowDiffs []string
+	differ := &tableDataDiffer{
+		source:       &fakeTableDiffSide{rows: sourceRows},
+		target:       &fakeTableDiffSide{rows: targetRows},
+		keyColumns:   []string{`"id"`},
+		fanout:       4,
+		leafRowCount: 100,
+		onRowDiff: func(rowDiff *RowDiff) error {
+			rowDiffs = append(rowDiffs, fmt.Sprintf("%s:%s", rowDiff.Type, rowDiff.Key["id"]))
+			return nil
+		},
+	}
+	assert.NoError(differ.diff())
+	assert.ElementsMatch([]string{
+		"missing:000017",
+		"missing:009999",
+		"changed:000500",
+		"extra:020000",
+	}, rowDiffs)
+}
+
+func TestTableDataDifferNoDiff(t *testing.T) {
+	assert := assert.New(t)
+	rows := map[string]string{"1": "a", "2": "b", "3": "c"}
+	differ := &tableDataDiffer{
+		source:       &fakeTableDiffSide{rows: rows},
+		target:       &fakeTableDiffSide{rows: rows},
+		keyColumns:   []string{`"id"`},
+		fanout:       2,
+		leafRowCount: 1,
+		onRowDiff: func(rowDiff *RowDiff) error {
+			assert.Fail("unexpected row diff", "%v", rowDiff)
+			return nil
+		},
+	}
+	assert.NoError(differ.diff())
+}
+
+func TestKeyRangePGPredicate(t *testing.T) {
+	assert := assert.New(t)
+	keyColumns := []string{`"a"`, `"b"`}
+	assert.Equal(`"a" IS NOT NULL AND "b" IS NOT NULL`, (&srcdb.KeyRange{}).PGPredicate(keyColumns))
+	keyRange := &srcdb.KeyRange{Lower: []string{"1", "it's"}, Upper: []string{"2", "x"}}
+	assert.Equal(`"a" IS NOT NULL AND "b" IS NOT NULL AND ("a", "b") > ('1', 'it''s') AND ("a", "b") <= ('2', 'x')`,
+		keyRange.PGPredicate(keyColumns))
+}
+
+func TestPickKeyColumnsForDiff(t *testing.T) {
+	assert := assert.New(t)
+	pk := func(column string) *tableKeyColumn {
+		return &tableKeyColumn{constraintType: "PRIMARY KEY", constraintName: "t_pkey", column: column}
+	}
+	uk := func(constraintName string, column string, nullable bool) *tableKeyColumn {
+		return &tableKeyColumn{constraintType: "UNIQUE", constraintName: constraintName, column: column, nullable: nullable}
+	}
+	assert.Equal([]string{"id", "region"}, pickKeyColumnsForDiff([]*tableKeyColumn{
+		uk("t_a_key", "a", false), pk("id"), pk("region"),
+	}))
+	// The first unique constraint by name with all the columns NOT NULL, instead of all the unique columns merged.
+	assert.Equal([]string{"c", "d"}, pickKeyColumnsForDiff([]*tableKeyColumn{
+		uk("t_e_key", "e", false),
+		uk("t_a_b_key", "a", false), uk("t_a_b_key", "b", true),
+		uk("t_c_d_key", "c", false), uk("t_c_d_key", "d", false),
+	}))
+	assert.Empty(pickKeyColumnsForDiff([]*tableKeyColumn{uk("t_a_key", "a", true)}))
+	assert.Empty(pickKeyColumnsForDiff(nil))
+}
+
+func TestDisplayDataComparisonReport(t *testing.T) {
+	assert := assert.New(t)
+	report := &DataComparisonReport{Tables: []*TableDataComparison{
+		{SourceTableName: "a", Status: COMPARISON_STATUS_PASS},
+		{SourceTableName: "b", Status: COMPARISON_STATUS_NOT_SUPPORTED},
+		{SourceTableName: "c", Status: COMPARISON_STATUS_FAIL},
+	}}
+	// The tables not supported for checksum comparison are neither passed nor failed.
+	assert.Equal(map[string]int{COMPARISON_STATUS_PASS: 1, COMPARISON_STATUS_NOT_SUPPORTED: 1, COMPARISON_STATUS_FAIL: 1},
+		countTablesByStatus(report))
+	assert.Equal(1, displayDataComparisonReport(report))
+}
@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadExportedSchema(t *testing.T) {
+	assert := assert.New(t)
+	exportDir = t.TempDir()
+	sourceDBType = POSTGRESQL
+	schemaDir := filepath.Join(exportDir, "schema")
+	writeSchemaFile(t, schemaDir, "SEQUENCE", `CREATE SEQUENCE public.orders_id_seq;
+
+ALTER TABLE ONLY public.orders ALTER COLUMN id SET DEFAULT nextval('public.orders_id_seq'::regclass);
+`)
+	writeSchemaFile(t, schemaDir, "TABLE", `CREATE TABLE public.orders (
+    id integer NOT NULL,
+    amount numeric(10,2)
+);
+
+ALTER TABLE ONLY public.orders
+    ADD CONSTRAINT orders_pkey PRIMARY KEY (id);
+`)
+	writeSchemaFile(t, schemaDir, "INDEX", `CREATE INDEX orders_amount_idx ON public.orders USING btree (amount);
+`)
+	writeSchemaFile(t, schemaDir, "PARTITION_INDEX", `CREATE INDEX events_2024_ts_idx ON public.events_2024 USING btree (ts);
+`)
+
+	schema := readExportedSchema()
+	assert.Equal([]string{"public"}, schema.Schemas())
+	assert.True(schema.Sequences["public.orders_id_seq"])
+	orders := schema.Tables["public.orders"]
+	assert.NotNil(orders)
+	assert.Equal("nextval('public.orders_id_seq')", orders.Column("id").Default)
+	assert.NotNil(schema.Constraints["public.orders.orders_pkey"])
+	assert.Len(schema.Indexes, 2)
+}
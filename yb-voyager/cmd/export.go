@@ -35,6 +35,7 @@ var source srcdb.Source
 var disablePb utils.BoolStr
 var exportType string
 var useDebezium bool
+var useNativePGCDC bool
 var runId string
 var excludeTableListFilePath string
 var tableListFilePath string
@@ -42,7 +43,7 @@ var tableListFilePath string
 var exportCmd = &cobra.Command{
 	Use:   "export",
 	Short: "export schema and data from compatible databases",
-	Long: `Export has various sub-commands i.e. export schema, export data to export from various compatible source databases(Oracle, MySQL, PostgreSQL) 
+	Long: `Export has various sub-commands i.e. export schema, export data to export from various compatible source databases(Oracle, MySQL, PostgreSQL, SQLite) 
 	and export data from target in case of live migration with fall-back/fall-forward workflows.`,
 }
 
@@ -57,7 +58,7 @@ func registerCommonExportFlags(cmd *cobra.Command) {
 
 func registerSourceDBConnFlags(cmd *cobra.Command, includeOracleCDBFlags bool) {
 	cmd.Flags().StringVar(&source.DBType, "source-db-type", "",
-		"source database type: (oracle, mysql, postgresql)\n")
+		"source database type: (oracle, mysql, postgresql, sqlite)\n")
 
 	cmd.Flags().StringVar(&source.Host, "source-db-host", "localhost",
 		"source database server host")
@@ -75,6 +76,9 @@ func registerSourceDBConnFlags(cmd *cobra.Command, includeOracleCDBFlags bool) {
 	cmd.Flags().StringVar(&source.DBName, "source-db-name", "",
 		"source database name to be migrated to YugabyteDB")
 
+	cmd.Flags().StringVar(&source.DBFilePath, "source-db-path", "",
+		"[For SQLite Only] path of the SQLite database file to be migrated to YugabyteDB")
+
 	cmd.Flags().StringVar(&source.DBSid, "oracle-db-sid", "",
 		"[For Oracle Only] Oracle System Identifier (SID) that you wish to use while exporting data from Oracle instances")
 
@@ -133,6 +137,14 @@ func setExportFlagsDefaults() {
 	if ok {
 		useDebezium = (val == "true" || val == "1" || val == "yes")
 	}
+	val, ok = os.LookupEnv("BETA_NATIVE_PG_CDC")
+	if ok {
+		useNativePGCDC = (val == "true" || val == "1" || val == "yes")
+	}
+	val, ok = os.LookupEnv("BETA_NATIVE_PG_EXPORT")
+	if ok && source.DBType == POSTGRESQL {
+		source.UseNativeExport = utils.BoolStr(val == "true" || val == "1" || val == "yes")
+	}
 }
 
 func setSourceDefaultPort() {
@@ -188,7 +200,9 @@ func validateExportFlags(cmd *cobra.Command, exporterRole string) error {
 
 	switch exporterRole {
 	case SOURCE_DB_EXPORTER_ROLE:
-		getAndStoreSourceDBPasswordInSourceConf(cmd)
+		if source.DBType != SQLITE { // sqlite database file is read without any credentials
+			getAndStoreSourceDBPasswordInSourceConf(cmd)
+		}
 	case TARGET_DB_EXPORTER_FF_ROLE, TARGET_DB_EXPORTER_FB_ROLE:
 		getAndStoreTargetDBPasswordInSourceConf(cmd)
 	}
@@ -205,6 +219,9 @@ func validateExportFlags(cmd *cobra.Command, exporterRole string) error {
 			return fmt.Errorf("--oracle-tns-alias flag is only valid for 'oracle' db type")
 		}
 	}
+	if source.DBType != SQLITE && source.DBFilePath != "" {
+		return fmt.Errorf("--source-db-path flag is only valid for 'sqlite' db type")
+	}
 	return nil
 }
 
@@ -243,7 +260,7 @@ func validateSourceDBType() {
 
 	source.DBType = strings.ToLower(source.DBType)
 	if !slices.Contains(supportedSourceDBTypes, source.DBType) {
-		utils.ErrExit("Error: Invalid source-db-type: %q. Supported source db types are: (postgresql, oracle, mysql)", source.DBType)
+		utils.ErrExit("Error: Invalid source-db-type: %q. Supported source db types are: (postgresql, oracle, mysql, sqlite)", source.DBType)
 	}
 }
 
@@ -265,6 +282,8 @@ func validateSourceSchema() {
 	switch source.DBType {
 	case MYSQL:
 		utils.ErrExit("Error: --source-db-schema flag is not valid for 'MySQL' db type")
+	case SQLITE:
+		utils.ErrExit("Error: --source-db-schema flag is not valid for 'SQLite' db type")
 	case ORACLE:
 		if len(schemaList) > 1 {
 			utils.ErrExit("Error: single schema at a time is allowed to export from oracle. List of schemas provided: %s", schemaList)
@@ -282,7 +301,7 @@ func validatePortRange() {
 }
 
 func validateSSLMode() {
-	if source.DBType == ORACLE || slices.Contains(validSSLModes[source.DBType], source.SSLMode) {
+	if source.DBType == ORACLE || source.DBType == SQLITE || slices.Contains(validSSLModes[source.DBType], source.SSLMode) {
 		return
 	} else {
 		utils.ErrExit("Error: Invalid sslmode: %q. Valid SSL modes are %v", validSSLModes[source.DBType])
@@ -350,14 +369,19 @@ func getAndStoreTargetDBPasswordInSourceConf(cmd *cobra.Command) {
 func markFlagsRequired(cmd *cobra.Command) {
 	// mandatory for all
 	cmd.MarkFlagRequired("source-db-type")
-	cmd.MarkFlagRequired("source-db-user")
 
 	switch source.DBType {
 	case POSTGRESQL, ORACLE: // schema and database names are mandatory
+		cmd.MarkFlagRequired("source-db-user")
 		cmd.MarkFlagRequired("source-db-name")
 		cmd.MarkFlagRequired("source-db-schema")
 	case MYSQL:
+		cmd.MarkFlagRequired("source-db-user")
 		cmd.MarkFlagRequired("source-db-name")
+	case SQLITE: // only the path of the database file is needed
+		cmd.MarkFlagRequired("source-db-path")
+	default:
+		cmd.MarkFlagRequired("source-db-user")
 	}
 }
 
@@ -92,6 +92,12 @@ func init() {
 	registerSourceDBConnFlags(exportDataFromSrcCmd, true)
 	registerExportDataFlags(exportDataCmd)
 	registerExportDataFlags(exportDataFromSrcCmd)
+	registerExportDataFormatFlag(exportDataCmd)
+	registerExportDataFormatFlag(exportDataFromSrcCmd)
+	registerExportDataCompressionFlag(exportDataCmd)
+	registerExportDataCompressionFlag(exportDataFromSrcCmd)
+	registerExportDataDirFlag(exportDataCmd)
+	registerExportDataDirFlag(exportDataFromSrcCmd)
 }
 
 func exportDataCommandPreRun(cmd *cobra.Command, args []string) {
@@ -102,9 +108,19 @@ func exportDataCommandPreRun(cmd *cobra.Command, args []string) {
 	}
 	validateExportTypeFlag()
 	markFlagsRequired(cmd)
+	if source.DBType == SQLITE {
+		if changeStreamingIsEnabled(exportType) {
+			utils.ErrExit("Error: --export-type %s is not supported for 'sqlite' db type", exportType)
+		}
+		// The data of a sqlite database file is always exported natively.
+		useDebezium = false
+	}
 	if changeStreamingIsEnabled(exportType) {
 		useDebezium = true
 	}
+	validateExportDataFormatFlag()
+	validateExportDataCompressionFlag()
+	validateExportDataDirFlag()
 }
 
 func exportDataCommandFn(cmd *cobra.Command, args []string) {
@@ -188,11 +204,13 @@ func exportData() bool {
 			log.Errorf("Failed to prepare dbzm config: %v", err)
 			return false
 		}
+		var sequenceValueMap map[*sqlname.SourceName]int64
 		if source.DBType == POSTGRESQL && changeStreamingIsEnabled(exportType) {
 			// pg live migration. Steps are as follows:
 			// 1. create publication, replication slot.
 			// 2. export snapshot corresponding to replication slot by passing it to pg_dump
 			// 3. start debezium with configration to read changes from the created replication slot, publication.
+			//    (or stream the changes natively if BETA_NATIVE_PG_CDC is set)
 
 			err := source.DB().ValidateTablesReadyForLiveMigration(finalTableList)
 			if err != nil {
@@ -212,7 +230,7 @@ func exportData() bool {
 			}
 
 			// Setting up sequence values for debezium to start tracking from..
-			sequenceValueMap, err := getPGDumpSequencesAndValues()
+			sequenceValueMap, err = getPGDumpSequencesAndValues()
 			if err != nil {
 				utils.ErrExit("get pg dump sequence values: %v", err)
 			}
@@ -227,11 +245,35 @@ func exportData() bool {
 			config.PublicationName = msr.PGPublicationName
 			config.InitSequenceMaxMapping = sequenceInitValues.String()
 		}
+		if source.DBType == MYSQL {
+			// mysql live migration: debezium exports the snapshot and then streams the changes from the binlog.
+			if changeStreamingIsEnabled(exportType) {
+				err := source.DB().ValidateTablesReadyForLiveMigration(finalTableList)
+				if err != nil {
+					utils.ErrExit("error: validate if tables are ready for live migration: %v", err)
+				}
+			}
+			// The sequences of the auto increment columns start from the AUTO_INCREMENT values, which are restored
+			// on the target after the snapshot import or at the cutover.
+			autoIncrementValues, err := source.DB().GetAutoIncrementLastValues(finalTableList)
+			if err != nil {
+				utils.ErrExit("get auto increment values: %v", err)
+			}
+			config.InitSequenceMaxMapping = getSequenceMaxMapping(autoIncrementValues)
+		}
 		saveTableToUniqueKeyColumnsMapInMetaDB(finalTableList)
-		err = debeziumExportData(ctx, config, tableNametoApproxRowCountMap)
-		if err != nil {
-			log.Errorf("Export Data using debezium failed: %v", err)
-			return false
+		if source.DBType == POSTGRESQL && changeStreamingIsEnabled(exportType) && useNativePGCDC {
+			err = exportPGChangesWithLogicalReplication(ctx, finalTableList, sequenceValueMap)
+			if err != nil {
+				log.Errorf("Export of changes using logical replication failed: %v", err)
+				return false
+			}
+		} else {
+			err = debeziumExportData(ctx, config, tableNametoApproxRowCountMap)
+			if err != nil {
+				log.Errorf("Export Data using debezium failed: %v", err)
+				return false
+			}
 		}
 
 		if changeStreamingIsEnabled(exportType) {
@@ -558,8 +600,13 @@ func exportDataOffline(ctx context.Context, cancel context.CancelFunc, finalTabl
 		switch source.DBType {
 		case POSTGRESQL:
 			record.SnapshotMechanism = "pg_dump"
+			if source.UseNativeExport {
+				record.SnapshotMechanism = "native"
+			}
 		case ORACLE, MYSQL:
 			record.SnapshotMechanism = "ora2pg"
+		case SQLITE:
+			record.SnapshotMechanism = "sqlite"
 		}
 	})
 	if err != nil {
@@ -577,6 +624,10 @@ func exportDataOffline(ctx context.Context, cancel context.CancelFunc, finalTabl
 			finalTableList = append(finalTableList, name)
 		}
 	}
+	if exporterWritesDataStoreDirectly() {
+		source.ExportDataDir = exportDataDestination
+		source.ExportDataCompression = exportDataCompression
+	}
 	fmt.Printf("Initiating data export.\n")
 	utils.WaitGroup.Add(1)
 	go source.DB().ExportData(ctx, exportDir, finalTableList, quitChan, exportDataStart, exportSuccessChan, tablesColumnList, snapshotName)
@@ -600,6 +651,12 @@ func exportDataOffline(ctx context.Context, cancel context.CancelFunc, finalTabl
 	}
 
 	source.DB().ExportDataPostProcessing(exportDir, tablesProgressMetadata)
+	if exportDataFilesPostProcessingRequired() {
+		err = postProcessExportedDataFiles()
+		if err != nil {
+			return fmt.Errorf("post-process exported data files: %w", err)
+		}
+	}
 	displayExportedRowCountSnapshot(false)
 
 	if exporterRole == SOURCE_DB_EXPORTER_ROLE {
@@ -695,6 +752,8 @@ func getDefaultSourceSchemaName() (string, bool) {
 		return GetDefaultPGSchema(source.Schema, "|")
 	case ORACLE:
 		return source.Schema, false
+	case SQLITE:
+		return srcdb.SQLITE_SCHEMA_NAME, false
 	default:
 		panic("invalid db type")
 	}
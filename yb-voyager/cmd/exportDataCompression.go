@@ -0,0 +1,69 @@
+/*
+Copyright (c) YugabyteDB, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/yugabyte/yb-voyager/yb-voyager/src/datafile"
+	"github.com/yugabyte/yb-voyager/yb-voyager/src/datastore"
+	"github.com/yugabyte/yb-voyager/yb-voyager/src/utils"
+)
+
+var exportDataCompression string
+
+func registerExportDataCompressionFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&exportDataCompression, "data-compression", "",
+		fmt.Sprintf("compress the exported data files using the given algorithm: (%s, %s).\n"+
+			"Only supported for --export-type %s without BETA_FAST_DATA_EXPORT.", datastore.GZIP, datastore.ZSTD, SNAPSHOT_ONLY))
+}
+
+func validateExportDataCompressionFlag() {
+	switch exportDataCompression {
+	case "":
+	case datastore.GZIP, datastore.ZSTD:
+		if changeStreamingIsEnabled(exportType) || useDebezium {
+			utils.ErrExit("Error: --data-compression is supported only with --export-type %s and without BETA_FAST_DATA_EXPORT", SNAPSHOT_ONLY)
+		}
+		if exportDataFormat == datafile.PARQUET {
+			utils.ErrExit("Error: --data-compression is not supported with --data-format %s, as parquet files are already compressed", datafile.PARQUET)
+		}
+	default:
+		utils.ErrExit("Error: Invalid data-compression: %q. Supported values are: (%s, %s)", exportDataCompression, datastore.GZIP, datastore.ZSTD)
+	}
+}
+
+// writeCompressedDataFile writes the compressed contents of the exported data file to `w`.
+func writeCompressedDataFile(filePath string, w io.Writer) error {
+	src, err := os.Open(filePath)
+	if err != nil {
+		return fmt.Errorf("open %q: %w", filePath, err)
+	}
+	defer src.Close()
+	cw, err := datastore.NewCompressedWriter(w, exportDataCompression)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(cw, src)
+	if err != nil {
+		return fmt.Errorf("compress %q: %w", filePath, err)
+	}
+	return cw.Close()
+}
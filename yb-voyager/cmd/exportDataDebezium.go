@@ -91,9 +91,7 @@ func prepareDebeziumConfig(partitionsToRootTableMap map[string]string, tableList
 	}
 
 	colToSeqMap := source.DB().GetColumnToSequenceMap(tableList)
-	columnSequenceMapping := strings.Join(lo.MapToSlice(colToSeqMap, func(k, v string) string {
-		return fmt.Sprintf("%s:%s", k, v)
-	}), ",")
+	columnSequenceMapping := getColumnSequenceMapping(colToSeqMap)
 
 	err = prepareSSLParamsForDebezium(absExportDir)
 	if err != nil {
@@ -194,6 +192,21 @@ func prepareDebeziumConfig(partitionsToRootTableMap map[string]string, tableList
 	return config, tableNameToApproxRowCountMap, nil
 }
 
+// getColumnSequenceMapping gives the column_sequence.map of debezium, from <schema>.<table>.<column> to the sequence.
+func getColumnSequenceMapping(colToSeqMap map[string]string) string {
+	return strings.Join(lo.MapToSlice(colToSeqMap, func(k, v string) string {
+		return fmt.Sprintf("%s:%s", k, v)
+	}), ",")
+}
+
+// getSequenceMaxMapping gives the sequence.max.map of debezium, from the sequences of column_sequence.map to
+// the values debezium starts tracking them from.
+func getSequenceMaxMapping(sequenceValues map[string]int64) string {
+	return strings.Join(lo.MapToSlice(sequenceValues, func(k string, v int64) string {
+		return fmt.Sprintf("%s:%d", k, v)
+	}), ",")
+}
+
 func prepareSSLParamsForDebezium(exportDir string) error {
 	switch source.DBType {
 	case "postgresql", "yugabytedb": //TODO test for yugabytedb
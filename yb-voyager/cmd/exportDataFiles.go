@@ -0,0 +1,197 @@
+/*
+Copyright (c) YugabyteDB, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/yugabyte/yb-voyager/yb-voyager/src/datafile"
+	"github.com/yugabyte/yb-voyager/yb-voyager/src/datastore"
+	"github.com/yugabyte/yb-voyager/yb-voyager/src/utils"
+	"github.com/yugabyte/yb-voyager/yb-voyager/src/utils/az"
+	"github.com/yugabyte/yb-voyager/yb-voyager/src/utils/gcs"
+	"github.com/yugabyte/yb-voyager/yb-voyager/src/utils/s3"
+)
+
+// Object store location to which the exported data files are written.
+var exportDataDestination string
+
+func registerExportDataDirFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&exportDataDestination, "data-dir", "",
+		"object store location (s3://, gs:// or https:// for Azure) to write the exported data files to, instead of the export directory.\n"+
+			"S3-compatible stores can be configured as in import data file --data-dir, e.g. s3://<bucket-name>/<path>?endpoint=http://<host>:<port>&use_path_style=true\n"+
+			"The native PostgreSQL export (BETA_NATIVE_PG_EXPORT) writes the data files to the object store directly. With pg_dump and ora2pg, "+
+			"and with --data-format parquet, the data files are first written to the export directory, and need the local disk space to stage the whole table data; "+
+			"they are removed from it once uploaded.\n"+
+			fmt.Sprintf("Only supported for --export-type %s without BETA_FAST_DATA_EXPORT.", SNAPSHOT_ONLY))
+}
+
+func validateExportDataDirFlag() {
+	if exportDataDestination == "" {
+		return
+	}
+	if !datastore.IsRemoteLocation(exportDataDestination) {
+		utils.ErrExit("Error: --data-dir must be an s3://, gs:// or https:// location: %q", exportDataDestination)
+	}
+	if changeStreamingIsEnabled(exportType) || useDebezium {
+		utils.ErrExit("Error: --data-dir is supported only with --export-type %s and without BETA_FAST_DATA_EXPORT", SNAPSHOT_ONLY)
+	}
+	err := validateObjectStoreURL(exportDataDestination)
+	if err != nil {
+		utils.ErrExit("Error: invalid --data-dir %q: %v", exportDataDestination, err)
+	}
+	exportDataDestination = strings.TrimSuffix(exportDataDestination, "/")
+}
+
+func validateObjectStoreURL(location string) error {
+	switch true {
+	case strings.HasPrefix(location, "s3://"):
+		return s3.ValidateObjectURL(location)
+	case strings.HasPrefix(location, "gs://"):
+		return gcs.ValidateObjectURL(location)
+	case strings.HasPrefix(location, "https://"):
+		return az.ValidateObjectURL(location)
+	}
+	return fmt.Errorf("%q is not an object store location", location)
+}
+
+func exportDataFilesPostProcessingRequired() bool {
+	if exporterWritesDataStoreDirectly() {
+		return false
+	}
+	return exportDataFormat != "" || exportDataCompression != "" || exportDataDestination != ""
+}
+
+// The native PG export writes the compressed data files straight to the --data-dir. The files written by
+// pg_dump and ora2pg, and the conversion to parquet, need to go through the local disk.
+func exporterWritesDataStoreDirectly() bool {
+	return source.DBType == POSTGRESQL && bool(source.UseNativeExport) && exportDataFormat == ""
+}
+
+// postProcessExportedDataFiles rewrites the data files listed in the data file descriptor as required
+// by the --data-format, --data-compression and --data-dir flags, and updates the descriptor to point to them.
+// The local files are first written under a temporary name and renamed once complete, so that a partially
+// written file is never mistaken for an exported one.
+func postProcessExportedDataFiles() error {
+	dfd := datafile.OpenDescriptor(exportDir)
+	dataDir := filepath.Join(exportDir, "data")
+	if exportDataDestination != "" {
+		dataDir = exportDataDestination
+		utils.PrintAndLog("writing exported data files to %s...", dataDir)
+	}
+	ds := datastore.NewDataStore(dataDir)
+	for _, fileEntry := range dfd.DataFileList {
+		fileName := filepath.Base(fileEntry.FilePath)
+		var writeFn func(w io.Writer) error
+		switch true {
+		case exportDataFormat == datafile.PARQUET:
+			fileName = strings.TrimSuffix(fileName, filepath.Ext(fileName)) + ".parquet"
+			writeFn = func(w io.Writer) error { return writeDataFileAsParquet(fileEntry, dfd, w) }
+		case exportDataCompression != "":
+			fileName = fileName + datastore.GetCompressedFileExtension(exportDataCompression)
+			writeFn = func(w io.Writer) error { return writeCompressedDataFile(fileEntry.FilePath, w) }
+		default:
+			writeFn = func(w io.Writer) error { return copyDataFile(fileEntry.FilePath, w) }
+		}
+		dstFilePath := datastore.JoinPath(dataDir, fileName)
+		log.Infof("writing %q to %q", fileEntry.FilePath, dstFilePath)
+		err := writeDataStoreFile(ds, dstFilePath, writeFn)
+		if err != nil {
+			return err
+		}
+		err = os.Remove(fileEntry.FilePath)
+		if err != nil {
+			return fmt.Errorf("remove %q: %w", fileEntry.FilePath, err)
+		}
+		fileEntry.FilePath = fileName
+		if exportDataDestination != "" {
+			fileEntry.FilePath = dstFilePath
+		}
+		fileEntry.FileSize, err = ds.FileSize(dstFilePath)
+		if err != nil {
+			return fmt.Errorf("get size of %q: %w", dstFilePath, err)
+		}
+	}
+	if exportDataFormat != "" {
+		dfd.FileFormat = exportDataFormat
+	}
+	dfd.DataDir = exportDataDestination
+	dfd.Save()
+	return nil
+}
+
+func writeDataStoreFile(ds datastore.DataStore, filePath string, writeFn func(w io.Writer) error) error {
+	if datastore.IsRemoteLocation(filePath) {
+		// The objects become visible only when the writer is closed, and renaming one is a copy, so they are
+		// written to the final key directly. A failed upload is aborted instead of being published.
+		w, err := ds.Create(filePath)
+		if err != nil {
+			return fmt.Errorf("create %q: %w", filePath, err)
+		}
+		err = writeFn(w)
+		if err != nil {
+			_ = datastore.AbortWrite(w)
+			return err
+		}
+		err = w.Close()
+		if err != nil {
+			return fmt.Errorf("close %q: %w", filePath, err)
+		}
+		return nil
+	}
+
+	tmpFilePath := filePath + ".tmp"
+	w, err := ds.Create(tmpFilePath)
+	if err != nil {
+		return fmt.Errorf("create %q: %w", tmpFilePath, err)
+	}
+	err = writeFn(w)
+	if err != nil {
+		w.Close()
+		_ = ds.Remove(tmpFilePath)
+		return err
+	}
+	err = w.Close()
+	if err != nil {
+		_ = ds.Remove(tmpFilePath)
+		return fmt.Errorf("close %q: %w", tmpFilePath, err)
+	}
+	err = ds.Rename(tmpFilePath, filePath)
+	if err != nil {
+		return fmt.Errorf("rename %q to %q: %w", tmpFilePath, filePath, err)
+	}
+	return nil
+}
+
+func copyDataFile(filePath string, w io.Writer) error {
+	src, err := os.Open(filePath)
+	if err != nil {
+		return fmt.Errorf("open %q: %w", filePath, err)
+	}
+	defer src.Close()
+	_, err = io.Copy(w, src)
+	if err != nil {
+		return fmt.Errorf("copy %q: %w", filePath, err)
+	}
+	return nil
+}
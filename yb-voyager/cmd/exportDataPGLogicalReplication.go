@@ -0,0 +1,88 @@
+/*
+Copyright (c) YugabyteDB, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/fatih/color"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/yugabyte/yb-voyager/yb-voyager/src/cdc"
+	"github.com/yugabyte/yb-voyager/yb-voyager/src/srcdb"
+	"github.com/yugabyte/yb-voyager/yb-voyager/src/utils/sqlname"
+)
+
+// exportPGChangesWithLogicalReplication streams the changes from the replication slot created before the
+// snapshot into the event queue without debezium.
+func exportPGChangesWithLogicalReplication(ctx context.Context, tableList []*sqlname.SourceName, sequenceValueMap map[*sqlname.SourceName]int64) error {
+	msr, err := metaDB.GetMigrationStatusRecord()
+	if err != nil {
+		return fmt.Errorf("get migration status record: %w", err)
+	}
+	absExportDir, err := filepath.Abs(exportDir)
+	if err != nil {
+		return fmt.Errorf("get absolute path for export dir: %w", err)
+	}
+	pgDB := source.DB().(*srcdb.PostgreSQL)
+	tableToKeyColumns, err := pgDB.GetTableToPrimaryKeyColumnsMap(tableList)
+	if err != nil {
+		return fmt.Errorf("get primary key columns of tables: %w", err)
+	}
+	initSequenceValues := make(map[string]int64)
+	for seqName, seqValue := range sequenceValueMap {
+		initSequenceValues[seqName.Qualified.Quoted] = seqValue
+	}
+	config := &cdc.PGExporterConfig{
+		ExportDir:            absExportDir,
+		RunId:                runId,
+		ExporterRole:         exporterRole,
+		SlotName:             msr.PGReplicationSlotName,
+		PublicationName:      msr.PGPublicationName,
+		TableToKeyColumns:    tableToKeyColumns,
+		ColumnToSequence:     source.DB().GetColumnToSequenceMap(tableList),
+		InitSequenceValues:   initSequenceValues,
+		UseSchemaNameInStats: len(strings.Split(source.Schema, "|")) > 1,
+	}
+
+	replicationConn, err := pgDB.GetReplicationConnection()
+	if err != nil {
+		return fmt.Errorf("create replication connection: %w", err)
+	}
+	defer func() {
+		err := replicationConn.Close(context.Background())
+		if err != nil {
+			log.Errorf("close replication connection: %v", err)
+		}
+	}()
+	exporter, err := cdc.NewPGExporter(config, replicationConn, metaDB)
+	if err != nil {
+		return fmt.Errorf("initialize logical replication exporter: %w", err)
+	}
+	color.Blue("streaming changes to a local queue file...")
+	if !disablePb {
+		go reportStreamingProgress()
+	}
+	err = exporter.Run(ctx)
+	if err != nil {
+		return fmt.Errorf("stream changes from replication slot %q: %w", config.SlotName, err)
+	}
+	log.Info("logical replication exporter exited normally.")
+	return nil
+}
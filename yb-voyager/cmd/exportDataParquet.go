@@ -0,0 +1,74 @@
+/*
+Copyright (c) YugabyteDB, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/yugabyte/yb-voyager/yb-voyager/src/datafile"
+	"github.com/yugabyte/yb-voyager/yb-voyager/src/utils"
+)
+
+var exportDataFormat string
+
+func registerExportDataFormatFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&exportDataFormat, "data-format", "",
+		fmt.Sprintf("format of the exported data files. Set to %q to write parquet files instead of the default format of the source database type.\n"+
+			"Only supported for --export-type %s without BETA_FAST_DATA_EXPORT.", datafile.PARQUET, SNAPSHOT_ONLY))
+}
+
+func validateExportDataFormatFlag() {
+	switch exportDataFormat {
+	case "":
+	case datafile.PARQUET:
+		if changeStreamingIsEnabled(exportType) || useDebezium {
+			utils.ErrExit("Error: --data-format %q is supported only with --export-type %s and without BETA_FAST_DATA_EXPORT", exportDataFormat, SNAPSHOT_ONLY)
+		}
+	default:
+		utils.ErrExit("Error: Invalid data-format: %q. Supported data formats are: (%s)", exportDataFormat, datafile.PARQUET)
+	}
+}
+
+// writeDataFileAsParquet writes the rows of the exported data file to `w` in the parquet format.
+func writeDataFileAsParquet(fileEntry *datafile.FileEntry, dfd *datafile.Descriptor, w io.Writer) error {
+	columns := dfd.TableNameToExportedColumns[fileEntry.TableName]
+	if len(columns) == 0 {
+		return fmt.Errorf("exported columns of table %q are not known", fileEntry.TableName)
+	}
+	reader, err := os.Open(fileEntry.FilePath)
+	if err != nil {
+		return fmt.Errorf("open %q: %w", fileEntry.FilePath, err)
+	}
+	dataFile, err := datafile.NewDataFile(fileEntry.FilePath, reader, dfd)
+	if err != nil {
+		return fmt.Errorf("open datafile %q: %w", fileEntry.FilePath, err)
+	}
+	defer dataFile.Close()
+	numRows, err := datafile.WriteParquet(w, columns, dataFile, dfd)
+	if err != nil {
+		return fmt.Errorf("convert %q to parquet: %w", fileEntry.FilePath, err)
+	}
+	if numRows != fileEntry.RowCount {
+		log.Warnf("converted %d rows of %q to parquet, expected %d", numRows, fileEntry.FilePath, fileEntry.RowCount)
+		fileEntry.RowCount = numRows
+	}
+	return nil
+}
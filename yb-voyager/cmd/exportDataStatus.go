@@ -129,7 +129,10 @@ func exportDataStatus(ctx context.Context, tablesProgressMetadata map[string]*ut
 			if quit {
 				break
 			}
-			if tablesProgressMetadata[key].Status == utils.TABLE_MIGRATION_NOT_STARTED && (utils.FileOrFolderExists(tablesProgressMetadata[key].InProgressFilePath) ||
+			if tablesProgressMetadata[key].Status == utils.TABLE_MIGRATION_NOT_STARTED && nativeExportStarted(key) {
+				tablesProgressMetadata[key].Status = utils.TABLE_MIGRATION_IN_PROGRESS
+				go startNativeExportPB(progressContainer, key, disablePb)
+			} else if tablesProgressMetadata[key].Status == utils.TABLE_MIGRATION_NOT_STARTED && (utils.FileOrFolderExists(tablesProgressMetadata[key].InProgressFilePath) ||
 				utils.FileOrFolderExists(tablesProgressMetadata[key].FinalFilePath)) {
 				tablesProgressMetadata[key].Status = utils.TABLE_MIGRATION_IN_PROGRESS
 				go startExportPB(progressContainer, key, quitChan2, disablePb)
@@ -268,6 +271,50 @@ func startExportPB(progressContainer *mpb.Progress, mapKey string, quitChan chan
 	tableMetadata.Status = utils.TABLE_MIGRATION_DONE
 }
 
+func nativeExportStarted(mapKey string) bool {
+	if source.DBType != POSTGRESQL || !source.UseNativeExport {
+		return false
+	}
+	_, started, _ := source.DB().(*srcdb.PostgreSQL).GetExportedRowCount(tablesProgressMetadata[mapKey].TableName)
+	return started
+}
+
+// The native export splits tables into chunks exported in parallel, so instead of reading the data
+// file the exported rows are polled from the exporter.
+func startNativeExportPB(progressContainer *mpb.Progress, mapKey string, disablePb bool) {
+	tableName := mapKey
+	tableMetadata := tablesProgressMetadata[mapKey]
+	pg := source.DB().(*srcdb.PostgreSQL)
+
+	pbr := pbreporter.NewExportPB(progressContainer, tableName, disablePb)
+	pbr.SetTotalRowCount(tableMetadata.CountTotalRows, false)
+
+	go func() {
+		actualRowCount := source.DB().GetTableRowCount(tableMetadata.TableName.Qualified.MinQuoted)
+		log.Infof("Replacing actualRowCount=%d inplace of expectedRowCount=%d for table=%s",
+			actualRowCount, tableMetadata.CountTotalRows, tableMetadata.TableName.Qualified.MinQuoted)
+		pbr.SetTotalRowCount(actualRowCount, false)
+		tableMetadata.CountTotalRows = actualRowCount
+	}()
+
+	for {
+		rowCount, _, done := pg.GetExportedRowCount(tableMetadata.TableName)
+		tableMetadata.CountLiveRows = rowCount
+		pbr.SetExportedRowCount(rowCount)
+		if exporterRole == SOURCE_DB_EXPORTER_ROLE {
+			exportDataTableMetrics := createUpdateExportedRowCountEventList([]string{tableName})
+			controlPlane.UpdateExportedRowCount(exportDataTableMetrics)
+		}
+		if done {
+			break
+		}
+		time.Sleep(time.Millisecond * 500)
+	}
+
+	pbr.SetTotalRowCount(-1, true)
+	tableMetadata.Status = utils.TABLE_MIGRATION_DONE
+}
+
 func updateExportSnapshotStatus(ctx context.Context, tableMetadata map[string]*utils.TableProgressMetadata) {
 	updateTicker := time.NewTicker(1 * time.Second) //TODO: confirm if this is fine
 	defer updateTicker.Stop()
@@ -295,7 +342,7 @@ func checkForEndOfFile(source *srcdb.Source, tableMetadata *utils.TableProgressM
 		if strings.HasPrefix(line, "\\.") {
 			return true
 		}
-	} else if source.DBType == "oracle" || source.DBType == "mysql" {
+	} else if source.DBType == "oracle" || source.DBType == "mysql" || source.DBType == "sqlite" {
 		if !utils.FileOrFolderExists(tableMetadata.InProgressFilePath) && utils.FileOrFolderExists(tableMetadata.FinalFilePath) {
 			return true
 		}
@@ -327,6 +374,7 @@ This function checks for based structure of data file which can be different
 for different source db type based on tool used for export
 postgresql - file has only data lines with "\." at the end
 oracle/mysql - multiple copy statements with each having specific count of rows
+sqlite - csv file with a header line, insideCopyStmt tracks if the header is already read
 */
 func isDataLine(line string, sourceDBType string, insideCopyStmt *bool) bool {
 	emptyLine := (len(line) == 0)
@@ -347,6 +395,12 @@ func isDataLine(line string, sourceDBType string, insideCopyStmt *bool) bool {
 			}
 			return false
 		}
+	} else if sourceDBType == "sqlite" {
+		if !*insideCopyStmt {
+			*insideCopyStmt = true
+			return false
+		}
+		return !(emptyLine || newLineChar)
 	} else {
 		panic("Invalid source db type")
 	}
@@ -113,7 +113,7 @@ func runExportDataStatusCmd() error {
 	source = *msr.SourceDBConf
 	sqlname.SourceDBType = source.DBType
 	var finalFullTableName string
-	if source.DBType == "postgresql" {
+	if source.DBType == "postgresql" && !source.UseNativeExport {
 		tableMap = getMappingForTableNameVsTableFileName(dataDir, true)
 	}
 	var outputRows []*exportTableMigStatusOutputRow
@@ -130,7 +130,7 @@ func runExportDataStatusCmd() error {
 			finalFullTableName = sqlTableName.Qualified.MinQuoted
 		}
 
-		if source.DBType == POSTGRESQL {
+		if source.DBType == POSTGRESQL && !source.UseNativeExport {
 			//for the cases where partitioned table will not have datafile but we have it in tableList
 			//TODO: fix with partition fix later
 			_, ok := tableMap[sqlTableName.Qualified.MinQuoted]
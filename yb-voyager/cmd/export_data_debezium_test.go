@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSequenceMaxMappingMatchesColumnSequenceMapping(t *testing.T) {
+	assert := assert.New(t)
+	columnSequenceMapping := getColumnSequenceMapping(map[string]string{
+		"test.orders.id":      "orders_id_seq",
+		"test.order_items.id": "order_items_id_seq",
+	})
+	sequenceMaxMapping := getSequenceMaxMapping(map[string]int64{
+		"orders_id_seq":      100,
+		"order_items_id_seq": 0,
+	})
+
+	sequences := map[string]bool{}
+	for _, entry := range strings.Split(columnSequenceMapping, ",") {
+		column, sequence, found := strings.Cut(entry, ":")
+		assert.True(found, entry)
+		assert.Len(strings.Split(column, "."), 3, entry)
+		sequences[sequence] = true
+	}
+	maxValues := map[string]string{}
+	for _, entry := range strings.Split(sequenceMaxMapping, ",") {
+		sequence, value, found := strings.Cut(entry, ":")
+		assert.True(found, entry)
+		assert.True(sequences[sequence], "%s is not a sequence of column_sequence.map %s", sequence, columnSequenceMapping)
+		maxValues[sequence] = value
+	}
+	assert.Equal(map[string]string{"orders_id_seq": "100", "order_items_id_seq": "0"}, maxValues)
+	assert.Empty(getSequenceMaxMapping(nil))
+}
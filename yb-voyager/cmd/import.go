@@ -81,6 +81,10 @@ func validateImportFlags(cmd *cobra.Command, importerRole string) error {
 		fmt.Println("WARNING: The --disable-transactional-writes feature is in the experimental phase, not for production use case.")
 	}
 	validateBatchSizeFlag(batchSize)
+	err = validateMaxRejectedRowsPerTableFlag()
+	if err != nil {
+		return err
+	}
 	switch importerRole {
 	case TARGET_DB_IMPORTER_ROLE:
 		getTargetPassword(cmd)
@@ -226,6 +230,14 @@ func registerImportDataCommonFlags(cmd *cobra.Command) {
 	BoolVar(cmd.Flags(), &truncateSplits, "truncate-splits", true,
 		"Truncate splits after importing")
 	cmd.Flags().MarkHidden("truncate-splits")
+
+	cmd.Flags().Int64Var(&maxRejectedRowsPerTable, "max-rejected-rows-per-table", 0,
+		"Maximum number of rows of a table that can be rejected by the target database during the snapshot import. "+
+			"The rows of a failed batch that are rejected are isolated and recorded along with the error in the export-dir/data/errors/<table>.csv file, "+
+			"and the rest of the rows of the batch are imported. The import of a table is aborted if more rows are rejected. "+
+			"(default 0, i.e. a failed batch aborts the import)")
+
+	registerTransformationsFlag(cmd)
 }
 
 func registerImportDataFlags(cmd *cobra.Command) {
@@ -254,6 +266,18 @@ func registerImportSchemaFlags(cmd *cobra.Command) {
 		"Refreshes the materialised views on target during post snapshot import phase (default false)")
 	BoolVar(cmd.Flags(), &enableOrafce, "enable-orafce", true,
 		"enable Orafce extension on target(if source db type is Oracle)")
+	BoolVar(cmd.Flags(), &flagRollback, "rollback", false,
+		"Drops the schema objects created in the target YugabyteDB by the previous runs of import schema, in the reverse order of their creation. "+
+			"The objects which already existed in the target, and the constraints added to the tables which already existed, are left untouched (default false)")
+	BoolVar(cmd.Flags(), &flagRetryFailed, "retry-failed", false,
+		"Executes again only the statements which failed in the previous runs of import schema, as present (and possibly edited) in the schema/failed.sql file. "+
+			"The statements executed successfully by the previous runs are always skipped (default false)")
+	cmd.Flags().IntVar(&tconf.Parallelism, "parallel-jobs", 4,
+		"number of DDL statements to execute in parallel. The statements are executed in the order of the dependencies between the schema objects. "+
+			"With --post-snapshot-import, number of indexes to create in parallel, the indexes of a table being created one after the other "+
+			"(ignored with --straight-order)")
+	BoolVar(cmd.Flags(), &disablePb, "disable-pb", false,
+		"Disable the progress bars of the index creation during post snapshot import (default false)")
 }
 
 func validateTargetPortRange() {
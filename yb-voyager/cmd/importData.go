@@ -109,8 +109,12 @@ func importDataCommandFn(cmd *cobra.Command, args []string) {
 	checkExportDataDoneFlag()
 	sourceDBType = GetSourceDBTypeFromMSR()
 	sqlname.SourceDBType = sourceDBType
-	dataStore = datastore.NewDataStore(filepath.Join(exportDir, "data"))
 	dataFileDescriptor = datafile.OpenDescriptor(exportDir)
+	if dataFileDescriptor.DataDir != "" {
+		dataStore = datastore.NewDataStore(dataFileDescriptor.DataDir)
+	} else {
+		dataStore = datastore.NewDataStore(filepath.Join(exportDir, "data"))
+	}
 	// TODO: handle case-sensitive in table names with oracle ff-db
 	// quoteTableNameIfRequired()
 	importFileTasks := discoverFilesToImport()
@@ -380,6 +384,7 @@ func importData(importFileTasks []*ImportFileTask) {
 	if err != nil {
 		utils.ErrExit("failed to get migration UUID: %w", err)
 	}
+	loadTransformations()
 
 	if importerRole == TARGET_DB_IMPORTER_ROLE {
 		importDataStartEvent := createSnapshotImportStartedEvent()
@@ -504,6 +509,12 @@ func importData(importFileTasks []*ImportFileTask) {
 			}
 			time.Sleep(time.Second * 2)
 		}
+		reportRejectedRows()
+		abortedTables := getAbortedTables()
+		if len(abortedTables) > 0 {
+			utils.ErrExit("import of the following tables is aborted as more than %d rows of each are rejected: %s",
+				maxRejectedRowsPerTable, strings.Join(abortedTables, ", "))
+		}
 		utils.PrintAndLog("snapshot data import complete\n\n")
 		callhome.PackAndSendPayload(exportDir)
 	}
@@ -701,6 +712,8 @@ func cleanImportState(state *ImportDataState, tasks []*ImportFileTask) {
 		}
 	}
 
+	cleanRejectedRowsFiles(tableNames)
+
 	sqlldrDir := filepath.Join(exportDir, "sqlldr")
 	if utils.FileOrFolderExists(sqlldrDir) {
 		err := os.RemoveAll(sqlldrDir)
@@ -724,13 +737,17 @@ func cleanImportState(state *ImportDataState, tasks []*ImportFileTask) {
 
 func getImportBatchArgsProto(tableName, filePath string) *tgtdb.ImportBatchArgs {
 	columns := TableToColumnNames[tableName]
+	if rt := getRowTransformer(tableName); rt != nil {
+		columns = rt.ColumnNames()
+	}
 	columns, err := tdb.IfRequiredQuoteColumnNames(tableName, columns)
 	if err != nil {
 		utils.ErrExit("if required quote column names: %s", err)
 	}
 	// If `columns` is unset at this point, no attribute list is passed in the COPY command.
 	fileFormat := dataFileDescriptor.FileFormat
-	if fileFormat == datafile.SQL {
+	if fileFormat == datafile.SQL || fileFormat == datafile.PARQUET {
+		// Rows of both are read as lines in the TEXT format.
 		fileFormat = datafile.TEXT
 	}
 	importBatchArgsProto := &tgtdb.ImportBatchArgs{
@@ -758,7 +775,7 @@ func importFile(state *ImportDataState, task *ImportFileTask, updateProgressFn f
 		utils.ErrExit("preparing for file import: %s", err)
 	}
 	log.Infof("Collect all interrupted/remaining splits.")
-	pendingBatches, lastBatchNumber, lastOffset, fileFullySplit, err := state.Recover(task.FilePath, task.TableName)
+	pendingBatches, lastBatchNumber, lastOffset, lastByteOffset, fileFullySplit, err := state.Recover(task.FilePath, task.TableName)
 	if err != nil {
 		utils.ErrExit("recovering state for table %q: %s", task.TableName, err)
 	}
@@ -766,42 +783,74 @@ func importFile(state *ImportDataState, task *ImportFileTask, updateProgressFn f
 		submitBatch(batch, updateProgressFn, importBatchArgsProto)
 	}
 	if !fileFullySplit {
-		splitFilesForTable(state, origDataFile, task.TableName, lastBatchNumber, lastOffset, updateProgressFn, importBatchArgsProto)
+		splitFilesForTable(state, origDataFile, task.TableName, lastBatchNumber, lastOffset, lastByteOffset, updateProgressFn, importBatchArgsProto)
 	}
 }
 
 func splitFilesForTable(state *ImportDataState, filePath string, t string,
-	lastBatchNumber int64, lastOffset int64, updateProgressFn func(int64), importBatchArgsProto *tgtdb.ImportBatchArgs) {
-	log.Infof("Split data file %q: tableName=%q, largestSplit=%v, largestOffset=%v", filePath, t, lastBatchNumber, lastOffset)
+	lastBatchNumber int64, lastOffset int64, lastByteOffset int64, updateProgressFn func(int64), importBatchArgsProto *tgtdb.ImportBatchArgs) {
+	log.Infof("Split data file %q: tableName=%q, largestSplit=%v, largestOffset=%v, byteOffset=%v", filePath, t, lastBatchNumber, lastOffset, lastByteOffset)
 	batchNum := lastBatchNumber + 1
 	numLinesTaken := lastOffset
 
-	reader, err := dataStore.Open(filePath)
-	if err != nil {
-		utils.ErrExit("preparing reader for split generation on file %q: %v", filePath, err)
-	}
+	var dataFile datafile.DataFile
+	var err error
+	header := ""
+	if lastOffset > 0 && lastByteOffset > 0 && dataFileSupportsOffsetReads(filePath) {
+		// Resume reading right after the last batch instead of skipping the lines already split.
+		if dataFileDescriptor.HasHeader {
+			header = readDataFileHeader(filePath)
+		}
+		log.Infof("Reading %q from byte offset %d", filePath, lastByteOffset)
+		var reader io.ReadCloser
+		reader, err = dataStore.OpenAt(filePath, lastByteOffset)
+		if err != nil {
+			utils.ErrExit("preparing reader for split generation on file %q at offset %d: %v", filePath, lastByteOffset, err)
+		}
+		dataFile, err = datafile.NewDataFileAtOffset(filePath, reader, dataFileDescriptor)
+		if err != nil {
+			utils.ErrExit("open datafile %q: %v", filePath, err)
+		}
+	} else {
+		var reader io.ReadCloser
+		reader, err = dataStore.Open(filePath)
+		if err != nil {
+			utils.ErrExit("preparing reader for split generation on file %q: %v", filePath, err)
+		}
+		dataFile, err = datafile.NewDataFile(filePath, reader, dataFileDescriptor)
+		if err != nil {
+			utils.ErrExit("open datafile %q: %v", filePath, err)
+		}
 
-	dataFile, err := datafile.NewDataFile(filePath, reader, dataFileDescriptor)
-	if err != nil {
-		utils.ErrExit("open datafile %q: %v", filePath, err)
+		log.Infof("Skipping %d lines from %q", lastOffset, filePath)
+		err = dataFile.SkipLines(lastOffset)
+		if err != nil {
+			utils.ErrExit("skipping line for offset=%d: %v", lastOffset, err)
+		}
+		if lastByteOffset > 0 {
+			// The skipped bytes are already counted in the batches split so far. Otherwise, the batches
+			// were recorded without their byte counts, and the next batch accounts for all of them.
+			dataFile.ResetBytesRead()
+		}
+		if dataFileDescriptor.HasHeader {
+			header = dataFile.GetHeader()
+		}
 	}
 	defer dataFile.Close()
 
-	log.Infof("Skipping %d lines from %q", lastOffset, filePath)
-	err = dataFile.SkipLines(lastOffset)
-	if err != nil {
-		utils.ErrExit("skipping line for offset=%d: %v", lastOffset, err)
+	rowTransformer := getRowTransformer(t)
+	if header != "" && rowTransformer != nil {
+		header = transformHeader(rowTransformer, header)
 	}
-
 	var readLineErr error = nil
 	var line string
 	var batchWriter *BatchWriter
-	header := ""
-	if dataFileDescriptor.HasHeader {
-		header = dataFile.GetHeader()
-	}
 	for readLineErr == nil {
 
+		if isTableImportAborted(t) {
+			log.Infof("splitFilesForTable: stop splitting data file %q as the import of table %q is aborted", filePath, t)
+			return
+		}
 		if batchWriter == nil {
 			batchWriter = state.NewBatchWriter(filePath, t, batchNum)
 			err := batchWriter.Init()
@@ -828,13 +877,22 @@ func splitFilesForTable(state *ImportDataState, filePath string, t string,
 			if err != nil {
 				utils.ErrExit("transforming line number=%d for table %q in file %s: %s", batchWriter.NumRecordsWritten+1, t, filePath, err)
 			}
+			if rowTransformer != nil {
+				line, err = transformLine(rowTransformer, line)
+				if err != nil {
+					utils.ErrExit("applying transformations to line number=%d for table %q in file %s: %s", batchWriter.NumRecordsWritten+1, t, filePath, err)
+				}
+			}
 		}
 		err = batchWriter.WriteRecord(line)
 		if err != nil {
 			utils.ErrExit("Write to batch %d: %s", batchNum, err)
 		}
+		// The bytes read from a compressed data file are fewer than the bytes of the batch, so the
+		// size of the batch is checked as well.
 		if batchWriter.NumRecordsWritten == batchSize ||
 			dataFile.GetBytesRead() >= tdb.MaxBatchSizeInBytes() ||
+			batchWriter.NumBytesWritten >= tdb.MaxBatchSizeInBytes() ||
 			readLineErr != nil {
 
 			isLastBatch := false
@@ -861,6 +919,24 @@ func splitFilesForTable(state *ImportDataState, filePath string, t string,
 	log.Infof("splitFilesForTable: done splitting data file %q for table %q", filePath, t)
 }
 
+// The byte offsets recorded in the batches are exact only for the uncompressed text based data files.
+func dataFileSupportsOffsetReads(filePath string) bool {
+	return dataFileDescriptor.FileFormat != datafile.PARQUET && datastore.GetCompressionType(filePath) == ""
+}
+
+func readDataFileHeader(filePath string) string {
+	reader, err := dataStore.Open(filePath)
+	if err != nil {
+		utils.ErrExit("preparing reader to read header of file %q: %v", filePath, err)
+	}
+	dataFile, err := datafile.NewDataFile(filePath, reader, dataFileDescriptor)
+	if err != nil {
+		utils.ErrExit("open datafile %q: %v", filePath, err)
+	}
+	defer dataFile.Close()
+	return dataFile.GetHeader()
+}
+
 func executePostSnapshotImportSqls() {
 	sequenceFilePath := filepath.Join(exportDir, "data", "postdata.sql")
 	if utils.FileOrFolderExists(sequenceFilePath) {
@@ -885,6 +961,10 @@ func submitBatch(batch *Batch, updateProgressFn func(int64), importBatchArgsProt
 }
 
 func importBatch(batch *Batch, importBatchArgsProto *tgtdb.ImportBatchArgs) {
+	if isTableImportAborted(batch.TableName) {
+		log.Infof("skipping %q as the import of table %s is aborted", batch.FilePath, batch.TableName)
+		return
+	}
 	err := batch.MarkPending()
 	if err != nil {
 		utils.ErrExit("marking batch %d as pending: %s", batch.Number, err)
@@ -896,9 +976,14 @@ func importBatch(batch *Batch, importBatchArgsProto *tgtdb.ImportBatchArgs) {
 	importBatchArgs.RowsPerTransaction = batch.OffsetEnd - batch.OffsetStart
 
 	var rowsAffected int64
+	var tableAborted bool
 	sleepIntervalSec := 0
 	for attempt := 0; attempt < COPY_MAX_RETRY_COUNT; attempt++ {
 		rowsAffected, err = tdb.ImportBatch(batch, &importBatchArgs, exportDir, TableNameToSchema[batch.TableName])
+		if err != nil && maxRejectedRowsPerTable > 0 {
+			log.Warnf("COPY FROM file %q: %s. Retrying by isolating the rejected rows.", batch.FilePath, err)
+			rowsAffected, tableAborted, err = importBatchIsolatingRejectedRows(batch, &importBatchArgs)
+		}
 		if err == nil || tdb.IsNonRetryableCopyError(err) {
 			break
 		}
@@ -915,6 +1000,10 @@ func importBatch(batch *Batch, importBatchArgsProto *tgtdb.ImportBatchArgs) {
 	if err != nil {
 		utils.ErrExit("import %q into %s: %s", batch.FilePath, batch.TableName, err)
 	}
+	if tableAborted {
+		log.Warnf("aborting import of table %s as more than %d rows are rejected", batch.TableName, maxRejectedRowsPerTable)
+		return
+	}
 	err = batch.MarkDone()
 	if err != nil {
 		utils.ErrExit("marking batch %q as done: %s", batch.FilePath, err)
@@ -987,23 +1076,20 @@ func executeSqlFile(file string, objType string, skipFn func(string, string) boo
 			conn = newTargetConn()
 		}
 
-		setOrSelectStmt := strings.HasPrefix(strings.ToUpper(sqlInfo.stmt), "SET ") ||
-			strings.HasPrefix(strings.ToUpper(sqlInfo.stmt), "SELECT ")
-		if !setOrSelectStmt && skipFn != nil && skipFn(objType, sqlInfo.stmt) {
+		if !isSetOrSelectStmt(sqlInfo.stmt) && skipFn != nil && skipFn(objType, sqlInfo.stmt) {
 			continue
 		}
 
-		if objType == "TABLE" {
-			stmt := strings.ToUpper(sqlInfo.stmt)
-			skip := strings.Contains(stmt, "ALTER TABLE") && strings.Contains(stmt, "REPLICA IDENTITY")
-			if skip {
-				//skipping DDLS like ALTER TABLE ... REPLICA IDENTITY .. as this is not supported in YB
-				log.Infof("Skipping DDL: %s", sqlInfo.stmt)
-				continue
-			}
+		if isUnsupportedDDL(objType, sqlInfo.stmt) {
+			log.Infof("Skipping DDL: %s", sqlInfo.stmt)
+			updateSchemaStmtStatus(getSchemaStmtKey(objType, sqlInfo.formattedStmt), metadb.SCHEMA_STMT_SKIPPED, "")
+			continue
 		}
 
 		err := executeSqlStmtWithRetries(&conn, sqlInfo, objType)
+		if isSchemaImportStopped(err) {
+			exitOnSchemaStmtError(err)
+		}
 		if err != nil {
 			conn.Close(context.Background())
 			conn = nil
@@ -1011,6 +1097,22 @@ func executeSqlFile(file string, objType string, skipFn func(string, string) boo
 	}
 }
 
+// isSetOrSelectStmt returns true for the statements setting up the session, like SET search_path or
+// SELECT pg_catalog.set_config(...), which are run before the DDLs of the file.
+func isSetOrSelectStmt(stmt string) bool {
+	return strings.HasPrefix(strings.ToUpper(stmt), "SET ") ||
+		strings.HasPrefix(strings.ToUpper(stmt), "SELECT ")
+}
+
+func isUnsupportedDDL(objType string, stmt string) bool {
+	if objType == "TABLE" {
+		stmt = strings.ToUpper(stmt)
+		//skipping DDLS like ALTER TABLE ... REPLICA IDENTITY .. as this is not supported in YB
+		return strings.Contains(stmt, "ALTER TABLE") && strings.Contains(stmt, "REPLICA IDENTITY")
+	}
+	return false
+}
+
 func setOrafceSearchPath(conn *pgx.Conn) {
 	// append oracle schema in the search_path for orafce
 	updateSearchPath := `SELECT set_config('search_path', current_setting('search_path') || ', oracle', false)`
@@ -1039,6 +1141,12 @@ func getIndexName(sqlQuery string, indexName string) (string, error) {
 
 func executeSqlStmtWithRetries(conn **pgx.Conn, sqlInfo sqlInfo, objType string) error {
 	var err error
+	// The statements setting up the session are run on every connection.
+	trackStatus := !isSetOrSelectStmt(sqlInfo.stmt)
+	if trackStatus && isSchemaStmtImported(objType, sqlInfo) {
+		log.Infof("Skipping DDL imported by a previous run: %s", sqlInfo.stmt)
+		return nil
+	}
 	log.Infof("On %s run query:\n%s\n", tconf.Host, sqlInfo.formattedStmt)
 	for retryCount := 0; retryCount <= DDL_MAX_RETRY_COUNT; retryCount++ {
 		if retryCount > 0 { // Not the first iteration.
@@ -1056,6 +1164,10 @@ func executeSqlStmtWithRetries(conn **pgx.Conn, sqlInfo sqlInfo, objType string)
 		_, err = (*conn).Exec(context.Background(), sqlInfo.formattedStmt)
 		if err == nil {
 			utils.PrintSqlStmtIfDDL(sqlInfo.stmt, utils.GetObjectFileName(filepath.Join(exportDir, "schema"), objType))
+			if trackStatus {
+				updateSchemaStmtStatus(getSchemaStmtKey(objType, sqlInfo.formattedStmt), metadb.SCHEMA_STMT_DONE, "")
+			}
+			recordImportedSchemaObject(sqlInfo.parseTree)
 			return nil
 		}
 
@@ -1072,27 +1184,37 @@ func executeSqlStmtWithRetries(conn **pgx.Conn, sqlInfo sqlInfo, objType string)
 			*conn = newTargetConn()
 
 			// Extract the schema name and add to the index name
-			fullyQualifiedObjName, err := getIndexName(sqlInfo.stmt, sqlInfo.objName)
-			if err != nil {
-				utils.ErrExit("extract qualified index name from DDL [%v]: %v", sqlInfo.stmt, err)
+			fullyQualifiedObjName, err2 := getIndexName(sqlInfo.stmt, sqlInfo.objName)
+			if err2 != nil {
+				return fmt.Errorf("extract qualified index name from DDL [%v]: %w", sqlInfo.stmt, err2)
 			}
 
 			// DROP INDEX in case INVALID index got created
 			// `err` is already being used for retries, so using `err2`
-			err2 := dropIdx(*conn, fullyQualifiedObjName)
+			err2 = dropIdx(*conn, fullyQualifiedObjName)
 			if err2 != nil {
 				return fmt.Errorf("drop invalid index %q: %w", fullyQualifiedObjName, err2)
 			}
 			continue
+		} else if getSchemaImportParallelism() > 1 && isCatalogVersionMismatch(err) {
+			// The DDLs run in parallel by the other connections bump the catalog version of the target.
+			(*conn).Close(context.Background())
+			*conn = newTargetConn()
+			continue
 		} else if missingRequiredSchemaObject(err) {
 			log.Infof("deffering execution of SQL: %s", sqlInfo.formattedStmt)
-			defferedSqlStmts = append(defferedSqlStmts, sqlInfo)
+			importSchemaStmtsMutex.Lock()
+			defferedSqlStmts = append(defferedSqlStmts, schemaDDLStmt{objType: objType, sqlInfo: sqlInfo})
+			importSchemaStmtsMutex.Unlock()
 		} else if isAlreadyExists(err.Error()) {
 			// pg_dump generates `CREATE SCHEMA public;` in the schemas.sql. Because the `public`
 			// schema already exists on the target YB db, the create schema statement fails with
 			// "already exists" error. Ignore the error.
 			if bool(tconf.IgnoreIfExists) || strings.EqualFold(strings.Trim(sqlInfo.stmt, " \n"), "CREATE SCHEMA public;") {
 				err = nil
+				if trackStatus {
+					updateSchemaStmtStatus(getSchemaStmtKey(objType, sqlInfo.formattedStmt), metadb.SCHEMA_STMT_SKIPPED, "")
+				}
 			}
 		}
 		break // no more iteration in case of non retriable error
@@ -1103,18 +1225,31 @@ func executeSqlStmtWithRetries(conn **pgx.Conn, sqlInfo sqlInfo, objType string)
 		} else {
 			utils.PrintSqlStmtIfDDL(sqlInfo.stmt, utils.GetObjectFileName(filepath.Join(exportDir, "schema"), objType))
 			color.Red(fmt.Sprintf("%s\n", err.Error()))
-			if tconf.ContinueOnError {
-				log.Infof("appending stmt to failedSqlStmts list: %s\n", utils.GetSqlStmtToPrint(sqlInfo.stmt))
-				errString := "/*\n" + err.Error() + "\n*/\n"
-				failedSqlStmts = append(failedSqlStmts, errString+sqlInfo.formattedStmt)
-			} else {
-				utils.ErrExit("error: %s\n", err)
-			}
+			log.Infof("appending stmt to failedSqlStmts list: %s\n", utils.GetSqlStmtToPrint(sqlInfo.stmt))
+			markSchemaStmtFailed(objType, sqlInfo, err.Error())
 		}
 	}
 	return err
 }
 
+func isCatalogVersionMismatch(err error) bool {
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, strings.ToLower(SCHEMA_VERSION_MISMATCH_ERR)) ||
+		strings.Contains(msg, strings.ToLower(CATALOG_VERSION_MISMATCH_ERR)) ||
+		strings.Contains(msg, strings.ToLower(CATALOG_SNAPSHOT_INVALIDATED_ERR))
+}
+
+// isSchemaImportStopped returns true if the import must stop because of the error of the DDL. The DDLs missing
+// an object are retried at the end, and the failed ones are only recorded with --continue-on-error.
+func isSchemaImportStopped(err error) bool {
+	return err != nil && !missingRequiredSchemaObject(err) && !bool(tconf.ContinueOnError)
+}
+
+func exitOnSchemaStmtError(err error) {
+	dumpFailedSqlStmts()
+	utils.ErrExit("error: %s\n", err)
+}
+
 // TODO: need automation tests for this, covering cases like schema(public vs non-public) or case sensitive names
 func beforeIndexCreation(sqlInfo sqlInfo, conn **pgx.Conn, objType string) error {
 	if !strings.Contains(strings.ToUpper(sqlInfo.stmt), "CREATE INDEX") {
@@ -1140,9 +1275,6 @@ func beforeIndexCreation(sqlInfo sqlInfo, conn **pgx.Conn, objType string) error
 			return fmt.Errorf("drop invalid index %q: %w", fullyQualifiedObjName, err)
 		}
 	}
-
-	// print the index name as index creation takes time and user can see the progress
-	color.Yellow("creating index %s ...", fullyQualifiedObjName)
 	return nil
 }
 
@@ -21,6 +21,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/samber/lo"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"golang.org/x/exp/maps"
@@ -42,7 +43,7 @@ var (
 	dataDir               string
 	fileTableMapping      string
 	hasHeader             utils.BoolStr
-	supportedFileFormats  = []string{datafile.CSV, datafile.TEXT}
+	supportedFileFormats  = []string{datafile.CSV, datafile.TEXT, datafile.PARQUET}
 	fileOpts              string
 	escapeChar            string
 	quoteChar             string
@@ -96,6 +97,10 @@ func prepareForImportDataCmd(importFileTasks []*ImportFileTask) {
 		ExportDir:    exportDir,
 		NullString:   nullString,
 	}
+	if fileFormat == datafile.PARQUET {
+		// The column names of a parquet file are read from its schema.
+		dataFileDescriptor.HasHeader = true
+	}
 	if quoteChar != "" {
 		quoteCharBytes := []byte(quoteChar)
 		dataFileDescriptor.QuoteChar = quoteCharBytes[0]
@@ -148,7 +153,7 @@ func prepareImportFileTasks() []*ImportFileTask {
 	kvs := strings.Split(fileTableMapping, ",")
 	for i, kv := range kvs {
 		globPattern, table := strings.Split(kv, ":")[0], strings.Split(kv, ":")[1]
-		filePaths, err := dataStore.Glob(globPattern)
+		filePaths, err := globDataFiles(globPattern)
 		if err != nil {
 			utils.ErrExit("find files matching pattern %q: %v", globPattern, err)
 		}
@@ -167,6 +172,23 @@ func prepareImportFileTasks() []*ImportFileTask {
 	return result
 }
 
+// globDataFiles returns the files matching the pattern along with their compressed variants.
+// For example, `orders.csv.gz` is returned for the pattern `orders*.csv`.
+func globDataFiles(pattern string) ([]string, error) {
+	filePaths, err := dataStore.Glob(pattern)
+	if err != nil {
+		return nil, err
+	}
+	for _, ext := range datastore.CompressedFileExtensions() {
+		compressedFilePaths, err := dataStore.Glob(pattern + ext)
+		if err != nil {
+			return nil, err
+		}
+		filePaths = append(filePaths, compressedFilePaths...)
+	}
+	return lo.Uniq(filePaths), nil
+}
+
 func checkImportDataFileFlags(cmd *cobra.Command) {
 	fileFormat = strings.ToLower(fileFormat)
 	checkFileFormat()
@@ -200,7 +222,10 @@ func checkDataDirFlag() {
 		utils.ErrExit(`Error: required flag "data-dir" not set`)
 	}
 	if strings.HasPrefix(dataDir, "s3://") {
-		s3.ValidateObjectURL(dataDir)
+		err := s3.ValidateObjectURL(dataDir)
+		if err != nil {
+			utils.ErrExit("invalid data-dir %q: %v", dataDir, err)
+		}
 		return
 	} else if strings.HasPrefix(dataDir, "gs://") {
 		gcs.ValidateObjectURL(dataDir)
@@ -299,12 +324,15 @@ func checkAndParseEscapeAndQuoteChar() {
 
 func setDefaultForNullString() {
 	if nullString != "" {
+		if fileFormat == datafile.PARQUET {
+			utils.ErrExit("ERROR: --null-string flag is invalid for %q format", fileFormat)
+		}
 		return
 	}
 	switch fileFormat {
 	case datafile.CSV:
 		nullString = ""
-	case datafile.TEXT:
+	case datafile.TEXT, datafile.PARQUET:
 		nullString = "\\N"
 	default:
 		panic("unsupported file format")
@@ -313,12 +341,15 @@ func setDefaultForNullString() {
 
 func setDefaultForDelimiter() {
 	if delimiter != "" {
+		if fileFormat == datafile.PARQUET {
+			utils.ErrExit("ERROR: --delimiter flag is invalid for %q format", fileFormat)
+		}
 		return
 	}
 	switch fileFormat {
 	case datafile.CSV:
 		delimiter = `,`
-	case datafile.TEXT:
+	case datafile.TEXT, datafile.PARQUET:
 		delimiter = `\t`
 	default:
 		panic("unsupported file format")
@@ -366,6 +397,7 @@ func init() {
 		"path to the directory which contains data files to import into table(s)\n"+
 			"Note: data-dir can be a local directory or a cloud storage URL\n"+
 			"\tfor AWS S3, e.g. s3://<bucket-name>/<path-to-data-dir>\n"+
+			"\tfor S3-compatible stores (MinIO, Ceph), e.g. s3://<bucket-name>/<path-to-data-dir>?endpoint=http://<host>:<port>&use_path_style=true&profile=<aws-profile>\n"+
 			"\tfor GCS buckets, e.g. gs://<bucket-name>/<path-to-data-dir>\n"+
 			"\tfor Azure blob storage, e.g. https://<account_name>.blob.core.windows.net/<container_name>/<path-to-data-dir>")
 	err := importDataFileCmd.MarkFlagRequired("data-dir")
@@ -375,7 +407,8 @@ func init() {
 
 	importDataFileCmd.Flags().StringVar(&fileTableMapping, "file-table-map", "",
 		"comma separated list of mapping between file name in '--data-dir' to a table in database\n"+
-			"You can import multiple files in one table either by providing one entry for each file 'fileName1:tableName,fileName2:tableName' OR by passing a glob expression in place of the file name. 'fileName*:tableName'")
+			"You can import multiple files in one table either by providing one entry for each file 'fileName1:tableName,fileName2:tableName' OR by passing a glob expression in place of the file name. 'fileName*:tableName'\n"+
+			"Gzip (.gz) and zstd (.zst) compressed files are decompressed on the fly. A file name or glob expression also matches the compressed variants of the files, e.g. 'orders.csv' matches 'orders.csv.gz'.")
 
 	err = importDataFileCmd.MarkFlagRequired("file-table-map")
 	if err != nil {
@@ -0,0 +1,286 @@
+/*
+Copyright (c) YugabyteDB, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/yugabyte/yb-voyager/yb-voyager/src/datafile"
+	"github.com/yugabyte/yb-voyager/yb-voyager/src/tgtdb"
+	"github.com/yugabyte/yb-voyager/yb-voyager/src/utils"
+)
+
+/*
+When --max-rejected-rows-per-table is set, a batch which fails to import is imported again in the
+error-tolerant mode: the rows rejected by the target database are isolated by bisecting the batch,
+and the rest of the rows of the batch are committed. The rejected rows are recorded along with the
+database error in the export-dir/data/errors/<table>.csv file before the batch is committed.
+If the number of rejected rows of a table exceeds the limit, the import of that table is aborted.
+The pending batches of the aborted table are imported again when the import is resumed.
+*/
+
+var maxRejectedRowsPerTable int64
+
+var rejectedRowsFileHeader = []string{"data_file", "row", "error"}
+
+type rejectedRowsRecorder struct {
+	sync.Mutex
+	tableName       string
+	filePath        string
+	numRejectedRows int64
+	aborted         bool
+}
+
+var (
+	rejectedRowsRecordersMutex sync.Mutex
+	rejectedRowsRecorders      = make(map[string]*rejectedRowsRecorder)
+)
+
+func getRejectedRowsFilePath(tableName string) string {
+	return filepath.Join(exportDir, "data", "errors", fmt.Sprintf("%s.csv", tableName))
+}
+
+func getRejectedRowsRecorder(tableName string) (*rejectedRowsRecorder, error) {
+	rejectedRowsRecordersMutex.Lock()
+	defer rejectedRowsRecordersMutex.Unlock()
+	recorder, ok := rejectedRowsRecorders[tableName]
+	if ok {
+		return recorder, nil
+	}
+	recorder = &rejectedRowsRecorder{
+		tableName: tableName,
+		filePath:  getRejectedRowsFilePath(tableName),
+	}
+	// The rows rejected in the previous runs of the import count against the limit.
+	numRejectedRows, err := countRejectedRows(recorder.filePath)
+	if err != nil {
+		return nil, err
+	}
+	recorder.numRejectedRows = numRejectedRows
+	rejectedRowsRecorders[tableName] = recorder
+	return recorder, nil
+}
+
+func countRejectedRows(filePath string) (int64, error) {
+	if !utils.FileOrFolderExists(filePath) {
+		return 0, nil
+	}
+	file, err := os.Open(filePath)
+	if err != nil {
+		return 0, fmt.Errorf("open %q: %w", filePath, err)
+	}
+	defer file.Close()
+	reader := csv.NewReader(file)
+	var count int64
+	for {
+		_, err = reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, fmt.Errorf("read %q: %w", filePath, err)
+		}
+		count++
+	}
+	if count > 0 {
+		count-- // Header.
+	}
+	return count, nil
+}
+
+func (r *rejectedRowsRecorder) isAborted() bool {
+	r.Lock()
+	defer r.Unlock()
+	return r.aborted
+}
+
+func (r *rejectedRowsRecorder) abort() {
+	r.Lock()
+	defer r.Unlock()
+	r.aborted = true
+}
+
+func (r *rejectedRowsRecorder) remainingBudget() int64 {
+	r.Lock()
+	defer r.Unlock()
+	return maxRejectedRowsPerTable - r.numRejectedRows
+}
+
+// Appends the rejected rows to the errors file of the table, and syncs it to the disk. Returns false if
+// the limit is exceeded.
+func (r *rejectedRowsRecorder) record(dataFilePath string, rejectedRecords []*tgtdb.RejectedRecord) (bool, error) {
+	r.Lock()
+	defer r.Unlock()
+	if len(rejectedRecords) > 0 {
+		err := os.MkdirAll(filepath.Dir(r.filePath), 0755)
+		if err != nil {
+			return false, fmt.Errorf("create directory %q: %w", filepath.Dir(r.filePath), err)
+		}
+		writeHeader := !utils.FileOrFolderExists(r.filePath)
+		file, err := os.OpenFile(r.filePath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+		if err != nil {
+			return false, fmt.Errorf("open %q: %w", r.filePath, err)
+		}
+		defer file.Close()
+		writer := csv.NewWriter(file)
+		if writeHeader {
+			err = writer.Write(rejectedRowsFileHeader)
+			if err != nil {
+				return false, fmt.Errorf("write header to %q: %w", r.filePath, err)
+			}
+		}
+		for _, rejectedRecord := range rejectedRecords {
+			err = writer.Write([]string{dataFilePath, rejectedRecord.Record, rejectedRecord.Error})
+			if err != nil {
+				return false, fmt.Errorf("write rejected row to %q: %w", r.filePath, err)
+			}
+		}
+		writer.Flush()
+		if writer.Error() != nil {
+			return false, fmt.Errorf("flush %q: %w", r.filePath, writer.Error())
+		}
+		// The batch is committed only after the rejected rows are durably recorded.
+		err = file.Sync()
+		if err != nil {
+			return false, fmt.Errorf("sync %q: %w", r.filePath, err)
+		}
+		r.numRejectedRows += int64(len(rejectedRecords))
+	}
+	if r.numRejectedRows > maxRejectedRowsPerTable {
+		r.aborted = true
+	}
+	return !r.aborted, nil
+}
+
+// Imports the batch in the error-tolerant mode. Returns true if the table is aborted as the limit on the
+// number of rejected rows is exceeded; the batch remains pending in that case.
+func importBatchIsolatingRejectedRows(batch *Batch, importBatchArgs *tgtdb.ImportBatchArgs) (int64, bool, error) {
+	recorder, err := getRejectedRowsRecorder(batch.TableName)
+	if err != nil {
+		return 0, false, err
+	}
+	records, err := readBatchRecords(batch, importBatchArgs)
+	if err != nil {
+		return 0, false, err
+	}
+	log.Infof("importing %d records of %q isolating the rejected rows", len(records), batch.FilePath)
+	withinBudget := true
+	recordRejectedRecords := func(rejectedRecords []*tgtdb.RejectedRecord) error {
+		var err error
+		withinBudget, err = recorder.record(batch.BaseFilePath, rejectedRecords)
+		return err
+	}
+	rowsAffected, err := tdb.ImportBatchIsolatingErrors(batch, importBatchArgs, records, recorder.remainingBudget(), recordRejectedRecords)
+	if errors.Is(err, tgtdb.ErrTooManyRejectedRecords) {
+		recorder.abort()
+		return 0, true, nil
+	} else if err != nil {
+		return 0, false, err
+	}
+	return rowsAffected, !withinBudget, nil
+}
+
+func readBatchRecords(batch *Batch, importBatchArgs *tgtdb.ImportBatchArgs) ([]string, error) {
+	file, err := batch.Open()
+	if err != nil {
+		return nil, fmt.Errorf("open %q: %w", batch.FilePath, err)
+	}
+	descriptor := *dataFileDescriptor
+	descriptor.FileFormat = importBatchArgs.FileFormat
+	dataFile, err := datafile.NewDataFile(batch.FilePath, file, &descriptor)
+	if err != nil {
+		return nil, fmt.Errorf("open datafile %q: %w", batch.FilePath, err)
+	}
+	defer dataFile.Close()
+	if importBatchArgs.HasHeader {
+		dataFile.GetHeader()
+	}
+	var records []string
+	for {
+		line, err := dataFile.NextLine()
+		if line != "" {
+			records = append(records, line)
+		}
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("read line from %q: %w", batch.FilePath, err)
+		}
+	}
+	return records, nil
+}
+
+func isTableImportAborted(tableName string) bool {
+	rejectedRowsRecordersMutex.Lock()
+	recorder, ok := rejectedRowsRecorders[tableName]
+	rejectedRowsRecordersMutex.Unlock()
+	return ok && recorder.isAborted()
+}
+
+func getAbortedTables() []string {
+	rejectedRowsRecordersMutex.Lock()
+	defer rejectedRowsRecordersMutex.Unlock()
+	var tables []string
+	for tableName, recorder := range rejectedRowsRecorders {
+		if recorder.isAborted() {
+			tables = append(tables, tableName)
+		}
+	}
+	sort.Strings(tables)
+	return tables
+}
+
+func reportRejectedRows() {
+	rejectedRowsRecordersMutex.Lock()
+	defer rejectedRowsRecordersMutex.Unlock()
+	tableNames := make([]string, 0, len(rejectedRowsRecorders))
+	for tableName := range rejectedRowsRecorders {
+		tableNames = append(tableNames, tableName)
+	}
+	sort.Strings(tableNames)
+	for _, tableName := range tableNames {
+		recorder := rejectedRowsRecorders[tableName]
+		if recorder.numRejectedRows > 0 {
+			utils.PrintAndLog("%d rows of table %s are rejected. Refer %q", recorder.numRejectedRows, tableName, recorder.filePath)
+		}
+	}
+}
+
+func cleanRejectedRowsFiles(tableNames []string) {
+	for _, tableName := range tableNames {
+		filePath := getRejectedRowsFilePath(tableName)
+		err := os.RemoveAll(filePath)
+		if err != nil {
+			utils.ErrExit("failed to remove rejected rows file %q: %s", filePath, err)
+		}
+	}
+}
+
+func validateMaxRejectedRowsPerTableFlag() error {
+	if maxRejectedRowsPerTable < 0 {
+		return fmt.Errorf("--max-rejected-rows-per-table must be a non-negative number")
+	}
+	return nil
+}
@@ -130,16 +130,13 @@ func (s *ImportDataState) GetFileImportState(filePath, tableName string) (FileIm
 	return FILE_IMPORT_IN_PROGRESS, nil
 }
 
-func (s *ImportDataState) Recover(filePath, tableName string) ([]*Batch, int64, int64, bool, error) {
-	var pendingBatches []*Batch
-
-	lastBatchNumber := int64(0)
-	lastOffset := int64(0)
-	fileFullySplit := false
-
+// Recover returns the pending batches of the file along with the number and the offset_end of the last
+// batch. lastByteOffset is the byte offset in the file from where the next batch starts. As the batches
+// are split sequentially, it is the total of the bytes read for all the batches so far.
+func (s *ImportDataState) Recover(filePath, tableName string) (pendingBatches []*Batch, lastBatchNumber, lastOffset, lastByteOffset int64, fileFullySplit bool, err error) {
 	batches, err := s.GetAllBatches(filePath, tableName)
 	if err != nil {
-		return nil, 0, 0, false, fmt.Errorf("error while getting all batches for %s: %w", tableName, err)
+		return nil, 0, 0, 0, false, fmt.Errorf("error while getting all batches for %s: %w", tableName, err)
 	}
 	for _, batch := range batches {
 		/*
@@ -156,11 +153,12 @@ func (s *ImportDataState) Recover(filePath, tableName string) ([]*Batch, int64,
 		if batch.OffsetEnd > lastOffset {
 			lastOffset = batch.OffsetEnd
 		}
+		lastByteOffset += batch.ByteCount
 		if !batch.IsDone() {
 			pendingBatches = append(pendingBatches, batch)
 		}
 	}
-	return pendingBatches, lastBatchNumber, lastOffset, fileFullySplit, nil
+	return pendingBatches, lastBatchNumber, lastOffset, lastByteOffset, fileFullySplit, nil
 }
 
 func (s *ImportDataState) Clean(filePath string, tableName string) error {
@@ -587,6 +585,7 @@ type BatchWriter struct {
 	batchNumber int64
 
 	NumRecordsWritten      int64
+	NumBytesWritten        int64
 	flagFirstRecordWritten bool
 
 	outFile *os.File
@@ -630,6 +629,7 @@ func (bw *BatchWriter) WriteRecord(record string) error {
 		return fmt.Errorf("write record to %q: %s", bw.outFile.Name(), err)
 	}
 	bw.NumRecordsWritten++
+	bw.NumBytesWritten += int64(len(record)) + 1
 	bw.flagFirstRecordWritten = true
 	return nil
 }
@@ -0,0 +1,163 @@
+/*
+Copyright (c) YugabyteDB, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/yugabyte/yb-voyager/yb-voyager/src/datafile"
+	"github.com/yugabyte/yb-voyager/yb-voyager/src/tgtdb"
+	"github.com/yugabyte/yb-voyager/yb-voyager/src/transform"
+	"github.com/yugabyte/yb-voyager/yb-voyager/src/utils"
+)
+
+var transformationsFilePath string
+
+// nil if no transformations file is given.
+var transformer *transform.Transformer
+
+func registerTransformationsFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&transformationsFilePath, "transformations-file", "",
+		"path of a JSON file with the transformations to apply to the columns of the imported rows, both for the snapshot and the changes. "+
+			"Supported actions: hash, redact, constant, regex_replace and drop. For example:\n"+
+			`{"rules": [{"table": "public.users", "column": "email", "action": "hash"}, {"table": "users", "column": "notes", "action": "drop"}]}`)
+}
+
+func loadTransformations() {
+	if transformationsFilePath == "" {
+		return
+	}
+	var err error
+	transformer, err = transform.NewTransformerFromFile(transformationsFilePath)
+	if err != nil {
+		utils.ErrExit("load transformations: %v", err)
+	}
+	utils.PrintAndLog("applying the transformations from %q to the imported data", transformationsFilePath)
+}
+
+// getRowTransformer returns the transformer of the rows of the data files of the table, nil if there are no transformations for it.
+func getRowTransformer(tableName string) *transform.RowTransformer {
+	rt, err := transformer.ForTable(tableName).ForColumns(TableToColumnNames[tableName])
+	if err != nil {
+		utils.ErrExit("prepare transformations of table %q: %v", tableName, err)
+	}
+	return rt
+}
+
+func transformLine(rt *transform.RowTransformer, line string) (string, error) {
+	values, err := datafile.ParseLine(line, dataFileDescriptor)
+	if err != nil {
+		return "", err
+	}
+	values, err = rt.TransformRow(values)
+	if err != nil {
+		return "", err
+	}
+	return datafile.FormatLine(values, dataFileDescriptor), nil
+}
+
+func transformHeader(rt *transform.RowTransformer, header string) string {
+	return strings.Join(rt.DropColumns(strings.Split(header, dataFileDescriptor.Delimiter)), dataFileDescriptor.Delimiter)
+}
+
+func getEventTableTransformer(event *tgtdb.Event) *transform.TableTransformer {
+	tableName := event.TableName
+	if event.SchemaName != "" {
+		tableName = event.SchemaName + "." + event.TableName
+	}
+	return transformer.ForTable(tableName)
+}
+
+// Returns true if the event updates only the dropped columns, in which case there is nothing to apply.
+func isEventFullyDropped(event *tgtdb.Event) bool {
+	if event.Op != "u" {
+		return false
+	}
+	dropped := getEventTableTransformer(event).DropsAllColumns(event.Fields)
+	if dropped {
+		log.Debugf("skipping event %v as it updates only the dropped columns", event.Vsn)
+	}
+	return dropped
+}
+
+func transformEvent(tt *transform.TableTransformer, event *tgtdb.Event) error {
+	err := tt.TransformMap(event.Key, true)
+	if err != nil {
+		return fmt.Errorf("transform key of event(vsn=%d): %w", event.Vsn, err)
+	}
+	err = tt.TransformMap(event.Fields, false)
+	if err != nil {
+		return fmt.Errorf("transform fields of event(vsn=%d): %w", event.Vsn, err)
+	}
+	return nil
+}
+
+// convertAndTransformEvent runs the values of the event through the value converter and the transformations.
+// The transformations must see the same unformatted values as in the snapshot, so when the values are formatted
+// as SQL literals, the transformed columns are converted without formatting and their results are formatted
+// as string literals afterwards.
+func convertAndTransformEvent(event *tgtdb.Event, tableName string, formatIfRequired bool) error {
+	// The converter renames the schema and the table as per the target, the rules match the names in the source.
+	tt := getEventTableTransformer(event)
+	if tt == nil || !formatIfRequired {
+		err := valueConverter.ConvertEvent(event, tableName, formatIfRequired)
+		if err != nil {
+			return fmt.Errorf("error transforming event key fields: %v", err)
+		}
+		return transformEvent(tt, event)
+	}
+
+	unformattedEvent := event.Copy()
+	err := valueConverter.ConvertEvent(event, tableName, true)
+	if err != nil {
+		return fmt.Errorf("error transforming event key fields: %v", err)
+	}
+	err = valueConverter.ConvertEvent(unformattedEvent, tableName, false)
+	if err != nil {
+		return fmt.Errorf("error transforming event key fields: %v", err)
+	}
+	err = transformEvent(tt, unformattedEvent)
+	if err != nil {
+		return err
+	}
+	replaceTransformedValues(tt, event.Key, unformattedEvent.Key)
+	replaceTransformedValues(tt, event.Fields, unformattedEvent.Fields)
+	return nil
+}
+
+func replaceTransformedValues(tt *transform.TableTransformer, m map[string]*string, transformed map[string]*string) {
+	for column := range m {
+		value, ok := transformed[column]
+		if !ok { // dropped
+			delete(m, column)
+		} else if tt.TransformsColumn(column) {
+			m[column] = quoteTransformedValue(value)
+		}
+	}
+}
+
+// The results of the transformations are text, which the target casts to the type of the column.
+func quoteTransformedValue(value *string) *string {
+	if value == nil {
+		return nil
+	}
+	quotedValue := "'" + strings.ReplaceAll(*value, "'", "''") + "'"
+	return &quotedValue
+}
@@ -24,12 +24,14 @@ import (
 
 	"github.com/fatih/color"
 	"github.com/jackc/pgx/v4"
+	"github.com/samber/lo"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"golang.org/x/exp/slices"
 
 	"github.com/yugabyte/yb-voyager/yb-voyager/src/callhome"
 	"github.com/yugabyte/yb-voyager/yb-voyager/src/cp"
+	"github.com/yugabyte/yb-voyager/yb-voyager/src/queryparser"
 	"github.com/yugabyte/yb-voyager/yb-voyager/src/srcdb"
 	"github.com/yugabyte/yb-voyager/yb-voyager/src/tgtdb"
 	"github.com/yugabyte/yb-voyager/yb-voyager/src/utils"
@@ -52,6 +54,12 @@ var importSchemaCmd = &cobra.Command{
 		if err != nil {
 			utils.ErrExit("Error: %s", err.Error())
 		}
+		if flagRollback && (flagPostSnapshotImport || startClean) {
+			utils.ErrExit("Error: --rollback cannot be used with --post-snapshot-import or --start-clean")
+		}
+		if flagRetryFailed && (flagRollback || startClean) {
+			utils.ErrExit("Error: --retry-failed cannot be used with --rollback or --start-clean")
+		}
 	},
 
 	Run: func(cmd *cobra.Command, args []string) {
@@ -80,6 +88,24 @@ func importSchema() {
 	}
 	tconf.Schema = strings.ToLower(tconf.Schema)
 
+	if flagRollback {
+		rollbackImportedSchema()
+		return
+	}
+
+	if startClean {
+		err = metaDB.ResetSchemaStmtStatuses()
+		if err != nil {
+			utils.ErrExit("reset the status of the schema statements in the meta db: %s", err)
+		}
+	}
+	loadSchemaStmtStatuses()
+	if flagRetryFailed {
+		retryFailedSqlStmts()
+		dumpFailedSqlStmts()
+		return
+	}
+
 	if flagPostSnapshotImport {
 		tdb = tgtdb.NewTargetDB(&tconf)
 		err = tdb.Init()
@@ -152,24 +178,36 @@ func importSchema() {
 		}
 		return false
 	}
-	skipFn := isSkipStatement
-	importSchemaInternal(exportDir, objectList, skipFn)
+	if !flagPostSnapshotImport && !importObjectsInStraightOrder {
+		// The dependencies between the objects take care of importing the foreign keys after the
+		// unique indexes and the sequences before the tables using them. Only split the indexes.
+		importSchemaInDependencyOrder(exportDir, objectList, func(objType, stmt string) bool {
+			return objType == "UNIQUE INDEX" && isSkipStatement(objType, stmt)
+		})
+	} else if flagPostSnapshotImport {
+		indexObjectList := lo.Filter(objectList, func(objType string, _ int) bool { return isIndexObjectType(objType) })
+		importIndexesInParallel(exportDir, indexObjectList, isSkipStatement)
+		importSchemaInternal(exportDir, utils.SetDifference(objectList, indexObjectList), isSkipStatement)
+	} else {
+		skipFn := isSkipStatement
+		importSchemaInternal(exportDir, objectList, skipFn)
 
-	// Import the skipped ALTER TABLE statements from sequence.sql and table.sql if it exists
-	skipFn = func(objType, stmt string) bool {
-		return !isSkipStatement(objType, stmt)
-	}
-	if slices.Contains(objectList, "SEQUENCE") {
-		importSchemaInternal(exportDir, []string{"SEQUENCE"}, skipFn)
-	}
-	if slices.Contains(objectList, "TABLE") {
-		importSchemaInternal(exportDir, []string{"TABLE"}, skipFn)
+		// Import the skipped ALTER TABLE statements from sequence.sql and table.sql if it exists
+		skipFn = func(objType, stmt string) bool {
+			return !isSkipStatement(objType, stmt)
+		}
+		if slices.Contains(objectList, "SEQUENCE") {
+			importSchemaInternal(exportDir, []string{"SEQUENCE"}, skipFn)
+		}
+		if slices.Contains(objectList, "TABLE") {
+			importSchemaInternal(exportDir, []string{"TABLE"}, skipFn)
+		}
 	}
 
 	importDefferedStatements()
 	log.Info("Schema import is complete.")
 
-	dumpStatements(failedSqlStmts, filepath.Join(exportDir, "schema", "failed.sql"))
+	dumpFailedSqlStmts()
 
 	if flagPostSnapshotImport {
 		if flagRefreshMViews {
@@ -283,7 +321,7 @@ func createTargetSchemas(conn *pgx.Conn) {
 		source = srcdb.Source{DBType: sourceDBType}
 		targetSchemas = append(targetSchemas, tconf.Schema)
 		targetSchemas = append(targetSchemas, utils.GetObjectNameListFromReport(analyzeSchemaInternal(), "PACKAGE")...)
-	case "mysql":
+	case "mysql", "sqlite":
 		source = srcdb.Source{DBType: sourceDBType}
 		targetSchemas = append(targetSchemas, tconf.Schema)
 
@@ -325,6 +363,10 @@ func createTargetSchemas(conn *pgx.Conn) {
 			if err != nil {
 				utils.ErrExit("Failed to create %q schema in the target DB: %s", tconf.Schema, err)
 			}
+			parseTree, err := queryparser.Parse(createSchemaQuery)
+			if err == nil {
+				recordImportedSchemaObject(parseTree)
+			}
 		}
 
 		if tconf.Schema == YUGABYTEDB_DEFAULT_SCHEMA &&
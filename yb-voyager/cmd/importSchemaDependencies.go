@@ -0,0 +1,228 @@
+/*
+Copyright (c) YugabyteDB, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/jackc/pgx/v4"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/yugabyte/yb-voyager/yb-voyager/src/depgraph"
+	"github.com/yugabyte/yb-voyager/yb-voyager/src/metadb"
+	"github.com/yugabyte/yb-voyager/yb-voyager/src/utils"
+)
+
+// schemaDDLStmt is a DDL statement of one of the exported schema files.
+type schemaDDLStmt struct {
+	objType string
+	sqlInfo sqlInfo
+}
+
+func (stmt schemaDDLStmt) String() string {
+	fileName := utils.GetObjectFileName(filepath.Join(exportDir, "schema"), stmt.objType)
+	line := 0
+	if len(stmt.sqlInfo.lineNumbers) > 0 {
+		line = stmt.sqlInfo.lineNumbers[0]
+	}
+	return fmt.Sprintf("%s:%d: %s", fileName, line, utils.GetSqlStmtToPrint(strings.TrimSpace(stmt.sqlInfo.stmt)))
+}
+
+/*
+Import the DDLs of the object types in the order of the dependencies between the objects they
+create and refer to, rather than in the order of the object types. The statements not depending
+on each other are run in parallel, on --parallel-jobs connections.
+
+Before running the first DDL of an object type, a connection runs the SET and SELECT statements
+setting up the session from the file of that object type.
+*/
+func importSchemaInDependencyOrder(exportDir string, importObjectList []string, skipFn func(string, string) bool) {
+	ddlStmts, sessionStmts, graph := readSchemaDDLStmts(exportDir, importObjectList, skipFn)
+	if len(ddlStmts) == 0 {
+		return
+	}
+	checkDependencyCycles(ddlStmts, graph)
+	utils.PrintAndLog("Importing %d DDL statements in the order of their dependencies, using %d parallel jobs\n", len(ddlStmts), getSchemaImportParallelism())
+	err := executeSchemaDDLStmts(ddlStmts, sessionStmts, graph, nil, nil)
+	if err != nil {
+		exitOnSchemaStmtError(err)
+	}
+}
+
+func getSchemaImportParallelism() int {
+	if tconf.Parallelism < 1 {
+		return 1
+	}
+	return tconf.Parallelism
+}
+
+func checkDependencyCycles(ddlStmts []schemaDDLStmt, graph *depgraph.Graph) {
+	cycles := graph.Cycles()
+	if len(cycles) > 0 {
+		msg := getDependencyCyclesMsg(ddlStmts, cycles)
+		if !tconf.ContinueOnError {
+			utils.ErrExit("%s\nBreak the cycles by editing the schema files, or use --continue-on-error to import the rest of the schema.", msg)
+		}
+		color.Red("%s\n", msg)
+	}
+}
+
+/*
+executeSchemaDDLStmts runs the DDLs on parallel connections, each one after the DDLs it depends on.
+If not nil, onStart and onDone are called from the workers before and after running a DDL.
+Unless --continue-on-error is given, no more DDLs are started after one fails, and its error is returned.
+The DDLs deferred because of a missing object, and the ones depending on them, are left to
+importDefferedStatements.
+*/
+func executeSchemaDDLStmts(ddlStmts []schemaDDLStmt, sessionStmts map[string][]sqlInfo, graph *depgraph.Graph,
+	onStart func(i int), onDone func(i int, err error)) error {
+	parallelism := getSchemaImportParallelism()
+	conns := make([]*pgx.Conn, parallelism)
+	sessionObjTypes := make([]string, parallelism)
+	deferred := make([]bool, len(ddlStmts))
+	blocked, err := graph.Run(parallelism, func(worker int, i int) error {
+		stmt := ddlStmts[i]
+		if isUnsupportedDDL(stmt.objType, stmt.sqlInfo.stmt) {
+			log.Infof("Skipping DDL: %s", stmt.sqlInfo.stmt)
+			updateSchemaStmtStatus(getSchemaStmtKey(stmt.objType, stmt.sqlInfo.formattedStmt), metadb.SCHEMA_STMT_SKIPPED, "")
+			return nil
+		}
+		if conns[worker] == nil {
+			conns[worker] = newTargetConn()
+			sessionObjTypes[worker] = ""
+		}
+		if sessionObjTypes[worker] != stmt.objType {
+			for _, sessionStmt := range sessionStmts[stmt.objType] {
+				err := executeSqlStmtWithRetries(&conns[worker], sessionStmt, stmt.objType)
+				if isSchemaImportStopped(err) {
+					return err
+				}
+			}
+			sessionObjTypes[worker] = stmt.objType
+		}
+		if onStart != nil {
+			onStart(i)
+		}
+		err := executeSqlStmtWithRetries(&conns[worker], stmt.sqlInfo, stmt.objType)
+		if onDone != nil {
+			onDone(i, err)
+		}
+		if err != nil {
+			conns[worker].Close(context.Background())
+			conns[worker] = nil
+		}
+		if missingRequiredSchemaObject(err) {
+			// Already appended to defferedSqlStmts. Keep the DDLs depending on it blocked.
+			deferred[i] = true
+			return depgraph.ErrNotRun
+		}
+		if isSchemaImportStopped(err) {
+			return err
+		}
+		return nil
+	})
+	for _, conn := range conns {
+		if conn != nil {
+			conn.Close(context.Background())
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, i := range blocked {
+		if deferred[i] {
+			continue
+		}
+		if dependsOnDeferredStmt(graph, i, deferred, map[int]bool{}) {
+			log.Infof("deffering execution of the DDL depending on a deffered one: %s", ddlStmts[i])
+			importSchemaStmtsMutex.Lock()
+			defferedSqlStmts = append(defferedSqlStmts, ddlStmts[i])
+			importSchemaStmtsMutex.Unlock()
+			continue
+		}
+		// Only with --continue-on-error. Leave the statements, which were never run, to the user.
+		log.Infof("not importing the DDL in a dependency cycle: %s", ddlStmts[i])
+		markSchemaStmtFailed(ddlStmts[i].objType, ddlStmts[i].sqlInfo, "not imported because of a dependency cycle between the schema objects")
+	}
+	return nil
+}
+
+// dependsOnDeferredStmt returns true if the DDL depends, directly or not, on a deferred DDL.
+func dependsOnDeferredStmt(graph *depgraph.Graph, i int, deferred []bool, visited map[int]bool) bool {
+	for _, dep := range graph.Dependencies(i) {
+		if visited[dep] {
+			continue
+		}
+		visited[dep] = true
+		if deferred[dep] || dependsOnDeferredStmt(graph, dep, deferred, visited) {
+			return true
+		}
+	}
+	return false
+}
+
+// readSchemaDDLStmts returns the DDLs of the schema files of the object types, the statements setting
+// up the session for each object type and the graph of the dependencies between the DDLs.
+func readSchemaDDLStmts(exportDir string, importObjectList []string,
+	skipFn func(string, string) bool) ([]schemaDDLStmt, map[string][]sqlInfo, *depgraph.Graph) {
+	schemaDir := filepath.Join(exportDir, "schema")
+	var ddlStmts []schemaDDLStmt
+	var graphStmts []depgraph.Statement
+	sessionStmts := make(map[string][]sqlInfo)
+	for group, objType := range importObjectList {
+		filePath := utils.GetObjectFilePath(schemaDir, objType)
+		if !utils.FileOrFolderExists(filePath) {
+			continue
+		}
+		for _, sqlInfo := range createSqlStrInfoArray(filePath, objType) {
+			if isSetOrSelectStmt(sqlInfo.stmt) {
+				sessionStmts[objType] = append(sessionStmts[objType], sqlInfo)
+				continue
+			}
+			if skipFn != nil && skipFn(objType, sqlInfo.stmt) {
+				continue
+			}
+			ddlStmts = append(ddlStmts, schemaDDLStmt{objType: objType, sqlInfo: sqlInfo})
+			graphStmts = append(graphStmts, depgraph.Statement{Group: group, ParseTree: sqlInfo.parseTree})
+		}
+	}
+
+	graph := depgraph.New(graphStmts)
+	for i := range ddlStmts {
+		for _, dep := range graph.Dependencies(i) {
+			log.Debugf("%s depends on %s", ddlStmts[i], ddlStmts[dep])
+		}
+	}
+	return ddlStmts, sessionStmts, graph
+}
+
+func getDependencyCyclesMsg(ddlStmts []schemaDDLStmt, cycles [][]int) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Found %d dependency cycle(s) between the schema objects. Every DDL of a cycle needs an object created by the next one:\n", len(cycles)))
+	for n, cycle := range cycles {
+		sb.WriteString(fmt.Sprintf("Cycle %d:\n", n+1))
+		for _, i := range cycle {
+			sb.WriteString(fmt.Sprintf("\t%s\n", ddlStmts[i]))
+		}
+		sb.WriteString(fmt.Sprintf("\t%s\n", ddlStmts[cycle[0]]))
+	}
+	return sb.String()
+}
@@ -0,0 +1,142 @@
+/*
+Copyright (c) YugabyteDB, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/vbauerster/mpb/v8"
+	"github.com/vbauerster/mpb/v8/decor"
+	"golang.org/x/exp/slices"
+
+	"github.com/yugabyte/yb-voyager/yb-voyager/src/utils"
+)
+
+var indexObjectTypes = []string{"INDEX", "FTS_INDEX", "PARTITION_INDEX"}
+
+/*
+importIndexesInParallel creates the indexes of the post snapshot import on --parallel-jobs connections.
+The indexes of a table are created one after the other, in the order of the schema files, and the
+ALTER INDEX statements run after the index they change.
+*/
+func importIndexesInParallel(exportDir string, importObjectList []string, skipFn func(string, string) bool) {
+	ddlStmts, sessionStmts, graph := readSchemaDDLStmts(exportDir, importObjectList, skipFn)
+	if len(ddlStmts) == 0 {
+		return
+	}
+	checkDependencyCycles(ddlStmts, graph)
+
+	// Fetch the invalid indexes, left by a previous run, before the workers look them up.
+	var err error
+	invalidTargetIndexesCache, err = tdb.InvalidIndexes()
+	if err != nil {
+		utils.ErrExit("failed to fetch invalid indexes: %s", err)
+	}
+
+	utils.PrintAndLog("Creating %d indexes using %d parallel jobs\n", len(ddlStmts), getSchemaImportParallelism())
+	progressReporter := newIndexProgressReporter(bool(disablePb))
+	err = executeSchemaDDLStmts(ddlStmts, sessionStmts, graph,
+		func(i int) {
+			progressReporter.indexCreationStarted(i, getIndexDisplayName(ddlStmts[i]))
+		},
+		func(i int, err error) {
+			progressReporter.indexCreationDone(i, err)
+		})
+	progressReporter.wait()
+	if err != nil {
+		exitOnSchemaStmtError(err)
+	}
+}
+
+func isIndexObjectType(objType string) bool {
+	return slices.Contains(indexObjectTypes, objType)
+}
+
+func getIndexDisplayName(stmt schemaDDLStmt) string {
+	if stmt.sqlInfo.objName == "" {
+		return stmt.String()
+	}
+	name, err := getIndexName(stmt.sqlInfo.stmt, stmt.sqlInfo.objName)
+	if err != nil {
+		return stmt.sqlInfo.objName
+	}
+	return name
+}
+
+// indexProgressReporter shows a progress bar, with the elapsed time, for each index being created.
+type indexProgressReporter struct {
+	sync.Mutex
+	disablePb    bool
+	progress     *mpb.Progress
+	progressBars map[int]*mpb.Bar
+}
+
+func newIndexProgressReporter(disablePb bool) *indexProgressReporter {
+	pr := &indexProgressReporter{
+		disablePb:    disablePb,
+		progressBars: make(map[int]*mpb.Bar),
+	}
+	if !disablePb {
+		pr.progress = mpb.New()
+	}
+	return pr
+}
+
+func (pr *indexProgressReporter) indexCreationStarted(i int, indexName string) {
+	pr.Lock()
+	defer pr.Unlock()
+
+	log.Infof("creating index %s", indexName)
+	if pr.disablePb {
+		fmt.Printf("creating index %s ...\n", indexName)
+		return
+	}
+	bar := pr.progress.AddSpinner(1,
+		mpb.BarFillerClearOnComplete(),
+		mpb.BarRemoveOnComplete(),
+		mpb.PrependDecorators(
+			decor.Name(indexName),
+		),
+		mpb.AppendDecorators(
+			decor.Elapsed(decor.ET_STYLE_GO, decor.WCSyncSpaceR),
+		),
+	)
+	pr.progressBars[i] = bar
+}
+
+func (pr *indexProgressReporter) indexCreationDone(i int, err error) {
+	pr.Lock()
+	defer pr.Unlock()
+
+	if pr.disablePb {
+		return
+	}
+	bar := pr.progressBars[i]
+	if err != nil {
+		bar.Abort(true)
+	} else {
+		bar.SetCurrent(1)
+	}
+	delete(pr.progressBars, i)
+}
+
+func (pr *indexProgressReporter) wait() {
+	if pr.progress != nil {
+		pr.progress.Wait()
+	}
+}
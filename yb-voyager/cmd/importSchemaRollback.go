@@ -0,0 +1,300 @@
+/*
+Copyright (c) YugabyteDB, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+	pg_query "github.com/pganalyze/pg_query_go/v5"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/yugabyte/yb-voyager/yb-voyager/src/metadb"
+	"github.com/yugabyte/yb-voyager/yb-voyager/src/queryparser"
+	"github.com/yugabyte/yb-voyager/yb-voyager/src/utils"
+)
+
+var flagRollback utils.BoolStr
+
+// recordImportedSchemaObject records in the metaDB the object created on the target by the DDL, so
+// that `import schema --rollback` can drop it. It must be called only once the DDL has succeeded.
+func recordImportedSchemaObject(parseTree []*pg_query.RawStmt) {
+	objType, objName, dropStmt, ok := getDropStmt(parseTree)
+	if !ok {
+		return
+	}
+	if objType == "CONSTRAINT" {
+		// The constraints added to the tables which already existed on the target are left to the user,
+		// like the tables themselves.
+		table := queryparser.RangeVarName(parseTree[0].Stmt.GetAlterTableStmt().Relation)
+		imported, err := metaDB.IsImportedSchemaObject("TABLE", table)
+		if err != nil {
+			utils.ErrExit("check if the table %s was created by import schema: %s", table, err)
+		}
+		if !imported {
+			log.Infof("not recording the constraints %s of the table not created by import schema", objName)
+			return
+		}
+	}
+	err := metaDB.InsertImportedSchemaObject(metadb.ImportedSchemaObject{
+		ObjectType: objType,
+		ObjectName: objName,
+		DropStmt:   dropStmt,
+	})
+	if err != nil {
+		utils.ErrExit("record the imported %s %s in the meta db: %s", objType, objName, err)
+	}
+}
+
+/*
+getDropStmt returns the statement dropping the object created by the DDL, along with the type and the
+name of the object. ok is false for the DDLs which do not create an object, and for those which might
+have kept an object already existing on the target: CREATE ... IF NOT EXISTS and CREATE OR REPLACE.
+
+ALTER TABLE ... ADD CONSTRAINT is rolled back by dropping the constraint before the table, so that the
+foreign keys referencing the other tables are dropped first.
+*/
+func getDropStmt(parseTree []*pg_query.RawStmt) (objType string, objName string, dropStmt string, ok bool) {
+	if len(parseTree) != 1 {
+		return "", "", "", false
+	}
+	stmt := parseTree[0].Stmt
+	var removeType pg_query.ObjectType
+	var object *pg_query.Node
+	switch {
+	case stmt.GetCreateSchemaStmt() != nil && !stmt.GetCreateSchemaStmt().IfNotExists:
+		removeType, object = pg_query.ObjectType_OBJECT_SCHEMA, pg_query.MakeStrNode(stmt.GetCreateSchemaStmt().Schemaname)
+	case stmt.GetCreateExtensionStmt() != nil && !stmt.GetCreateExtensionStmt().IfNotExists:
+		removeType, object = pg_query.ObjectType_OBJECT_EXTENSION, pg_query.MakeStrNode(stmt.GetCreateExtensionStmt().Extname)
+	case stmt.GetCreateStmt() != nil && !stmt.GetCreateStmt().IfNotExists:
+		removeType, object = pg_query.ObjectType_OBJECT_TABLE, makeRangeVarNameList(stmt.GetCreateStmt().Relation)
+	case stmt.GetCreateForeignTableStmt() != nil && !stmt.GetCreateForeignTableStmt().BaseStmt.IfNotExists:
+		removeType, object = pg_query.ObjectType_OBJECT_FOREIGN_TABLE, makeRangeVarNameList(stmt.GetCreateForeignTableStmt().BaseStmt.Relation)
+	case stmt.GetViewStmt() != nil && !stmt.GetViewStmt().Replace:
+		removeType, object = pg_query.ObjectType_OBJECT_VIEW, makeRangeVarNameList(stmt.GetViewStmt().View)
+	case stmt.GetCreateTableAsStmt() != nil && !stmt.GetCreateTableAsStmt().IfNotExists:
+		removeType, object = pg_query.ObjectType_OBJECT_TABLE, makeRangeVarNameList(stmt.GetCreateTableAsStmt().Into.Rel)
+		if stmt.GetCreateTableAsStmt().Objtype == pg_query.ObjectType_OBJECT_MATVIEW {
+			removeType = pg_query.ObjectType_OBJECT_MATVIEW
+		}
+	case stmt.GetCreateSeqStmt() != nil && !stmt.GetCreateSeqStmt().IfNotExists:
+		removeType, object = pg_query.ObjectType_OBJECT_SEQUENCE, makeRangeVarNameList(stmt.GetCreateSeqStmt().Sequence)
+	case stmt.GetIndexStmt() != nil && !stmt.GetIndexStmt().IfNotExists && stmt.GetIndexStmt().Idxname != "":
+		s := stmt.GetIndexStmt()
+		removeType, object = pg_query.ObjectType_OBJECT_INDEX, makeRangeVarNameList(&pg_query.RangeVar{Schemaname: s.Relation.Schemaname, Relname: s.Idxname})
+	case stmt.GetCreateFunctionStmt() != nil && !stmt.GetCreateFunctionStmt().Replace:
+		s := stmt.GetCreateFunctionStmt()
+		removeType, object = pg_query.ObjectType_OBJECT_FUNCTION, makeObjectWithArgs(s.Funcname, s.Parameters)
+		if s.IsProcedure {
+			removeType = pg_query.ObjectType_OBJECT_PROCEDURE
+		}
+	case stmt.GetDefineStmt() != nil && !stmt.GetDefineStmt().Replace && !stmt.GetDefineStmt().IfNotExists:
+		s := stmt.GetDefineStmt()
+		switch s.Kind {
+		case pg_query.ObjectType_OBJECT_AGGREGATE:
+			// The args are the list of the parameters followed by the number of direct arguments.
+			var parameters []*pg_query.Node
+			if len(s.Args) > 0 && s.Args[0].GetList() != nil {
+				parameters = s.Args[0].GetList().Items
+			}
+			removeType, object = s.Kind, makeObjectWithArgs(s.Defnames, parameters)
+		case pg_query.ObjectType_OBJECT_TYPE:
+			removeType, object = s.Kind, makeTypeName(s.Defnames)
+		case pg_query.ObjectType_OBJECT_COLLATION:
+			removeType, object = s.Kind, pg_query.MakeListNode(s.Defnames)
+		}
+	case stmt.GetCreateEnumStmt() != nil:
+		removeType, object = pg_query.ObjectType_OBJECT_TYPE, makeTypeName(stmt.GetCreateEnumStmt().TypeName)
+	case stmt.GetCreateRangeStmt() != nil:
+		removeType, object = pg_query.ObjectType_OBJECT_TYPE, makeTypeName(stmt.GetCreateRangeStmt().TypeName)
+	case stmt.GetCompositeTypeStmt() != nil:
+		removeType, object = pg_query.ObjectType_OBJECT_TYPE, makeTypeName(makeRangeVarNameList(stmt.GetCompositeTypeStmt().Typevar).GetList().Items)
+	case stmt.GetCreateDomainStmt() != nil:
+		removeType, object = pg_query.ObjectType_OBJECT_DOMAIN, makeTypeName(stmt.GetCreateDomainStmt().Domainname)
+	case stmt.GetCreateTrigStmt() != nil && !stmt.GetCreateTrigStmt().Replace:
+		s := stmt.GetCreateTrigStmt()
+		removeType, object = pg_query.ObjectType_OBJECT_TRIGGER, makeNameOnTable(s.Relation, s.Trigname)
+	case stmt.GetRuleStmt() != nil && !stmt.GetRuleStmt().Replace:
+		s := stmt.GetRuleStmt()
+		removeType, object = pg_query.ObjectType_OBJECT_RULE, makeNameOnTable(s.Relation, s.Rulename)
+	case stmt.GetAlterTableStmt() != nil:
+		return getDropConstraintsStmt(stmt.GetAlterTableStmt())
+	}
+	if object == nil {
+		return "", "", "", false
+	}
+
+	drop := &pg_query.DropStmt{
+		Objects:    []*pg_query.Node{object},
+		RemoveType: removeType,
+		Behavior:   pg_query.DropBehavior_DROP_RESTRICT,
+		MissingOk:  true,
+	}
+	dropStmt, err := deparseStmt(&pg_query.Node{Node: &pg_query.Node_DropStmt{DropStmt: drop}})
+	if err != nil {
+		log.Warnf("not recording the object created by the DDL: deparse the DROP statement: %v", err)
+		return "", "", "", false
+	}
+	return strings.TrimPrefix(removeType.String(), "OBJECT_"), queryparser.ObjectName(object), dropStmt, true
+}
+
+// getDropConstraintsStmt returns the statement dropping the constraints added by ALTER TABLE, if the
+// statement does nothing else.
+func getDropConstraintsStmt(s *pg_query.AlterTableStmt) (objType string, objName string, dropStmt string, ok bool) {
+	if s.Objtype != pg_query.ObjectType_OBJECT_TABLE || len(s.Cmds) == 0 {
+		return "", "", "", false
+	}
+	drop := &pg_query.AlterTableStmt{
+		// Drop the constraint from the partitions as well.
+		Relation: &pg_query.RangeVar{Schemaname: s.Relation.Schemaname, Relname: s.Relation.Relname, Inh: true, Relpersistence: "p"},
+		Objtype:  pg_query.ObjectType_OBJECT_TABLE,
+	}
+	var constraintNames []string
+	for _, cmd := range s.Cmds {
+		alterTableCmd := cmd.GetAlterTableCmd()
+		constraint := alterTableCmd.GetDef().GetConstraint()
+		if alterTableCmd.GetSubtype() != pg_query.AlterTableType_AT_AddConstraint || constraint == nil || constraint.Conname == "" {
+			return "", "", "", false
+		}
+		drop.Cmds = append(drop.Cmds, &pg_query.Node{Node: &pg_query.Node_AlterTableCmd{AlterTableCmd: &pg_query.AlterTableCmd{
+			Subtype:   pg_query.AlterTableType_AT_DropConstraint,
+			Name:      constraint.Conname,
+			Behavior:  pg_query.DropBehavior_DROP_RESTRICT,
+			MissingOk: true,
+		}}})
+		constraintNames = append(constraintNames, queryparser.QuoteIdentifier(constraint.Conname))
+	}
+	dropStmt, err := deparseStmt(&pg_query.Node{Node: &pg_query.Node_AlterTableStmt{AlterTableStmt: drop}})
+	if err != nil {
+		log.Warnf("not recording the constraints added by the DDL: deparse the DROP CONSTRAINT statement: %v", err)
+		return "", "", "", false
+	}
+	objName = fmt.Sprintf("%s ON %s", strings.Join(constraintNames, ", "), queryparser.RangeVarName(s.Relation))
+	return "CONSTRAINT", objName, dropStmt, true
+}
+
+func deparseStmt(stmt *pg_query.Node) (string, error) {
+	return pg_query.Deparse(&pg_query.ParseResult{Stmts: []*pg_query.RawStmt{{Stmt: stmt}}})
+}
+
+func makeRangeVarNameList(rv *pg_query.RangeVar) *pg_query.Node {
+	var names []*pg_query.Node
+	if rv.Schemaname != "" {
+		names = append(names, pg_query.MakeStrNode(rv.Schemaname))
+	}
+	names = append(names, pg_query.MakeStrNode(rv.Relname))
+	return pg_query.MakeListNode(names)
+}
+
+// makeNameOnTable returns the name of a trigger or a rule, which is [schema,] table, name.
+func makeNameOnTable(rv *pg_query.RangeVar, name string) *pg_query.Node {
+	names := makeRangeVarNameList(rv).GetList().Items
+	return pg_query.MakeListNode(append(names, pg_query.MakeStrNode(name)))
+}
+
+func makeTypeName(names []*pg_query.Node) *pg_query.Node {
+	return &pg_query.Node{Node: &pg_query.Node_TypeName{TypeName: &pg_query.TypeName{Names: names, Typemod: -1}}}
+}
+
+// makeObjectWithArgs returns the signature of the function, made of the types of its input parameters.
+func makeObjectWithArgs(names []*pg_query.Node, parameters []*pg_query.Node) *pg_query.Node {
+	objectWithArgs := &pg_query.ObjectWithArgs{Objname: names}
+	for _, parameter := range parameters {
+		p := parameter.GetFunctionParameter()
+		if p == nil || p.Mode == pg_query.FunctionParameterMode_FUNC_PARAM_OUT || p.Mode == pg_query.FunctionParameterMode_FUNC_PARAM_TABLE {
+			continue
+		}
+		objectWithArgs.Objargs = append(objectWithArgs.Objargs, &pg_query.Node{Node: &pg_query.Node_TypeName{TypeName: p.ArgType}})
+	}
+	return &pg_query.Node{Node: &pg_query.Node_ObjectWithArgs{ObjectWithArgs: objectWithArgs}}
+}
+
+// rollbackImportedSchema drops the objects created on the target by import schema, in the reverse
+// order of their creation, so that every object is dropped before the objects it depends on.
+func rollbackImportedSchema() {
+	objs, err := metaDB.GetImportedSchemaObjects()
+	if err != nil {
+		utils.ErrExit("get the imported schema objects from the meta db: %s", err)
+	}
+	if len(objs) == 0 {
+		utils.PrintAndLog("No schema objects imported by import schema to roll back.")
+		return
+	}
+	if !utils.AskPrompt(fmt.Sprintf("do you really want to drop the %d schema objects created by import schema in the target database", len(objs))) {
+		utils.ErrExit("User selected not to roll back the imported schema. Exiting.")
+	}
+
+	conn := newTargetConn()
+	defer func() {
+		if conn != nil {
+			conn.Close(context.Background())
+		}
+	}()
+	numFailed, stopped := dropImportedSchemaObjects(objs, func(dropStmt string) error {
+		log.Infof("On %s run query:\n%s\n", tconf.Host, dropStmt)
+		_, err := conn.Exec(context.Background(), dropStmt)
+		if err != nil {
+			conn.Close(context.Background())
+			conn = newTargetConn()
+		}
+		return err
+	})
+	if stopped {
+		utils.ErrExit("Fix the error and run `import schema --rollback` again to drop the remaining objects.")
+	}
+	if numFailed > 0 {
+		utils.PrintAndLog("\nCould not drop %d schema objects. Run `import schema --rollback` again to drop them.", numFailed)
+		return
+	}
+	utils.PrintAndLog("\nDropped all the %d schema objects created by import schema.", len(objs))
+}
+
+// dropImportedSchemaObjects drops the objects in the reverse order of their creation, and returns the
+// number of objects which could not be dropped, and whether it stopped at the first failure. The status of the DDLs is reset once any object is
+// dropped, so that the next run of import schema executes them again instead of skipping them as done.
+func dropImportedSchemaObjects(objs []metadb.ImportedSchemaObject, execDropStmt func(dropStmt string) error) (numFailed int, stopped bool) {
+	numDropped := 0
+	defer func() {
+		if numDropped == 0 {
+			return
+		}
+		err := metaDB.ResetSchemaStmtStatuses()
+		if err != nil {
+			utils.ErrExit("reset the status of the schema statements in the meta db: %s", err)
+		}
+	}()
+	for i := len(objs) - 1; i >= 0; i-- {
+		obj := objs[i]
+		err := execDropStmt(obj.DropStmt)
+		if err != nil {
+			color.Red("drop %s %s: %s\n", obj.ObjectType, obj.ObjectName, err)
+			if !tconf.ContinueOnError {
+				return numFailed, true
+			}
+			numFailed++
+			continue
+		}
+		utils.PrintAndLog("%s\n", obj.DropStmt)
+		err = metaDB.DeleteImportedSchemaObject(obj.SeqNo)
+		if err != nil {
+			utils.ErrExit("delete the dropped %s %s from the meta db: %s", obj.ObjectType, obj.ObjectName, err)
+		}
+		numDropped++
+	}
+	return numFailed, false
+}
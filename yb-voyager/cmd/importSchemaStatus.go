@@ -0,0 +1,211 @@
+/*
+Copyright (c) YugabyteDB, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/exp/slices"
+
+	"github.com/yugabyte/yb-voyager/yb-voyager/src/metadb"
+	"github.com/yugabyte/yb-voyager/yb-voyager/src/queryparser"
+	"github.com/yugabyte/yb-voyager/yb-voyager/src/utils"
+)
+
+var flagRetryFailed utils.BoolStr
+
+// schemaStmtKey identifies a DDL statement of the exported schema files across the runs of import schema.
+type schemaStmtKey struct {
+	// Path of the schema file relative to the schema dir, e.g. tables/table.sql.
+	fileName string
+	// SHA-256 of the text of the statement.
+	stmtHash string
+}
+
+// Status of the DDLs in the previous runs of import schema. Loaded at the start of import schema and
+// not updated afterwards.
+var prevSchemaStmtStatuses map[schemaStmtKey]string
+
+func getSchemaStmtKey(objType string, stmt string) schemaStmtKey {
+	schemaDir := filepath.Join(exportDir, "schema")
+	fileName, err := filepath.Rel(schemaDir, utils.GetObjectFilePath(schemaDir, objType))
+	if err != nil {
+		utils.ErrExit("get the path of the %s file in %q: %s", objType, schemaDir, err)
+	}
+	hash := sha256.Sum256([]byte(strings.TrimSpace(stmt)))
+	return schemaStmtKey{fileName: fileName, stmtHash: hex.EncodeToString(hash[:])}
+}
+
+func loadSchemaStmtStatuses() {
+	statuses, err := metaDB.GetSchemaStmtStatuses()
+	if err != nil {
+		utils.ErrExit("get the status of the schema statements from the meta db: %s", err)
+	}
+	prevSchemaStmtStatuses = make(map[schemaStmtKey]string)
+	for _, status := range statuses {
+		prevSchemaStmtStatuses[schemaStmtKey{fileName: status.FileName, stmtHash: status.StmtHash}] = status.Status
+	}
+}
+
+// isSchemaStmtImported returns true if a previous run of import schema has executed the DDL, or
+// skipped it because the object already existed.
+func isSchemaStmtImported(objType string, sqlInfo sqlInfo) bool {
+	status := prevSchemaStmtStatuses[getSchemaStmtKey(objType, sqlInfo.formattedStmt)]
+	return status == metadb.SCHEMA_STMT_DONE || status == metadb.SCHEMA_STMT_SKIPPED
+}
+
+func updateSchemaStmtStatus(key schemaStmtKey, status string, errMsg string) {
+	err := metaDB.UpdateSchemaStmtStatus(metadb.SchemaStmtStatus{
+		FileName: key.fileName,
+		StmtHash: key.stmtHash,
+		Status:   status,
+		Error:    errMsg,
+	})
+	if err != nil {
+		utils.ErrExit("update the status of the statement of %s in the meta db: %s", key.fileName, err)
+	}
+}
+
+// markSchemaStmtFailed records the failure of the DDL and adds it to the statements to be dumped in
+// schema/failed.sql.
+func markSchemaStmtFailed(objType string, sqlInfo sqlInfo, errMsg string) {
+	key := getSchemaStmtKey(objType, sqlInfo.formattedStmt)
+	updateSchemaStmtStatus(key, metadb.SCHEMA_STMT_FAILED, errMsg)
+	importSchemaStmtsMutex.Lock()
+	defer importSchemaStmtsMutex.Unlock()
+	failedSqlStmts = append(failedSqlStmts, formatFailedSqlStmt(key, errMsg, sqlInfo.formattedStmt))
+}
+
+// dumpFailedSqlStmts writes the failed statements to schema/failed.sql. The workers of the parallel DDLs
+// may still be adding to them.
+func dumpFailedSqlStmts() {
+	importSchemaStmtsMutex.Lock()
+	stmts := slices.Clone(failedSqlStmts)
+	importSchemaStmtsMutex.Unlock()
+	dumpStatements(stmts, filepath.Join(exportDir, "schema", "failed.sql"))
+}
+
+/*
+A failed statement is dumped in schema/failed.sql with a comment giving the statement it came from and
+the error, like:
+
+	/*
+	file: tables/table.sql
+	hash: 1f2e...
+	ERROR: relation "public.orders" does not exist (SQLSTATE 42P01)
+	*\/
+	ALTER TABLE ONLY public.order_items ADD CONSTRAINT ...;
+
+The statement can be edited in the file, and executed again with `import schema --retry-failed`.
+*/
+func formatFailedSqlStmt(key schemaStmtKey, errMsg string, stmt string) string {
+	return fmt.Sprintf("/*\nfile: %s\nhash: %s\n%s\n*/\n%s", key.fileName, key.stmtHash, errMsg, stmt)
+}
+
+var failedSqlStmtHeaderRegex = regexp.MustCompile(`(?m)^/\*\nfile: (.+)\nhash: ([0-9a-f]+)\n`)
+
+type failedSqlStmt struct {
+	key  schemaStmtKey
+	err  string
+	stmt string
+}
+
+func parseFailedSqlStmts(text string) ([]failedSqlStmt, error) {
+	headers := failedSqlStmtHeaderRegex.FindAllStringSubmatchIndex(text, -1)
+	if len(headers) == 0 && strings.TrimSpace(text) != "" {
+		return nil, fmt.Errorf("no statements found with the file and the hash they come from")
+	}
+	var stmts []failedSqlStmt
+	for i, header := range headers {
+		end := len(text)
+		if i+1 < len(headers) {
+			end = headers[i+1][0]
+		}
+		entry := text[header[1]:end]
+		commentEnd := strings.Index(entry, "\n*/\n")
+		if commentEnd < 0 {
+			return nil, fmt.Errorf("comment not terminated for the statement of %s", text[header[2]:header[3]])
+		}
+		stmts = append(stmts, failedSqlStmt{
+			key:  schemaStmtKey{fileName: text[header[2]:header[3]], stmtHash: text[header[4]:header[5]]},
+			err:  entry[:commentEnd],
+			stmt: strings.TrimSpace(entry[commentEnd+len("\n*/\n"):]),
+		})
+	}
+	return stmts, nil
+}
+
+/*
+retryFailedSqlStmts executes again the statements of schema/failed.sql, possibly edited by the user since.
+As a failed statement may need another one, the statements still failing are retried as long as some
+statement succeeds. The statements which still fail are dumped again in schema/failed.sql.
+*/
+func retryFailedSqlStmts() {
+	filePath := filepath.Join(exportDir, "schema", "failed.sql")
+	if !utils.FileOrFolderExists(filePath) {
+		utils.PrintAndLog("No failed statements to retry: %q does not exist.", filePath)
+		return
+	}
+	text, err := os.ReadFile(filePath)
+	if err != nil {
+		utils.ErrExit("read %q: %s", filePath, err)
+	}
+	pending, err := parseFailedSqlStmts(string(text))
+	if err != nil {
+		utils.ErrExit("parse %q: %s", filePath, err)
+	}
+	utils.PrintAndLog("Retrying %d failed statements from %q\n", len(pending), filePath)
+
+	conn := newTargetConn()
+	defer func() { conn.Close(context.Background()) }()
+	for len(pending) > 0 {
+		var stillFailing []failedSqlStmt
+		for _, stmt := range pending {
+			_, err := conn.Exec(context.Background(), stmt.stmt)
+			if err != nil {
+				log.Infof("failed retry of failed stmt: %s\n%v", utils.GetSqlStmtToPrint(stmt.stmt), err)
+				stmt.err = err.Error()
+				stillFailing = append(stillFailing, stmt)
+				conn.Close(context.Background())
+				conn = newTargetConn()
+				continue
+			}
+			utils.PrintAndLog("%s: %s\n", filepath.Base(stmt.key.fileName), utils.GetSqlStmtToPrint(stmt.stmt))
+			updateSchemaStmtStatus(stmt.key, metadb.SCHEMA_STMT_DONE, "")
+			parseTree, err := queryparser.Parse(stmt.stmt)
+			if err == nil {
+				recordImportedSchemaObject(parseTree)
+			}
+		}
+		if len(stillFailing) == len(pending) {
+			break
+		}
+		pending = stillFailing
+	}
+
+	for _, stmt := range pending {
+		updateSchemaStmtStatus(stmt.key, metadb.SCHEMA_STMT_FAILED, stmt.err)
+		failedSqlStmts = append(failedSqlStmts, formatFailedSqlStmt(stmt.key, stmt.err, stmt.stmt))
+	}
+}
@@ -19,15 +19,20 @@ import (
 	"context"
 	"path/filepath"
 	"strings"
+	"sync"
 
 	log "github.com/sirupsen/logrus"
+	"github.com/yugabyte/yb-voyager/yb-voyager/src/metadb"
 	"github.com/yugabyte/yb-voyager/yb-voyager/src/utils"
 	"golang.org/x/exp/slices"
 )
 
-var defferedSqlStmts []sqlInfo
+var defferedSqlStmts []schemaDDLStmt
 var failedSqlStmts []string
 
+// Guards defferedSqlStmts and failedSqlStmts, which the DDLs run in parallel append to.
+var importSchemaStmtsMutex sync.Mutex
+
 func importSchemaInternal(exportDir string, importObjectList []string,
 	skipFn func(string, string) bool) {
 	schemaDir := filepath.Join(exportDir, "schema")
@@ -62,18 +67,20 @@ func importDefferedStatements() {
 	// max loop iterations to remove all errors
 	for i := 1; i <= maxIterations && len(defferedSqlStmts) > 0; i++ {
 		for j := 0; j < len(defferedSqlStmts); {
-			_, err = conn.Exec(context.Background(), defferedSqlStmts[j].formattedStmt)
+			stmt := defferedSqlStmts[j]
+			_, err = conn.Exec(context.Background(), stmt.sqlInfo.formattedStmt)
 			if err == nil {
-				utils.PrintAndLog("%s\n", utils.GetSqlStmtToPrint(defferedSqlStmts[j].stmt))
+				utils.PrintAndLog("%s\n", utils.GetSqlStmtToPrint(stmt.sqlInfo.stmt))
+				updateSchemaStmtStatus(getSchemaStmtKey(stmt.objType, stmt.sqlInfo.formattedStmt), metadb.SCHEMA_STMT_DONE, "")
+				recordImportedSchemaObject(stmt.sqlInfo.parseTree)
 				// removing successfully executed SQL
 				defferedSqlStmts = append(defferedSqlStmts[:j], defferedSqlStmts[j+1:]...)
 				break // no increment in j
 			} else {
-				log.Infof("failed retry of deffered stmt: %s\n%v", utils.GetSqlStmtToPrint(defferedSqlStmts[j].stmt), err)
+				log.Infof("failed retry of deffered stmt: %s\n%v", utils.GetSqlStmtToPrint(stmt.sqlInfo.stmt), err)
 				// fails to execute in final attempt
 				if i == maxIterations {
-					errString := "/*\n" + err.Error() + "\n*/\n"
-					failedSqlStmts = append(failedSqlStmts, errString+defferedSqlStmts[j].formattedStmt)
+					markSchemaStmtFailed(stmt.objType, stmt.sqlInfo, err.Error())
 				}
 				conn.Close(context.Background())
 				conn = newTargetConn()
@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yugabyte/yb-voyager/yb-voyager/src/tgtdb"
+)
+
+func TestRejectedRowsRecorder(t *testing.T) {
+	assert := assert.New(t)
+	exportDir = t.TempDir()
+	maxRejectedRowsPerTable = 3
+
+	recorder, err := getRejectedRowsRecorder("public.foo")
+	assert.NoError(err)
+	withinBudget, err := recorder.record("foo_data.csv", []*tgtdb.RejectedRecord{
+		{Record: "1,\"a,b\"\n", Error: `invalid input syntax for type integer: "x"`},
+		{Record: "2,c", Error: "duplicate key value violates unique constraint"},
+	})
+	assert.NoError(err)
+	assert.True(withinBudget)
+	assert.Equal(int64(1), recorder.remainingBudget())
+
+	// The rows rejected earlier are counted when the import is resumed.
+	numRejectedRows, err := countRejectedRows(getRejectedRowsFilePath("public.foo"))
+	assert.NoError(err)
+	assert.Equal(int64(2), numRejectedRows)
+
+	withinBudget, err = recorder.record("foo_data.csv", []*tgtdb.RejectedRecord{
+		{Record: "3,d", Error: "value too long"},
+		{Record: "4,e", Error: "value too long"},
+	})
+	assert.NoError(err)
+	assert.False(withinBudget)
+	assert.True(isTableImportAborted("public.foo"))
+	assert.Equal([]string{"public.foo"}, getAbortedTables())
+}
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yugabyte/yb-voyager/yb-voyager/src/datafile"
+	"github.com/yugabyte/yb-voyager/yb-voyager/src/tgtdb"
+	tgtdbsuite "github.com/yugabyte/yb-voyager/yb-voyager/src/tgtdb/suites"
+	"github.com/yugabyte/yb-voyager/yb-voyager/src/transform"
+)
+
+// Formats the values of the events as string literals, like the value converter does for the STRING columns.
+type quotingValueConverter struct{}
+
+func (c *quotingValueConverter) ConvertRow(tableName string, columnNames []string, row string) (string, error) {
+	return row, nil
+}
+
+func (c *quotingValueConverter) ConvertEvent(ev *tgtdb.Event, table string, formatIfRequired bool) error {
+	for _, m := range []map[string]*string{ev.Key, ev.Fields} {
+		for column, value := range m {
+			convertedValue, err := tgtdbsuite.YBValueConverterSuite["STRING"](*value, formatIfRequired, nil)
+			if err != nil {
+				return err
+			}
+			m[column] = &convertedValue
+		}
+	}
+	return nil
+}
+
+func (c *quotingValueConverter) GetTableNameToSchema() map[string]map[string]map[string]string {
+	return nil
+}
+
+func TestTransformationsOfSnapshotAndChangesMatch(t *testing.T) {
+	assert := assert.New(t)
+	var err error
+	transformer, err = transform.NewTransformer(&transform.Config{Rules: []*transform.Rule{
+		{Table: "public.users", Column: "email", Action: transform.HASH, Salt: "s3cret"},
+		{Table: "users", Column: "name", Action: transform.REDACT},
+		{Table: "users", Column: "phone", Action: transform.REGEX_REPLACE, Pattern: "[0-9]", Replacement: "#"},
+		{Table: "users", Column: "country", Action: transform.CONSTANT, Value: lo.ToPtr("IN")},
+		{Table: "users", Column: "notes", Action: transform.DROP},
+	}})
+	assert.NoError(err)
+	valueConverter = &quotingValueConverter{}
+	dataFileDescriptor = &datafile.Descriptor{FileFormat: datafile.TEXT, Delimiter: "\t", NullString: `\N`}
+	tconf.TargetDBType = YUGABYTEDB
+	defer func() {
+		tconf.TargetDBType = ""
+		transformer = nil
+		valueConverter = nil
+		dataFileDescriptor = nil
+	}()
+
+	columns := []string{"id", "email", "name", "phone", "country", "notes"}
+	values := []string{"1", "o'brien@example.com", "O'Brien", "555-1234", "US", "hello"}
+
+	rt, err := transformer.ForTable("public.users").ForColumns(columns)
+	assert.NoError(err)
+	line, err := transformLine(rt, strings.Join(values, "\t"))
+	assert.NoError(err)
+	snapshotValues := strings.Split(line, "\t")
+	assert.Equal(columns[:5], rt.ColumnNames())
+
+	event := &tgtdb.Event{Op: "u", SchemaName: "public", TableName: "users", Key: map[string]*string{}, Fields: map[string]*string{}}
+	event.Key["id"] = lo.ToPtr(values[0])
+	for i := 1; i < len(columns); i++ {
+		event.Fields[columns[i]] = lo.ToPtr(values[i])
+	}
+	assert.True(shouldFormatValues(event))
+	err = convertAndTransformEvent(event, "users", shouldFormatValues(event))
+	assert.NoError(err)
+
+	assert.Equal("'1'", *event.Key["id"])
+	assert.Len(event.Fields, 4)
+	for i := 1; i < len(snapshotValues); i++ {
+		expected := "'" + strings.ReplaceAll(snapshotValues[i], "'", "''") + "'"
+		assert.Equal(expected, *event.Fields[columns[i]], columns[i])
+	}
+}
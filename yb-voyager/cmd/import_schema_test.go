@@ -0,0 +1,311 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yugabyte/yb-voyager/yb-voyager/src/metadb"
+	"github.com/yugabyte/yb-voyager/yb-voyager/src/queryparser"
+	"github.com/yugabyte/yb-voyager/yb-voyager/src/utils"
+)
+
+const pgDumpSessionStmts = `SET statement_timeout = 0;
+SET client_encoding = 'UTF8';
+SELECT pg_catalog.set_config('search_path', '', false);
+
+`
+
+func writeSchemaFile(t *testing.T, schemaDir string, objType string, content string) {
+	path := utils.GetObjectFilePath(schemaDir, objType)
+	assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	assert.NoError(t, os.WriteFile(path, []byte(pgDumpSessionStmts+content), 0644))
+}
+
+func TestImportSchemaDependencyOrder(t *testing.T) {
+	assert := assert.New(t)
+	exportDir = t.TempDir()
+	schemaDir := filepath.Join(exportDir, "schema")
+	writeSchemaFile(t, schemaDir, "SCHEMA", `CREATE SCHEMA sales;
+`)
+	writeSchemaFile(t, schemaDir, "SEQUENCE", `CREATE SEQUENCE sales.orders_id_seq
+    START WITH 1
+    INCREMENT BY 1;
+
+ALTER SEQUENCE sales.orders_id_seq OWNED BY sales.orders.id;
+
+ALTER TABLE ONLY sales.orders ALTER COLUMN id SET DEFAULT nextval('sales.orders_id_seq'::regclass);
+`)
+	writeSchemaFile(t, schemaDir, "TABLE", `CREATE TABLE sales.customers (
+    id integer NOT NULL,
+    region text DEFAULT sales.default_region()
+);
+
+CREATE TABLE sales.orders (
+    id integer NOT NULL,
+    customer_id integer
+);
+
+ALTER TABLE ONLY sales.orders REPLICA IDENTITY FULL;
+
+ALTER TABLE ONLY sales.orders
+    ADD CONSTRAINT orders_customer_fkey FOREIGN KEY (customer_id) REFERENCES sales.customers(id);
+`)
+	writeSchemaFile(t, schemaDir, "INDEX", `CREATE UNIQUE INDEX customers_id_idx ON sales.customers USING btree (id);
+
+CREATE INDEX orders_customer_idx ON sales.orders USING btree (customer_id);
+`)
+	writeSchemaFile(t, schemaDir, "FUNCTION", `CREATE FUNCTION sales.default_region() RETURNS text
+    LANGUAGE sql
+    AS $$ SELECT 'us' $$;
+`)
+	writeSchemaFile(t, schemaDir, "VIEW", `CREATE VIEW sales.customer_orders AS
+ SELECT c.id,
+    o.id AS order_id
+   FROM (sales.customers c
+     JOIN sales.orders o ON ((o.customer_id = c.id)));
+`)
+
+	objectList := []string{"SCHEMA", "SEQUENCE", "TABLE", "FUNCTION", "VIEW", "UNIQUE INDEX"}
+	ddlStmts, sessionStmts, graph := readSchemaDDLStmts(exportDir, objectList, func(objType, stmt string) bool {
+		return objType == "UNIQUE INDEX" && !strings.Contains(strings.ToUpper(stmt), "UNIQUE INDEX")
+	})
+	assert.Len(sessionStmts["TABLE"], 3)
+	// The non-unique index is not imported. The REPLICA IDENTITY is kept, to be skipped on import.
+	assert.Len(ddlStmts, 11)
+	assert.Equal("table.sql:17: ALTER TABLE ONLY sales.orders     ADD CONSTRAINT orders_customer_fkey FOREIGN KE ...", ddlStmts[7].String())
+
+	// The ALTERs of sequence.sql need the table created by table.sql.
+	assert.Equal([]int{0, 1, 5}, graph.Dependencies(3))
+	// The table using the function for a default is created after it.
+	assert.Equal([]int{0, 8}, graph.Dependencies(4))
+	// The foreign key is added after the unique index of the referenced table, and after the other
+	// changes to the table.
+	assert.Equal([]int{0, 3, 4, 5, 6, 10}, graph.Dependencies(7))
+	assert.Equal([]int{0, 4, 5}, graph.Dependencies(9))
+	assert.Empty(graph.Cycles())
+
+	executed := map[int]bool{}
+	blocked, err := graph.Run(1, func(worker int, i int) error {
+		for _, dep := range graph.Dependencies(i) {
+			assert.True(executed[dep], "%s executed before %s", ddlStmts[i], ddlStmts[dep])
+		}
+		executed[i] = true
+		return nil
+	})
+	assert.NoError(err)
+	assert.Empty(blocked)
+	assert.Len(executed, len(ddlStmts))
+
+	// The table, its unique index, the foreign key and the view wait for the function, if it is deferred.
+	deferred := make([]bool, len(ddlStmts))
+	deferred[8] = true
+	var dependents []int
+	for i := range ddlStmts {
+		if dependsOnDeferredStmt(graph, i, deferred, map[int]bool{}) {
+			dependents = append(dependents, i)
+		}
+	}
+	assert.Equal([]int{4, 7, 9, 10}, dependents)
+}
+
+func TestPostSnapshotIndexesOrder(t *testing.T) {
+	assert := assert.New(t)
+	exportDir = t.TempDir()
+	schemaDir := filepath.Join(exportDir, "schema")
+	writeSchemaFile(t, schemaDir, "INDEX", `CREATE UNIQUE INDEX orders_id_idx ON public.orders USING btree (id);
+
+CREATE INDEX orders_customer_idx ON public.orders USING btree (customer_id);
+
+CREATE INDEX customers_name_idx ON public.customers USING btree (name);
+
+CREATE INDEX orders_date_idx ON public.orders USING btree (order_date);
+
+CREATE INDEX events_ts_idx ON ONLY public.events USING btree (ts);
+`)
+	writeSchemaFile(t, schemaDir, "PARTITION_INDEX", `CREATE INDEX events_2024_ts_idx ON public.events_2024 USING btree (ts);
+
+ALTER INDEX public.events_ts_idx ATTACH PARTITION public.events_2024_ts_idx;
+`)
+
+	ddlStmts, _, graph := readSchemaDDLStmts(exportDir, indexObjectTypes, func(objType, stmt string) bool {
+		return strings.Contains(stmt, "UNIQUE INDEX")
+	})
+	assert.Len(ddlStmts, 6)
+	assert.Equal("public.orders_customer_idx", getIndexDisplayName(ddlStmts[0]))
+	// The indexes of a table are created one after the other.
+	assert.Empty(graph.Dependencies(0))
+	assert.Empty(graph.Dependencies(1))
+	assert.Equal([]int{0}, graph.Dependencies(2))
+	assert.Empty(graph.Dependencies(3))
+	assert.Empty(graph.Dependencies(4))
+	// The index of the partition is attached once both the indexes are created.
+	assert.Equal([]int{3, 4}, graph.Dependencies(5))
+}
+
+func TestDependencyCyclesMsg(t *testing.T) {
+	assert := assert.New(t)
+	exportDir = t.TempDir()
+	writeSchemaFile(t, filepath.Join(exportDir, "schema"), "TABLE", `CREATE TABLE a (id int PRIMARY KEY, b_id int REFERENCES b(id));
+
+CREATE TABLE b (id int PRIMARY KEY, a_id int REFERENCES a(id));
+
+CREATE TABLE c (id int);
+`)
+	ddlStmts, _, graph := readSchemaDDLStmts(exportDir, []string{"TABLE"}, nil)
+	assert.Equal([]int{0, 1}, graph.Blocked())
+	assert.Equal(`Found 1 dependency cycle(s) between the schema objects. Every DDL of a cycle needs an object created by the next one:
+Cycle 1:
+	table.sql:5: CREATE TABLE a (id int PRIMARY KEY, b_id int REFERENCES b(id));
+	table.sql:7: CREATE TABLE b (id int PRIMARY KEY, a_id int REFERENCES a(id));
+	table.sql:5: CREATE TABLE a (id int PRIMARY KEY, b_id int REFERENCES b(id));
+`, getDependencyCyclesMsg(ddlStmts, graph.Cycles()))
+}
+
+func TestGetDropStmt(t *testing.T) {
+	assert := assert.New(t)
+	testcases := []struct {
+		ddl      string
+		objType  string
+		objName  string
+		dropStmt string
+	}{
+		{`CREATE SCHEMA sales`, "SCHEMA", "sales", "DROP SCHEMA IF EXISTS sales"},
+		{`CREATE TABLE sales."Orders" (id int)`, "TABLE", `sales."Orders"`, `DROP TABLE IF EXISTS sales."Orders"`},
+		{`CREATE MATERIALIZED VIEW mv AS SELECT 1`, "MATVIEW", "mv", "DROP MATERIALIZED VIEW IF EXISTS mv"},
+		{`CREATE UNIQUE INDEX orders_idx ON sales.orders (id)`, "INDEX", "sales.orders_idx", "DROP INDEX IF EXISTS sales.orders_idx"},
+		{`CREATE FUNCTION sales.f(a int, OUT b text, VARIADIC c int[]) RETURNS text LANGUAGE sql AS 'SELECT 1'`,
+			"FUNCTION", "sales.f", "DROP FUNCTION IF EXISTS sales.f(int, int[])"},
+		{`CREATE PROCEDURE p() LANGUAGE sql AS 'SELECT 1'`, "PROCEDURE", "p", "DROP PROCEDURE IF EXISTS p()"},
+		{`CREATE AGGREGATE sales.total(int) (sfunc = int4pl, stype = int)`, "AGGREGATE", "sales.total", "DROP AGGREGATE IF EXISTS sales.total(int)"},
+		{`CREATE TYPE sales.status AS ENUM ('new')`, "TYPE", "sales.status", "DROP TYPE IF EXISTS sales.status"},
+		{`CREATE TYPE sales.pair AS (a int, b int)`, "TYPE", "sales.pair", "DROP TYPE IF EXISTS sales.pair"},
+		{`CREATE DOMAIN sales.email AS text`, "DOMAIN", "sales.email", "DROP DOMAIN IF EXISTS sales.email"},
+		{`CREATE TRIGGER trg BEFORE INSERT ON sales.orders FOR EACH ROW EXECUTE FUNCTION sales.f()`,
+			"TRIGGER", "sales.orders.trg", "DROP TRIGGER IF EXISTS trg ON sales.orders"},
+		{`ALTER TABLE ONLY sales.orders ADD CONSTRAINT orders_pkey PRIMARY KEY (id), ADD CONSTRAINT orders_fkey FOREIGN KEY (id) REFERENCES t(id)`,
+			"CONSTRAINT", "orders_pkey, orders_fkey ON sales.orders",
+			"ALTER TABLE sales.orders DROP CONSTRAINT IF EXISTS orders_pkey, DROP CONSTRAINT IF EXISTS orders_fkey"},
+	}
+	for _, tc := range testcases {
+		parseTree, err := queryparser.Parse(tc.ddl)
+		assert.NoError(err)
+		objType, objName, dropStmt, ok := getDropStmt(parseTree)
+		assert.True(ok, tc.ddl)
+		assert.Equal(tc.objType, objType, tc.ddl)
+		assert.Equal(tc.objName, objName, tc.ddl)
+		assert.Equal(tc.dropStmt, dropStmt, tc.ddl)
+	}
+
+	// The DDLs which might have kept an existing object, or do not create one.
+	for _, ddl := range []string{
+		`CREATE EXTENSION IF NOT EXISTS pgcrypto`,
+		`CREATE TABLE IF NOT EXISTS t (id int)`,
+		`CREATE OR REPLACE VIEW v AS SELECT 1`,
+		`CREATE OR REPLACE FUNCTION f() RETURNS int LANGUAGE sql AS 'SELECT 1'`,
+		`ALTER TABLE t ADD COLUMN c int`,
+		`ALTER TABLE t ADD PRIMARY KEY (id)`,
+		`COMMENT ON TABLE t IS 'comment'`,
+	} {
+		parseTree, err := queryparser.Parse(ddl)
+		assert.NoError(err)
+		_, _, _, ok := getDropStmt(parseTree)
+		assert.False(ok, ddl)
+	}
+}
+
+func TestRecordImportedConstraints(t *testing.T) {
+	assert := assert.New(t)
+	exportDir := t.TempDir()
+	assert.NoError(os.MkdirAll(filepath.Join(exportDir, "metainfo"), 0755))
+	assert.NoError(metadb.CreateAndInitMetaDBIfRequired(exportDir))
+	var err error
+	metaDB, err = metadb.NewMetaDB(exportDir)
+	assert.NoError(err)
+	defer func() { metaDB = nil }()
+
+	for _, ddl := range []string{
+		`CREATE TABLE sales.orders (id int)`,
+		`ALTER TABLE ONLY sales.orders ADD CONSTRAINT orders_pkey PRIMARY KEY (id)`,
+		// sales.customers already existed on the target, and was skipped with --ignore-exist.
+		`ALTER TABLE ONLY sales.customers ADD CONSTRAINT customers_pkey PRIMARY KEY (id)`,
+	} {
+		parseTree, err := queryparser.Parse(ddl)
+		assert.NoError(err)
+		recordImportedSchemaObject(parseTree)
+	}
+	objs, err := metaDB.GetImportedSchemaObjects()
+	assert.NoError(err)
+	var objNames []string
+	for _, obj := range objs {
+		objNames = append(objNames, obj.ObjectType+" "+obj.ObjectName)
+	}
+	assert.Equal([]string{"TABLE sales.orders", "CONSTRAINT orders_pkey ON sales.orders"}, objNames)
+}
+
+func TestRollbackResetsSchemaStmtStatuses(t *testing.T) {
+	assert := assert.New(t)
+	exportDir = t.TempDir()
+	assert.NoError(os.MkdirAll(filepath.Join(exportDir, "metainfo"), 0755))
+	assert.NoError(metadb.CreateAndInitMetaDBIfRequired(exportDir))
+	var err error
+	metaDB, err = metadb.NewMetaDB(exportDir)
+	assert.NoError(err)
+	defer func() { metaDB = nil }()
+
+	stmt := sqlInfo{formattedStmt: "CREATE TABLE sales.orders (id int);"}
+	parseTree, err := queryparser.Parse(stmt.formattedStmt)
+	assert.NoError(err)
+	recordImportedSchemaObject(parseTree)
+	updateSchemaStmtStatus(getSchemaStmtKey("TABLE", stmt.formattedStmt), metadb.SCHEMA_STMT_DONE, "")
+	loadSchemaStmtStatuses()
+	assert.True(isSchemaStmtImported("TABLE", stmt))
+
+	objs, err := metaDB.GetImportedSchemaObjects()
+	assert.NoError(err)
+	var dropStmts []string
+	numFailed, stopped := dropImportedSchemaObjects(objs, func(dropStmt string) error {
+		dropStmts = append(dropStmts, dropStmt)
+		return nil
+	})
+	assert.Equal(0, numFailed)
+	assert.False(stopped)
+	assert.Equal([]string{"DROP TABLE IF EXISTS sales.orders"}, dropStmts)
+
+	// The next run of import schema creates the table again.
+	loadSchemaStmtStatuses()
+	assert.False(isSchemaStmtImported("TABLE", stmt))
+	objs, err = metaDB.GetImportedSchemaObjects()
+	assert.NoError(err)
+	assert.Empty(objs)
+}
+
+func TestFailedSqlStmts(t *testing.T) {
+	assert := assert.New(t)
+	exportDir = t.TempDir()
+	key := getSchemaStmtKey("TABLE", "ALTER TABLE t ADD CONSTRAINT t_fkey FOREIGN KEY (a) REFERENCES u(a);\n")
+	assert.Equal("tables/table.sql", key.fileName)
+	assert.Equal(key, getSchemaStmtKey("TABLE", "  ALTER TABLE t ADD CONSTRAINT t_fkey FOREIGN KEY (a) REFERENCES u(a);"))
+	assert.NotEqual(key, getSchemaStmtKey("TABLE", "ALTER TABLE t ADD CONSTRAINT t_fkey FOREIGN KEY (b) REFERENCES u(b);"))
+	assert.NotEqual(key.fileName, getSchemaStmtKey("UNIQUE INDEX", "CREATE UNIQUE INDEX i ON t (a);").fileName)
+
+	indexKey := getSchemaStmtKey("INDEX", "CREATE INDEX i ON t (a);")
+	text := formatFailedSqlStmt(key, `ERROR: relation "u" does not exist (SQLSTATE 42P01)`,
+		"ALTER TABLE t ADD CONSTRAINT t_fkey FOREIGN KEY (a) REFERENCES u(a);") + "\n\n" +
+		formatFailedSqlStmt(indexKey, "ERROR: multi-line\nerror", "CREATE INDEX i\n    ON t (a);") + "\n\n"
+	stmts, err := parseFailedSqlStmts(text)
+	assert.NoError(err)
+	assert.Equal([]failedSqlStmt{
+		{key: key, err: `ERROR: relation "u" does not exist (SQLSTATE 42P01)`, stmt: "ALTER TABLE t ADD CONSTRAINT t_fkey FOREIGN KEY (a) REFERENCES u(a);"},
+		{key: indexKey, err: "ERROR: multi-line\nerror", stmt: "CREATE INDEX i\n    ON t (a);"},
+	}, stmts)
+
+	_, err = parseFailedSqlStmts("CREATE INDEX i ON t (a);\n")
+	assert.Error(err)
+	stmts, err = parseFailedSqlStmts("")
+	assert.NoError(err)
+	assert.Empty(stmts)
+}
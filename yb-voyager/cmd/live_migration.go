@@ -207,6 +207,9 @@ func handleEvent(event *tgtdb.Event, evChans []chan *tgtdb.Event) error {
 		return nil
 	}
 	log.Debugf("handling event: %v", event)
+	if isEventFullyDropped(event) {
+		return nil
+	}
 	tableName := event.TableName
 	if sourceDBType == "postgresql" && event.SchemaName != "public" {
 		tableName = event.SchemaName + "." + event.TableName
@@ -239,9 +242,9 @@ func handleEvent(event *tgtdb.Event, evChans []chan *tgtdb.Event) error {
 	}
 
 	// preparing value converters for the streaming mode
-	err := valueConverter.ConvertEvent(event, tableName, shouldFormatValues(event))
+	err := convertAndTransformEvent(event, tableName, shouldFormatValues(event))
 	if err != nil {
-		return fmt.Errorf("error transforming event key fields: %v", err)
+		return err
 	}
 
 	evChans[h] <- event
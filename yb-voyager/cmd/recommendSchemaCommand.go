@@ -0,0 +1,259 @@
+/*
+Copyright (c) YugabyteDB, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/gosuri/uitable"
+	pg_query "github.com/pganalyze/pg_query_go/v5"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/yugabyte/yb-voyager/yb-voyager/src/queryparser"
+	"github.com/yugabyte/yb-voyager/yb-voyager/src/schemaadvisor"
+	"github.com/yugabyte/yb-voyager/yb-voyager/src/utils"
+)
+
+const (
+	SCHEMA_RECOMMENDATIONS_REPORT_FILE_NAME = "schema_recommendations_report"
+	RECOMMENDATION_COMMENT_PREFIX           = "-- yb-voyager recommend schema: "
+)
+
+var (
+	recommendSchemaOutputFormat   string
+	colocationSizeThreshold       int64
+	rewriteDDLWithRecommendations utils.BoolStr
+)
+
+var recommendSchemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Recommend the sharding of the keys and the colocation of the tables of the exported schema in YugabyteDB.",
+	Long: `Recommend HASH or ASC sharding for the primary keys, unique constraints and indexes of the exported schema, flag the indexes which are hotspots for the inserts of increasing timestamps or sequence values, and recommend colocating the small tables.
+The sizes of the tables are read from the report of assess migration, which is to be run first for the colocation recommendations.
+With --rewrite-ddl, the schema files rewritten with the recommendations are saved in the export-dir/reports/recommended_schema directory, leaving the exported schema unchanged.`,
+
+	PreRun: func(cmd *cobra.Command, args []string) {
+		if !schemaIsExported() {
+			utils.ErrExit("Error: schema is not exported yet.")
+		}
+		sourceDBType = GetSourceDBTypeFromMSR()
+		recommendSchemaOutputFormat = strings.ToLower(recommendSchemaOutputFormat)
+		if recommendSchemaOutputFormat != "txt" && recommendSchemaOutputFormat != "json" {
+			utils.ErrExit("Error: Invalid output format: %s. Supported formats are [txt json]", recommendSchemaOutputFormat)
+		}
+		if colocationSizeThreshold < 0 {
+			utils.ErrExit("Error: --colocation-size-threshold must not be negative")
+		}
+	},
+
+	Run: recommendSchemaCommandFn,
+}
+
+type SchemaRecommendationsReport struct {
+	MigrationUUID string `json:"migration_uuid"`
+	GeneratedAt   string `json:"generated_at"`
+	SourceDBType  string `json:"source_db_type"`
+	*schemaadvisor.Recommendations
+}
+
+func recommendSchemaCommandFn(cmd *cobra.Command, args []string) {
+	err := retrieveMigrationUUID()
+	if err != nil {
+		utils.ErrExit("failed to get migration UUID: %w", err)
+	}
+
+	tableSizes := readAssessedTableSizes()
+	advisor := schemaadvisor.NewAdvisor(YUGABYTEDB_DEFAULT_SCHEMA, tableSizes, colocationSizeThreshold*1024*1024)
+	stmts, unparsedStmts := readExportedSchemaStmts()
+	for _, stmt := range unparsedStmts {
+		log.Warnf("no recommendations for the unparsable statement %s: %v", stmt, stmt.sqlInfo.parseErr)
+		color.Yellow("WARNING: no recommendations for the statement %s, which failed to parse: %s\n", stmt, stmt.sqlInfo.parseErr)
+	}
+	advisor.AddDDLs(stmts)
+
+	report := &SchemaRecommendationsReport{
+		MigrationUUID:   migrationUUID.String(),
+		GeneratedAt:     time.Now().Format(time.RFC3339),
+		SourceDBType:    sourceDBType,
+		Recommendations: advisor.Recommend(),
+	}
+	txtReport := generateSchemaRecommendationsTxtReport(report)
+	fmt.Print("\n" + txtReport + "\n")
+	finalReport := txtReport
+	if recommendSchemaOutputFormat == "json" {
+		jsonBytes, err := json.Marshal(report)
+		if err != nil {
+			panic(err)
+		}
+		finalReport = utils.PrettifyJsonString(string(jsonBytes))
+	}
+	reportPath := filepath.Join(exportDir, "reports", SCHEMA_RECOMMENDATIONS_REPORT_FILE_NAME+"."+recommendSchemaOutputFormat)
+	err = os.WriteFile(reportPath, []byte(finalReport), 0644)
+	if err != nil {
+		utils.ErrExit("failed to write report to %q: %s", reportPath, err)
+	}
+	utils.PrintAndLog("schema recommendations report is saved at %q", reportPath)
+
+	if rewriteDDLWithRecommendations {
+		rewriteSchemaWithRecommendations(advisor)
+	}
+}
+
+// readAssessedTableSizes returns the sizes of the tables of the source database found by assess migration,
+// keyed by the names of the tables.
+func readAssessedTableSizes() map[string]int64 {
+	tableSizes := make(map[string]int64)
+	reportPath := filepath.Join(exportDir, "reports", MIGRATION_ASSESSMENT_REPORT_FILE_NAME+".json")
+	if !utils.FileOrFolderExists(reportPath) {
+		utils.PrintAndLog("The sizes of the tables are not known, run assess migration for the colocation recommendations.")
+		return tableSizes
+	}
+	content, err := os.ReadFile(reportPath)
+	if err != nil {
+		utils.ErrExit("read migration assessment report %q: %s", reportPath, err)
+	}
+	var assessmentReport MigrationAssessmentReport
+	err = json.Unmarshal(content, &assessmentReport)
+	if err != nil {
+		utils.ErrExit("parse migration assessment report %q: %s", reportPath, err)
+	}
+	for _, table := range assessmentReport.Tables {
+		if table.Size >= 0 {
+			tableSizes[table.Name] = table.Size
+		}
+	}
+	return tableSizes
+}
+
+// rewriteSchemaWithRecommendations saves the schema files of the tables and the indexes rewritten with the
+// recommendations in the export-dir/reports/recommended_schema directory. The statements not changed by the
+// recommendations are kept as they are.
+func rewriteSchemaWithRecommendations(advisor *schemaadvisor.Advisor) {
+	schemaDir := filepath.Join(exportDir, "schema")
+	recommendedSchemaDir := filepath.Join(exportDir, "reports", "recommended_schema")
+	for _, objType := range []string{"TABLE", "INDEX", "PARTITION_INDEX"} {
+		filePath := utils.GetObjectFilePath(schemaDir, objType)
+		if !utils.FileOrFolderExists(filePath) {
+			continue
+		}
+		sqlInfoArr := createSqlStrInfoArray(filePath, objType)
+		var fixes []indexedSchemaFix
+		for i := range sqlInfoArr {
+			for _, rawStmt := range sqlInfoArr[i].parseTree {
+				fix, ok := getRecommendationFix(advisor, objType, &sqlInfoArr[i], rawStmt)
+				if ok {
+					fixes = append(fixes, indexedSchemaFix{schemaFix: fix, sqlInfoIdx: i})
+				}
+			}
+		}
+		if len(fixes) == 0 {
+			continue
+		}
+		content, err := os.ReadFile(filePath)
+		if err != nil {
+			utils.ErrExit("read %q: %s", filePath, err)
+		}
+		rewritten, _ := getFixedText(string(content), filePath, "", sqlInfoArr, fixes)
+		relPath, err := filepath.Rel(schemaDir, filePath)
+		if err != nil {
+			utils.ErrExit("get path of %q relative to %q: %s", filePath, schemaDir, err)
+		}
+		rewrittenFilePath := filepath.Join(recommendedSchemaDir, relPath)
+		err = os.MkdirAll(filepath.Dir(rewrittenFilePath), 0755)
+		if err != nil {
+			utils.ErrExit("create directory of %q: %s", rewrittenFilePath, err)
+		}
+		err = os.WriteFile(rewrittenFilePath, []byte(rewritten), 0644)
+		if err != nil {
+			utils.ErrExit("write %q: %s", rewrittenFilePath, err)
+		}
+		utils.PrintAndLog("applied %d recommendation(s) to %q, saved at %q", len(fixes), filePath, rewrittenFilePath)
+	}
+}
+
+// getRecommendationFix returns the fix replacing the statement with the statement rewritten by the advisor.
+func getRecommendationFix(advisor *schemaadvisor.Advisor, objType string, sqlInfo *sqlInfo, rawStmt *pg_query.RawStmt) (schemaFix, bool) {
+	rewritten, description := advisor.Rewrite(rawStmt.Stmt)
+	if rewritten == nil {
+		return schemaFix{}, false
+	}
+	deparsed, err := pg_query.Deparse(&pg_query.ParseResult{Stmts: []*pg_query.RawStmt{{Stmt: rewritten}}})
+	if err != nil {
+		log.Infof("deparse %q rewritten with the recommendations: %v", sqlInfo.formattedStmt, err)
+		return schemaFix{}, false
+	}
+	return schemaFix{
+		start:       queryparser.StmtStart(sqlInfo.formattedStmt, rawStmt),
+		end:         getStmtEnd(sqlInfo, rawStmt),
+		replacement: RECOMMENDATION_COMMENT_PREFIX + description + "\n" + deparsed + ";",
+		description: description,
+		objType:     objType,
+		objName:     sqlInfo.objName,
+	}, true
+}
+
+func generateSchemaRecommendationsTxtReport(report *SchemaRecommendationsReport) string {
+	var sb strings.Builder
+	sb.WriteString("Schema Recommendations Report\n")
+	sb.WriteString(fmt.Sprintf("Migration UUID: %s\n", report.MigrationUUID))
+	sb.WriteString(fmt.Sprintf("Generated at: %s\n\n", report.GeneratedAt))
+	if report.ColocatedDatabase {
+		sb.WriteString("Create the target database WITH COLOCATION = true, to colocate the small tables.\n\n")
+	}
+
+	uitbl := uitable.New()
+	uitbl.MaxColWidth = 50
+	uitbl.Wrap = true
+	uitbl.AddRow("TABLE", "SIZE", "COLOCATED", "REASON")
+	for _, rec := range report.Colocation {
+		size := "unknown"
+		if rec.Size >= 0 {
+			size = utils.HumanReadableByteCount(rec.Size)
+		}
+		uitbl.AddRow(rec.TableName, size, rec.Colocated, rec.Reason)
+	}
+	sb.WriteString(uitbl.String())
+	sb.WriteString("\n\n")
+
+	uitbl = uitable.New()
+	uitbl.MaxColWidth = 50
+	uitbl.Wrap = true
+	uitbl.AddRow("OBJECT TYPE", "OBJECT NAME", "TABLE", "LEADING COLUMN", "SHARDING", "RECOMMENDED", "HOTSPOT", "REASON")
+	for _, rec := range report.Sharding {
+		uitbl.AddRow(rec.ObjectType, rec.ObjectName, rec.TableName, rec.LeadingColumn, rec.Sharding, rec.Recommended, rec.Hotspot, rec.Reason)
+	}
+	sb.WriteString(uitbl.String())
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+func init() {
+	recommendCmd.AddCommand(recommendSchemaCmd)
+	registerCommonGlobalFlags(recommendSchemaCmd)
+	recommendSchemaCmd.Flags().StringVar(&recommendSchemaOutputFormat, "output-format", "txt",
+		"format in which the report will be saved: (txt, json)")
+	recommendSchemaCmd.Flags().Int64Var(&colocationSizeThreshold, "colocation-size-threshold", 1024,
+		"size in MB up to which a table is recommended to be colocated")
+	BoolVar(recommendSchemaCmd.Flags(), &rewriteDDLWithRecommendations, "rewrite-ddl", false,
+		"save the schema files rewritten with the recommendations in the export-dir/reports/recommended_schema directory")
+}
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yugabyte/yb-voyager/yb-voyager/src/schemaadvisor"
+	"github.com/yugabyte/yb-voyager/yb-voyager/src/utils"
+)
+
+func TestRewriteSchemaWithRecommendations(t *testing.T) {
+	assert := assert.New(t)
+	exportDir = t.TempDir()
+	sourceDBType = POSTGRESQL
+	schemaDir := filepath.Join(exportDir, "schema")
+	writeSchemaFile(t, schemaDir, "TABLE", `CREATE TABLE public.orders (
+    id integer GENERATED BY DEFAULT AS IDENTITY NOT NULL,
+    created_at timestamp without time zone
+);
+
+CREATE TABLE public.countries (
+    code text NOT NULL
+);
+`)
+	writeSchemaFile(t, schemaDir, "INDEX", `CREATE INDEX orders_created_at_idx ON public.orders USING btree (created_at);
+
+CREATE INDEX orders_id_idx ON public.orders USING btree (id DESC);
+`)
+
+	stmts, unparsedStmts := readExportedSchemaStmts()
+	assert.Empty(unparsedStmts)
+	sizes := map[string]int64{"public.orders": 10 << 30, "public.countries": 1 << 20}
+	advisor := schemaadvisor.NewAdvisor(YUGABYTEDB_DEFAULT_SCHEMA, sizes, 1<<30)
+	advisor.AddDDLs(stmts)
+	advisor.Recommend()
+	rewriteSchemaWithRecommendations(advisor)
+
+	recommendedSchemaDir := filepath.Join(exportDir, "reports", "recommended_schema")
+	content, err := os.ReadFile(utils.GetObjectFilePath(recommendedSchemaDir, "TABLE"))
+	assert.NoError(err)
+	assert.Equal(pgDumpSessionStmts+`-- yb-voyager recommend schema: table not colocated
+CREATE TABLE public.orders (id int GENERATED BY DEFAULT AS IDENTITY NOT NULL, created_at timestamp) WITH (colocation=false);
+
+CREATE TABLE public.countries (
+    code text NOT NULL
+);
+`, string(content))
+	content, err = os.ReadFile(utils.GetObjectFilePath(recommendedSchemaDir, "INDEX"))
+	assert.NoError(err)
+	// The timestamp index is kept HASH sharded, and the DESC index of the identity column is HASH sharded.
+	assert.Equal(pgDumpSessionStmts+`CREATE INDEX orders_created_at_idx ON public.orders USING btree (created_at);
+
+-- yb-voyager recommend schema: HASH sharding of the index
+CREATE INDEX orders_id_idx ON public.orders USING btree (id);
+`, string(content))
+}
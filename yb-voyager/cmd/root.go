@@ -125,6 +125,7 @@ var noLockNeededList = []string{
 	"yb-voyager initiate",
 	"yb-voyager end",
 	"yb-voyager archive",
+	"yb-voyager compare",
 }
 
 var noPersistentPreRunNeededList = []string{
@@ -141,6 +142,7 @@ var noPersistentPreRunNeededList = []string{
 	"yb-voyager cutover",
 	"yb-voyager archive",
 	"yb-voyager end",
+	"yb-voyager compare",
 }
 
 func shouldLock(cmd *cobra.Command) bool {
@@ -0,0 +1,150 @@
+package cdc
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jackc/pglogrepl"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yugabyte/yb-voyager/yb-voyager/src/metadb"
+	"github.com/yugabyte/yb-voyager/yb-voyager/src/tgtdb"
+)
+
+func strPtr(s string) *string {
+	return &s
+}
+
+func textColumn(s string) *pglogrepl.TupleDataColumn {
+	return &pglogrepl.TupleDataColumn{DataType: pglogrepl.TupleDataTypeText, Length: uint32(len(s)), Data: []byte(s)}
+}
+
+func TestPGOutputDecoder(t *testing.T) {
+	assert := assert.New(t)
+	decoder := NewPGOutputDecoder(map[string][]string{"public.foo": {"id"}})
+	// with REPLICA IDENTITY FULL every column is flagged as part of the key.
+	decoder.AddRelation(&pglogrepl.RelationMessage{
+		RelationID: 1, Namespace: "public", RelationName: "foo",
+		Columns: []*pglogrepl.RelationMessageColumn{{Flags: 1, Name: "id"}, {Flags: 1, Name: "name"}, {Flags: 1, Name: "doc"}},
+	})
+	tuple := func(cols ...*pglogrepl.TupleDataColumn) *pglogrepl.TupleData {
+		return &pglogrepl.TupleData{ColumnNum: uint16(len(cols)), Columns: cols}
+	}
+	null := &pglogrepl.TupleDataColumn{DataType: pglogrepl.TupleDataTypeNull}
+	toast := &pglogrepl.TupleDataColumn{DataType: pglogrepl.TupleDataTypeToast}
+
+	events, err := decoder.DecodeChange(&pglogrepl.InsertMessage{RelationID: 1, Tuple: tuple(textColumn("1"), textColumn("a"), null)})
+	assert.NoError(err)
+	assert.Equal([]*tgtdb.Event{{
+		Op: "c", SchemaName: "public", TableName: "foo",
+		Key:    map[string]*string{"id": strPtr("1")},
+		Fields: map[string]*string{"id": strPtr("1"), "name": strPtr("a"), "doc": nil},
+	}}, events)
+
+	// only the changed columns are exported; the unchanged toasted column is left out.
+	events, err = decoder.DecodeChange(&pglogrepl.UpdateMessage{
+		RelationID: 1, OldTupleType: pglogrepl.UpdateMessageTupleTypeOld,
+		OldTuple: tuple(textColumn("1"), textColumn("a"), textColumn("big")),
+		NewTuple: tuple(textColumn("1"), textColumn("b"), toast),
+	})
+	assert.NoError(err)
+	assert.Equal([]*tgtdb.Event{{
+		Op: "u", SchemaName: "public", TableName: "foo",
+		Key:          map[string]*string{"id": strPtr("1")},
+		Fields:       map[string]*string{"name": strPtr("b")},
+		BeforeFields: map[string]*string{"name": strPtr("a")},
+	}}, events)
+
+	// change of the primary key is exported as a delete followed by an insert.
+	events, err = decoder.DecodeChange(&pglogrepl.UpdateMessage{
+		RelationID: 1, OldTupleType: pglogrepl.UpdateMessageTupleTypeOld,
+		OldTuple: tuple(textColumn("1"), textColumn("b"), textColumn("big")),
+		NewTuple: tuple(textColumn("2"), textColumn("b"), toast),
+	})
+	assert.NoError(err)
+	assert.Len(events, 2)
+	assert.Equal("d", events[0].Op)
+	assert.Equal(map[string]*string{"id": strPtr("1")}, events[0].Key)
+	assert.Equal("c", events[1].Op)
+	assert.Equal(map[string]*string{"id": strPtr("2"), "name": strPtr("b"), "doc": strPtr("big")}, events[1].Fields)
+
+	events, err = decoder.DecodeChange(&pglogrepl.DeleteMessage{
+		RelationID: 1, OldTupleType: pglogrepl.DeleteMessageTupleTypeOld,
+		OldTuple: tuple(textColumn("2"), textColumn("b"), null),
+	})
+	assert.NoError(err)
+	assert.Equal([]*tgtdb.Event{{
+		Op: "d", SchemaName: "public", TableName: "foo",
+		Key:          map[string]*string{"id": strPtr("2")},
+		BeforeFields: map[string]*string{"id": strPtr("2"), "name": strPtr("b"), "doc": nil},
+	}}, events)
+
+	_, err = decoder.DecodeChange(&pglogrepl.InsertMessage{RelationID: 2, Tuple: tuple(textColumn("1"))})
+	assert.Error(err)
+}
+
+func TestEventQueueWriterRecovery(t *testing.T) {
+	assert := assert.New(t)
+	exportDir := t.TempDir()
+	assert.NoError(os.MkdirAll(filepath.Join(exportDir, "metainfo"), 0755))
+	assert.NoError(metadb.CreateAndInitMetaDBIfRequired(exportDir))
+	mdb, err := metadb.NewMetaDB(exportDir)
+	assert.NoError(err)
+
+	newEvent := func(id string) *tgtdb.Event {
+		return &tgtdb.Event{Op: "c", SchemaName: "public", TableName: "foo", Key: map[string]*string{"id": strPtr(id)},
+			Fields: map[string]*string{"id": strPtr(id)}}
+	}
+	eqw, err := NewEventQueueWriter(exportDir, "source_db_exporter", "run1", mdb)
+	assert.NoError(err)
+	assert.NoError(eqw.WriteEvent(newEvent("1")))
+	assert.NoError(eqw.WriteEvent(newEvent("2")))
+	assert.NoError(eqw.Sync("", nil))
+	// not synced; must be discarded on restart.
+	assert.NoError(eqw.WriteEvent(newEvent("3")))
+	assert.NoError(eqw.writer.Flush())
+	committedSize, err := mdb.GetLastValidOffsetInSegmentFile(0)
+	assert.NoError(err)
+
+	eqw, err = NewEventQueueWriter(exportDir, "source_db_exporter", "run1", mdb)
+	assert.NoError(err)
+	info, err := os.Stat(eqw.segmentFilePath(0))
+	assert.NoError(err)
+	assert.Equal(committedSize, info.Size())
+	assert.Equal(int64(3), eqw.nextVsn)
+
+	// rotation closes the segment with the EOF marker; vsn continues in the next segment.
+	eqw.MaxSegmentSize = 1
+	assert.NoError(eqw.RotateIfRequired())
+	assert.Equal(int64(1), eqw.segmentNum)
+	assert.NoError(eqw.WriteEvent(newEvent("4")))
+	assert.NoError(eqw.Sync("", nil))
+	lastVsn, closed, err := readLastVsnOfSegment(eqw.segmentFilePath(0))
+	assert.NoError(err)
+	assert.Equal(int64(2), lastVsn)
+	assert.True(closed)
+	lastVsn, closed, err = readLastVsnOfSegment(eqw.segmentFilePath(1))
+	assert.NoError(err)
+	assert.Equal(int64(3), lastVsn)
+	assert.False(closed)
+
+	stats, err := mdb.GetExportedEventsStatsForTable("", "foo")
+	assert.NoError(err)
+	assert.Equal(int64(3), stats.NumInserts)
+
+	// restart after the last segment is closed; the segment is left as is and the events go to the next one.
+	assert.NoError(eqw.Close())
+	closedSegment, err := os.ReadFile(eqw.segmentFilePath(1))
+	assert.NoError(err)
+	eqw, err = NewEventQueueWriter(exportDir, "source_db_exporter", "run1", mdb)
+	assert.NoError(err)
+	assert.Equal(int64(2), eqw.segmentNum)
+	assert.Equal(int64(4), eqw.nextVsn)
+	segment, err := os.ReadFile(eqw.segmentFilePath(1))
+	assert.NoError(err)
+	assert.Equal(closedSegment, segment)
+	assert.Equal(1, bytes.Count(segment, EOFMarker))
+}
@@ -0,0 +1,341 @@
+/*
+Copyright (c) YugabyteDB, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cdc
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/yugabyte/yb-voyager/yb-voyager/src/metadb"
+	"github.com/yugabyte/yb-voyager/yb-voyager/src/tgtdb"
+)
+
+const (
+	QUEUE_DIR_NAME               = "queue"
+	QUEUE_SEGMENT_FILE_NAME      = "segment"
+	QUEUE_SEGMENT_FILE_EXTENSION = "ndjson"
+	DEFAULT_MAX_SEGMENT_SIZE     = 1024 * 1024 * 1024 // 1 GB
+)
+
+var EOFMarker = []byte(`\.`)
+
+var segmentFileNameRegex = regexp.MustCompile(fmt.Sprintf(`^%s\.(\d+)\.%s$`, QUEUE_SEGMENT_FILE_NAME, QUEUE_SEGMENT_FILE_EXTENSION))
+
+/*
+EventQueueWriter writes events to the queue segment files (data/queue/segment.N.ndjson) in the same
+format as the debezium exporter plugin, so that `import data` can stream them unchanged.
+
+Events are buffered in memory until Sync() is called. Sync() fsyncs the segment file and then records
+the committed size of the segment (and the event stats) in the metaDB. The importer never reads
+beyond the committed size. On restart, the writer truncates the last segment to its committed size
+and continues the VSN sequence from the last committed event.
+*/
+type EventQueueWriter struct {
+	queueDirPath   string
+	exporterRole   string
+	runId          string
+	metaDB         *metadb.MetaDB
+	MaxSegmentSize int64
+	// if false, the per table stats are recorded without the schema name (when only one schema is migrated)
+	UseSchemaNameInStats bool
+
+	segmentNum int64
+	file       *os.File
+	writer     *bufio.Writer
+	byteCount  int64
+	nextVsn    int64
+	deltas     map[string]*metadb.TableEventsDelta
+}
+
+func NewEventQueueWriter(exportDir string, exporterRole string, runId string, metaDB *metadb.MetaDB) (*EventQueueWriter, error) {
+	eqw := &EventQueueWriter{
+		queueDirPath:   filepath.Join(exportDir, "data", QUEUE_DIR_NAME),
+		exporterRole:   exporterRole,
+		runId:          runId,
+		metaDB:         metaDB,
+		MaxSegmentSize: getMaxSegmentSize(),
+		nextVsn:        1,
+		deltas:         make(map[string]*metadb.TableEventsDelta),
+	}
+	err := os.MkdirAll(eqw.queueDirPath, 0755)
+	if err != nil {
+		return nil, fmt.Errorf("create queue dir %q: %w", eqw.queueDirPath, err)
+	}
+	err = eqw.recover()
+	if err != nil {
+		return nil, fmt.Errorf("recover event queue: %w", err)
+	}
+	return eqw, nil
+}
+
+func getMaxSegmentSize() int64 {
+	maxSegmentSize, err := strconv.ParseInt(os.Getenv("QUEUE_SEGMENT_MAX_BYTES"), 10, 64)
+	if err != nil {
+		log.Infof("QUEUE_SEGMENT_MAX_BYTES not set, defaulting to 1GB")
+		return DEFAULT_MAX_SEGMENT_SIZE
+	}
+	log.Infof("QUEUE_SEGMENT_MAX_BYTES: %d", maxSegmentSize)
+	return maxSegmentSize
+}
+
+func (eqw *EventQueueWriter) segmentFilePath(segmentNum int64) string {
+	return filepath.Join(eqw.queueDirPath, fmt.Sprintf("%s.%d.%s", QUEUE_SEGMENT_FILE_NAME, segmentNum, QUEUE_SEGMENT_FILE_EXTENSION))
+}
+
+func (eqw *EventQueueWriter) recover() error {
+	entries, err := os.ReadDir(eqw.queueDirPath)
+	if err != nil {
+		return fmt.Errorf("read queue dir %q: %w", eqw.queueDirPath, err)
+	}
+	lastSegmentNum := int64(-1)
+	for _, entry := range entries {
+		matches := segmentFileNameRegex.FindStringSubmatch(entry.Name())
+		if matches == nil {
+			continue
+		}
+		segmentNum, err := strconv.ParseInt(matches[1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("parse segment number of %q: %w", entry.Name(), err)
+		}
+		if segmentNum > lastSegmentNum {
+			lastSegmentNum = segmentNum
+		}
+	}
+	if lastSegmentNum == -1 {
+		log.Infof("no queue segments found in %q. starting from segment 0", eqw.queueDirPath)
+		return eqw.openSegment(0)
+	}
+
+	err = eqw.openSegment(lastSegmentNum)
+	if err != nil {
+		return err
+	}
+	lastVsn, closed, err := readLastVsnOfSegment(eqw.segmentFilePath(lastSegmentNum))
+	if err != nil {
+		return err
+	}
+	if lastVsn == -1 && lastSegmentNum > 0 {
+		lastVsn, _, err = readLastVsnOfSegment(eqw.segmentFilePath(lastSegmentNum - 1))
+		if err != nil {
+			return err
+		}
+	}
+	if lastVsn != -1 {
+		eqw.nextVsn = lastVsn + 1
+	}
+	log.Infof("recovered event queue at segment %d with byte count %d; next vsn %d", eqw.segmentNum, eqw.byteCount, eqw.nextVsn)
+	if closed {
+		// The segment already has its EOF marker, writing the next events to the next segment.
+		log.Infof("queue segment %d is already closed. opening segment %d", eqw.segmentNum, eqw.segmentNum+1)
+		err = eqw.file.Close()
+		if err != nil {
+			return fmt.Errorf("close queue segment %d: %w", eqw.segmentNum, err)
+		}
+		return eqw.openSegment(eqw.segmentNum + 1)
+	}
+	return nil
+}
+
+// openSegment opens the segment for appending after discarding any bytes which were not committed.
+func (eqw *EventQueueWriter) openSegment(segmentNum int64) error {
+	filePath := eqw.segmentFilePath(segmentNum)
+	err := eqw.metaDB.CreateQueueSegmentMetaIfNotExists(segmentNum, filePath, eqw.exporterRole)
+	if err != nil {
+		return fmt.Errorf("create meta of queue segment %d: %w", segmentNum, err)
+	}
+	committedSize, err := eqw.metaDB.GetLastValidOffsetInSegmentFile(segmentNum)
+	if err != nil {
+		return fmt.Errorf("get committed size of queue segment %d: %w", segmentNum, err)
+	}
+	file, err := os.OpenFile(filePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("open queue segment %q: %w", filePath, err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("stat queue segment %q: %w", filePath, err)
+	}
+	if info.Size() > committedSize {
+		log.Infof("truncating queue segment %q from %d to committed size %d", filePath, info.Size(), committedSize)
+		err = file.Truncate(committedSize)
+		if err != nil {
+			file.Close()
+			return fmt.Errorf("truncate queue segment %q: %w", filePath, err)
+		}
+	}
+	eqw.segmentNum = segmentNum
+	eqw.file = file
+	eqw.writer = bufio.NewWriterSize(file, 1024*1024)
+	eqw.byteCount = committedSize
+	return nil
+}
+
+// readLastVsnOfSegment returns the vsn of the last event in the segment (-1 if empty) and whether the segment is closed.
+func readLastVsnOfSegment(filePath string) (int64, bool, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return -1, false, fmt.Errorf("open queue segment %q: %w", filePath, err)
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	var lastLine []byte
+	closed := false
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return -1, false, fmt.Errorf("read queue segment %q: %w", filePath, err)
+		}
+		line = bytes.TrimSpace(line)
+		if bytes.Equal(line, EOFMarker) {
+			closed = true
+			break
+		}
+		if len(line) > 0 {
+			lastLine = line
+		}
+		if err == io.EOF {
+			break
+		}
+	}
+	if lastLine == nil {
+		return -1, closed, nil
+	}
+	var event tgtdb.Event
+	err = json.Unmarshal(lastLine, &event)
+	if err != nil {
+		return -1, false, fmt.Errorf("parse last event of queue segment %q: %w", filePath, err)
+	}
+	return event.Vsn, closed, nil
+}
+
+// WriteEvent assigns the next vsn to the event and appends it to the current segment.
+func (eqw *EventQueueWriter) WriteEvent(event *tgtdb.Event) error {
+	if event.Op == "u" && len(event.Fields) == 0 {
+		log.Debugf("skipping event %v as there are no values to update", event)
+		return nil
+	}
+	event.Vsn = eqw.nextVsn
+	event.ExporterRole = eqw.exporterRole
+	for _, m := range []*map[string]*string{&event.Key, &event.Fields, &event.BeforeFields} {
+		if *m == nil {
+			*m = map[string]*string{}
+		}
+	}
+	line, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("marshal event %v: %w", event, err)
+	}
+	line = append(line, '\n')
+	_, err = eqw.writer.Write(line)
+	if err != nil {
+		return fmt.Errorf("write event to queue segment %d: %w", eqw.segmentNum, err)
+	}
+	eqw.byteCount += int64(len(line))
+	eqw.nextVsn++
+	eqw.countEvent(event)
+	return nil
+}
+
+func (eqw *EventQueueWriter) countEvent(event *tgtdb.Event) {
+	if event.Op != "c" && event.Op != "u" && event.Op != "d" {
+		return
+	}
+	schemaName := event.SchemaName
+	if !eqw.UseSchemaNameInStats {
+		schemaName = ""
+	}
+	key := schemaName + "." + event.TableName
+	delta, ok := eqw.deltas[key]
+	if !ok {
+		delta = &metadb.TableEventsDelta{SchemaName: schemaName, TableName: event.TableName}
+		eqw.deltas[key] = delta
+	}
+	delta.CountEvent(event)
+}
+
+/*
+Sync makes the events written so far durable and commits them to the metaDB. If progressKey is not empty,
+progress is saved against it in the same metaDB transaction.
+*/
+func (eqw *EventQueueWriter) Sync(progressKey string, progress any) error {
+	err := eqw.writer.Flush()
+	if err != nil {
+		return fmt.Errorf("flush queue segment %d: %w", eqw.segmentNum, err)
+	}
+	err = eqw.file.Sync()
+	if err != nil {
+		return fmt.Errorf("fsync queue segment %d: %w", eqw.segmentNum, err)
+	}
+	deltas := make([]*metadb.TableEventsDelta, 0, len(eqw.deltas))
+	for _, delta := range eqw.deltas {
+		deltas = append(deltas, delta)
+	}
+	err = eqw.metaDB.CommitQueueSegment(eqw.segmentNum, eqw.byteCount, eqw.runId, eqw.exporterRole, deltas, progressKey, progress)
+	if err != nil {
+		return fmt.Errorf("commit queue segment %d: %w", eqw.segmentNum, err)
+	}
+	eqw.deltas = make(map[string]*metadb.TableEventsDelta)
+	return nil
+}
+
+// RotateIfRequired closes the current segment and opens the next one if the current segment is full.
+// It must be called only when all the written events are synced.
+func (eqw *EventQueueWriter) RotateIfRequired() error {
+	if eqw.byteCount < eqw.MaxSegmentSize {
+		return nil
+	}
+	return eqw.rotate()
+}
+
+func (eqw *EventQueueWriter) rotate() error {
+	err := eqw.Close()
+	if err != nil {
+		return err
+	}
+	log.Infof("rotating queue segment to %d", eqw.segmentNum+1)
+	return eqw.openSegment(eqw.segmentNum + 1)
+}
+
+// Close writes the EOF marker to the current segment and syncs it.
+func (eqw *EventQueueWriter) Close() error {
+	log.Infof("closing queue segment %d", eqw.segmentNum)
+	marker := append(append([]byte{}, EOFMarker...), '\n', '\n')
+	_, err := eqw.writer.Write(marker)
+	if err != nil {
+		return fmt.Errorf("write EOF marker to queue segment %d: %w", eqw.segmentNum, err)
+	}
+	eqw.byteCount += int64(len(marker))
+	err = eqw.Sync("", nil)
+	if err != nil {
+		return err
+	}
+	err = eqw.file.Close()
+	if err != nil {
+		return fmt.Errorf("close queue segment %d: %w", eqw.segmentNum, err)
+	}
+	return nil
+}
@@ -0,0 +1,454 @@
+/*
+Copyright (c) YugabyteDB, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cdc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pglogrepl"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgproto3"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/yugabyte/yb-voyager/yb-voyager/src/dbzm"
+	"github.com/yugabyte/yb-voyager/yb-voyager/src/metadb"
+	"github.com/yugabyte/yb-voyager/yb-voyager/src/tgtdb"
+)
+
+const (
+	PG_CDC_PROGRESS_KEY     = "pg_cdc_progress"
+	syncInterval            = 2 * time.Second
+	standbyMessageInterval  = 10 * time.Second
+	CUTOVER_TO_TARGET_EVENT = "cutover.target"
+)
+
+type PGExporterConfig struct {
+	ExportDir       string
+	RunId           string
+	ExporterRole    string
+	SlotName        string
+	PublicationName string
+	// primary key columns keyed by `schema.table`
+	TableToKeyColumns map[string][]string
+	// sequence name keyed by `schema.table.column`
+	ColumnToSequence map[string]string
+	// last values of the sequences at the time of the snapshot
+	InitSequenceValues map[string]int64
+	// whether the per table stats in the metaDB should carry the schema name
+	UseSchemaNameInStats bool
+}
+
+// pgCDCProgress is saved in the metaDB along with every sync of the queue.
+type pgCDCProgress struct {
+	// end LSN of the last transaction written to the queue.
+	LastCommittedLSN string `json:"last_committed_lsn"`
+}
+
+/*
+PGExporter streams changes from a logical replication slot using the pgoutput plugin and writes them to
+the event queue. It is a replacement for the debezium exporter for PostgreSQL live migration.
+
+Events of a transaction are written to the queue as they arrive, but the queue is synced only at
+transaction boundaries. The end LSN of the last synced transaction is saved in the metaDB in the same
+transaction which commits the queue segment, and is then confirmed to the server. On restart, the queue is
+truncated to its committed size and transactions at or before the saved LSN are skipped, so that every
+transaction is written to the queue exactly once.
+*/
+type PGExporter struct {
+	config  *PGExporterConfig
+	conn    *pgconn.PgConn
+	metaDB  *metadb.MetaDB
+	queue   *EventQueueWriter
+	decoder *PGOutputDecoder
+
+	exportStatusFilePath string
+	exportStatus         *dbzm.ExportStatus
+	schemaDirPath        string
+	writtenSchemas       map[string][]byte
+
+	inTxn              bool
+	skipTxn            bool
+	syncedLSN          pglogrepl.LSN // end LSN of the last transaction committed in the queue
+	pendingLSN         pglogrepl.LSN // end LSN of the last transaction written to the queue but not synced
+	confirmedLSN       pglogrepl.LSN // LSN last confirmed to the server
+	hasPendingTxns     bool
+	sequencesChanged   bool
+	lastSyncTime       time.Time
+	nextStandbyMsgTime time.Time
+}
+
+func NewPGExporter(config *PGExporterConfig, conn *pgconn.PgConn, metaDB *metadb.MetaDB) (*PGExporter, error) {
+	queue, err := NewEventQueueWriter(config.ExportDir, config.ExporterRole, config.RunId, metaDB)
+	if err != nil {
+		return nil, err
+	}
+	queue.UseSchemaNameInStats = config.UseSchemaNameInStats
+	e := &PGExporter{
+		config:               config,
+		conn:                 conn,
+		metaDB:               metaDB,
+		queue:                queue,
+		decoder:              NewPGOutputDecoder(config.TableToKeyColumns),
+		exportStatusFilePath: filepath.Join(config.ExportDir, "data", "export_status.json"),
+		schemaDirPath:        filepath.Join(config.ExportDir, "data", "schemas", config.ExporterRole),
+		writtenSchemas:       make(map[string][]byte),
+	}
+	err = os.MkdirAll(e.schemaDirPath, 0755)
+	if err != nil {
+		return nil, fmt.Errorf("create schemas dir %q: %w", e.schemaDirPath, err)
+	}
+	err = e.initExportStatus()
+	if err != nil {
+		return nil, err
+	}
+
+	progress := &pgCDCProgress{}
+	found, err := metaDB.GetJsonObject(nil, PG_CDC_PROGRESS_KEY, progress)
+	if err != nil {
+		return nil, fmt.Errorf("get pg cdc progress: %w", err)
+	}
+	if found {
+		e.syncedLSN, err = pglogrepl.ParseLSN(progress.LastCommittedLSN)
+		if err != nil {
+			return nil, fmt.Errorf("parse last committed lsn %q: %w", progress.LastCommittedLSN, err)
+		}
+	}
+	e.confirmedLSN = e.syncedLSN
+	return e, nil
+}
+
+func (e *PGExporter) initExportStatus() error {
+	status, err := dbzm.ReadExportStatus(e.exportStatusFilePath)
+	if err != nil {
+		return err
+	}
+	if status == nil {
+		status = &dbzm.ExportStatus{Tables: []dbzm.TableExportStatus{}}
+	}
+	sequences := make(map[string]int64)
+	for seqName, value := range e.config.InitSequenceValues {
+		sequences[seqName] = value
+	}
+	for _, seqName := range e.config.ColumnToSequence {
+		if _, ok := sequences[seqName]; !ok {
+			sequences[seqName] = 0
+		}
+	}
+	// values tracked by the previous run take precedence.
+	for seqName, value := range status.Sequences {
+		sequences[seqName] = value
+	}
+	status.Mode = dbzm.MODE_STREAMING
+	status.Sequences = sequences
+	e.exportStatus = status
+	return e.flushExportStatus()
+}
+
+func (e *PGExporter) flushExportStatus() error {
+	bytes, err := json.MarshalIndent(e.exportStatus, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal export status: %w", err)
+	}
+	// write to a temp file and rename, so that readers never see a partially written file.
+	tmpFilePath := e.exportStatusFilePath + ".tmp"
+	err = os.WriteFile(tmpFilePath, bytes, 0644)
+	if err != nil {
+		return fmt.Errorf("write export status file %q: %w", tmpFilePath, err)
+	}
+	err = os.Rename(tmpFilePath, e.exportStatusFilePath)
+	if err != nil {
+		return fmt.Errorf("rename %q to %q: %w", tmpFilePath, e.exportStatusFilePath, err)
+	}
+	e.sequencesChanged = false
+	return nil
+}
+
+// Run streams the changes until cutover or end migration is requested or ctx is cancelled.
+func (e *PGExporter) Run(ctx context.Context) error {
+	pluginArgs := []string{
+		"proto_version '1'",
+		fmt.Sprintf("publication_names '%s'", e.config.PublicationName),
+	}
+	log.Infof("starting replication from slot %q at lsn %s", e.config.SlotName, e.syncedLSN)
+	err := pglogrepl.StartReplication(ctx, e.conn, e.config.SlotName, e.syncedLSN,
+		pglogrepl.StartReplicationOptions{PluginArgs: pluginArgs})
+	if err != nil {
+		return fmt.Errorf("start replication on slot %q: %w", e.config.SlotName, err)
+	}
+	e.lastSyncTime = time.Now()
+	e.nextStandbyMsgTime = time.Now().Add(standbyMessageInterval)
+
+	for {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if !e.inTxn && time.Since(e.lastSyncTime) >= syncInterval {
+			done, err := e.syncAndHandleControlRequests()
+			if err != nil || done {
+				return err
+			}
+		}
+		if !time.Now().Before(e.nextStandbyMsgTime) {
+			err = e.sendStandbyStatus()
+			if err != nil {
+				return err
+			}
+		}
+
+		deadline := e.nextStandbyMsgTime
+		if syncDeadline := e.lastSyncTime.Add(syncInterval); syncDeadline.Before(deadline) {
+			deadline = syncDeadline
+		}
+		receiveCtx, cancel := context.WithDeadline(ctx, deadline)
+		rawMsg, err := e.conn.ReceiveMessage(receiveCtx)
+		cancel()
+		if err != nil {
+			if pgconn.Timeout(err) {
+				continue
+			}
+			return fmt.Errorf("receive replication message: %w", err)
+		}
+
+		switch msg := rawMsg.(type) {
+		case *pgproto3.ErrorResponse:
+			return fmt.Errorf("replication error from server: %+v", msg)
+		case *pgproto3.CopyData:
+			switch msg.Data[0] {
+			case pglogrepl.PrimaryKeepaliveMessageByteID:
+				pkm, err := pglogrepl.ParsePrimaryKeepaliveMessage(msg.Data[1:])
+				if err != nil {
+					return fmt.Errorf("parse primary keepalive message: %w", err)
+				}
+				if !e.inTxn && !e.hasPendingTxns && pkm.ServerWALEnd > e.confirmedLSN {
+					// all the changes before ServerWALEnd have been received and written.
+					e.confirmedLSN = pkm.ServerWALEnd
+				}
+				if pkm.ReplyRequested {
+					e.nextStandbyMsgTime = time.Time{}
+				}
+			case pglogrepl.XLogDataByteID:
+				xld, err := pglogrepl.ParseXLogData(msg.Data[1:])
+				if err != nil {
+					return fmt.Errorf("parse xlog data: %w", err)
+				}
+				err = e.handleWALData(xld.WALData)
+				if err != nil {
+					return err
+				}
+			}
+		default:
+			log.Warnf("unexpected replication message %T", rawMsg)
+		}
+	}
+}
+
+func (e *PGExporter) handleWALData(walData []byte) error {
+	logicalMsg, err := pglogrepl.Parse(walData)
+	if err != nil {
+		return fmt.Errorf("parse logical replication message: %w", err)
+	}
+	switch msg := logicalMsg.(type) {
+	case *pglogrepl.RelationMessage:
+		e.decoder.AddRelation(msg)
+		return e.writeTableSchema(msg)
+	case *pglogrepl.BeginMessage:
+		e.inTxn = true
+		// the final LSN of a transaction is before its end LSN, and after the end LSN of every transaction committed before it.
+		e.skipTxn = msg.FinalLSN < e.syncedLSN
+		if e.skipTxn {
+			log.Infof("skipping transaction %d with commit lsn %s already written to the queue", msg.Xid, msg.FinalLSN)
+		}
+	case *pglogrepl.CommitMessage:
+		e.inTxn = false
+		if !e.skipTxn {
+			e.pendingLSN = msg.TransactionEndLSN
+			e.hasPendingTxns = true
+		}
+	case *pglogrepl.InsertMessage, *pglogrepl.UpdateMessage, *pglogrepl.DeleteMessage:
+		if e.skipTxn {
+			return nil
+		}
+		events, err := e.decoder.DecodeChange(logicalMsg)
+		if err != nil {
+			return fmt.Errorf("decode change: %w", err)
+		}
+		for _, event := range events {
+			e.trackSequences(event)
+			err = e.queue.WriteEvent(event)
+			if err != nil {
+				return err
+			}
+		}
+	case *pglogrepl.TruncateMessage:
+		log.Warnf("ignoring TRUNCATE of relations %v", msg.RelationIDs)
+	default:
+		log.Debugf("ignoring logical replication message of type %s", logicalMsg.Type())
+	}
+	return nil
+}
+
+func (e *PGExporter) trackSequences(event *tgtdb.Event) {
+	if event.Op == "d" {
+		// the max would have already been updated by the create/update events.
+		return
+	}
+	for column, value := range event.Fields {
+		seqName, ok := e.config.ColumnToSequence[fmt.Sprintf("%s.%s.%s", event.SchemaName, event.TableName, column)]
+		if !ok || value == nil {
+			continue
+		}
+		columnValue, err := strconv.ParseInt(*value, 10, 64)
+		if err != nil {
+			log.Warnf("parse value %q of column %s.%s.%s backed by sequence %s: %v", *value, event.SchemaName, event.TableName, column, seqName, err)
+			continue
+		}
+		if columnValue > e.exportStatus.Sequences[seqName] {
+			e.exportStatus.Sequences[seqName] = columnValue
+			e.sequencesChanged = true
+		}
+	}
+}
+
+// writeTableSchema writes the schema file of the table which is used by the importer to convert the values.
+func (e *PGExporter) writeTableSchema(rel *pglogrepl.RelationMessage) error {
+	fileName := rel.RelationName
+	if rel.Namespace != "public" {
+		fileName = rel.Namespace + "." + rel.RelationName
+	}
+	tableSchema := tableSchemaFile{Columns: make([]tableSchemaColumn, 0, len(rel.Columns))}
+	for i, column := range rel.Columns {
+		tableSchema.Columns = append(tableSchema.Columns, tableSchemaColumn{
+			Name:  column.Name,
+			Index: i,
+			// values are in PostgreSQL text format, which the target accepts as string literals.
+			Schema: tableSchemaColumnType{Type: "STRING", Parameters: map[string]string{}},
+		})
+	}
+	bytes, err := json.MarshalIndent(tableSchema, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal schema of table %s: %w", fileName, err)
+	}
+	if string(e.writtenSchemas[fileName]) == string(bytes) {
+		return nil
+	}
+	filePath := filepath.Join(e.schemaDirPath, fileName+"_schema.json")
+	err = os.WriteFile(filePath, bytes, 0644)
+	if err != nil {
+		return fmt.Errorf("write schema file %q: %w", filePath, err)
+	}
+	e.writtenSchemas[fileName] = bytes
+	return nil
+}
+
+type tableSchemaFile struct {
+	Columns []tableSchemaColumn `json:"columns"`
+}
+
+type tableSchemaColumn struct {
+	Name   string                `json:"name"`
+	Index  int                   `json:"index"`
+	Schema tableSchemaColumnType `json:"schema"`
+}
+
+type tableSchemaColumnType struct {
+	Type       string            `json:"type"`
+	Name       string            `json:"name"`
+	Parameters map[string]string `json:"parameters"`
+}
+
+func (e *PGExporter) sync() error {
+	var err error
+	if e.hasPendingTxns {
+		err = e.queue.Sync(PG_CDC_PROGRESS_KEY, &pgCDCProgress{LastCommittedLSN: e.pendingLSN.String()})
+		if err != nil {
+			return err
+		}
+		e.syncedLSN = e.pendingLSN
+		e.hasPendingTxns = false
+		if e.syncedLSN > e.confirmedLSN {
+			e.confirmedLSN = e.syncedLSN
+		}
+		err = e.queue.RotateIfRequired()
+		if err != nil {
+			return err
+		}
+	}
+	if e.sequencesChanged {
+		err = e.flushExportStatus()
+		if err != nil {
+			return err
+		}
+	}
+	e.lastSyncTime = time.Now()
+	return nil
+}
+
+func (e *PGExporter) sendStandbyStatus() error {
+	err := pglogrepl.SendStandbyStatusUpdate(context.Background(), e.conn,
+		pglogrepl.StandbyStatusUpdate{WALWritePosition: e.confirmedLSN})
+	if err != nil {
+		return fmt.Errorf("send standby status update: %w", err)
+	}
+	log.Debugf("sent standby status update with lsn %s", e.confirmedLSN)
+	e.nextStandbyMsgTime = time.Now().Add(standbyMessageInterval)
+	return nil
+}
+
+// syncAndHandleControlRequests syncs the queue and returns true if the export should stop.
+func (e *PGExporter) syncAndHandleControlRequests() (bool, error) {
+	err := e.sync()
+	if err != nil {
+		return false, err
+	}
+	msr, err := e.metaDB.GetMigrationStatusRecord()
+	if err != nil {
+		return false, fmt.Errorf("get migration status record: %w", err)
+	}
+	if msr == nil {
+		return false, nil
+	}
+	switch {
+	case msr.CutoverToTargetRequested:
+		log.Infof("observed cutover to target request in metadb. cutting over...")
+		err = e.queue.WriteEvent(&tgtdb.Event{Op: CUTOVER_TO_TARGET_EVENT})
+		if err != nil {
+			return false, err
+		}
+	case msr.EndMigrationRequested:
+		log.Infof("observed request for end migration in metadb. shutting down...")
+	default:
+		return false, nil
+	}
+	err = e.queue.Close()
+	if err != nil {
+		return false, err
+	}
+	err = e.flushExportStatus()
+	if err != nil {
+		return false, err
+	}
+	err = e.sendStandbyStatus()
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,224 @@
+/*
+Copyright (c) YugabyteDB, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cdc
+
+import (
+	"fmt"
+
+	"github.com/jackc/pglogrepl"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/yugabyte/yb-voyager/yb-voyager/src/tgtdb"
+)
+
+/*
+PGOutputDecoder converts the row change messages of the pgoutput plugin into events. Values are kept in
+the text format of PostgreSQL.
+
+The key of an event consists of the primary key columns of the table. Since live migration requires
+REPLICA IDENTITY FULL (where every column is flagged as a key column in the relation message), the primary
+key columns are looked up in tableToKeyColumns; the relation flags are used only if the table is not present there.
+
+Unchanged TOASTed values are not sent by the server and are therefore left out of the events.
+*/
+type PGOutputDecoder struct {
+	relations         map[uint32]*pglogrepl.RelationMessage
+	tableToKeyColumns map[string][]string
+}
+
+func NewPGOutputDecoder(tableToKeyColumns map[string][]string) *PGOutputDecoder {
+	return &PGOutputDecoder{
+		relations:         make(map[uint32]*pglogrepl.RelationMessage),
+		tableToKeyColumns: tableToKeyColumns,
+	}
+}
+
+func (d *PGOutputDecoder) AddRelation(rel *pglogrepl.RelationMessage) {
+	d.relations[rel.RelationID] = rel
+}
+
+// DecodeChange returns the events for an insert, update or delete message. An update which changes the key
+// is returned as a delete followed by an insert.
+func (d *PGOutputDecoder) DecodeChange(msg pglogrepl.Message) ([]*tgtdb.Event, error) {
+	switch msg := msg.(type) {
+	case *pglogrepl.InsertMessage:
+		rel, err := d.getRelation(msg.RelationID)
+		if err != nil {
+			return nil, err
+		}
+		values, err := tupleValues(rel, msg.Tuple)
+		if err != nil {
+			return nil, err
+		}
+		return []*tgtdb.Event{d.newEvent(rel, "c", d.keyValues(rel, values), values, nil)}, nil
+
+	case *pglogrepl.UpdateMessage:
+		rel, err := d.getRelation(msg.RelationID)
+		if err != nil {
+			return nil, err
+		}
+		newValues, err := tupleValues(rel, msg.NewTuple)
+		if err != nil {
+			return nil, err
+		}
+		if msg.OldTuple == nil {
+			// key is unchanged and old values are not available.
+			return []*tgtdb.Event{d.newEvent(rel, "u", d.keyValues(rel, newValues), newValues, nil)}, nil
+		}
+		oldValues, err := tupleValues(rel, msg.OldTuple)
+		if err != nil {
+			return nil, err
+		}
+		oldKey := d.keyValues(rel, oldValues)
+		newKey := d.keyValues(rel, withDefaults(newValues, oldValues))
+		if !valuesEqual(oldKey, newKey) {
+			if msg.OldTupleType == pglogrepl.UpdateMessageTupleTypeKey {
+				oldValues = oldKey
+			}
+			return []*tgtdb.Event{
+				d.newEvent(rel, "d", oldKey, nil, oldValues),
+				d.newEvent(rel, "c", newKey, withDefaults(newValues, oldValues), nil),
+			}, nil
+		}
+		if msg.OldTupleType == pglogrepl.UpdateMessageTupleTypeKey {
+			return []*tgtdb.Event{d.newEvent(rel, "u", newKey, newValues, nil)}, nil
+		}
+		fields := make(map[string]*string)
+		beforeFields := make(map[string]*string)
+		for column, value := range newValues {
+			oldValue, ok := oldValues[column]
+			if ok && valueEqual(oldValue, value) {
+				continue
+			}
+			fields[column] = value
+			if ok {
+				beforeFields[column] = oldValue
+			}
+		}
+		return []*tgtdb.Event{d.newEvent(rel, "u", newKey, fields, beforeFields)}, nil
+
+	case *pglogrepl.DeleteMessage:
+		rel, err := d.getRelation(msg.RelationID)
+		if err != nil {
+			return nil, err
+		}
+		oldValues, err := tupleValues(rel, msg.OldTuple)
+		if err != nil {
+			return nil, err
+		}
+		key := d.keyValues(rel, oldValues)
+		if msg.OldTupleType == pglogrepl.DeleteMessageTupleTypeKey {
+			oldValues = key
+		}
+		return []*tgtdb.Event{d.newEvent(rel, "d", key, nil, oldValues)}, nil
+	}
+	return nil, fmt.Errorf("unexpected pgoutput message type %s", msg.Type())
+}
+
+func (d *PGOutputDecoder) getRelation(relationID uint32) (*pglogrepl.RelationMessage, error) {
+	rel, ok := d.relations[relationID]
+	if !ok {
+		return nil, fmt.Errorf("unknown relation id %d", relationID)
+	}
+	return rel, nil
+}
+
+func (d *PGOutputDecoder) newEvent(rel *pglogrepl.RelationMessage, op string, key, fields, beforeFields map[string]*string) *tgtdb.Event {
+	return &tgtdb.Event{
+		Op:           op,
+		SchemaName:   rel.Namespace,
+		TableName:    rel.RelationName,
+		Key:          key,
+		Fields:       fields,
+		BeforeFields: beforeFields,
+	}
+}
+
+func (d *PGOutputDecoder) keyValues(rel *pglogrepl.RelationMessage, values map[string]*string) map[string]*string {
+	key := make(map[string]*string)
+	keyColumns, ok := d.tableToKeyColumns[rel.Namespace+"."+rel.RelationName]
+	if ok {
+		for _, column := range keyColumns {
+			key[column] = values[column]
+		}
+		return key
+	}
+	for _, column := range rel.Columns {
+		if column.Flags == 1 {
+			key[column.Name] = values[column.Name]
+		}
+	}
+	return key
+}
+
+// tupleValues returns the column values of the tuple leaving out the unchanged TOASTed values.
+func tupleValues(rel *pglogrepl.RelationMessage, tuple *pglogrepl.TupleData) (map[string]*string, error) {
+	if tuple == nil {
+		return nil, fmt.Errorf("missing tuple data for relation %s.%s", rel.Namespace, rel.RelationName)
+	}
+	if len(tuple.Columns) != len(rel.Columns) {
+		return nil, fmt.Errorf("relation %s.%s has %d columns but tuple has %d", rel.Namespace, rel.RelationName,
+			len(rel.Columns), len(tuple.Columns))
+	}
+	values := make(map[string]*string, len(tuple.Columns))
+	for i, col := range tuple.Columns {
+		name := rel.Columns[i].Name
+		switch col.DataType {
+		case pglogrepl.TupleDataTypeNull:
+			values[name] = nil
+		case pglogrepl.TupleDataTypeText:
+			value := string(col.Data)
+			values[name] = &value
+		case pglogrepl.TupleDataTypeToast:
+			log.Debugf("skipping unchanged toasted value of %s.%s.%s", rel.Namespace, rel.RelationName, name)
+		default:
+			return nil, fmt.Errorf("unsupported tuple data type %q for %s.%s.%s", col.DataType, rel.Namespace, rel.RelationName, name)
+		}
+	}
+	return values, nil
+}
+
+// withDefaults returns values with the columns missing in it taken from defaults.
+func withDefaults(values, defaults map[string]*string) map[string]*string {
+	result := make(map[string]*string, len(defaults))
+	for column, value := range defaults {
+		result[column] = value
+	}
+	for column, value := range values {
+		result[column] = value
+	}
+	return result
+}
+
+func valueEqual(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+func valuesEqual(a, b map[string]*string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for column, value := range a {
+		other, ok := b[column]
+		if !ok || !valueEqual(value, other) {
+			return false
+		}
+	}
+	return true
+}
@@ -42,7 +42,6 @@ func (df *CsvDataFile) SkipLines(numLines int64) error {
 			return err
 		}
 	}
-	df.ResetBytesRead()
 	return nil
 }
 
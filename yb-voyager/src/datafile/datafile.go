@@ -22,12 +22,15 @@ import (
 )
 
 const (
-	CSV  = "csv"
-	SQL  = "sql"
-	TEXT = "text"
+	CSV     = "csv"
+	SQL     = "sql"
+	TEXT    = "text"
+	PARQUET = "parquet"
 )
 
 type DataFile interface {
+	// SkipLines reads past the first numLines data lines. The bytes skipped are counted in GetBytesRead(),
+	// so that the bytes read are still relative to the start of the file.
 	SkipLines(numLines int64) error
 	NextLine() (string, error)
 	GetBytesRead() int64
@@ -40,15 +43,63 @@ type DataFile interface {
 var reCopy = regexp.MustCompile(`(?i)COPY .* FROM STDIN;`)
 
 func NewDataFile(fileName string, reader io.ReadCloser, descriptor *Descriptor) (DataFile, error) {
+	var df DataFile
+	var err error
 	switch descriptor.FileFormat {
 	case CSV:
-		return newCsvDataFile(fileName, reader, descriptor)
+		df, err = newCsvDataFile(fileName, reader, descriptor)
 	case TEXT:
-		return newTextDataFile(fileName, reader, descriptor)
+		df, err = newTextDataFile(fileName, reader, descriptor)
 	case SQL:
-		return newSqlDataFile(fileName, reader, descriptor)
+		df, err = newSqlDataFile(fileName, reader, descriptor)
+	case PARQUET:
+		// The parquet data file accounts for the compressed size by itself.
+		return newParquetDataFile(fileName, reader, descriptor)
 	default:
 		panic(fmt.Sprintf("Unknown file type %q", descriptor.FileFormat))
 
 	}
+	if err != nil {
+		return nil, err
+	}
+	if cr, ok := reader.(compressedReader); ok {
+		df = &compressedDataFile{DataFile: df, reader: cr}
+	}
+	return df, nil
+}
+
+// NewDataFileAtOffset returns the DataFile for a reader that starts at a byte offset of the file,
+// at which an earlier DataFile stopped after returning a line, e.g. the end of an import batch.
+func NewDataFileAtOffset(fileName string, reader io.ReadCloser, descriptor *Descriptor) (DataFile, error) {
+	df, err := NewDataFile(fileName, reader, descriptor)
+	if err != nil {
+		return nil, err
+	}
+	if sqlDataFile, ok := df.(*SqlDataFile); ok {
+		// The data lines are returned only from inside a COPY statement, so the offset is inside one.
+		sqlDataFile.insideCopyStmt = true
+	}
+	return df, nil
+}
+
+// Implemented by the readers that decompress the data file on the fly.
+type compressedReader interface {
+	CompressedBytesRead() int64
+}
+
+// compressedDataFile reports the bytes read in terms of the compressed file, so that the progress
+// of the import can be tracked against the size of the compressed file.
+type compressedDataFile struct {
+	DataFile
+	reader           compressedReader
+	bytesReadAtReset int64
+}
+
+func (df *compressedDataFile) GetBytesRead() int64 {
+	return df.reader.CompressedBytesRead() - df.bytesReadAtReset
+}
+
+func (df *compressedDataFile) ResetBytesRead() {
+	df.DataFile.ResetBytesRead()
+	df.bytesReadAtReset = df.reader.CompressedBytesRead()
 }
@@ -0,0 +1,106 @@
+package datafile
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewDataFileAtOffset(t *testing.T) {
+	testCases := []struct {
+		fileFormat string
+		content    string
+	}{
+		{SQL, "SET client_encoding TO 'UTF8';\nCOPY foo (id, v) FROM STDIN;\n1\tone\n2\ttwo\n3\tthree\n\\.\n"},
+		{TEXT, "1\tone\n2\ttwo\n3\tthree\n"},
+		{CSV, "1,\"one\nline\"\n2,two\n3,three\n"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.fileFormat, func(t *testing.T) {
+			assert := assert.New(t)
+			filePath := filepath.Join(t.TempDir(), "foo_data."+tc.fileFormat)
+			assert.NoError(os.WriteFile(filePath, []byte(tc.content), 0644))
+			descriptor := &Descriptor{FileFormat: tc.fileFormat, Delimiter: "\t"}
+			if tc.fileFormat == CSV {
+				descriptor.Delimiter = ","
+			}
+
+			f, err := os.Open(filePath)
+			assert.NoError(err)
+			df, err := NewDataFile(filePath, f, descriptor)
+			assert.NoError(err)
+			_, err = df.NextLine()
+			assert.NoError(err)
+			offset := df.GetBytesRead()
+			var expectedLines []string
+			for {
+				line, err := df.NextLine()
+				if line != "" {
+					expectedLines = append(expectedLines, line)
+				}
+				if err == io.EOF {
+					break
+				}
+				assert.NoError(err)
+			}
+			df.Close()
+			assert.Equal(2, len(expectedLines))
+
+			// The bytes skipped are counted, as the offsets of the batches are relative to the start of the file.
+			f, err = os.Open(filePath)
+			assert.NoError(err)
+			df, err = NewDataFile(filePath, f, descriptor)
+			assert.NoError(err)
+			assert.NoError(df.SkipLines(1))
+			assert.Equal(offset, df.GetBytesRead())
+			df.Close()
+
+			f, err = os.Open(filePath)
+			assert.NoError(err)
+			_, err = f.Seek(offset, io.SeekStart)
+			assert.NoError(err)
+			df, err = NewDataFileAtOffset(filePath, f, descriptor)
+			assert.NoError(err)
+			defer df.Close()
+			var lines []string
+			for {
+				line, err := df.NextLine()
+				if line != "" {
+					lines = append(lines, line)
+				}
+				if err == io.EOF {
+					break
+				}
+				assert.NoError(err)
+			}
+			assert.Equal(expectedLines, lines)
+		})
+	}
+}
+
+func TestParseAndFormatLine(t *testing.T) {
+	str := func(s string) *string { return &s }
+	testCases := []struct {
+		descriptor *Descriptor
+		line       string
+		values     []*string
+	}{
+		{&Descriptor{FileFormat: TEXT, Delimiter: "\t", NullString: `\N`}, "1\ttab\\there\t\\N", []*string{str("1"), str("tab\there"), nil}},
+		{&Descriptor{FileFormat: SQL, Delimiter: "\t"}, "1\tnew\\nline\t", []*string{str("1"), str("new\nline"), str("")}},
+		{&Descriptor{FileFormat: TEXT, Delimiter: "|"}, `1|a\|b|\N`, []*string{str("1"), str("a|b"), nil}},
+		{&Descriptor{FileFormat: CSV, Delimiter: ","}, `1,"a,b","",,"say ""hi"""`, []*string{str("1"), str("a,b"), str(""), nil, str(`say "hi"`)}},
+		{&Descriptor{FileFormat: CSV, Delimiter: ",", NullString: "__YBV_NULL__"}, `1,__YBV_NULL__,"__YBV_NULL__"`, []*string{str("1"), nil, str("__YBV_NULL__")}},
+		{&Descriptor{FileFormat: CSV, Delimiter: ";", QuoteChar: '\'', EscapeChar: '\\'}, `1;'it\'s;\\'`, []*string{str("1"), str(`it's;\`)}},
+	}
+	for _, tc := range testCases {
+		values, err := ParseLine(tc.line, tc.descriptor)
+		assert.NoError(t, err, tc.line)
+		assert.Equal(t, tc.values, values, tc.line)
+		assert.Equal(t, tc.line, FormatLine(values, tc.descriptor))
+	}
+	_, err := ParseLine(`1,"unterminated`, &Descriptor{FileFormat: CSV, Delimiter: ","})
+	assert.Error(t, err)
+}
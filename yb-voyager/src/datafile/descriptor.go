@@ -58,6 +58,8 @@ type Descriptor struct {
 	NullString                 string              `json:"NullString,omitempty"`
 	DataFileList               []*FileEntry        `json:"FileList"`
 	TableNameToExportedColumns map[string][]string `json:"TableNameToExportedColumns"`
+	// Object store location of the data files, if they were not exported to the export directory.
+	DataDir string `json:"DataDir,omitempty"`
 }
 
 func OpenDescriptor(exportDir string) *Descriptor {
@@ -0,0 +1,402 @@
+/*
+Copyright (c) YugabyteDB, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package datafile
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/reader"
+	"github.com/xitongsys/parquet-go/source"
+)
+
+const (
+	// Number of rows read from each column in one go.
+	PARQUET_READ_BATCH_SIZE = 1024
+
+	julianDayOfUnixEpoch = 2440588
+)
+
+// ParquetDataFile presents the rows of a Parquet file as lines in the PostgreSQL
+// TEXT format, so that the rest of the import pipeline can treat it like a TEXT file.
+// The column names are taken from the Parquet schema and returned as the header.
+type ParquetDataFile struct {
+	file      *parquetLocalFile
+	reader    *reader.ParquetReader
+	columns   []*parquet.SchemaElement
+	Delimiter string
+	Header    string
+
+	totalRows int64
+	fileSize  int64
+	rowsRead  int64
+	// Parquet files are compressed and column oriented, so there is no exact mapping from a row to
+	// the bytes it occupies. Every row is accounted an equal share of the file size instead.
+	bytesRead int64
+
+	rows    [][]string
+	nextRow int
+}
+
+func (df *ParquetDataFile) SkipLines(numLines int64) error {
+	for i := int64(1); i <= numLines; i++ {
+		_, err := df.NextLine()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (df *ParquetDataFile) NextLine() (string, error) {
+	if df.nextRow >= len(df.rows) {
+		if df.rowsRead >= df.totalRows {
+			return "", io.EOF
+		}
+		err := df.readRows()
+		if err != nil {
+			return "", err
+		}
+	}
+	line := strings.Join(df.rows[df.nextRow], df.Delimiter)
+	df.nextRow++
+	df.bytesRead += df.sizeOfRows(df.rowsRead+1) - df.sizeOfRows(df.rowsRead)
+	df.rowsRead++
+	return line, nil
+}
+
+// Returns the share of the file size attributed to the first `numRows` rows of the file.
+func (df *ParquetDataFile) sizeOfRows(numRows int64) int64 {
+	if df.totalRows == 0 {
+		return 0
+	}
+	return int64(float64(df.fileSize) * float64(numRows) / float64(df.totalRows))
+}
+
+func (df *ParquetDataFile) readRows() error {
+	numRows := df.totalRows - df.rowsRead
+	if numRows > PARQUET_READ_BATCH_SIZE {
+		numRows = PARQUET_READ_BATCH_SIZE
+	}
+	rows := make([][]string, numRows)
+	for i := range rows {
+		rows[i] = make([]string, len(df.columns))
+	}
+	for colIdx, column := range df.columns {
+		values, _, _, err := df.reader.ReadColumnByIndex(int64(colIdx), numRows)
+		if err != nil {
+			return fmt.Errorf("read column %q: %w", column.GetName(), err)
+		}
+		if int64(len(values)) != numRows {
+			return fmt.Errorf("read column %q: expected %d values, got %d", column.GetName(), numRows, len(values))
+		}
+		for rowIdx, value := range values {
+			rows[rowIdx][colIdx], err = parquetValueToText(column, value)
+			if err != nil {
+				return fmt.Errorf("convert value of column %q: %w", column.GetName(), err)
+			}
+		}
+	}
+	df.rows = rows
+	df.nextRow = 0
+	return nil
+}
+
+func (df *ParquetDataFile) Close() {
+	df.reader.ReadStop()
+	err := df.file.Close()
+	if err != nil {
+		log.Warnf("closing parquet file %q: %v", df.file.path, err)
+	}
+	if df.file.isTemp {
+		err = os.Remove(df.file.path)
+		if err != nil {
+			log.Warnf("removing temporary copy %q of the parquet file: %v", df.file.path, err)
+		}
+	}
+}
+
+func (df *ParquetDataFile) GetBytesRead() int64 {
+	return df.bytesRead
+}
+
+func (df *ParquetDataFile) ResetBytesRead() {
+	df.bytesRead = 0
+}
+
+func (df *ParquetDataFile) GetHeader() string {
+	return df.Header
+}
+
+func newParquetDataFile(filePath string, fileReadCloser io.ReadCloser, descriptor *Descriptor) (*ParquetDataFile, error) {
+	file, err := newParquetLocalFile(filePath, fileReadCloser)
+	if err != nil {
+		return nil, err
+	}
+	pr, err := reader.NewParquetColumnReader(file, 1)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("read parquet footer of %q: %w", filePath, err)
+	}
+	fileSize, err := file.Seek(0, io.SeekEnd)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("find size of %q: %w", filePath, err)
+	}
+	if cr, ok := fileReadCloser.(compressedReader); ok {
+		// The progress is tracked against the size of the compressed file.
+		fileSize = cr.CompressedBytesRead()
+	}
+
+	var columns []*parquet.SchemaElement
+	var columnNames []string
+	for _, path := range pr.SchemaHandler.ValueColumns {
+		idx := pr.SchemaHandler.MapIndex[path]
+		column := pr.SchemaHandler.SchemaElements[idx]
+		name := pr.SchemaHandler.Infos[idx].ExName
+		// Only flat schemas can be mapped to table rows. The path of a top level column is `root.column`.
+		if strings.Count(path, "\x01") != 1 || column.GetRepetitionType() == parquet.FieldRepetitionType_REPEATED {
+			file.Close()
+			return nil, fmt.Errorf("parquet file %q has nested or repeated column %q, which is not supported", filePath, name)
+		}
+		columns = append(columns, column)
+		columnNames = append(columnNames, name)
+	}
+
+	parquetDataFile := &ParquetDataFile{
+		file:      file,
+		reader:    pr,
+		columns:   columns,
+		Delimiter: descriptor.Delimiter,
+		Header:    strings.Join(columnNames, descriptor.Delimiter),
+		totalRows: pr.GetNumRows(),
+		fileSize:  fileSize,
+	}
+	log.Infof("created parquet data file struct for file: %s, rows: %d, columns: %v", filePath, parquetDataFile.totalRows, columnNames)
+	return parquetDataFile, nil
+}
+
+//============================================================================
+
+// Converts a value read from a Parquet column into its PostgreSQL TEXT format representation.
+func parquetValueToText(column *parquet.SchemaElement, value interface{}) (string, error) {
+	if value == nil {
+		return `\N`, nil
+	}
+	logicalType := column.GetLogicalType()
+	var convertedType *parquet.ConvertedType
+	if column.IsSetConvertedType() {
+		convertedType = column.ConvertedType
+	}
+	isConvertedType := func(ct parquet.ConvertedType) bool {
+		return convertedType != nil && *convertedType == ct
+	}
+
+	switch v := value.(type) {
+	case bool:
+		return strconv.FormatBool(v), nil
+	case float32:
+		return formatFloat(float64(v), 32), nil
+	case float64:
+		return formatFloat(v, 64), nil
+	case int32, int64:
+		var n int64
+		if i, ok := v.(int32); ok {
+			n = int64(i)
+		} else {
+			n = v.(int64)
+		}
+		switch {
+		case (logicalType != nil && logicalType.IsSetDECIMAL()) || isConvertedType(parquet.ConvertedType_DECIMAL):
+			return formatDecimal(big.NewInt(n), decimalScale(column)), nil
+		case (logicalType != nil && logicalType.IsSetDATE()) || isConvertedType(parquet.ConvertedType_DATE):
+			return time.Unix(n*24*60*60, 0).UTC().Format("2006-01-02"), nil
+		case logicalType != nil && logicalType.IsSetTIMESTAMP():
+			ts := timeFromUnit(n, logicalType.TIMESTAMP.Unit)
+			return formatTimestamp(ts, logicalType.TIMESTAMP.IsAdjustedToUTC), nil
+		case isConvertedType(parquet.ConvertedType_TIMESTAMP_MILLIS):
+			return formatTimestamp(time.UnixMilli(n).UTC(), true), nil
+		case isConvertedType(parquet.ConvertedType_TIMESTAMP_MICROS):
+			return formatTimestamp(time.UnixMicro(n).UTC(), true), nil
+		case logicalType != nil && logicalType.IsSetTIME():
+			return timeFromUnit(n, logicalType.TIME.Unit).Format("15:04:05.999999"), nil
+		case isConvertedType(parquet.ConvertedType_TIME_MILLIS):
+			return time.UnixMilli(n).UTC().Format("15:04:05.999999"), nil
+		case isConvertedType(parquet.ConvertedType_TIME_MICROS):
+			return time.UnixMicro(n).UTC().Format("15:04:05.999999"), nil
+		case isConvertedType(parquet.ConvertedType_UINT_32):
+			return strconv.FormatUint(uint64(uint32(n)), 10), nil
+		case isConvertedType(parquet.ConvertedType_UINT_64) ||
+			(logicalType != nil && logicalType.IsSetINTEGER() && !logicalType.INTEGER.IsSigned && logicalType.INTEGER.BitWidth == 64):
+			return strconv.FormatUint(uint64(n), 10), nil
+		default:
+			return strconv.FormatInt(n, 10), nil
+		}
+	case string:
+		switch {
+		case column.GetType() == parquet.Type_INT96:
+			return formatTimestamp(timeFromInt96(v), true), nil
+		case (logicalType != nil && logicalType.IsSetDECIMAL()) || isConvertedType(parquet.ConvertedType_DECIMAL):
+			return formatDecimal(bigIntFromBytes([]byte(v)), decimalScale(column)), nil
+		case logicalType != nil && logicalType.IsSetUUID():
+			if len(v) != 16 {
+				return "", fmt.Errorf("invalid UUID of length %d", len(v))
+			}
+			h := hex.EncodeToString([]byte(v))
+			return fmt.Sprintf("%s-%s-%s-%s-%s", h[0:8], h[8:12], h[12:16], h[16:20], h[20:32]), nil
+		case (logicalType != nil && (logicalType.IsSetSTRING() || logicalType.IsSetENUM() || logicalType.IsSetJSON())) ||
+			isConvertedType(parquet.ConvertedType_UTF8) || isConvertedType(parquet.ConvertedType_ENUM) ||
+			isConvertedType(parquet.ConvertedType_JSON):
+			return escapeTextValue(v), nil
+		default:
+			// Binary data is loaded using the hex format of bytea.
+			return escapeTextValue(`\x` + hex.EncodeToString([]byte(v))), nil
+		}
+	default:
+		return "", fmt.Errorf("unsupported value %v of type %T", value, value)
+	}
+}
+
+func formatFloat(f float64, bitSize int) string {
+	switch {
+	case math.IsNaN(f):
+		return "NaN"
+	case math.IsInf(f, 1):
+		return "Infinity"
+	case math.IsInf(f, -1):
+		return "-Infinity"
+	}
+	return strconv.FormatFloat(f, 'g', -1, bitSize)
+}
+
+func decimalScale(column *parquet.SchemaElement) int32 {
+	logicalType := column.GetLogicalType()
+	if logicalType != nil && logicalType.IsSetDECIMAL() {
+		return logicalType.DECIMAL.Scale
+	}
+	return column.GetScale()
+}
+
+func formatDecimal(unscaled *big.Int, scale int32) string {
+	s := unscaled.String()
+	if scale <= 0 {
+		return s
+	}
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+	if len(s) <= int(scale) {
+		s = strings.Repeat("0", int(scale)-len(s)+1) + s
+	}
+	return sign + s[:len(s)-int(scale)] + "." + s[len(s)-int(scale):]
+}
+
+// Decodes a big-endian two's complement integer.
+func bigIntFromBytes(b []byte) *big.Int {
+	n := new(big.Int).SetBytes(b)
+	if len(b) > 0 && b[0]&0x80 != 0 {
+		n.Sub(n, new(big.Int).Lsh(big.NewInt(1), uint(len(b)*8)))
+	}
+	return n
+}
+
+func timeFromUnit(n int64, unit *parquet.TimeUnit) time.Time {
+	switch {
+	case unit.IsSetMILLIS():
+		return time.UnixMilli(n).UTC()
+	case unit.IsSetMICROS():
+		return time.UnixMicro(n).UTC()
+	default:
+		return time.Unix(0, n).UTC()
+	}
+}
+
+// INT96 timestamps are stored as nanoseconds of the day followed by the julian day number.
+func timeFromInt96(v string) time.Time {
+	b := []byte(v)
+	nanos := int64(binary.LittleEndian.Uint64(b[:8]))
+	julianDay := int64(binary.LittleEndian.Uint32(b[8:12]))
+	return time.Unix((julianDay-julianDayOfUnixEpoch)*24*60*60, nanos).UTC()
+}
+
+func formatTimestamp(ts time.Time, isAdjustedToUTC bool) string {
+	if isAdjustedToUTC {
+		return ts.Format("2006-01-02 15:04:05.999999") + "+00"
+	}
+	return ts.Format("2006-01-02 15:04:05.999999")
+}
+
+var textValueEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, "\r", `\r`, "\t", `\t`)
+
+func escapeTextValue(s string) string {
+	return textValueEscaper.Replace(s)
+}
+
+//============================================================================
+
+// parquetLocalFile implements source.ParquetFile on top of a file on the local disk.
+// The Parquet reader needs random access to the file, so the files that are not
+// on the local disk (e.g. S3 objects) are first copied to a temporary file.
+type parquetLocalFile struct {
+	*os.File
+	path   string
+	isTemp bool
+}
+
+var _ source.ParquetFile = (*parquetLocalFile)(nil)
+
+func newParquetLocalFile(filePath string, fileReadCloser io.ReadCloser) (*parquetLocalFile, error) {
+	if f, ok := fileReadCloser.(*os.File); ok {
+		return &parquetLocalFile{File: f, path: f.Name()}, nil
+	}
+	defer fileReadCloser.Close()
+	f, err := os.CreateTemp("", "yb-voyager-*.parquet")
+	if err != nil {
+		return nil, fmt.Errorf("create temporary file for %q: %w", filePath, err)
+	}
+	log.Infof("copying parquet file %q to %q", filePath, f.Name())
+	_, err = io.Copy(f, fileReadCloser)
+	if err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return nil, fmt.Errorf("copy %q to temporary file: %w", filePath, err)
+	}
+	return &parquetLocalFile{File: f, path: f.Name(), isTemp: true}, nil
+}
+
+// Open returns a new handle on the same file. The reader uses a separate handle for each column.
+func (f *parquetLocalFile) Open(name string) (source.ParquetFile, error) {
+	file, err := os.Open(f.path)
+	if err != nil {
+		return nil, err
+	}
+	return &parquetLocalFile{File: file, path: f.path}, nil
+}
+
+func (f *parquetLocalFile) Create(name string) (source.ParquetFile, error) {
+	return nil, fmt.Errorf("parquet file %q is opened for reading", f.path)
+}
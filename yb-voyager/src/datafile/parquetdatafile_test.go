@@ -0,0 +1,91 @@
+package datafile
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xitongsys/parquet-go/writer"
+)
+
+func TestParquetRoundTrip(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	textFilePath := filepath.Join(dir, "foo_data.sql")
+	textLines := "COPY foo (id, \"Name\", data) FROM STDIN;\n1\tone\t\\N\n2\ttab\\there\\\\x01\t\n3\tnew\\nline\t\\\\x0a0b\n\\.\n"
+	assert.NoError(os.WriteFile(textFilePath, []byte(textLines), 0644))
+
+	textDescriptor := &Descriptor{FileFormat: SQL, Delimiter: "\t", NullString: `\N`}
+	f, err := os.Open(textFilePath)
+	assert.NoError(err)
+	textFile, err := NewDataFile(textFilePath, f, textDescriptor)
+	assert.NoError(err)
+	parquetFilePath := filepath.Join(dir, "foo_data.parquet")
+	parquetFileWriter, err := os.Create(parquetFilePath)
+	assert.NoError(err)
+	numRows, err := WriteParquet(parquetFileWriter, []string{"id", `"Name"`, "data"}, textFile, textDescriptor)
+	textFile.Close()
+	assert.NoError(err)
+	assert.NoError(parquetFileWriter.Close())
+	assert.Equal(int64(3), numRows)
+
+	parquetDescriptor := &Descriptor{FileFormat: PARQUET, Delimiter: "\t", NullString: `\N`}
+	f, err = os.Open(parquetFilePath)
+	assert.NoError(err)
+	parquetFile, err := NewDataFile(parquetFilePath, f, parquetDescriptor)
+	assert.NoError(err)
+	defer parquetFile.Close()
+	assert.Equal("id\tName\tdata", parquetFile.GetHeader())
+
+	expectedLines := []string{
+		"1\tone\t\\N",
+		"2\ttab\\there\\\\x01\t",
+		"3\tnew\\nline\t\\\\x0a0b",
+	}
+	for _, expectedLine := range expectedLines {
+		line, err := parquetFile.NextLine()
+		assert.NoError(err)
+		assert.Equal(expectedLine, line)
+	}
+	_, err = parquetFile.NextLine()
+	assert.Equal(io.EOF, err)
+	fileInfo, err := os.Stat(parquetFilePath)
+	assert.NoError(err)
+	assert.Equal(fileInfo.Size(), parquetFile.GetBytesRead())
+}
+
+func TestParquetLogicalTypes(t *testing.T) {
+	assert := assert.New(t)
+	filePath := filepath.Join(t.TempDir(), "types.parquet")
+	f, err := os.Create(filePath)
+	assert.NoError(err)
+	pw, err := writer.NewCSVWriterFromWriter([]string{
+		"name=i, type=INT64, repetitiontype=OPTIONAL",
+		"name=b, type=BOOLEAN, repetitiontype=OPTIONAL",
+		"name=d, type=INT32, convertedtype=DATE, repetitiontype=OPTIONAL",
+		"name=ts, type=INT64, logicaltype=TIMESTAMP, logicaltype.isadjustedtoutc=true, logicaltype.unit=MICROS, repetitiontype=OPTIONAL",
+		"name=amount, type=INT64, convertedtype=DECIMAL, scale=2, precision=10, repetitiontype=OPTIONAL",
+		"name=s, type=BYTE_ARRAY, convertedtype=UTF8, repetitiontype=OPTIONAL",
+		"name=raw, type=BYTE_ARRAY, repetitiontype=OPTIONAL",
+	}, f, 1)
+	assert.NoError(err)
+	str := func(s string) *string { return &s }
+	assert.NoError(pw.WriteString([]*string{str("42"), str("true"), str("19635"), str("1696500000000000"), str("-12.34"), str("a\\b"), str("\x01\xff")}))
+	assert.NoError(pw.WriteString([]*string{nil, nil, nil, nil, nil, nil, nil}))
+	assert.NoError(pw.WriteStop())
+	assert.NoError(f.Close())
+
+	f, err = os.Open(filePath)
+	assert.NoError(err)
+	df, err := NewDataFile(filePath, f, &Descriptor{FileFormat: PARQUET, Delimiter: "\t"})
+	assert.NoError(err)
+	defer df.Close()
+	line, err := df.NextLine()
+	assert.NoError(err)
+	assert.Equal("42\ttrue\t2023-10-05\t2023-10-05 10:00:00+00\t-12.34\ta\\\\b\t\\\\x01ff", line)
+	line, err = df.NextLine()
+	assert.NoError(err)
+	assert.Equal("\\N\t\\N\t\\N\t\\N\t\\N\t\\N\t\\N", line)
+}
@@ -0,0 +1,181 @@
+/*
+Copyright (c) YugabyteDB, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package datafile
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/xitongsys/parquet-go/writer"
+)
+
+// WriteParquet writes all the rows of `dataFile` to `w` as a Parquet file and returns the number of
+// rows written. The lines of `dataFile` must be in the TEXT format, which is the case for the TEXT and
+// SQL data files. Every column is written as a nullable UTF8 string holding the TEXT representation of
+// the value, which the target database casts to the column type on import.
+func WriteParquet(w io.Writer, columnNames []string, dataFile DataFile, descriptor *Descriptor) (int64, error) {
+	if descriptor.FileFormat != TEXT && descriptor.FileFormat != SQL {
+		return 0, fmt.Errorf("conversion of %q data files to parquet is not supported", descriptor.FileFormat)
+	}
+	var schema []string
+	for _, name := range columnNames {
+		name = strings.Trim(name, `"`)
+		if strings.ContainsAny(name, ",=") {
+			return 0, fmt.Errorf("column name %q is not supported in parquet files", name)
+		}
+		schema = append(schema, fmt.Sprintf("name=%s, type=BYTE_ARRAY, convertedtype=UTF8, repetitiontype=OPTIONAL", name))
+	}
+
+	pw, err := writer.NewCSVWriterFromWriter(schema, w, 4)
+	if err != nil {
+		return 0, fmt.Errorf("create parquet writer: %w", err)
+	}
+
+	var numRows int64
+	for {
+		line, err := dataFile.NextLine()
+		if err != nil && err != io.EOF {
+			return numRows, fmt.Errorf("read line: %w", err)
+		}
+		if line != "" {
+			values, parseErr := parseTextLine(line, descriptor.Delimiter, descriptor.NullString)
+			if parseErr != nil {
+				return numRows, fmt.Errorf("parse line %d: %w", numRows+1, parseErr)
+			}
+			if len(values) != len(columnNames) {
+				return numRows, fmt.Errorf("line %d has %d values, expected %d", numRows+1, len(values), len(columnNames))
+			}
+			writeErr := pw.WriteString(values)
+			if writeErr != nil {
+				return numRows, fmt.Errorf("write row %d: %w", numRows+1, writeErr)
+			}
+			numRows++
+		}
+		if err == io.EOF {
+			break
+		}
+	}
+	err = pw.WriteStop()
+	if err != nil {
+		return numRows, fmt.Errorf("finalize parquet file: %w", err)
+	}
+	log.Infof("wrote %d rows in parquet format", numRows)
+	return numRows, nil
+}
+
+// Splits a line in the TEXT format into its values, resolving the backslash escape sequences.
+// A nil value represents NULL.
+func parseTextLine(line string, delimiter string, nullString string) ([]*string, error) {
+	if nullString == "" {
+		nullString = `\N`
+	}
+	var values []*string
+	for _, field := range splitTextFields(line, delimiter) {
+		if field == nullString {
+			values = append(values, nil)
+			continue
+		}
+		value, err := unescapeTextValue(field)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, &value)
+	}
+	return values, nil
+}
+
+// Splits the line at the delimiters that are not escaped with a backslash.
+func splitTextFields(line string, delimiter string) []string {
+	if !strings.Contains(line, `\`) {
+		return strings.Split(line, delimiter)
+	}
+	var fields []string
+	start := 0
+	for i := 0; i < len(line); i++ {
+		if line[i] == '\\' {
+			i++
+			continue
+		}
+		if strings.HasPrefix(line[i:], delimiter) {
+			fields = append(fields, line[start:i])
+			start = i + len(delimiter)
+			i = start - 1
+		}
+	}
+	return append(fields, line[start:])
+}
+
+func unescapeTextValue(field string) (string, error) {
+	if !strings.Contains(field, `\`) {
+		return field, nil
+	}
+	var sb strings.Builder
+	for i := 0; i < len(field); i++ {
+		if field[i] != '\\' {
+			sb.WriteByte(field[i])
+			continue
+		}
+		i++
+		if i == len(field) {
+			return "", fmt.Errorf("value %q ends with an incomplete escape sequence", field)
+		}
+		switch c := field[i]; c {
+		case 'b':
+			sb.WriteByte('\b')
+		case 'f':
+			sb.WriteByte('\f')
+		case 'n':
+			sb.WriteByte('\n')
+		case 'r':
+			sb.WriteByte('\r')
+		case 't':
+			sb.WriteByte('\t')
+		case 'v':
+			sb.WriteByte('\v')
+		case 'x':
+			j := i + 1
+			for j < len(field) && j < i+3 && isHexDigit(field[j]) {
+				j++
+			}
+			if j == i+1 {
+				// Not followed by a hex digit; taken literally.
+				sb.WriteByte(c)
+				continue
+			}
+			b, _ := strconv.ParseUint(field[i+1:j], 16, 8)
+			sb.WriteByte(byte(b))
+			i = j - 1
+		case '0', '1', '2', '3', '4', '5', '6', '7':
+			j := i
+			for j < len(field) && j < i+3 && field[j] >= '0' && field[j] <= '7' {
+				j++
+			}
+			b, _ := strconv.ParseUint(field[i:j], 8, 16)
+			sb.WriteByte(byte(b))
+			i = j - 1
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return sb.String(), nil
+}
+
+func isHexDigit(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
@@ -0,0 +1,159 @@
+/*
+Copyright (c) YugabyteDB, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package datafile
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ParseLine splits a line returned by DataFile.NextLine() into its column values.
+// A nil value represents NULL. The lines of the SQL and PARQUET data files are in the TEXT format.
+func ParseLine(line string, descriptor *Descriptor) ([]*string, error) {
+	switch descriptor.FileFormat {
+	case CSV:
+		return parseCsvLine(line, descriptor)
+	case TEXT, SQL, PARQUET:
+		return parseTextLine(line, textDelimiter(descriptor), descriptor.NullString)
+	default:
+		return nil, fmt.Errorf("parsing lines of %q data files is not supported", descriptor.FileFormat)
+	}
+}
+
+// FormatLine joins the column values into a line in the format of the data file. It is the inverse of ParseLine.
+func FormatLine(values []*string, descriptor *Descriptor) string {
+	fields := make([]string, len(values))
+	switch descriptor.FileFormat {
+	case CSV:
+		delimiter, quoteChar, escapeChar := csvDialect(descriptor)
+		for i, value := range values {
+			if value == nil {
+				fields[i] = descriptor.NullString
+			} else {
+				fields[i] = formatCsvValue(*value, delimiter, quoteChar, escapeChar, descriptor.NullString)
+			}
+		}
+		return strings.Join(fields, delimiter)
+	default:
+		delimiter := textDelimiter(descriptor)
+		nullString := descriptor.NullString
+		if nullString == "" {
+			nullString = `\N`
+		}
+		for i, value := range values {
+			if value == nil {
+				fields[i] = nullString
+			} else {
+				fields[i] = escapeTextValue(*value)
+				if delimiter != "\t" {
+					fields[i] = strings.ReplaceAll(fields[i], delimiter, `\`+delimiter)
+				}
+			}
+		}
+		return strings.Join(fields, delimiter)
+	}
+}
+
+func textDelimiter(descriptor *Descriptor) string {
+	if descriptor.Delimiter == "" {
+		return "\t"
+	}
+	return descriptor.Delimiter
+}
+
+func csvDialect(descriptor *Descriptor) (delimiter string, quoteChar, escapeChar byte) {
+	delimiter, quoteChar, escapeChar = descriptor.Delimiter, descriptor.QuoteChar, descriptor.EscapeChar
+	if delimiter == "" {
+		delimiter = ","
+	}
+	if quoteChar == 0 {
+		quoteChar = '"'
+	}
+	if escapeChar == 0 {
+		escapeChar = quoteChar
+	}
+	return delimiter, quoteChar, escapeChar
+}
+
+// Parses a line in the CSV format as understood by the COPY command: only the unquoted values that
+// match the null string are NULL, and inside the quoted values the escape character escapes the
+// quote and the escape characters.
+func parseCsvLine(line string, descriptor *Descriptor) ([]*string, error) {
+	delimiter, quoteChar, escapeChar := csvDialect(descriptor)
+	var values []*string
+	i := 0
+	for {
+		var sb strings.Builder
+		quoted := false
+		for i < len(line) && !strings.HasPrefix(line[i:], delimiter) {
+			if line[i] != quoteChar {
+				sb.WriteByte(line[i])
+				i++
+				continue
+			}
+			quoted = true
+			i++ // Opening quote.
+			closed := false
+			for i < len(line) {
+				c := line[i]
+				if c == escapeChar && i+1 < len(line) && (line[i+1] == quoteChar || line[i+1] == escapeChar) &&
+					!(escapeChar == quoteChar && line[i+1] != quoteChar) {
+					sb.WriteByte(line[i+1])
+					i += 2
+					continue
+				}
+				if c == quoteChar {
+					closed = true
+					i++
+					break
+				}
+				sb.WriteByte(c)
+				i++
+			}
+			if !closed {
+				return nil, fmt.Errorf("unterminated quoted value in line %q", line)
+			}
+		}
+		value := sb.String()
+		if !quoted && value == descriptor.NullString {
+			values = append(values, nil)
+		} else {
+			values = append(values, &value)
+		}
+		if i >= len(line) {
+			return values, nil
+		}
+		i += len(delimiter)
+	}
+}
+
+func formatCsvValue(value string, delimiter string, quoteChar, escapeChar byte, nullString string) string {
+	needsQuotes := value == "" || value == nullString || strings.Contains(value, delimiter) ||
+		strings.ContainsAny(value, string([]byte{quoteChar, escapeChar, '\n', '\r'}))
+	if !needsQuotes {
+		return value
+	}
+	var sb strings.Builder
+	sb.WriteByte(quoteChar)
+	for i := 0; i < len(value); i++ {
+		if value[i] == quoteChar || value[i] == escapeChar {
+			sb.WriteByte(escapeChar)
+		}
+		sb.WriteByte(value[i])
+	}
+	sb.WriteByte(quoteChar)
+	return sb.String()
+}
@@ -38,7 +38,6 @@ func (df *SqlDataFile) SkipLines(numLines int64) error {
 			return err
 		}
 	}
-	df.ResetBytesRead()
 	return nil
 }
 
@@ -41,7 +41,6 @@ func (df *TextDataFile) SkipLines(numLines int64) error {
 			return err
 		}
 	}
-	df.ResetBytesRead()
 	return nil
 }
 
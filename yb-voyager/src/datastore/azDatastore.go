@@ -75,12 +75,43 @@ func (ds *AzDataStore) FileSize(filePath string) (int64, error) {
 // Open the file at the given path for reading.
 func (ds *AzDataStore) Open(objectPath string) (io.ReadCloser, error) {
 	if strings.HasPrefix(objectPath, "https://") {
-		return az.NewObjectReader(objectPath)
+		r, err := az.NewObjectReader(objectPath)
+		return decompressIfRequired(objectPath, r, err)
 	}
 	// if objectPath is hidden underneath a symlink for az blobs...
 	objectPath, err := os.Readlink(objectPath)
 	if err != nil {
 		utils.ErrExit("unable to resolve symlink %v to gcs resource: %w", objectPath, err)
 	}
-	return az.NewObjectReader(objectPath)
+	r, err := az.NewObjectReader(objectPath)
+	return decompressIfRequired(objectPath, r, err)
+}
+
+func (ds *AzDataStore) OpenAt(objectPath string, offset int64) (io.ReadCloser, error) {
+	if !strings.HasPrefix(objectPath, "https://") {
+		// if objectPath is hidden underneath a symlink...
+		targetPath, err := os.Readlink(objectPath)
+		if err != nil {
+			utils.ErrExit("unable to resolve symlink %v to az blob: %w", objectPath, err)
+		}
+		objectPath = targetPath
+	}
+	err := checkOffsetReadSupported(objectPath, offset)
+	if err != nil {
+		return nil, err
+	}
+	r, err := az.NewObjectRangeReader(objectPath, offset)
+	return decompressIfRequired(objectPath, r, err)
+}
+
+func (ds *AzDataStore) Create(objectPath string) (io.WriteCloser, error) {
+	return az.NewObjectWriter(objectPath)
+}
+
+func (ds *AzDataStore) Remove(objectPath string) error {
+	return az.DeleteObject(objectPath)
+}
+
+func (ds *AzDataStore) Rename(oldObjectPath, newObjectPath string) error {
+	return az.RenameObject(oldObjectPath, newObjectPath)
 }
@@ -0,0 +1,138 @@
+/*
+Copyright (c) YugabyteDB, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package datastore
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	GZIP = "gzip"
+	ZSTD = "zstd"
+)
+
+var compressionTypeToFileExtension = map[string]string{
+	GZIP: ".gz",
+	ZSTD: ".zst",
+}
+
+// CompressedFileExtensions returns the file extensions of the supported compression types.
+func CompressedFileExtensions() []string {
+	return []string{compressionTypeToFileExtension[GZIP], compressionTypeToFileExtension[ZSTD]}
+}
+
+// GetCompressionType returns the compression type of the file as per its extension, or "" if the file
+// is not compressed.
+func GetCompressionType(filePath string) string {
+	for compressionType, ext := range compressionTypeToFileExtension {
+		if strings.HasSuffix(filePath, ext) {
+			return compressionType
+		}
+	}
+	return ""
+}
+
+func GetCompressedFileExtension(compressionType string) string {
+	return compressionTypeToFileExtension[compressionType]
+}
+
+// DecompressingReader decompresses the underlying file on the fly. It also tracks the number of
+// compressed bytes consumed so far, so that the progress can be reported against the size of the file.
+type DecompressingReader struct {
+	io.Reader
+	compressed *countingReadCloser
+	closeFn    func()
+}
+
+func (r *DecompressingReader) CompressedBytesRead() int64 {
+	return r.compressed.bytesRead
+}
+
+func (r *DecompressingReader) Close() error {
+	if r.closeFn != nil {
+		r.closeFn()
+	}
+	return r.compressed.Close()
+}
+
+type countingReadCloser struct {
+	io.ReadCloser
+	bytesRead int64
+}
+
+func (r *countingReadCloser) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.bytesRead += int64(n)
+	return n, err
+}
+
+// Compressed files can only be read from the start, as decompression requires the preceding bytes.
+func checkOffsetReadSupported(filePath string, offset int64) error {
+	if offset != 0 && GetCompressionType(filePath) != "" {
+		return fmt.Errorf("reading the compressed file %q from offset %d is not supported", filePath, offset)
+	}
+	return nil
+}
+
+// decompressIfRequired wraps the reader of a compressed file into a DecompressingReader.
+// Readers of the files that are not compressed are returned as is.
+func decompressIfRequired(filePath string, r io.ReadCloser, err error) (io.ReadCloser, error) {
+	if err != nil {
+		return nil, err
+	}
+	compressionType := GetCompressionType(filePath)
+	if compressionType == "" {
+		return r, nil
+	}
+	compressed := &countingReadCloser{ReadCloser: r}
+	switch compressionType {
+	case GZIP:
+		gzipReader, err := gzip.NewReader(bufio.NewReader(compressed))
+		if err != nil {
+			r.Close()
+			return nil, fmt.Errorf("open gzip reader for %q: %w", filePath, err)
+		}
+		return &DecompressingReader{Reader: gzipReader, compressed: compressed, closeFn: func() { gzipReader.Close() }}, nil
+	case ZSTD:
+		zstdReader, err := zstd.NewReader(compressed)
+		if err != nil {
+			r.Close()
+			return nil, fmt.Errorf("open zstd reader for %q: %w", filePath, err)
+		}
+		return &DecompressingReader{Reader: zstdReader, compressed: compressed, closeFn: zstdReader.Close}, nil
+	default:
+		panic(fmt.Sprintf("unknown compression type %q", compressionType))
+	}
+}
+
+// NewCompressedWriter returns a writer that compresses the data written to it into `w`.
+// Closing the returned writer doesn't close `w`.
+func NewCompressedWriter(w io.Writer, compressionType string) (io.WriteCloser, error) {
+	switch compressionType {
+	case GZIP:
+		return gzip.NewWriter(w), nil
+	case ZSTD:
+		return zstd.NewWriter(w)
+	default:
+		return nil, fmt.Errorf("unknown compression type %q", compressionType)
+	}
+}
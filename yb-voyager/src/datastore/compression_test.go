@@ -0,0 +1,75 @@
+package datastore
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yugabyte/yb-voyager/yb-voyager/src/datafile"
+)
+
+func TestCompressedDataFile(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	filePath := filepath.Join(dir, "orders.csv")
+	var lines []string
+	for i := 0; i < 1000; i++ {
+		lines = append(lines, fmt.Sprintf("%d,order-%d", i, i))
+	}
+	assert.NoError(os.WriteFile(filePath, []byte(strings.Join(lines, "\n")+"\n"), 0644))
+
+	ds := NewLocalDataStore(dir)
+	for _, compressionType := range []string{GZIP, ZSTD} {
+		compressedFilePath := filePath + GetCompressedFileExtension(compressionType)
+		compressFile(t, filePath, compressedFilePath, compressionType)
+		assert.Equal(compressionType, GetCompressionType(compressedFilePath))
+		fileSize, err := ds.FileSize(compressedFilePath)
+		assert.NoError(err)
+
+		r, err := ds.Open(compressedFilePath)
+		assert.NoError(err)
+		df, err := datafile.NewDataFile(compressedFilePath, r, &datafile.Descriptor{FileFormat: datafile.CSV, Delimiter: ","})
+		assert.NoError(err)
+		var readLines []string
+		var bytesRead int64
+		for {
+			line, err := df.NextLine()
+			if line != "" {
+				readLines = append(readLines, line)
+			}
+			if len(readLines)%100 == 0 {
+				bytesRead += df.GetBytesRead()
+				df.ResetBytesRead()
+			}
+			if err == io.EOF {
+				break
+			}
+			assert.NoError(err)
+		}
+		bytesRead += df.GetBytesRead()
+		df.Close()
+		assert.Equal(lines, readLines, compressionType)
+		// The bytes read add up to the size of the compressed file, against which the progress is reported.
+		assert.Equal(fileSize, bytesRead, compressionType)
+	}
+
+}
+
+func compressFile(t *testing.T, srcPath, dstPath, compressionType string) {
+	src, err := os.Open(srcPath)
+	assert.NoError(t, err)
+	defer src.Close()
+	dst, err := os.Create(dstPath)
+	assert.NoError(t, err)
+	defer dst.Close()
+	w, err := NewCompressedWriter(dst, compressionType)
+	assert.NoError(t, err)
+	_, err = io.Copy(w, src)
+	assert.NoError(t, err)
+	assert.NoError(t, w.Close())
+}
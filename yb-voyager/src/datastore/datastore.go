@@ -17,6 +17,7 @@ package datastore
 
 import (
 	"io"
+	"path/filepath"
 	"strings"
 )
 
@@ -25,6 +26,46 @@ type DataStore interface {
 	AbsolutePath(string) (string, error)
 	FileSize(string) (int64, error)
 	Open(string) (io.ReadCloser, error)
+	// OpenAt opens the file for reading from the given byte offset. The remote files are read using
+	// HTTP range requests. Compressed files can only be opened at offset 0.
+	OpenAt(string, int64) (io.ReadCloser, error)
+	// Create returns a writer for a new file at the given path, replacing the existing file if any.
+	// In case of the object stores, the file becomes visible only after the writer is closed.
+	Create(string) (io.WriteCloser, error)
+	// Remove deletes the file at the given path. It is not an error if the file doesn't exist.
+	Remove(string) error
+	Rename(string, string) error
+}
+
+// AbortWrite discards the file being written by a writer returned by Create. The object store writers
+// cancel the upload, so that a partially written object is never published.
+func AbortWrite(w io.WriteCloser) error {
+	if aborter, ok := w.(interface{ Abort() error }); ok {
+		return aborter.Abort()
+	}
+	return w.Close()
+}
+
+// IsRemoteLocation returns true if the location is in an object store (AWS S3, GCS or Azure blob storage).
+func IsRemoteLocation(location string) bool {
+	return strings.HasPrefix(location, "s3://") ||
+		strings.HasPrefix(location, "gs://") ||
+		strings.HasPrefix(location, "https://")
+}
+
+// JoinPath joins the file name to the location of a data store.
+func JoinPath(location string, name string) string {
+	switch true {
+	case strings.HasPrefix(location, "s3://"):
+		// The query parameters of the s3 URLs hold the connection settings of the bucket,
+		// which are registered when the data store is created.
+		location, _, _ = strings.Cut(location, "?")
+		return strings.TrimSuffix(location, "/") + "/" + name
+	case IsRemoteLocation(location):
+		return strings.TrimSuffix(location, "/") + "/" + name
+	default:
+		return filepath.Join(location, name)
+	}
 }
 
 func NewDataStore(location string) DataStore {
@@ -75,12 +75,43 @@ func (ds *GCSDataStore) FileSize(filePath string) (int64, error) {
 
 func (ds *GCSDataStore) Open(resourceName string) (io.ReadCloser, error) {
 	if strings.HasPrefix(resourceName, "gs://") {
-		return gcs.NewObjectReader(resourceName)
+		r, err := gcs.NewObjectReader(resourceName)
+		return decompressIfRequired(resourceName, r, err)
 	}
 	// if resourceName is hidden underneath a symlink for gcs objects...
 	objectPath, err := os.Readlink(resourceName)
 	if err != nil {
 		utils.ErrExit("unable to resolve symlink %v to gcs resource: %w", resourceName, err)
 	}
-	return gcs.NewObjectReader(objectPath)
+	r, err := gcs.NewObjectReader(objectPath)
+	return decompressIfRequired(objectPath, r, err)
+}
+
+func (ds *GCSDataStore) OpenAt(resourceName string, offset int64) (io.ReadCloser, error) {
+	if !strings.HasPrefix(resourceName, "gs://") {
+		// if resourceName is hidden underneath a symlink...
+		objectPath, err := os.Readlink(resourceName)
+		if err != nil {
+			utils.ErrExit("unable to resolve symlink %v to gcs resource: %w", resourceName, err)
+		}
+		resourceName = objectPath
+	}
+	err := checkOffsetReadSupported(resourceName, offset)
+	if err != nil {
+		return nil, err
+	}
+	r, err := gcs.NewObjectRangeReader(resourceName, offset)
+	return decompressIfRequired(resourceName, r, err)
+}
+
+func (ds *GCSDataStore) Create(objectPath string) (io.WriteCloser, error) {
+	return gcs.NewObjectWriter(objectPath)
+}
+
+func (ds *GCSDataStore) Remove(objectPath string) error {
+	return gcs.DeleteObject(objectPath)
+}
+
+func (ds *GCSDataStore) Rename(oldObjectPath, newObjectPath string) error {
+	return gcs.RenameObject(oldObjectPath, newObjectPath)
 }
@@ -17,6 +17,7 @@ limitations under the License.
 package datastore
 
 import (
+	"fmt"
 	"io"
 	"os"
 	"path/filepath"
@@ -55,5 +56,42 @@ func (ds *LocalDataStore) FileSize(filePath string) (int64, error) {
 }
 
 func (ds *LocalDataStore) Open(filePath string) (io.ReadCloser, error) {
-	return os.Open(filePath)
+	file, err := os.Open(filePath)
+	return decompressIfRequired(filePath, file, err)
+}
+
+func (ds *LocalDataStore) OpenAt(filePath string, offset int64) (io.ReadCloser, error) {
+	err := checkOffsetReadSupported(filePath, offset)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(filePath)
+	if err == nil && offset > 0 {
+		_, err = file.Seek(offset, io.SeekStart)
+		if err != nil {
+			file.Close()
+			return nil, fmt.Errorf("seek to offset %d in %q: %w", offset, filePath, err)
+		}
+	}
+	return decompressIfRequired(filePath, file, err)
+}
+
+func (ds *LocalDataStore) Create(filePath string) (io.WriteCloser, error) {
+	err := os.MkdirAll(filepath.Dir(filePath), 0755)
+	if err != nil {
+		return nil, err
+	}
+	return os.Create(filePath)
+}
+
+func (ds *LocalDataStore) Remove(filePath string) error {
+	err := os.Remove(filePath)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (ds *LocalDataStore) Rename(oldPath, newPath string) error {
+	return os.Rename(oldPath, newPath)
 }
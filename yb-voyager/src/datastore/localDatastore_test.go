@@ -0,0 +1,78 @@
+package datastore
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocalDataStoreWrite(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	ds := NewLocalDataStore(dir)
+
+	tmpFilePath := filepath.Join(dir, "segments", "segment.1.ndjson.tmp")
+	w, err := ds.Create(tmpFilePath)
+	assert.NoError(err)
+	_, err = io.WriteString(w, "event\n")
+	assert.NoError(err)
+	assert.NoError(w.Close())
+
+	filePath := filepath.Join(dir, "segments", "segment.1.ndjson")
+	assert.NoError(ds.Rename(tmpFilePath, filePath))
+	size, err := ds.FileSize(filePath)
+	assert.NoError(err)
+	assert.Equal(int64(len("event\n")), size)
+	_, err = os.Stat(tmpFilePath)
+	assert.True(os.IsNotExist(err))
+
+	assert.NoError(ds.Remove(filePath))
+	_, err = os.Stat(filePath)
+	assert.True(os.IsNotExist(err))
+	// Removing a file that doesn't exist is not an error.
+	assert.NoError(ds.Remove(filePath))
+}
+
+func TestIsRemoteLocation(t *testing.T) {
+	assert := assert.New(t)
+	assert.True(IsRemoteLocation("s3://bucket/dir"))
+	assert.True(IsRemoteLocation("gs://bucket/dir"))
+	assert.True(IsRemoteLocation("https://account.blob.core.windows.net/container"))
+	assert.False(IsRemoteLocation("/home/user/export-dir"))
+}
+
+func TestJoinPath(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal("/export-dir/data/orders_data.sql", JoinPath("/export-dir/data/", "orders_data.sql"))
+	assert.Equal("gs://bucket/dir/orders_data.sql", JoinPath("gs://bucket/dir/", "orders_data.sql"))
+	assert.Equal("s3://bucket/dir/orders_data.sql", JoinPath("s3://bucket/dir?endpoint=http://minio:9000&use_path_style=true", "orders_data.sql"))
+}
+
+func TestLocalDataStoreOpenAt(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	ds := NewLocalDataStore(dir)
+	filePath := filepath.Join(dir, "orders_data.sql")
+	assert.NoError(os.WriteFile(filePath, []byte("1\tone\n2\ttwo\n"), 0644))
+
+	r, err := ds.OpenAt(filePath, int64(len("1\tone\n")))
+	assert.NoError(err)
+	content, err := io.ReadAll(r)
+	assert.NoError(err)
+	assert.NoError(r.Close())
+	assert.Equal("2\ttwo\n", string(content))
+
+	compressedFilePath := filePath + GetCompressedFileExtension(GZIP)
+	compressFile(t, filePath, compressedFilePath, GZIP)
+	_, err = ds.OpenAt(compressedFilePath, 1)
+	assert.ErrorContains(err, "not supported")
+	r, err = ds.OpenAt(compressedFilePath, 0)
+	assert.NoError(err)
+	content, err = io.ReadAll(r)
+	assert.NoError(err)
+	assert.NoError(r.Close())
+	assert.Equal("1\tone\n2\ttwo\n", string(content))
+}
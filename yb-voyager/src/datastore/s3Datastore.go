@@ -33,8 +33,14 @@ type S3DataStore struct {
 	bucketName string
 }
 
+// The connection settings of S3-compatible stores can be given as query parameters of the resource URL.
+// See s3.Config for details.
 func NewS3DataStore(resourceName string) *S3DataStore {
-	url, err := url.Parse(resourceName)
+	dataDir, _, err := s3.ParseDataDirURL(resourceName)
+	if err != nil {
+		utils.ErrExit("invalid s3 resource URL %v: %v", resourceName, err)
+	}
+	url, err := url.Parse(dataDir)
 	if err != nil {
 		utils.ErrExit("invalid s3 resource URL %v", resourceName)
 	}
@@ -75,12 +81,43 @@ func (ds *S3DataStore) FileSize(filePath string) (int64, error) {
 
 func (ds *S3DataStore) Open(resourceName string) (io.ReadCloser, error) {
 	if strings.HasPrefix(resourceName, "s3://") {
-		return s3.NewObjectReader(resourceName)
+		r, err := s3.NewObjectReader(resourceName)
+		return decompressIfRequired(resourceName, r, err)
 	}
 	// if resourceName is hidden underneath a symlink for s3 objects...
 	objectPath, err := os.Readlink(resourceName)
 	if err != nil {
 		utils.ErrExit("unable to resolve symlink %v to s3 resource: %w", resourceName, err)
 	}
-	return s3.NewObjectReader(objectPath)
+	r, err := s3.NewObjectReader(objectPath)
+	return decompressIfRequired(objectPath, r, err)
+}
+
+func (ds *S3DataStore) OpenAt(resourceName string, offset int64) (io.ReadCloser, error) {
+	if !strings.HasPrefix(resourceName, "s3://") {
+		// if resourceName is hidden underneath a symlink...
+		objectPath, err := os.Readlink(resourceName)
+		if err != nil {
+			utils.ErrExit("unable to resolve symlink %v to s3 resource: %w", resourceName, err)
+		}
+		resourceName = objectPath
+	}
+	err := checkOffsetReadSupported(resourceName, offset)
+	if err != nil {
+		return nil, err
+	}
+	r, err := s3.NewObjectRangeReader(resourceName, offset)
+	return decompressIfRequired(resourceName, r, err)
+}
+
+func (ds *S3DataStore) Create(objectPath string) (io.WriteCloser, error) {
+	return s3.NewObjectWriter(objectPath)
+}
+
+func (ds *S3DataStore) Remove(objectPath string) error {
+	return s3.DeleteObject(objectPath)
+}
+
+func (ds *S3DataStore) Rename(oldObjectPath, newObjectPath string) error {
+	return s3.RenameObject(oldObjectPath, newObjectPath)
 }
@@ -193,6 +193,15 @@ debezium.source.connector.class=io.debezium.connector.mysql.MySqlConnector
 debezium.source.schema.history.internal=io.debezium.storage.file.history.FileSchemaHistory
 debezium.source.schema.history.internal.file.filename=%s
 debezium.source.include.schema.changes=false
+debezium.source.bigint.unsigned.handling.mode=precise
+`
+
+// The snapshot is consistent with the binlog position it starts streaming from. The global read lock is held
+// only while reading the schemas of the tables.
+var mysqlLiveMigrationSrcConfigTemplate = `
+debezium.source.snapshot.locking.mode=minimal
+debezium.source.schema.history.internal.store.only.captured.tables.ddl=true
+debezium.source.schema.history.internal.skip.unparseable.ddl=true
 `
 
 var mysqlConfigTemplate = baseConfigTemplate +
@@ -369,6 +378,9 @@ func (c *Config) String() string {
 			c.MetadataDBPath,
 			c.RunId,
 			c.ExporterRole)
+		if c.SnapshotMode == "initial" || c.SnapshotMode == "never" {
+			conf = conf + mysqlLiveMigrationSrcConfigTemplate
+		}
 		sslConf := fmt.Sprintf(mysqlSSLConfigTemplate, c.SSLMode)
 		if c.SSLKeyStore != "" {
 			sslConf += fmt.Sprintf(mysqlSSLKeyStoreConfigTemplate,
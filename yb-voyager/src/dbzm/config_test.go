@@ -0,0 +1,41 @@
+package dbzm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMySQLLiveMigrationConfig(t *testing.T) {
+	assert := assert.New(t)
+	liveMigrationProps := []string{
+		"debezium.source.snapshot.locking.mode=minimal",
+		"debezium.source.schema.history.internal.store.only.captured.tables.ddl=true",
+		"debezium.source.schema.history.internal.skip.unparseable.ddl=true",
+	}
+	for snapshotMode, isLiveMigration := range map[string]bool{
+		"initial":      true,
+		"never":        true,
+		"initial_only": false,
+	} {
+		config := &Config{
+			SourceDBType: "mysql",
+			ExportDir:    t.TempDir(),
+			Host:         "localhost",
+			Port:         3306,
+			DatabaseName: "test",
+			TableList:    []string{"test.foo"},
+			SnapshotMode: snapshotMode,
+		}
+		conf := config.String()
+		assert.Contains(conf, "debezium.source.snapshot.mode="+snapshotMode)
+		assert.Contains(conf, "debezium.source.bigint.unsigned.handling.mode=precise")
+		for _, prop := range liveMigrationProps {
+			if isLiveMigration {
+				assert.Contains(conf, prop, "snapshot mode %s", snapshotMode)
+			} else {
+				assert.NotContains(conf, prop, "snapshot mode %s", snapshotMode)
+			}
+		}
+	}
+}
@@ -0,0 +1,306 @@
+/*
+Copyright (c) YugabyteDB, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package depgraph
+
+import (
+	"errors"
+	"sort"
+	"sync"
+
+	pg_query "github.com/pganalyze/pg_query_go/v5"
+	"golang.org/x/exp/slices"
+)
+
+// Statement is a DDL statement to be placed in the dependency graph.
+type Statement struct {
+	// Position of the object type of the statement in the default import order. The statements which
+	// could not be parsed are ordered by it.
+	Group int
+	// The parse tree of the statement, nil if it could not be parsed.
+	ParseTree []*pg_query.RawStmt
+}
+
+// Graph is the graph of the dependencies between DDL statements, built from the objects each
+// statement creates and refers to.
+type Graph struct {
+	stmts []*node
+}
+
+type node struct {
+	Statement
+	stmtObjects
+	deps       []int
+	dependents []int
+}
+
+// New builds the dependency graph of the statements. The references to objects not created by any
+// of the statements are ignored, except that the types, functions and collations not found are
+// assumed to come from one of the extensions created by the statements.
+func New(stmts []Statement) *Graph {
+	g := &Graph{}
+	for _, stmt := range stmts {
+		n := &node{Statement: stmt}
+		for _, rawStmt := range stmt.ParseTree {
+			so := getStmtObjects(rawStmt.Stmt)
+			n.defines = append(n.defines, so.defines...)
+			n.references = append(n.references, so.references...)
+			if n.target == nil {
+				n.target = so.target
+			}
+		}
+		g.stmts = append(g.stmts, n)
+	}
+
+	// Index the statements by the names of the objects they create, qualified and unqualified.
+	definedBy := map[Object][]int{}
+	// The definitions not qualifying the name, like in the schema exported from Oracle and MySQL.
+	definedUnqualifiedBy := map[Object][]int{}
+	var extensions []int
+	for i, n := range g.stmts {
+		for _, def := range n.defines {
+			definedBy[def] = append(definedBy[def], i)
+			if def.Schema != "" {
+				unqualified := Object{Kind: def.Kind, Name: def.Name}
+				definedBy[unqualified] = append(definedBy[unqualified], i)
+			} else {
+				definedUnqualifiedBy[def] = append(definedUnqualifiedBy[def], i)
+			}
+			if def.Kind == EXTENSION {
+				extensions = append(extensions, i)
+			}
+		}
+	}
+
+	for i, n := range g.stmts {
+		if n.ParseTree == nil {
+			// Nothing is known about the statement. Run it after all the statements of the object
+			// types imported before it.
+			for j, other := range g.stmts {
+				if other.Group < n.Group {
+					g.addEdge(i, j)
+				}
+			}
+			continue
+		}
+		for _, ref := range n.references {
+			providers := definedBy[ref]
+			if len(providers) == 0 && ref.Schema != "" {
+				providers = definedUnqualifiedBy[Object{Kind: ref.Kind, Name: ref.Name}]
+			}
+			if len(providers) == 0 && (ref.Kind == TYPE || ref.Kind == FUNCTION || ref.Kind == COLLATION) {
+				providers = extensions
+			}
+			for _, j := range providers {
+				g.addEdge(i, j)
+			}
+		}
+	}
+
+	// Changing the same object in parallel would make the statements conflict with each other. Run
+	// them in the given order, unless a statement needs another one given after it.
+	stmtsOnTarget := map[Object][]int{}
+	for i, n := range g.stmts {
+		if n.target == nil {
+			continue
+		}
+		for _, j := range stmtsOnTarget[*n.target] {
+			if !g.dependsOn(j, i) {
+				g.addEdge(i, j)
+			}
+		}
+		stmtsOnTarget[*n.target] = append(stmtsOnTarget[*n.target], i)
+	}
+	for _, n := range g.stmts {
+		sort.Ints(n.deps)
+	}
+	return g
+}
+
+// addEdge records that statement i depends on statement j.
+func (g *Graph) addEdge(i, j int) {
+	if i == j || slices.Contains(g.stmts[i].deps, j) {
+		return
+	}
+	g.stmts[i].deps = append(g.stmts[i].deps, j)
+	g.stmts[j].dependents = append(g.stmts[j].dependents, i)
+}
+
+// dependsOn returns true if statement i depends on statement j, directly or indirectly.
+func (g *Graph) dependsOn(i, j int) bool {
+	visited := map[int]bool{}
+	stack := []int{i}
+	for len(stack) > 0 {
+		k := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if k == j {
+			return true
+		}
+		if visited[k] {
+			continue
+		}
+		visited[k] = true
+		stack = append(stack, g.stmts[k].deps...)
+	}
+	return false
+}
+
+// Defines returns the objects created by statement i.
+func (g *Graph) Defines(i int) []Object {
+	return g.stmts[i].defines
+}
+
+// Dependencies returns the statements which have to be run before statement i.
+func (g *Graph) Dependencies(i int) []int {
+	return g.stmts[i].deps
+}
+
+// Blocked returns the statements which can never be run because they are part of a dependency cycle,
+// or depend on a statement which is.
+func (g *Graph) Blocked() []int {
+	pending := make([]int, len(g.stmts))
+	var ready []int
+	for i, n := range g.stmts {
+		pending[i] = len(n.deps)
+		if pending[i] == 0 {
+			ready = append(ready, i)
+		}
+	}
+	done := make([]bool, len(g.stmts))
+	for len(ready) > 0 {
+		i := ready[0]
+		ready = ready[1:]
+		done[i] = true
+		for _, j := range g.stmts[i].dependents {
+			pending[j]--
+			if pending[j] == 0 {
+				ready = append(ready, j)
+			}
+		}
+	}
+	var blocked []int
+	for i := range g.stmts {
+		if !done[i] {
+			blocked = append(blocked, i)
+		}
+	}
+	return blocked
+}
+
+// Cycles returns the dependency cycles of the graph. Each statement of a cycle depends on the next
+// one, and the last one on the first. At least one cycle is found for every group of blocked statements.
+func (g *Graph) Cycles() [][]int {
+	blocked := g.Blocked()
+	visited := map[int]bool{}
+	var cycles [][]int
+	for _, start := range blocked {
+		var path []int
+		position := map[int]int{}
+		for i := start; !visited[i]; {
+			visited[i] = true
+			position[i] = len(path)
+			path = append(path, i)
+			// Every blocked statement depends on another blocked statement.
+			next := -1
+			for _, j := range g.stmts[i].deps {
+				if slices.Contains(blocked, j) {
+					next = j
+					break
+				}
+			}
+			if pos, ok := position[next]; ok {
+				cycles = append(cycles, path[pos:])
+				break
+			}
+			i = next
+		}
+	}
+	return cycles
+}
+
+// ErrNotRun is returned by the exec function of Run for a statement which could not be run for now,
+// and is left to be run later by the caller. The statements depending on it are not run either.
+var ErrNotRun = errors.New("statement not run")
+
+// Run calls exec for the statements in the order of their dependencies: a statement is run only after
+// all its dependencies have been run. Up to parallelism statements are run at the same time, worker
+// being the number, from 0 to parallelism-1, of the goroutine running it. The statements which are
+// blocked by a cycle, or by a statement for which exec returned ErrNotRun, are not run; they are
+// returned, along with the latter. If exec returns another error, no more statements are started,
+// and the first error is returned once the running statements are done.
+func (g *Graph) Run(parallelism int, exec func(worker int, i int) error) ([]int, error) {
+	type result struct {
+		i   int
+		err error
+	}
+	tasks := make(chan int, len(g.stmts))
+	done := make(chan result, len(g.stmts))
+	var wg sync.WaitGroup
+	if parallelism < 1 {
+		parallelism = 1
+	}
+	for worker := 0; worker < parallelism; worker++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			for i := range tasks {
+				done <- result{i: i, err: exec(worker, i)}
+			}
+		}(worker)
+	}
+
+	pending := make([]int, len(g.stmts))
+	running := 0
+	for i, n := range g.stmts {
+		pending[i] = len(n.deps)
+		if pending[i] == 0 {
+			tasks <- i
+			running++
+		}
+	}
+	executed := make([]bool, len(g.stmts))
+	var firstErr error
+	for running > 0 {
+		r := <-done
+		running--
+		if r.err != nil && r.err != ErrNotRun && firstErr == nil {
+			firstErr = r.err
+		}
+		if r.err != nil || firstErr != nil {
+			continue
+		}
+		executed[r.i] = true
+		for _, j := range g.stmts[r.i].dependents {
+			pending[j]--
+			if pending[j] == 0 {
+				tasks <- j
+				running++
+			}
+		}
+	}
+	close(tasks)
+	wg.Wait()
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	var blocked []int
+	for i := range g.stmts {
+		if !executed[i] {
+			blocked = append(blocked, i)
+		}
+	}
+	return blocked, nil
+}
@@ -0,0 +1,194 @@
+package depgraph
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/exp/slices"
+
+	"github.com/yugabyte/yb-voyager/yb-voyager/src/queryparser"
+)
+
+func newGraph(t *testing.T, sqls ...string) *Graph {
+	var stmts []Statement
+	for i, sql := range sqls {
+		parseTree, err := queryparser.Parse(sql)
+		if err != nil {
+			parseTree = nil
+		}
+		stmts = append(stmts, Statement{Group: i, ParseTree: parseTree})
+	}
+	return New(stmts)
+}
+
+func TestDependencies(t *testing.T) {
+	assert := assert.New(t)
+	g := newGraph(t,
+		/* 0 */ `CREATE SCHEMA sales`,
+		/* 1 */ `CREATE EXTENSION IF NOT EXISTS citext WITH SCHEMA public`,
+		/* 2 */ `CREATE TYPE sales.status AS ENUM ('new', 'shipped')`,
+		/* 3 */ `CREATE SEQUENCE sales.orders_id_seq`,
+		/* 4 */ `CREATE TABLE sales.orders (id int DEFAULT nextval('sales.orders_id_seq'::regclass), status sales.status, customer_id int, email public.citext)`,
+		/* 5 */ `CREATE TABLE sales.customers (id int, region text DEFAULT sales.default_region())`,
+		/* 6 */ `ALTER TABLE ONLY sales.customers ADD CONSTRAINT customers_pkey PRIMARY KEY (id)`,
+		/* 7 */ `ALTER TABLE ONLY sales.orders ADD CONSTRAINT orders_customer_fkey FOREIGN KEY (customer_id) REFERENCES sales.customers(id)`,
+		/* 8 */ `CREATE FUNCTION sales.default_region() RETURNS text LANGUAGE sql AS $$ SELECT region FROM sales.regions LIMIT 1 $$`,
+		/* 9 */ `CREATE VIEW sales.order_view AS WITH recent AS (SELECT * FROM sales.orders) SELECT * FROM recent JOIN customers c ON true`,
+		/* 10 */ `ALTER SEQUENCE sales.orders_id_seq OWNED BY sales.orders.id`,
+		/* 11 */ `COMMENT ON COLUMN sales.orders.status IS 'status'`,
+		/* 12 */ `CREATE TABLE sales.orders_2024 PARTITION OF sales.orders FOR VALUES FROM (1) TO (10)`,
+	)
+	assert.Equal([]Object{{RELATION, "sales", "orders"}, {TYPE, "sales", "orders"}}, g.Defines(4))
+	assert.Equal([]int{0, 1, 2, 3}, g.Dependencies(4))
+	// text is not qualified with pg_catalog, so it could come from the extension.
+	assert.Equal([]int{0, 1, 8}, g.Dependencies(5))
+	assert.Equal([]int{0, 1}, g.Dependencies(8)) // the function body is not looked into
+	assert.Equal([]int{0, 5}, g.Dependencies(6))
+	assert.Equal([]int{0, 4, 5, 6}, g.Dependencies(7))
+	assert.Equal([]int{0, 4, 5}, g.Dependencies(9)) // recent is a CTE
+	assert.Equal([]int{0, 3, 4}, g.Dependencies(10))
+	assert.Equal([]int{0, 4, 7}, g.Dependencies(11)) // the statements on the same table are run one after the other
+	assert.Equal([]int{0, 4}, g.Dependencies(12))
+	assert.Empty(g.Blocked())
+	assert.Empty(g.Cycles())
+}
+
+func TestUnparsedStatementsAndUnqualifiedNames(t *testing.T) {
+	assert := assert.New(t)
+	stmts := []Statement{}
+	for _, s := range []struct {
+		group int
+		sql   string
+	}{
+		{0, `CREATE TABLE orders (id int PRIMARY KEY)`},
+		{0, `CREATE TABLE items (order_id int REFERENCES sales.orders(id))`},
+		{1, `CREATE OR REPLACE PACKAGE pkg AS END`},
+		{2, `CREATE VIEW v AS SELECT * FROM items`},
+	} {
+		parseTree, _ := queryparser.Parse(s.sql)
+		stmts = append(stmts, Statement{Group: s.group, ParseTree: parseTree})
+	}
+	g := New(stmts)
+	assert.Equal([]int{0}, g.Dependencies(1))
+	assert.Equal([]int{0, 1}, g.Dependencies(2))
+	assert.Equal([]int{1}, g.Dependencies(3))
+}
+
+func TestStatementsOnSameObject(t *testing.T) {
+	assert := assert.New(t)
+	g := newGraph(t,
+		/* 0 */ `ALTER TABLE ONLY orders ALTER COLUMN id SET DEFAULT nextval('orders_id_seq'::regclass)`,
+		/* 1 */ `CREATE SEQUENCE orders_id_seq`,
+		/* 2 */ `CREATE TABLE orders (id int, parent_id int)`,
+		/* 3 */ `ALTER TABLE ONLY orders ADD CONSTRAINT orders_parent_fkey FOREIGN KEY (parent_id) REFERENCES orders(id)`,
+		/* 4 */ `CREATE UNIQUE INDEX orders_id_idx ON orders (id)`,
+	)
+	// The ALTERs are run one after the other, but after the table they need is created.
+	assert.Equal([]int{1, 2}, g.Dependencies(0))
+	assert.Empty(g.Dependencies(2))
+	assert.Equal([]int{0, 2, 4}, g.Dependencies(3))
+	assert.Equal([]int{0, 2}, g.Dependencies(4))
+	assert.Empty(g.Cycles())
+}
+
+func TestCycles(t *testing.T) {
+	assert := assert.New(t)
+	g := newGraph(t,
+		/* 0 */ `CREATE TABLE a (id int, b_id int REFERENCES b(id), PRIMARY KEY (id))`,
+		/* 1 */ `CREATE TABLE b (id int PRIMARY KEY, c_id int REFERENCES c(id))`,
+		/* 2 */ `CREATE TABLE c (id int PRIMARY KEY, a_id int REFERENCES a(id))`,
+		/* 3 */ `CREATE VIEW v AS SELECT * FROM a`,
+		/* 4 */ `CREATE TABLE d (id int)`,
+	)
+	assert.Equal([]int{0, 1, 2, 3}, g.Blocked())
+	assert.Equal([][]int{{0, 1, 2}}, g.Cycles())
+
+	var mu sync.Mutex
+	var executed []int
+	blocked, err := g.Run(2, func(worker, i int) error {
+		mu.Lock()
+		defer mu.Unlock()
+		executed = append(executed, i)
+		return nil
+	})
+	assert.NoError(err)
+	assert.Equal([]int{4}, executed)
+	assert.Equal([]int{0, 1, 2, 3}, blocked)
+}
+
+func TestRun(t *testing.T) {
+	assert := assert.New(t)
+	var sqls []string
+	for _, table := range []string{"a", "b", "c", "d", "e", "f"} {
+		sqls = append(sqls, "CREATE TABLE "+table+" (id int PRIMARY KEY)")
+	}
+	sqls = append(sqls, "CREATE VIEW v AS SELECT * FROM a JOIN b USING (id) JOIN f USING (id)")
+	sqls = append(sqls, "CREATE VIEW w AS SELECT * FROM v")
+	g := newGraph(t, sqls...)
+
+	var mu sync.Mutex
+	var executed []int
+	workers := map[int]bool{}
+	blocked, err := g.Run(3, func(worker, i int) error {
+		mu.Lock()
+		defer mu.Unlock()
+		for _, dep := range g.Dependencies(i) {
+			assert.True(slices.Contains(executed, dep), "%d run before %d", i, dep)
+		}
+		executed = append(executed, i)
+		workers[worker] = true
+		return nil
+	})
+	assert.NoError(err)
+	assert.Empty(blocked)
+	assert.Len(executed, len(sqls))
+	assert.Equal(7, executed[len(executed)-1])
+	for worker := range workers {
+		assert.True(worker >= 0 && worker < 3)
+	}
+}
+
+func TestRunNotRunStatement(t *testing.T) {
+	assert := assert.New(t)
+	g := newGraph(t,
+		/* 0 */ `CREATE TABLE a (id int PRIMARY KEY)`,
+		/* 1 */ `CREATE VIEW v AS SELECT * FROM a`,
+		/* 2 */ `CREATE VIEW w AS SELECT * FROM v`,
+		/* 3 */ `CREATE TABLE b (id int PRIMARY KEY)`,
+	)
+	var mu sync.Mutex
+	var executed []int
+	blocked, err := g.Run(2, func(worker, i int) error {
+		mu.Lock()
+		defer mu.Unlock()
+		executed = append(executed, i)
+		if i == 1 {
+			return ErrNotRun
+		}
+		return nil
+	})
+	assert.NoError(err)
+	assert.ElementsMatch([]int{0, 1, 3}, executed)
+	assert.Equal([]int{1, 2}, blocked)
+}
+
+func TestRunStopsOnError(t *testing.T) {
+	assert := assert.New(t)
+	g := newGraph(t,
+		/* 0 */ `CREATE TABLE a (id int PRIMARY KEY)`,
+		/* 1 */ `CREATE VIEW v AS SELECT * FROM a`,
+		/* 2 */ `CREATE VIEW w AS SELECT * FROM v`,
+	)
+	var executed []int
+	_, err := g.Run(2, func(worker, i int) error {
+		executed = append(executed, i)
+		if i == 1 {
+			return fmt.Errorf("failed %d", i)
+		}
+		return nil
+	})
+	assert.EqualError(err, "failed 1")
+	assert.Equal([]int{0, 1}, executed)
+}
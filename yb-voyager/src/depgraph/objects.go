@@ -0,0 +1,373 @@
+/*
+Copyright (c) YugabyteDB, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package depgraph
+
+import (
+	"strings"
+
+	pg_query "github.com/pganalyze/pg_query_go/v5"
+	"golang.org/x/exp/slices"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/yugabyte/yb-voyager/yb-voyager/src/queryparser"
+)
+
+// ObjectKind is the namespace in which the name of a database object is looked up.
+type ObjectKind string
+
+const (
+	SCHEMA ObjectKind = "SCHEMA"
+	// Tables, views, materialized views, sequences and indexes share the same namespace in PostgreSQL.
+	RELATION  ObjectKind = "RELATION"
+	TYPE      ObjectKind = "TYPE"
+	FUNCTION  ObjectKind = "FUNCTION"
+	COLLATION ObjectKind = "COLLATION"
+	EXTENSION ObjectKind = "EXTENSION"
+	// The primary key and unique constraints of a table, which a foreign key referencing the table needs.
+	KEYS ObjectKind = "KEYS"
+)
+
+// Object is a database object created or referred to by a DDL statement. Schema is empty if the
+// statement does not qualify the name.
+type Object struct {
+	Kind   ObjectKind
+	Schema string
+	Name   string
+}
+
+func (o Object) String() string {
+	name := queryparser.QuoteIdentifier(o.Name)
+	if o.Schema != "" {
+		name = queryparser.QuoteIdentifier(o.Schema) + "." + name
+	}
+	return strings.ToLower(string(o.Kind)) + " " + name
+}
+
+// sameName returns true if both are the same object, treating an unqualified name as matching the
+// name in any schema.
+func (o Object) sameName(other Object) bool {
+	return o.Kind == other.Kind && o.Name == other.Name &&
+		(o.Schema == other.Schema || o.Schema == "" || other.Schema == "")
+}
+
+func objectFromRangeVar(kind ObjectKind, rv *pg_query.RangeVar) Object {
+	return Object{Kind: kind, Schema: rv.Schemaname, Name: rv.Relname}
+}
+
+// objectFromNames returns the object named by the list of String nodes, like [schema, name].
+func objectFromNames(kind ObjectKind, names []*pg_query.Node) (Object, bool) {
+	var parts []string
+	for _, name := range names {
+		if s := name.GetString_(); s != nil {
+			parts = append(parts, s.Sval)
+		}
+	}
+	if len(parts) == 0 {
+		return Object{}, false
+	}
+	obj := Object{Kind: kind, Name: parts[len(parts)-1]}
+	if len(parts) > 1 {
+		obj.Schema = parts[len(parts)-2]
+	}
+	return obj, true
+}
+
+// Schemas which only have the built-in objects of the database.
+var systemSchemas = []string{"pg_catalog", "information_schema"}
+
+// stmtObjects are the objects a statement creates and refers to.
+type stmtObjects struct {
+	defines    []Object
+	references []Object
+	// The object the statement creates or alters. The statements on the same object are not run in parallel.
+	target *Object
+}
+
+func (so *stmtObjects) define(obj Object) {
+	if so.target == nil && obj.Kind == RELATION {
+		so.target = &obj
+	}
+	so.defines = append(so.defines, obj)
+}
+
+func (so *stmtObjects) refer(obj Object) {
+	if obj.Name == "" || slices.Contains(systemSchemas, obj.Schema) {
+		return
+	}
+	so.references = append(so.references, obj)
+}
+
+func (so *stmtObjects) alter(obj Object) {
+	if so.target == nil {
+		so.target = &obj
+	}
+	so.refer(obj)
+}
+
+// getStmtObjects returns the objects created and referred to by the parsed statement.
+func getStmtObjects(stmt *pg_query.Node) stmtObjects {
+	var so stmtObjects
+	switch {
+	case stmt.GetCreateSchemaStmt() != nil:
+		so.define(Object{Kind: SCHEMA, Name: stmt.GetCreateSchemaStmt().Schemaname})
+	case stmt.GetCreateExtensionStmt() != nil:
+		s := stmt.GetCreateExtensionStmt()
+		so.define(Object{Kind: EXTENSION, Name: s.Extname})
+		for _, option := range s.Options {
+			if defElem := option.GetDefElem(); defElem != nil && defElem.Defname == "schema" {
+				so.refer(Object{Kind: SCHEMA, Name: defElem.Arg.GetString_().GetSval()})
+			}
+		}
+	case stmt.GetCreateStmt() != nil:
+		defineTable(&so, stmt.GetCreateStmt())
+	case stmt.GetCreateForeignTableStmt() != nil:
+		defineTable(&so, stmt.GetCreateForeignTableStmt().BaseStmt)
+	case stmt.GetViewStmt() != nil:
+		so.define(objectFromRangeVar(RELATION, stmt.GetViewStmt().View))
+		so.define(objectFromRangeVar(TYPE, stmt.GetViewStmt().View))
+	case stmt.GetCreateTableAsStmt() != nil && stmt.GetCreateTableAsStmt().Into != nil:
+		so.define(objectFromRangeVar(RELATION, stmt.GetCreateTableAsStmt().Into.Rel))
+		so.define(objectFromRangeVar(TYPE, stmt.GetCreateTableAsStmt().Into.Rel))
+	case stmt.GetCreateSeqStmt() != nil:
+		so.define(objectFromRangeVar(RELATION, stmt.GetCreateSeqStmt().Sequence))
+	case stmt.GetAlterSeqStmt() != nil:
+		s := stmt.GetAlterSeqStmt()
+		so.alter(objectFromRangeVar(RELATION, s.Sequence))
+		for _, option := range s.Options {
+			// OWNED BY table.column
+			if defElem := option.GetDefElem(); defElem != nil && defElem.Defname == "owned_by" && defElem.Arg.GetList() != nil {
+				names := defElem.Arg.GetList().Items
+				if obj, ok := objectFromNames(RELATION, names[:len(names)-1]); ok {
+					so.refer(obj)
+				}
+			}
+		}
+	case stmt.GetIndexStmt() != nil:
+		s := stmt.GetIndexStmt()
+		table := objectFromRangeVar(RELATION, s.Relation)
+		so.alter(table)
+		if s.Idxname != "" {
+			so.defines = append(so.defines, Object{Kind: RELATION, Schema: s.Relation.Schemaname, Name: s.Idxname})
+		}
+		if s.Unique {
+			so.define(objectFromRangeVar(KEYS, s.Relation))
+		}
+	case stmt.GetAlterTableStmt() != nil:
+		s := stmt.GetAlterTableStmt()
+		so.alter(objectFromRangeVar(RELATION, s.Relation))
+		for _, cmd := range s.Cmds {
+			if constraint := cmd.GetAlterTableCmd().GetDef().GetConstraint(); constraint != nil && isKeyConstraint(constraint) {
+				so.define(objectFromRangeVar(KEYS, s.Relation))
+			}
+		}
+	case stmt.GetCreateFunctionStmt() != nil:
+		if obj, ok := objectFromNames(FUNCTION, stmt.GetCreateFunctionStmt().Funcname); ok {
+			so.define(obj)
+		}
+	case stmt.GetCreateTrigStmt() != nil:
+		s := stmt.GetCreateTrigStmt()
+		so.alter(objectFromRangeVar(RELATION, s.Relation))
+		if obj, ok := objectFromNames(FUNCTION, s.Funcname); ok {
+			so.refer(obj)
+		}
+	case stmt.GetRuleStmt() != nil:
+		so.alter(objectFromRangeVar(RELATION, stmt.GetRuleStmt().Relation))
+	case stmt.GetDefineStmt() != nil:
+		defineObject(&so, stmt.GetDefineStmt())
+	case stmt.GetCreateEnumStmt() != nil:
+		if obj, ok := objectFromNames(TYPE, stmt.GetCreateEnumStmt().TypeName); ok {
+			so.define(obj)
+		}
+	case stmt.GetCreateRangeStmt() != nil:
+		if obj, ok := objectFromNames(TYPE, stmt.GetCreateRangeStmt().TypeName); ok {
+			so.define(obj)
+		}
+	case stmt.GetCompositeTypeStmt() != nil:
+		so.define(objectFromRangeVar(TYPE, stmt.GetCompositeTypeStmt().Typevar))
+	case stmt.GetCreateDomainStmt() != nil:
+		if obj, ok := objectFromNames(TYPE, stmt.GetCreateDomainStmt().Domainname); ok {
+			so.define(obj)
+		}
+	case stmt.GetCommentStmt() != nil:
+		if obj, ok := getCommentedObject(stmt.GetCommentStmt()); ok {
+			so.alter(obj)
+		}
+	}
+	referTo(&so, stmt)
+	// The schema of every object has to be created first.
+	for _, obj := range append(slices.Clone(so.defines), so.references...) {
+		if obj.Schema != "" && obj.Kind != SCHEMA {
+			so.refer(Object{Kind: SCHEMA, Name: obj.Schema})
+		}
+	}
+	so.references = removeSelfReferences(so.references, so.defines)
+	return so
+}
+
+func defineTable(so *stmtObjects, s *pg_query.CreateStmt) {
+	so.define(objectFromRangeVar(RELATION, s.Relation))
+	// Every table also is a composite type.
+	so.define(objectFromRangeVar(TYPE, s.Relation))
+	for _, elt := range s.TableElts {
+		constraints := []*pg_query.Node{elt}
+		if columnDef := elt.GetColumnDef(); columnDef != nil {
+			constraints = columnDef.Constraints
+		}
+		for _, constraint := range constraints {
+			if constraint.GetConstraint() != nil && isKeyConstraint(constraint.GetConstraint()) {
+				so.define(objectFromRangeVar(KEYS, s.Relation))
+				return
+			}
+		}
+	}
+}
+
+func isKeyConstraint(constraint *pg_query.Constraint) bool {
+	return constraint.Contype == pg_query.ConstrType_CONSTR_PRIMARY || constraint.Contype == pg_query.ConstrType_CONSTR_UNIQUE
+}
+
+// defineObject handles the CREATE AGGREGATE, CREATE TYPE and CREATE COLLATION statements.
+func defineObject(so *stmtObjects, s *pg_query.DefineStmt) {
+	var kind ObjectKind
+	switch s.Kind {
+	case pg_query.ObjectType_OBJECT_AGGREGATE:
+		kind = FUNCTION
+		// The support functions of the aggregate are given like type names, e.g. sfunc = public.int_add.
+		for _, def := range s.Definition {
+			defElem := def.GetDefElem()
+			if defElem == nil || !strings.HasSuffix(defElem.Defname, "func") || defElem.Arg.GetTypeName() == nil {
+				continue
+			}
+			if obj, ok := objectFromNames(FUNCTION, defElem.Arg.GetTypeName().Names); ok {
+				so.refer(obj)
+			}
+		}
+	case pg_query.ObjectType_OBJECT_TYPE:
+		kind = TYPE
+	case pg_query.ObjectType_OBJECT_COLLATION:
+		kind = COLLATION
+	default:
+		return
+	}
+	if obj, ok := objectFromNames(kind, s.Defnames); ok {
+		so.define(obj)
+	}
+}
+
+func getCommentedObject(s *pg_query.CommentStmt) (Object, bool) {
+	var names []*pg_query.Node
+	if s.Object.GetList() != nil {
+		names = s.Object.GetList().Items
+	}
+	switch s.Objtype {
+	case pg_query.ObjectType_OBJECT_TABLE, pg_query.ObjectType_OBJECT_VIEW, pg_query.ObjectType_OBJECT_MATVIEW,
+		pg_query.ObjectType_OBJECT_SEQUENCE, pg_query.ObjectType_OBJECT_INDEX, pg_query.ObjectType_OBJECT_FOREIGN_TABLE:
+		return objectFromNames(RELATION, names)
+	case pg_query.ObjectType_OBJECT_COLUMN, pg_query.ObjectType_OBJECT_TABCONSTRAINT,
+		pg_query.ObjectType_OBJECT_TRIGGER, pg_query.ObjectType_OBJECT_RULE:
+		// [schema,] table, name
+		if len(names) > 1 {
+			return objectFromNames(RELATION, names[:len(names)-1])
+		}
+	case pg_query.ObjectType_OBJECT_FUNCTION, pg_query.ObjectType_OBJECT_PROCEDURE, pg_query.ObjectType_OBJECT_AGGREGATE:
+		if s.Object.GetObjectWithArgs() != nil {
+			return objectFromNames(FUNCTION, s.Object.GetObjectWithArgs().Objname)
+		}
+	case pg_query.ObjectType_OBJECT_SCHEMA:
+		return Object{Kind: SCHEMA, Name: s.Object.GetString_().GetSval()}, true
+	}
+	return Object{}, false
+}
+
+// referTo adds the objects referred to anywhere in the statement: the relations, types, functions and
+// collations it uses. The bodies of functions given as string constants are not looked into, as they
+// are not validated at creation time by pg_dump's exports.
+func referTo(so *stmtObjects, stmt *pg_query.Node) {
+	cteNames := map[string]bool{}
+	queryparser.WalkMessages(stmt, func(msg proto.Message) bool {
+		if cte, ok := msg.(*pg_query.CommonTableExpr); ok {
+			cteNames[cte.Ctename] = true
+		}
+		return true
+	})
+	queryparser.WalkMessages(stmt, func(msg proto.Message) bool {
+		switch node := msg.(type) {
+		case *pg_query.RangeVar:
+			if node.Schemaname != "" || !cteNames[node.Relname] {
+				so.refer(objectFromRangeVar(RELATION, node))
+			}
+		case *pg_query.TypeName:
+			if obj, ok := objectFromNames(TYPE, node.Names); ok {
+				so.refer(obj)
+			}
+		case *pg_query.CollateClause:
+			if obj, ok := objectFromNames(COLLATION, node.Collname); ok {
+				so.refer(obj)
+			}
+		case *pg_query.FuncCall:
+			obj, ok := objectFromNames(FUNCTION, node.Funcname)
+			if !ok {
+				break
+			}
+			so.refer(obj)
+			// nextval('public.orders_id_seq') and the like
+			if slices.Contains([]string{"nextval", "currval", "setval"}, obj.Name) && len(node.Args) > 0 {
+				referToRelationNamedBy(so, node.Args[0])
+			}
+		case *pg_query.TypeCast:
+			// 'public.orders_id_seq'::regclass
+			if queryparser.TypeName(node.TypeName) == "regclass" {
+				referToRelationNamedBy(so, node.Arg)
+			}
+		case *pg_query.Constraint:
+			if node.Contype == pg_query.ConstrType_CONSTR_FOREIGN && node.Pktable != nil {
+				so.refer(objectFromRangeVar(KEYS, node.Pktable))
+			}
+		}
+		return true
+	})
+}
+
+// referToRelationNamedBy adds the relation named by the string constant node, if it is one.
+func referToRelationNamedBy(so *stmtObjects, node *pg_query.Node) {
+	if node.GetTypeCast() != nil {
+		node = node.GetTypeCast().Arg
+	}
+	name := node.GetAConst().GetSval().GetSval()
+	if name == "" {
+		return
+	}
+	// Let the parser deal with the quoting of the name.
+	stmts, err := queryparser.Parse("SELECT FROM " + name)
+	if err != nil || len(stmts) != 1 {
+		return
+	}
+	fromClause := stmts[0].Stmt.GetSelectStmt().GetFromClause()
+	if len(fromClause) == 1 && fromClause[0].GetRangeVar() != nil {
+		so.refer(objectFromRangeVar(RELATION, fromClause[0].GetRangeVar()))
+	}
+}
+
+func removeSelfReferences(references, defines []Object) []Object {
+	var result []Object
+	for _, ref := range references {
+		isSelf := slices.ContainsFunc(defines, func(def Object) bool { return def.sameName(ref) })
+		if !isSelf && !slices.Contains(result, ref) {
+			result = append(result, ref)
+		}
+	}
+	return result
+}
@@ -0,0 +1,88 @@
+/*
+Copyright (c) YugabyteDB, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package metadb
+
+import (
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// ImportedSchemaObject is an object created on the target by import schema.
+type ImportedSchemaObject struct {
+	// Position of the object in the order of creation.
+	SeqNo      int64
+	ObjectType string
+	ObjectName string
+	// The statement dropping the object from the target.
+	DropStmt string
+}
+
+func (m *MetaDB) InsertImportedSchemaObject(obj ImportedSchemaObject) error {
+	query := fmt.Sprintf(`INSERT INTO %s (object_type, object_name, drop_stmt) VALUES (?, ?, ?);`, IMPORTED_SCHEMA_OBJECTS_TABLE_NAME)
+	_, err := m.db.Exec(query, obj.ObjectType, obj.ObjectName, obj.DropStmt)
+	if err != nil {
+		return fmt.Errorf("error while running query on meta db - %s :%w", query, err)
+	}
+	log.Infof("Recorded imported schema object: %s %s", obj.ObjectType, obj.ObjectName)
+	return nil
+}
+
+// GetImportedSchemaObjects returns the objects created on the target by import schema, in the order
+// in which they were created.
+func (m *MetaDB) GetImportedSchemaObjects() ([]ImportedSchemaObject, error) {
+	query := fmt.Sprintf(`SELECT seq_no, object_type, object_name, drop_stmt FROM %s ORDER BY seq_no;`, IMPORTED_SCHEMA_OBJECTS_TABLE_NAME)
+	rows, err := m.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("run query on meta db -%s :%w", query, err)
+	}
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			log.Errorf("failed to close rows while fetching imported schema objects from query %s : %v", query, err)
+		}
+	}()
+	var objs []ImportedSchemaObject
+	for rows.Next() {
+		var obj ImportedSchemaObject
+		err := rows.Scan(&obj.SeqNo, &obj.ObjectType, &obj.ObjectName, &obj.DropStmt)
+		if err != nil {
+			return nil, fmt.Errorf("scan rows while fetching imported schema objects from query %s : %w", query, err)
+		}
+		objs = append(objs, obj)
+	}
+	return objs, rows.Err()
+}
+
+func (m *MetaDB) DeleteImportedSchemaObject(seqNo int64) error {
+	query := fmt.Sprintf(`DELETE FROM %s WHERE seq_no = ?;`, IMPORTED_SCHEMA_OBJECTS_TABLE_NAME)
+	result, err := m.db.Exec(query, seqNo)
+	if err != nil {
+		return fmt.Errorf("error while running query on meta db - %s :%w", query, err)
+	}
+	return checkRowsAffected(result, 1)
+}
+
+// IsImportedSchemaObject returns true if the object was created on the target by import schema.
+func (m *MetaDB) IsImportedSchemaObject(objType string, objName string) (bool, error) {
+	query := fmt.Sprintf(`SELECT count(*) FROM %s WHERE object_type = ? AND object_name = ?;`, IMPORTED_SCHEMA_OBJECTS_TABLE_NAME)
+	var count int64
+	err := m.db.QueryRow(query, objType, objName).Scan(&count)
+	if err != nil {
+		return false, fmt.Errorf("error while running query on meta db - %s :%w", query, err)
+	}
+	return count > 0, nil
+}
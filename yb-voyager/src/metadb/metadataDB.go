@@ -36,6 +36,8 @@ var (
 	EXPORTED_EVENTS_STATS_TABLE_NAME           = "exported_events_stats"
 	EXPORTED_EVENTS_STATS_PER_TABLE_TABLE_NAME = "exported_events_stats_per_table"
 	JSON_OBJECTS_TABLE_NAME                    = "json_objects"
+	IMPORTED_SCHEMA_OBJECTS_TABLE_NAME         = "imported_schema_objects"
+	SCHEMA_STMT_STATUS_TABLE_NAME              = "schema_stmt_status"
 	TARGET_DB_IDENTITY_COLUMNS_KEY             = "target_db_identity_columns_key"
 	FF_DB_IDENTITY_COLUMNS_KEY                 = "ff_db_identity_columns_key"
 	SOURCE_INDEXES_INFO_KEY                    = "source_indexes_info_key"
@@ -117,6 +119,17 @@ func initMetaDB(path string) error {
 		fmt.Sprintf(`CREATE TABLE %s (
 			key TEXT PRIMARY KEY,
 			json_text TEXT);`, JSON_OBJECTS_TABLE_NAME),
+		fmt.Sprintf(`CREATE TABLE %s (
+			seq_no INTEGER PRIMARY KEY AUTOINCREMENT,
+			object_type TEXT,
+			object_name TEXT,
+			drop_stmt TEXT);`, IMPORTED_SCHEMA_OBJECTS_TABLE_NAME),
+		fmt.Sprintf(`CREATE TABLE %s (
+			file_name TEXT,
+			stmt_hash TEXT,
+			status TEXT,
+			error TEXT,
+			PRIMARY KEY(file_name, stmt_hash) );`, SCHEMA_STMT_STATUS_TABLE_NAME),
 	}
 	for _, cmd := range cmds {
 		_, err = conn.Exec(cmd)
@@ -550,3 +563,104 @@ func (m *MetaDB) ResetQueueSegmentMeta(importerRole string) error {
 	}
 	return nil
 }
+
+// TableEventsDelta is the number of events of a table written to the event queue since the last commit.
+type TableEventsDelta struct {
+	SchemaName string
+	TableName  string
+	tgtdb.EventCounter
+}
+
+func (m *MetaDB) CreateQueueSegmentMetaIfNotExists(segmentNum int64, filePath string, exporterRole string) error {
+	query := fmt.Sprintf(`INSERT OR IGNORE INTO %s (segment_no, file_path, size_committed, total_events, exporter_role) VALUES (?, ?, 0, 0, ?);`,
+		QUEUE_SEGMENT_META_TABLE_NAME)
+	_, err := m.db.Exec(query, segmentNum, filePath, exporterRole)
+	if err != nil {
+		return fmt.Errorf("error while running query on meta db -%s :%w", query, err)
+	}
+	return nil
+}
+
+/*
+CommitQueueSegment records that the first committedSize bytes of the queue segment are durable. In the same
+transaction it adds the new events to the exported events stats and, if jsonObjectKey is not empty, saves
+jsonObject against it. This allows the exporter to persist its source position atomically with the queue.
+*/
+func (m *MetaDB) CommitQueueSegment(segmentNum int64, committedSize int64, runId string, exporterRole string,
+	deltas []*TableEventsDelta, jsonObjectKey string, jsonObject any) error {
+
+	tx, err := m.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error while starting transaction on meta db: %w", err)
+	}
+	defer func() {
+		err := tx.Rollback()
+		if err != nil && err != sql.ErrTxDone {
+			log.Errorf("failed to rollback transaction on meta db: %v", err)
+		}
+	}()
+
+	total := tgtdb.EventCounter{}
+	for _, delta := range deltas {
+		total.Merge(&delta.EventCounter)
+	}
+	query := fmt.Sprintf(`UPDATE %s SET size_committed = ?, total_events = total_events + ? WHERE segment_no = ?;`,
+		QUEUE_SEGMENT_META_TABLE_NAME)
+	result, err := tx.Exec(query, committedSize, total.TotalEvents, segmentNum)
+	if err != nil {
+		return fmt.Errorf("error while running query on meta db -%s :%w", query, err)
+	}
+	err = checkRowsAffected(result, 1)
+	if err != nil {
+		return fmt.Errorf("update queue segment %d meta: %w", segmentNum, err)
+	}
+
+	query = fmt.Sprintf(`INSERT INTO %s (exporter_role, schema_name, table_name, num_total, num_inserts, num_updates, num_deletes)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (exporter_role, schema_name, table_name) DO UPDATE SET
+		num_total = num_total + excluded.num_total, num_inserts = num_inserts + excluded.num_inserts,
+		num_updates = num_updates + excluded.num_updates, num_deletes = num_deletes + excluded.num_deletes;`,
+		EXPORTED_EVENTS_STATS_PER_TABLE_TABLE_NAME)
+	for _, delta := range deltas {
+		_, err = tx.Exec(query, exporterRole, delta.SchemaName, delta.TableName,
+			delta.TotalEvents, delta.NumInserts, delta.NumUpdates, delta.NumDeletes)
+		if err != nil {
+			return fmt.Errorf("error while running query on meta db -%s :%w", query, err)
+		}
+	}
+
+	if total.TotalEvents > 0 {
+		// stats are bucketed in 10 second intervals.
+		now := time.Now().Unix()
+		query = fmt.Sprintf(`INSERT INTO %s (run_id, exporter_role, timestamp, num_total, num_inserts, num_updates, num_deletes)
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT (run_id, exporter_role, timestamp) DO UPDATE SET
+			num_total = num_total + excluded.num_total, num_inserts = num_inserts + excluded.num_inserts,
+			num_updates = num_updates + excluded.num_updates, num_deletes = num_deletes + excluded.num_deletes;`,
+			EXPORTED_EVENTS_STATS_TABLE_NAME)
+		_, err = tx.Exec(query, runId, exporterRole, now-now%10,
+			total.TotalEvents, total.NumInserts, total.NumUpdates, total.NumDeletes)
+		if err != nil {
+			return fmt.Errorf("error while running query on meta db -%s :%w", query, err)
+		}
+	}
+
+	if jsonObjectKey != "" {
+		jsonText, err := json.Marshal(jsonObject)
+		if err != nil {
+			return fmt.Errorf("error while marshalling json: %w", err)
+		}
+		query = fmt.Sprintf(`INSERT INTO %s (key, json_text) VALUES (?, ?)
+			ON CONFLICT (key) DO UPDATE SET json_text = excluded.json_text;`, JSON_OBJECTS_TABLE_NAME)
+		_, err = tx.Exec(query, jsonObjectKey, string(jsonText))
+		if err != nil {
+			return fmt.Errorf("error while running query on meta db -%s :%w", query, err)
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("error while commiting transaction on meta db: %w", err)
+	}
+	return nil
+}
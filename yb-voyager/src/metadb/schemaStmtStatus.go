@@ -0,0 +1,81 @@
+/*
+Copyright (c) YugabyteDB, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package metadb
+
+import (
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Status of a DDL statement of the exported schema files, as imported by import schema.
+const (
+	SCHEMA_STMT_DONE    = "done"
+	SCHEMA_STMT_FAILED  = "failed"
+	SCHEMA_STMT_SKIPPED = "skipped"
+)
+
+// SchemaStmtStatus is the status of a DDL statement, identified by the schema file it is part of and
+// the hash of its text.
+type SchemaStmtStatus struct {
+	FileName string
+	StmtHash string
+	Status   string
+	// The error returned by the target for a failed statement.
+	Error string
+}
+
+func (m *MetaDB) UpdateSchemaStmtStatus(status SchemaStmtStatus) error {
+	query := fmt.Sprintf(`INSERT OR REPLACE INTO %s (file_name, stmt_hash, status, error) VALUES (?, ?, ?, ?);`, SCHEMA_STMT_STATUS_TABLE_NAME)
+	_, err := m.db.Exec(query, status.FileName, status.StmtHash, status.Status, status.Error)
+	if err != nil {
+		return fmt.Errorf("error while running query on meta db - %s :%w", query, err)
+	}
+	return nil
+}
+
+func (m *MetaDB) GetSchemaStmtStatuses() ([]SchemaStmtStatus, error) {
+	query := fmt.Sprintf(`SELECT file_name, stmt_hash, status, error FROM %s;`, SCHEMA_STMT_STATUS_TABLE_NAME)
+	rows, err := m.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("run query on meta db -%s :%w", query, err)
+	}
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			log.Errorf("failed to close rows while fetching schema statement statuses from query %s : %v", query, err)
+		}
+	}()
+	var statuses []SchemaStmtStatus
+	for rows.Next() {
+		var status SchemaStmtStatus
+		err := rows.Scan(&status.FileName, &status.StmtHash, &status.Status, &status.Error)
+		if err != nil {
+			return nil, fmt.Errorf("scan rows while fetching schema statement statuses from query %s : %w", query, err)
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, rows.Err()
+}
+
+func (m *MetaDB) ResetSchemaStmtStatuses() error {
+	query := fmt.Sprintf(`DELETE FROM %s;`, SCHEMA_STMT_STATUS_TABLE_NAME)
+	_, err := m.db.Exec(query)
+	if err != nil {
+		return fmt.Errorf("error while running query on meta db - %s :%w", query, err)
+	}
+	return nil
+}
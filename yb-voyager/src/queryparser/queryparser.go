@@ -0,0 +1,362 @@
+/*
+Copyright (c) YugabyteDB, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package queryparser
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	pg_query "github.com/pganalyze/pg_query_go/v5"
+	"github.com/pganalyze/pg_query_go/v5/parser"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Parse parses the SQL text into its raw statements using the PostgreSQL grammar.
+// psql meta-commands, like the `\set ON_ERROR_STOP ON` added by ora2pg to the exported files, are
+// blanked out before parsing. So the locations in the returned trees are still valid offsets into sql.
+func Parse(sql string) ([]*pg_query.RawStmt, error) {
+	tree, err := pg_query.Parse(blankMetaCommands(sql))
+	if err != nil {
+		return nil, err
+	}
+	return tree.Stmts, nil
+}
+
+func blankMetaCommands(sql string) string {
+	lines := strings.Split(sql, "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimLeft(line, " \t"), `\`) {
+			lines[i] = strings.Repeat(" ", len(line))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// ErrorOffset returns the offset in sql at which the parser reported the error, or -1 if not known.
+func ErrorOffset(sql string, err error) int {
+	var parseErr *parser.Error
+	if !errors.As(err, &parseErr) || parseErr.Cursorpos <= 0 {
+		return -1
+	}
+	// Cursorpos is the 1-based position of the character, not of the byte.
+	offset := 0
+	for i := 1; i < parseErr.Cursorpos && offset < len(sql); i++ {
+		_, size := utf8.DecodeRuneInString(sql[offset:])
+		offset += size
+	}
+	return offset
+}
+
+// StmtStart returns the offset in sql of the first character of the statement.
+func StmtStart(sql string, stmt *pg_query.RawStmt) int {
+	offset := int(stmt.StmtLocation)
+	for offset < len(sql) && strings.ContainsRune(" \t\r\n", rune(sql[offset])) {
+		offset++
+	}
+	return offset
+}
+
+// StmtText returns the text of the statement from sql, without the terminating semicolon.
+func StmtText(sql string, stmt *pg_query.RawStmt) string {
+	start := int(stmt.StmtLocation)
+	if stmt.StmtLen == 0 {
+		return sql[start:]
+	}
+	return sql[start : start+int(stmt.StmtLen)]
+}
+
+// Walk calls fn for every node of the tree rooted at msg in depth-first order.
+// The children of a node are not visited if fn returns false for it.
+func Walk(msg proto.Message, fn func(node *pg_query.Node) bool) {
+	WalkMessages(msg, func(msg proto.Message) bool {
+		node, ok := msg.(*pg_query.Node)
+		return !ok || fn(node)
+	})
+}
+
+// WalkMessages is like Walk, but calls fn for every message of the tree, not only for the nodes.
+// Some messages, like the RangeVar of a CREATE TABLE or the TypeName of a column definition, are
+// not wrapped in a node.
+func WalkMessages(msg proto.Message, fn func(msg proto.Message) bool) {
+	walk(msg.ProtoReflect(), fn)
+}
+
+func walk(msg protoreflect.Message, fn func(msg proto.Message) bool) {
+	if !fn(msg.Interface()) {
+		return
+	}
+	msg.Range(func(fd protoreflect.FieldDescriptor, value protoreflect.Value) bool {
+		if fd.Message() == nil || fd.IsMap() {
+			return true
+		}
+		if fd.IsList() {
+			list := value.List()
+			for i := 0; i < list.Len(); i++ {
+				walk(list.Get(i).Message(), fn)
+			}
+		} else {
+			walk(value.Message(), fn)
+		}
+		return true
+	})
+}
+
+// Location returns the location recorded by the parser in the node, or -1 if the node has none.
+func Location(node *pg_query.Node) int {
+	location := -1
+	node.ProtoReflect().Range(func(_ protoreflect.FieldDescriptor, value protoreflect.Value) bool {
+		msg := value.Message()
+		if fd := msg.Descriptor().Fields().ByName("location"); fd != nil {
+			location = int(msg.Get(fd).Int())
+		}
+		return false
+	})
+	return location
+}
+
+// QuoteIdentifier quotes the identifier only if required, like quote_ident() of PostgreSQL does.
+func QuoteIdentifier(name string) string {
+	if name == "" {
+		return name
+	}
+	safe := name[0] == '_' || (name[0] >= 'a' && name[0] <= 'z')
+	for i := 1; safe && i < len(name); i++ {
+		c := name[i]
+		safe = c == '_' || c == '$' || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9')
+	}
+	if safe && !isKeyword(name) {
+		return name
+	}
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+func isKeyword(name string) bool {
+	result, err := pg_query.Scan(name)
+	if err != nil || len(result.Tokens) != 1 {
+		return false
+	}
+	kind := result.Tokens[0].KeywordKind
+	return kind != pg_query.KeywordKind_NO_KEYWORD && kind != pg_query.KeywordKind_UNRESERVED_KEYWORD
+}
+
+// RangeVarName returns the name of the relation, qualified with the schema if the statement does so.
+func RangeVarName(rv *pg_query.RangeVar) string {
+	if rv == nil {
+		return ""
+	}
+	name := QuoteIdentifier(rv.Relname)
+	if rv.Schemaname != "" {
+		name = QuoteIdentifier(rv.Schemaname) + "." + name
+	}
+	return name
+}
+
+// NameFromList returns the qualified name made of a list of String nodes, as used for the names
+// of functions, types and other non-relation objects.
+func NameFromList(names []*pg_query.Node) string {
+	var parts []string
+	for _, name := range names {
+		if s := name.GetString_(); s != nil {
+			parts = append(parts, QuoteIdentifier(s.Sval))
+		}
+	}
+	return strings.Join(parts, ".")
+}
+
+// ObjectName returns the name of an object referred to by statements like DROP, ALTER ... RENAME or
+// ALTER ... SET SCHEMA.
+func ObjectName(node *pg_query.Node) string {
+	switch {
+	case node == nil:
+		return ""
+	case node.GetList() != nil:
+		return NameFromList(node.GetList().Items)
+	case node.GetString_() != nil:
+		return QuoteIdentifier(node.GetString_().Sval)
+	case node.GetObjectWithArgs() != nil:
+		return NameFromList(node.GetObjectWithArgs().Objname)
+	case node.GetTypeName() != nil:
+		return NameFromList(node.GetTypeName().Names)
+	case node.GetRangeVar() != nil:
+		return RangeVarName(node.GetRangeVar())
+	}
+	return ""
+}
+
+// TypeName returns the lower-cased unqualified name of the type, e.g. "interval" for pg_catalog.interval.
+func TypeName(typeName *pg_query.TypeName) string {
+	if typeName == nil || len(typeName.Names) == 0 {
+		return ""
+	}
+	last := typeName.Names[len(typeName.Names)-1].GetString_()
+	if last == nil {
+		return ""
+	}
+	return strings.ToLower(last.Sval)
+}
+
+// Token is a lexical token of an SQL text.
+type Token struct {
+	// Text of the token. Keywords are upper-cased.
+	Text string
+	// Offsets of the token in the scanned text.
+	Start int
+	End   int
+	Kind  pg_query.Token
+}
+
+// Tokens splits sql into its lexical tokens, leaving out the comments. Unlike Parse, it works on any
+// text, including statements the PostgreSQL grammar does not accept.
+func Tokens(sql string) ([]Token, error) {
+	result, err := pg_query.Scan(sql)
+	if err != nil {
+		return nil, err
+	}
+	var tokens []Token
+	for _, t := range result.Tokens {
+		if t.Token == pg_query.Token_SQL_COMMENT || t.Token == pg_query.Token_C_COMMENT {
+			continue
+		}
+		token := Token{Text: sql[t.Start:t.End], Start: int(t.Start), End: int(t.End), Kind: t.Token}
+		if t.KeywordKind != pg_query.KeywordKind_NO_KEYWORD || t.Token == pg_query.Token_IDENT {
+			token.Text = strings.ToUpper(token.Text)
+		}
+		tokens = append(tokens, token)
+	}
+	return tokens, nil
+}
+
+// FunctionBody returns the body of a CREATE FUNCTION or CREATE PROCEDURE statement given as a string
+// constant, along with its offset in sql. sql is the text the statement was parsed from.
+func FunctionBody(sql string, stmt *pg_query.CreateFunctionStmt) (string, int, bool) {
+	asLocation := -1
+	for _, option := range stmt.Options {
+		if defElem := option.GetDefElem(); defElem != nil && defElem.Defname == "as" {
+			asLocation = int(defElem.Location)
+		}
+	}
+	if asLocation < 0 || asLocation >= len(sql) {
+		return "", 0, false
+	}
+	tokens, err := Tokens(sql[asLocation:])
+	if err != nil || len(tokens) < 2 || tokens[1].Kind != pg_query.Token_SCONST {
+		return "", 0, false
+	}
+	literal := sql[asLocation+tokens[1].Start : asLocation+tokens[1].End]
+	offset := asLocation + tokens[1].Start
+	switch {
+	case strings.HasPrefix(literal, "$"):
+		delimiter := literal[:strings.Index(literal[1:], "$")+2]
+		return literal[len(delimiter) : len(literal)-len(delimiter)], offset + len(delimiter), true
+	case strings.HasPrefix(literal, "'"):
+		return strings.ReplaceAll(literal[1:len(literal)-1], "''", "'"), offset + 1, true
+	}
+	return "", 0, false
+}
+
+// PlPgSQLExpr is an SQL statement or expression embedded in the body of a PL/pgSQL function.
+type PlPgSQLExpr struct {
+	Query string
+	// How the query is to be parsed, as in the RawParseMode enum of PostgreSQL.
+	ParseMode int
+	// Line number, starting from 1, in the function body of the PL/pgSQL statement the query is part of.
+	LineNo int
+}
+
+const (
+	PARSE_MODE_DEFAULT       = 0
+	PARSE_MODE_TYPE_NAME     = 1
+	PARSE_MODE_PLPGSQL_EXPR  = 2
+	PARSE_MODE_PLPGSQL_ASSN1 = 3
+	PARSE_MODE_PLPGSQL_ASSN3 = 5
+)
+
+// SQL returns a statement which can be parsed with Parse for the query. A location in the parse tree
+// of the returned statement minus shift is the location in Query. ok is false for type names.
+func (e PlPgSQLExpr) SQL() (sql string, shift int, ok bool) {
+	const selectPrefix = "SELECT "
+	switch {
+	case e.ParseMode == PARSE_MODE_DEFAULT:
+		return e.Query, 0, true
+	case e.ParseMode == PARSE_MODE_PLPGSQL_EXPR:
+		return selectPrefix + e.Query, len(selectPrefix), true
+	case e.ParseMode >= PARSE_MODE_PLPGSQL_ASSN1 && e.ParseMode <= PARSE_MODE_PLPGSQL_ASSN3:
+		// Assignments look like "target := expression".
+		operator := ":="
+		idx := strings.Index(e.Query, operator)
+		if idx < 0 {
+			operator = "="
+			idx = strings.Index(e.Query, operator)
+		}
+		if idx < 0 {
+			return "", 0, false
+		}
+		exprStart := idx + len(operator)
+		return selectPrefix + e.Query[exprStart:], len(selectPrefix) - exprStart, true
+	}
+	return "", 0, false
+}
+
+// ParsePlPgSQL parses the body of a CREATE FUNCTION or CREATE PROCEDURE statement written in PL/pgSQL
+// and returns the SQL statements and expressions in it, ordered by line.
+func ParsePlPgSQL(createFunctionStmt string) ([]PlPgSQLExpr, error) {
+	result, err := pg_query.ParsePlPgSqlToJSON(createFunctionStmt)
+	if err != nil {
+		return nil, err
+	}
+	var functions []interface{}
+	err = json.Unmarshal([]byte(result), &functions)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal parsed PL/pgSQL function: %w", err)
+	}
+	var exprs []PlPgSQLExpr
+	collectPlPgSQLExprs(functions, 0, &exprs)
+	sort.SliceStable(exprs, func(i, j int) bool {
+		if exprs[i].LineNo != exprs[j].LineNo {
+			return exprs[i].LineNo < exprs[j].LineNo
+		}
+		return exprs[i].Query < exprs[j].Query
+	})
+	return exprs, nil
+}
+
+func collectPlPgSQLExprs(value interface{}, lineNo int, exprs *[]PlPgSQLExpr) {
+	switch value := value.(type) {
+	case []interface{}:
+		for _, item := range value {
+			collectPlPgSQLExprs(item, lineNo, exprs)
+		}
+	case map[string]interface{}:
+		if n, ok := value["lineno"].(float64); ok {
+			lineNo = int(n)
+		}
+		for key, child := range value {
+			expr, ok := child.(map[string]interface{})
+			if key != "PLpgSQL_expr" || !ok {
+				collectPlPgSQLExprs(child, lineNo, exprs)
+				continue
+			}
+			query, _ := expr["query"].(string)
+			parseMode, _ := expr["parseMode"].(float64)
+			*exprs = append(*exprs, PlPgSQLExpr{Query: query, ParseMode: int(parseMode), LineNo: lineNo})
+		}
+	}
+}
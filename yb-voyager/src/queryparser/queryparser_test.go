@@ -0,0 +1,97 @@
+package queryparser
+
+import (
+	"testing"
+
+	pg_query "github.com/pganalyze/pg_query_go/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	assert := assert.New(t)
+	sql := "\\set ON_ERROR_STOP ON\nCREATE TABLE \"Sales\".orders (id int);\n  ALTER TABLE ONLY public.\"select\" ADD COLUMN note text"
+	stmts, err := Parse(sql)
+	assert.NoError(err)
+	assert.Len(stmts, 2)
+	assert.Equal(`"Sales".orders`, RangeVarName(stmts[0].Stmt.GetCreateStmt().Relation))
+	assert.Equal(`public."select"`, RangeVarName(stmts[1].Stmt.GetAlterTableStmt().Relation))
+	assert.Equal("\n  ALTER TABLE ONLY public.\"select\" ADD COLUMN note text", StmtText(sql, stmts[1]))
+	assert.Equal("ALTER", sql[StmtStart(sql, stmts[1]):StmtStart(sql, stmts[1])+5])
+
+	var columnLocations []int
+	Walk(stmts[1].Stmt, func(node *pg_query.Node) bool {
+		if node.GetColumnDef() != nil {
+			columnLocations = append(columnLocations, Location(node))
+		}
+		return true
+	})
+	assert.Equal([]int{len(sql) - len("note text")}, columnLocations)
+
+	sql = "CREATE TABLE t (id int,)"
+	_, err = Parse(sql)
+	assert.Error(err)
+	assert.Equal(len(sql)-1, ErrorOffset(sql, err))
+}
+
+func TestQuoteIdentifier(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal("orders", QuoteIdentifier("orders"))
+	assert.Equal("order_2$", QuoteIdentifier("order_2$"))
+	assert.Equal(`"Orders"`, QuoteIdentifier("Orders"))
+	assert.Equal(`"uuid-ossp"`, QuoteIdentifier("uuid-ossp"))
+	assert.Equal(`"table"`, QuoteIdentifier("table"))
+	assert.Equal("name", QuoteIdentifier("name")) // unreserved keyword
+	assert.Equal(`"a""b"`, QuoteIdentifier(`a"b`))
+}
+
+func TestTokens(t *testing.T) {
+	assert := assert.New(t)
+	tokens, err := Tokens("CREATE TABLE t (id int) WITH OIDS; -- with oids\n/* fetch */ SELECT 'with oids'")
+	assert.NoError(err)
+	var texts []string
+	for _, token := range tokens {
+		texts = append(texts, token.Text)
+	}
+	assert.Equal([]string{"CREATE", "TABLE", "T", "(", "ID", "INT", ")", "WITH", "OIDS", ";", "SELECT", "'with oids'"}, texts)
+	assert.Equal(24, tokens[7].Start)
+}
+
+func TestParsePlPgSQL(t *testing.T) {
+	assert := assert.New(t)
+	sql := `CREATE FUNCTION f() RETURNS int LANGUAGE plpgsql AS $body$
+DECLARE
+    x int;
+BEGIN
+    x := 5 + 1;
+    IF x > 1 THEN
+        SELECT count(*) INTO x FROM t;
+    END IF;
+    RETURN x;
+END
+$body$`
+	stmts, err := Parse(sql)
+	assert.NoError(err)
+	body, offset, ok := FunctionBody(sql, stmts[0].Stmt.GetCreateFunctionStmt())
+	assert.True(ok)
+	assert.Equal(sql[offset:offset+len(body)], body)
+	assert.Equal("\nDECLARE", body[:8])
+
+	exprs, err := ParsePlPgSQL(sql)
+	assert.NoError(err)
+	assert.Equal([]PlPgSQLExpr{
+		{Query: "x := 5 + 1", ParseMode: PARSE_MODE_PLPGSQL_ASSN1, LineNo: 5},
+		{Query: "x > 1", ParseMode: PARSE_MODE_PLPGSQL_EXPR, LineNo: 6},
+		{Query: "SELECT count(*)        FROM t", ParseMode: PARSE_MODE_DEFAULT, LineNo: 7},
+		{Query: "x", ParseMode: PARSE_MODE_PLPGSQL_EXPR, LineNo: 9},
+	}, exprs)
+
+	exprSQL, shift, ok := exprs[0].SQL()
+	assert.True(ok)
+	stmts, err = Parse(exprSQL)
+	assert.NoError(err)
+	location := Location(stmts[0].Stmt.GetSelectStmt().TargetList[0].GetResTarget().Val)
+	assert.Equal("+ 1", exprs[0].Query[location-shift:])
+
+	_, err = ParsePlPgSQL("CREATE FUNCTION f() RETURNS int LANGUAGE plpgsql AS $$ BEGIN SELECT a BULK COLLECT INTO x FROM t; END $$")
+	assert.Error(err)
+}
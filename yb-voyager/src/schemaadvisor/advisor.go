@@ -0,0 +1,328 @@
+/*
+Copyright (c) YugabyteDB, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package schemaadvisor
+
+import (
+	"sort"
+	"strings"
+
+	pg_query "github.com/pganalyze/pg_query_go/v5"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/yugabyte/yb-voyager/yb-voyager/src/schemadiff"
+)
+
+// Sharding of the leading column of a primary key or an index in YugabyteDB. A key column without an
+// ordering is HASH sharded, except in a colocated table which is always range sharded.
+const (
+	HASH = "HASH"
+	ASC  = "ASC"
+	DESC = "DESC"
+)
+
+const (
+	PRIMARY_KEY = schemadiff.PRIMARY_KEY
+	UNIQUE      = schemadiff.UNIQUE
+	INDEX       = "INDEX"
+)
+
+type ShardingRecommendation struct {
+	ObjectType    string `json:"object_type"`
+	ObjectName    string `json:"object_name"`
+	TableName     string `json:"table_name"`
+	LeadingColumn string `json:"leading_column"`
+	Sharding      string `json:"sharding"`
+	Recommended   string `json:"recommended_sharding"`
+	// True if the inserts of increasing values of the leading column all go to the same tablet.
+	Hotspot bool   `json:"hotspot"`
+	Reason  string `json:"reason"`
+}
+
+type ColocationRecommendation struct {
+	TableName string `json:"table_name"`
+	// -1 if the size of the table is not known.
+	Size      int64  `json:"size_in_bytes"`
+	Colocated bool   `json:"colocated"`
+	Reason    string `json:"reason"`
+}
+
+type Recommendations struct {
+	// True if the tables are to be created in a database created WITH COLOCATION = true.
+	ColocatedDatabase bool                        `json:"colocated_database"`
+	Sharding          []*ShardingRecommendation   `json:"sharding"`
+	Colocation        []*ColocationRecommendation `json:"colocation"`
+}
+
+/*
+Advisor recommends the sharding of the primary keys and the indexes, and the colocation of the tables,
+from the DDLs of the exported schema and the sizes of the tables in the source database.
+
+The keys leading with a column of increasing values, filled from a sequence or with timestamps, are
+hotspots when range sharded, as all the inserts go to the last tablet. The tables smaller than the
+colocation threshold are recommended to be colocated in a single tablet. The partitioned tables and
+their partitions are left out of the colocation recommendations.
+*/
+type Advisor struct {
+	schema              *schemadiff.Schema
+	tableSizes          map[string]int64
+	colocationThreshold int64
+	// Tables in the order of their DDLs.
+	tables      []string
+	partitioned map[string]bool
+	indexes     []*pg_query.IndexStmt
+
+	recommendations *Recommendations
+	// The recommendations applied by Rewrite(), keyed by the name of the index and of the table.
+	indexSharding map[string]string
+	notColocated  map[string]bool
+}
+
+// NewAdvisor returns the advisor for the tables of the given sizes, keyed by their names, qualified or not.
+func NewAdvisor(defaultSchema string, tableSizes map[string]int64, colocationThreshold int64) *Advisor {
+	return &Advisor{
+		schema:              schemadiff.NewSchema(defaultSchema),
+		tableSizes:          tableSizes,
+		colocationThreshold: colocationThreshold,
+		partitioned:         make(map[string]bool),
+	}
+}
+
+func (a *Advisor) AddDDLs(stmts []*pg_query.Node) {
+	a.schema.AddDDLs(stmts)
+	for _, stmt := range stmts {
+		switch {
+		case stmt.GetCreateStmt() != nil:
+			createStmt := stmt.GetCreateStmt()
+			name := a.schema.RelationName(createStmt.Relation)
+			a.tables = append(a.tables, name)
+			if createStmt.Partspec != nil || createStmt.Partbound != nil {
+				a.partitioned[name] = true
+			}
+		case stmt.GetIndexStmt() != nil:
+			a.indexes = append(a.indexes, stmt.GetIndexStmt())
+		}
+	}
+}
+
+func (a *Advisor) Recommend() *Recommendations {
+	recs := &Recommendations{}
+	a.indexSharding = make(map[string]string)
+	a.notColocated = make(map[string]bool)
+
+	colocated := make(map[string]bool)
+	for _, table := range a.tables {
+		if a.partitioned[table] {
+			continue
+		}
+		size, ok := a.tableSize(table)
+		rec := &ColocationRecommendation{TableName: table, Size: size}
+		switch {
+		case !ok:
+			rec.Size = -1
+			rec.Reason = "the size of the table is not known"
+		case size <= a.colocationThreshold:
+			rec.Colocated = true
+			rec.Reason = "small table, colocating it saves a tablet per table and per index"
+			recs.ColocatedDatabase = true
+		default:
+			rec.Reason = "large table, to be split into tablets spread over the nodes"
+			a.notColocated[table] = true
+		}
+		colocated[table] = rec.Colocated
+		recs.Colocation = append(recs.Colocation, rec)
+	}
+	if !recs.ColocatedDatabase {
+		// The tables of a database not colocated are not colocated either.
+		a.notColocated = make(map[string]bool)
+	}
+
+	var constraints []*schemadiff.Constraint
+	for _, constraint := range a.schema.Constraints {
+		if constraint.Type == PRIMARY_KEY || constraint.Type == UNIQUE {
+			constraints = append(constraints, constraint)
+		}
+	}
+	sort.Slice(constraints, func(i, j int) bool {
+		return constraints[i].Table+"."+constraints[i].Name < constraints[j].Table+"."+constraints[j].Name
+	})
+	for _, constraint := range constraints {
+		if len(constraint.Columns) == 0 {
+			continue
+		}
+		// The key columns of a constraint cannot be given an ordering in the DDL.
+		rec := &ShardingRecommendation{ObjectType: constraint.Type, ObjectName: constraint.Name,
+			TableName: constraint.Table, LeadingColumn: constraint.Columns[0], Sharding: HASH}
+		a.recommendSharding(rec, colocated[constraint.Table])
+		recs.Sharding = append(recs.Sharding, rec)
+	}
+	for _, stmt := range a.indexes {
+		elem := stmt.IndexParams[0].GetIndexElem()
+		if elem == nil || elem.Name == "" || !isBtree(stmt) {
+			// Only the btree indexes on columns are sharded by the key.
+			continue
+		}
+		rec := &ShardingRecommendation{ObjectType: INDEX, ObjectName: a.schema.IndexName(stmt),
+			TableName: a.schema.RelationName(stmt.Relation), LeadingColumn: elem.Name, Sharding: getSharding(elem)}
+		a.recommendSharding(rec, colocated[rec.TableName])
+		if rec.Recommended != rec.Sharding {
+			a.indexSharding[rec.ObjectName] = rec.Recommended
+		}
+		recs.Sharding = append(recs.Sharding, rec)
+	}
+	a.recommendations = recs
+	return recs
+}
+
+func (a *Advisor) recommendSharding(rec *ShardingRecommendation, colocated bool) {
+	if colocated {
+		// The keys of the colocated tables without an ordering are range sharded in ascending order.
+		if rec.Sharding == HASH {
+			rec.Sharding = ASC
+		}
+		rec.Recommended = rec.Sharding
+		rec.Reason = "colocated tables are range sharded in a single tablet"
+		return
+	}
+	switch a.getColumnKind(rec.TableName, rec.LeadingColumn) {
+	case sequenceColumn:
+		rec.Recommended = HASH
+		if rec.Sharding != HASH {
+			rec.Hotspot = true
+			rec.Reason = "the values of the sequence are all inserted at the end of the range, into the last tablet; " +
+				"HASH sharding spreads them over the tablets"
+		} else {
+			rec.Reason = "HASH sharding spreads the increasing values of the sequence over the tablets"
+		}
+	case timestampColumn:
+		if rec.ObjectType != INDEX {
+			rec.Recommended = HASH
+			rec.Reason = "HASH sharding spreads the increasing timestamps over the tablets for the lookups of the key"
+			break
+		}
+		rec.Recommended = rec.Sharding
+		if rec.Sharding == HASH {
+			rec.Reason = "HASH sharding spreads the inserts of the increasing timestamps over the tablets; " +
+				"the range scans of the timestamps need ASC sharding of the index"
+			break
+		}
+		rec.Hotspot = true
+		rec.Reason = "range sharding serves the range scans of the timestamps, but the inserts of increasing timestamps " +
+			"all go to the last tablet; lead the index with a low cardinality column if the rate of the inserts is high"
+	default:
+		rec.Recommended = rec.Sharding
+		if rec.Sharding == HASH {
+			rec.Reason = "HASH sharding spreads the rows over the tablets for the lookups of the key"
+		} else {
+			rec.Reason = "range sharding of the DDL kept for the range scans of the key"
+		}
+	}
+}
+
+const (
+	otherColumn = iota
+	sequenceColumn
+	timestampColumn
+)
+
+func (a *Advisor) getColumnKind(tableName string, columnName string) int {
+	table := a.schema.Tables[tableName]
+	if table == nil {
+		return otherColumn
+	}
+	column := table.Column(columnName)
+	switch {
+	case column == nil:
+		return otherColumn
+	case column.Identity || strings.HasPrefix(column.Default, "nextval("):
+		return sequenceColumn
+	case strings.HasPrefix(column.Type, "timestamp") || column.Type == "date":
+		return timestampColumn
+	}
+	return otherColumn
+}
+
+func getSharding(elem *pg_query.IndexElem) string {
+	switch elem.Ordering {
+	case pg_query.SortByDir_SORTBY_ASC:
+		return ASC
+	case pg_query.SortByDir_SORTBY_DESC:
+		return DESC
+	}
+	return HASH
+}
+
+func isBtree(stmt *pg_query.IndexStmt) bool {
+	return len(stmt.IndexParams) > 0 && (stmt.AccessMethod == "" || stmt.AccessMethod == "btree")
+}
+
+// tableSize returns the size of the table, looked up by its qualified name, or else by its name if
+// a single table has that name.
+func (a *Advisor) tableSize(table string) (int64, bool) {
+	if size, ok := a.tableSizes[table]; ok {
+		return size, true
+	}
+	name := normalizedName(table)
+	var size int64
+	found := 0
+	for sizeTable, sizeOfTable := range a.tableSizes {
+		if normalizedName(sizeTable) == name {
+			size = sizeOfTable
+			found++
+		}
+	}
+	return size, found == 1
+}
+
+// normalizedName returns the lower case unqualified name of the table, compared with the names of
+// the tables in the DDLs, which the export may have lower cased.
+func normalizedName(table string) string {
+	tree, err := pg_query.Parse("SELECT FROM " + table)
+	if err != nil {
+		return strings.ToLower(table)
+	}
+	return strings.ToLower(tree.Stmts[0].Stmt.GetSelectStmt().FromClause[0].GetRangeVar().Relname)
+}
+
+// Rewrite returns the statement applying the recommendations, and the description of the changes, or
+// nil if the recommendations do not change the statement. Recommend() must be called first.
+func (a *Advisor) Rewrite(stmt *pg_query.Node) (*pg_query.Node, string) {
+	switch {
+	case stmt.GetIndexStmt() != nil:
+		sharding, ok := a.indexSharding[a.schema.IndexName(stmt.GetIndexStmt())]
+		if !ok {
+			return nil, ""
+		}
+		rewritten := proto.Clone(stmt).(*pg_query.Node)
+		elem := rewritten.GetIndexStmt().IndexParams[0].GetIndexElem()
+		switch sharding {
+		case HASH:
+			elem.Ordering = pg_query.SortByDir_SORTBY_DEFAULT
+		case ASC:
+			elem.Ordering = pg_query.SortByDir_SORTBY_ASC
+		}
+		return rewritten, sharding + " sharding of the index"
+	case stmt.GetCreateStmt() != nil:
+		if !a.notColocated[a.schema.RelationName(stmt.GetCreateStmt().Relation)] {
+			return nil, ""
+		}
+		rewritten := proto.Clone(stmt).(*pg_query.Node)
+		createStmt := rewritten.GetCreateStmt()
+		createStmt.Options = append(createStmt.Options,
+			pg_query.MakeSimpleDefElemNode("colocation", pg_query.MakeStrNode("false"), -1))
+		return rewritten, "table not colocated"
+	}
+	return nil, ""
+}
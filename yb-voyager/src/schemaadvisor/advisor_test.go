@@ -0,0 +1,101 @@
+package schemaadvisor
+
+import (
+	"testing"
+
+	pg_query "github.com/pganalyze/pg_query_go/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+const testDDLs = `
+CREATE TABLE public.orders (id bigint NOT NULL, customer_id int, created_at timestamp with time zone, note text);
+CREATE SEQUENCE public.orders_id_seq;
+ALTER TABLE public.orders ALTER COLUMN id SET DEFAULT nextval('public.orders_id_seq'::regclass);
+ALTER TABLE ONLY public.orders ADD CONSTRAINT orders_pkey PRIMARY KEY (id);
+CREATE TABLE public.events (id bigint GENERATED ALWAYS AS IDENTITY, happened_on date, PRIMARY KEY (happened_on, id));
+CREATE TABLE public.countries (code text PRIMARY KEY, name text UNIQUE);
+CREATE TABLE public.metrics (ts timestamp, value float) PARTITION BY RANGE (ts);
+CREATE INDEX orders_created_at_idx ON public.orders USING btree (created_at);
+CREATE INDEX orders_id_idx ON public.orders USING btree (id DESC);
+CREATE INDEX orders_customer_idx ON public.orders USING btree (customer_id ASC);
+CREATE INDEX orders_note_idx ON public.orders USING gin (to_tsvector('english', note));
+CREATE INDEX countries_name_idx ON public.countries (name);
+`
+
+func newTestAdvisor(t *testing.T) *Advisor {
+	tree, err := pg_query.Parse(testDDLs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var stmts []*pg_query.Node
+	for _, rawStmt := range tree.Stmts {
+		stmts = append(stmts, rawStmt.Stmt)
+	}
+	sizes := map[string]int64{
+		"public.orders": 10 << 30,
+		// Names of the tables of the source database matched with the lower cased names of the DDLs.
+		`PUBLIC."COUNTRIES"`: 1 << 20,
+	}
+	advisor := NewAdvisor("public", sizes, 1<<30)
+	advisor.AddDDLs(stmts)
+	return advisor
+}
+
+func TestRecommend(t *testing.T) {
+	assert := assert.New(t)
+	recs := newTestAdvisor(t).Recommend()
+
+	assert.True(recs.ColocatedDatabase)
+	assert.Equal(3, len(recs.Colocation))
+	assert.Equal([]bool{false, false, true}, []bool{recs.Colocation[0].Colocated, recs.Colocation[1].Colocated, recs.Colocation[2].Colocated})
+	assert.Equal("public.events", recs.Colocation[1].TableName)
+	assert.Equal(int64(-1), recs.Colocation[1].Size)
+
+	type sharding struct {
+		objectName, column, sharding, recommended string
+		hotspot                                   bool
+	}
+	var actual []sharding
+	for _, rec := range recs.Sharding {
+		actual = append(actual, sharding{rec.ObjectName, rec.LeadingColumn, rec.Sharding, rec.Recommended, rec.Hotspot})
+	}
+	assert.Equal([]sharding{
+		{"countries_name_key", "name", ASC, ASC, false},
+		{"countries_pkey", "code", ASC, ASC, false},
+		{"events_pkey", "happened_on", HASH, HASH, false},
+		{"orders_pkey", "id", HASH, HASH, false},
+		{"public.orders_created_at_idx", "created_at", HASH, HASH, false},
+		{"public.orders_id_idx", "id", DESC, HASH, true},
+		{"public.orders_customer_idx", "customer_id", ASC, ASC, false},
+		{"public.countries_name_idx", "name", ASC, ASC, false},
+	}, actual)
+}
+
+func TestRewrite(t *testing.T) {
+	assert := assert.New(t)
+	advisor := newTestAdvisor(t)
+	advisor.Recommend()
+
+	rewritten := func(sql string) string {
+		tree, err := pg_query.Parse(sql)
+		if err != nil {
+			t.Fatal(err)
+		}
+		stmt, _ := advisor.Rewrite(tree.Stmts[0].Stmt)
+		if stmt == nil {
+			return ""
+		}
+		deparsed, err := pg_query.Deparse(&pg_query.ParseResult{Stmts: []*pg_query.RawStmt{{Stmt: stmt}}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return deparsed
+	}
+	assert.Equal("", rewritten("CREATE INDEX orders_created_at_idx ON public.orders USING btree (created_at);"))
+	assert.Equal("CREATE INDEX orders_id_idx ON public.orders USING btree (id)",
+		rewritten("CREATE INDEX orders_id_idx ON public.orders USING btree (id DESC);"))
+	assert.Equal("", rewritten("CREATE INDEX orders_customer_idx ON public.orders USING btree (customer_id ASC);"))
+	assert.Equal("CREATE TABLE public.orders (id bigint NOT NULL) WITH (colocation=false)",
+		rewritten("CREATE TABLE public.orders (id bigint NOT NULL);"))
+	assert.Equal("", rewritten("CREATE TABLE public.countries (code text PRIMARY KEY);"))
+}
@@ -0,0 +1,185 @@
+/*
+Copyright (c) YugabyteDB, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package schemadiff
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/exp/slices"
+
+	"github.com/yugabyte/yb-voyager/yb-voyager/src/queryparser"
+)
+
+const (
+	// The object is defined by the exported schema but not present in the target database.
+	MISSING = "MISSING"
+	// The object is present in the target database but not defined by the exported schema.
+	EXTRA = "EXTRA"
+	// The object is present in the target database with an attribute different from the exported schema.
+	DIFFERENT = "DIFFERENT"
+)
+
+type Diff struct {
+	ObjectType string `json:"object_type"`
+	ObjectName string `json:"object_name"`
+	Status     string `json:"status"`
+	// Attribute of a DIFFERENT object, e.g. the type of a column.
+	Attribute string `json:"attribute,omitempty"`
+	Expected  string `json:"expected,omitempty"`
+	Actual    string `json:"actual,omitempty"`
+}
+
+/*
+Compare returns the differences between the schema expected, from the DDLs of the exported schema, and
+the schema actually present in the target database. The differences are sorted by the object type and
+the name of the object.
+*/
+func Compare(expected *Schema, actual *Schema) []*Diff {
+	var diffs []*Diff
+	add := func(objType, name, status string) {
+		diffs = append(diffs, &Diff{ObjectType: objType, ObjectName: name, Status: status})
+	}
+	different := func(objType, name, attribute, expected, actual string) {
+		diffs = append(diffs, &Diff{ObjectType: objType, ObjectName: name, Status: DIFFERENT,
+			Attribute: attribute, Expected: expected, Actual: actual})
+	}
+
+	for name, table := range expected.Tables {
+		actualTable := actual.Tables[name]
+		if actualTable == nil {
+			add("TABLE", name, MISSING)
+			continue
+		}
+		if !table.columnsKnown || !actualTable.columnsKnown {
+			continue
+		}
+		for _, column := range table.Columns {
+			columnName := name + "." + queryparser.QuoteIdentifier(column.Name)
+			actualColumn := actualTable.Column(column.Name)
+			if actualColumn == nil {
+				add("COLUMN", columnName, MISSING)
+				continue
+			}
+			if !SameType(column.Type, actualColumn.Type) {
+				different("COLUMN", columnName, "type", column.Type, actualColumn.Type)
+			}
+			if column.Default != actualColumn.Default {
+				different("COLUMN", columnName, "default", column.Default, actualColumn.Default)
+			}
+			if column.NotNull != actualColumn.NotNull {
+				different("COLUMN", columnName, "not null", strconv.FormatBool(column.NotNull), strconv.FormatBool(actualColumn.NotNull))
+			}
+		}
+		for _, column := range actualTable.Columns {
+			if table.Column(column.Name) == nil {
+				add("COLUMN", name+"."+queryparser.QuoteIdentifier(column.Name), EXTRA)
+			}
+		}
+	}
+	for name := range actual.Tables {
+		if expected.Tables[name] == nil {
+			add("TABLE", name, EXTRA)
+		}
+	}
+
+	for name, index := range expected.Indexes {
+		actualIndex := actual.Indexes[name]
+		if actualIndex == nil {
+			add("INDEX", name, MISSING)
+			continue
+		}
+		if index.Table != actualIndex.Table {
+			different("INDEX", name, "table", index.Table, actualIndex.Table)
+		}
+		if index.Unique != actualIndex.Unique {
+			different("INDEX", name, "unique", strconv.FormatBool(index.Unique), strconv.FormatBool(actualIndex.Unique))
+		}
+		if !slices.Equal(index.Columns, actualIndex.Columns) {
+			different("INDEX", name, "columns", strings.Join(index.Columns, ", "), strings.Join(actualIndex.Columns, ", "))
+		}
+	}
+	for name, index := range actual.Indexes {
+		if expected.Indexes[name] == nil && !index.Inherited {
+			add("INDEX", name, EXTRA)
+		}
+	}
+
+	for key, constraint := range expected.Constraints {
+		name := constraintName(constraint)
+		actualConstraint := actual.Constraints[key]
+		if actualConstraint == nil {
+			add("CONSTRAINT", name, MISSING)
+			continue
+		}
+		if constraint.Type != actualConstraint.Type {
+			different("CONSTRAINT", name, "type", constraint.Type, actualConstraint.Type)
+			continue
+		}
+		if constraint.Type != CHECK && !slices.Equal(constraint.Columns, actualConstraint.Columns) {
+			different("CONSTRAINT", name, "columns", strings.Join(constraint.Columns, ", "), strings.Join(actualConstraint.Columns, ", "))
+		}
+		if constraint.RefTable != actualConstraint.RefTable {
+			different("CONSTRAINT", name, "referenced table", constraint.RefTable, actualConstraint.RefTable)
+		}
+	}
+	for key, constraint := range actual.Constraints {
+		if expected.Constraints[key] == nil && !constraint.Inherited {
+			add("CONSTRAINT", constraintName(constraint), EXTRA)
+		}
+	}
+
+	for name := range expected.Sequences {
+		if !actual.Sequences[name] {
+			add("SEQUENCE", name, MISSING)
+		}
+	}
+	for name := range actual.Sequences {
+		if !expected.Sequences[name] {
+			add("SEQUENCE", name, EXTRA)
+		}
+	}
+
+	for name, kind := range expected.Views {
+		actualKind, ok := actual.Views[name]
+		if !ok {
+			add(kind, name, MISSING)
+		} else if kind != actualKind {
+			different(kind, name, "kind", kind, actualKind)
+		}
+	}
+	for name, kind := range actual.Views {
+		if _, ok := expected.Views[name]; !ok {
+			add(kind, name, EXTRA)
+		}
+	}
+
+	sort.SliceStable(diffs, func(i, j int) bool {
+		if diffs[i].ObjectType != diffs[j].ObjectType {
+			return diffs[i].ObjectType < diffs[j].ObjectType
+		}
+		if diffs[i].ObjectName != diffs[j].ObjectName {
+			return diffs[i].ObjectName < diffs[j].ObjectName
+		}
+		return diffs[i].Attribute < diffs[j].Attribute
+	})
+	return diffs
+}
+
+func constraintName(constraint *Constraint) string {
+	return queryparser.QuoteIdentifier(constraint.Name) + " on " + constraint.Table
+}
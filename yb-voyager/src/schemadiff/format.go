@@ -0,0 +1,242 @@
+/*
+Copyright (c) YugabyteDB, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package schemadiff
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	pg_query "github.com/pganalyze/pg_query_go/v5"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/yugabyte/yb-voyager/yb-voyager/src/queryparser"
+)
+
+// Names printed by format_type() for the built-in types the parser refers to by their internal names.
+var builtinTypeNames = map[string]string{
+	"int2":        "smallint",
+	"int4":        "integer",
+	"int8":        "bigint",
+	"float4":      "real",
+	"float8":      "double precision",
+	"bool":        "boolean",
+	"varchar":     "character varying",
+	"bpchar":      "character",
+	"varbit":      "bit varying",
+	"timestamp":   "timestamp without time zone",
+	"timestamptz": "timestamp with time zone",
+	"time":        "time without time zone",
+	"timetz":      "time with time zone",
+}
+
+// Types of the serial pseudo-types.
+var serialTypes = map[string]string{
+	"smallserial": "smallint",
+	"serial2":     "smallint",
+	"serial":      "integer",
+	"serial4":     "integer",
+	"bigserial":   "bigint",
+	"serial8":     "bigint",
+}
+
+/*
+FormatType returns the name of the type the way format_type() of PostgreSQL prints it with an empty
+search_path, e.g. "character varying(255)" or "timestamp(3) with time zone", so that a type of a DDL
+compares with the type of the column in the catalog. The types not of pg_catalog keep their schema, if any.
+*/
+func FormatType(typeName *pg_query.TypeName) string {
+	if typeName == nil || len(typeName.Names) == 0 {
+		return ""
+	}
+	var names []string
+	for _, name := range typeName.Names {
+		if s := name.GetString_(); s != nil {
+			names = append(names, s.Sval)
+		}
+	}
+	if len(names) == 0 {
+		return ""
+	}
+	name := names[len(names)-1]
+	qualified := len(names) > 1 && names[len(names)-2] != "pg_catalog"
+	var typmods []string
+	for _, typmod := range typeName.Typmods {
+		if c := typmod.GetAConst(); c != nil {
+			typmods = append(typmods, constValue(c))
+		}
+	}
+	if builtin, ok := builtinTypeNames[name]; ok && !qualified {
+		name = builtin
+	} else if qualified {
+		name = queryparser.QuoteIdentifier(names[len(names)-2]) + "." + queryparser.QuoteIdentifier(name)
+	} else if !isLowerCaseName(name) {
+		// The types of pg_catalog, like numeric, are not quoted even if keywords.
+		name = queryparser.QuoteIdentifier(name)
+	}
+	// The precision of the interval types is encoded with its fields. Only the plain interval is handled.
+	if len(typmods) > 0 && name != "interval" {
+		mods := "(" + strings.Join(typmods, ",") + ")"
+		if prefix, zone, ok := strings.Cut(name, " with"); ok {
+			name = prefix + mods + " with" + zone
+		} else {
+			name += mods
+		}
+	}
+	if len(typeName.ArrayBounds) > 0 {
+		name += "[]"
+	}
+	return name
+}
+
+func isLowerCaseName(name string) bool {
+	for _, c := range name {
+		if c != '_' && c != '$' && (c < 'a' || c > 'z') && (c < '0' || c > '9') {
+			return false
+		}
+	}
+	return name != ""
+}
+
+// SameType returns true if the types are the same. A type not qualified with its schema matches the
+// type of that name in any schema.
+func SameType(type1, type2 string) bool {
+	if type1 == type2 {
+		return true
+	}
+	unqualify := func(typ string) string {
+		if strings.HasPrefix(typ, `"`) {
+			if end := strings.Index(typ[1:], `".`); end >= 0 {
+				return typ[end+3:]
+			}
+			return typ
+		}
+		if dot := strings.Index(typ, "."); dot >= 0 && !strings.Contains(typ[:dot], " ") {
+			return typ[dot+1:]
+		}
+		return typ
+	}
+	return unqualify(type1) == unqualify(type2)
+}
+
+/*
+NormalizeExpr returns the text of the expression, deparsed with the casts of the constants and of the
+columns removed and the constants printed as strings. The defaults and the index expressions of the
+catalog, as printed by pg_get_expr(), then compare with the ones written in the DDLs, e.g. 0 with
+(0)::numeric or lower(name) with lower((name)::text).
+*/
+func NormalizeExpr(expr *pg_query.Node) (string, error) {
+	if expr == nil {
+		return "", nil
+	}
+	tree, err := pg_query.Parse("SELECT 1")
+	if err != nil {
+		return "", err
+	}
+	expr = proto.Clone(expr).(*pg_query.Node)
+	if uncasted := uncast(expr); uncasted != nil {
+		expr = uncasted
+	}
+	normalizeConsts(expr.ProtoReflect())
+	tree.Stmts[0].Stmt.GetSelectStmt().TargetList[0].GetResTarget().Val = expr
+	sql, err := pg_query.Deparse(tree)
+	if err != nil {
+		return "", fmt.Errorf("deparse expression: %w", err)
+	}
+	return strings.TrimPrefix(sql, "SELECT "), nil
+}
+
+// NormalizeExprText is NormalizeExpr for the text of the expression.
+func NormalizeExprText(expr string) (string, error) {
+	if expr == "" {
+		return "", nil
+	}
+	tree, err := pg_query.Parse("SELECT " + expr)
+	if err != nil {
+		return "", err
+	}
+	targets := tree.Stmts[0].Stmt.GetSelectStmt().GetTargetList()
+	if len(targets) != 1 {
+		return "", fmt.Errorf("not a single expression: %s", expr)
+	}
+	return NormalizeExpr(targets[0].GetResTarget().Val)
+}
+
+// uncast returns the constant or the column of the node if the node is a, possibly nested, cast of it.
+func uncast(node *pg_query.Node) *pg_query.Node {
+	for node.GetTypeCast() != nil {
+		node = node.GetTypeCast().Arg
+	}
+	if node.GetAConst() == nil && node.GetColumnRef() == nil {
+		return nil
+	}
+	return node
+}
+
+func normalizeConsts(msg protoreflect.Message) {
+	if node, ok := msg.Interface().(*pg_query.Node); ok {
+		if c := node.GetAConst(); c != nil && !c.Isnull && c.GetBoolval() == nil {
+			c.Val = &pg_query.A_Const_Sval{Sval: &pg_query.String{Sval: constValue(c)}}
+			return
+		}
+	}
+	var fields []protoreflect.FieldDescriptor
+	msg.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		fields = append(fields, fd)
+		return true
+	})
+	for _, fd := range fields {
+		if fd.Kind() != protoreflect.MessageKind {
+			continue
+		}
+		if fd.IsList() {
+			list := msg.Mutable(fd).List()
+			for i := 0; i < list.Len(); i++ {
+				list.Set(i, protoreflect.ValueOfMessage(uncastNode(list.Get(i).Message())))
+			}
+		} else if !fd.IsMap() {
+			msg.Set(fd, protoreflect.ValueOfMessage(uncastNode(msg.Get(fd).Message())))
+		}
+	}
+}
+
+// uncastNode normalizes the message, replacing it by its constant or column if it is a cast of it.
+func uncastNode(msg protoreflect.Message) protoreflect.Message {
+	if node, ok := msg.Interface().(*pg_query.Node); ok {
+		if uncasted := uncast(node); uncasted != nil {
+			msg = uncasted.ProtoReflect()
+		}
+	}
+	normalizeConsts(msg)
+	return msg
+}
+
+func constValue(c *pg_query.A_Const) string {
+	switch {
+	case c.GetIval() != nil:
+		return strconv.Itoa(int(c.GetIval().Ival))
+	case c.GetFval() != nil:
+		return c.GetFval().Fval
+	case c.GetSval() != nil:
+		return c.GetSval().Sval
+	case c.GetBsval() != nil:
+		return c.GetBsval().Bsval
+	case c.GetBoolval() != nil:
+		return strconv.FormatBool(c.GetBoolval().Boolval)
+	}
+	return ""
+}
@@ -0,0 +1,476 @@
+/*
+Copyright (c) YugabyteDB, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package schemadiff
+
+import (
+	"fmt"
+	"strings"
+
+	pg_query "github.com/pganalyze/pg_query_go/v5"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/exp/slices"
+
+	"github.com/yugabyte/yb-voyager/yb-voyager/src/queryparser"
+)
+
+const (
+	PRIMARY_KEY = "PRIMARY KEY"
+	UNIQUE      = "UNIQUE"
+	FOREIGN_KEY = "FOREIGN KEY"
+	CHECK       = "CHECK"
+)
+
+type Column struct {
+	Name string
+	// Type as printed by format_type(), see FormatType().
+	Type string
+	// Default expression normalized by NormalizeExpr(). The expression of a generated column.
+	Default string
+	NotNull bool
+	// True for a column GENERATED AS IDENTITY. The identity is not compared.
+	Identity bool
+}
+
+type Table struct {
+	Name    string
+	Columns []*Column
+	// False for a table created by CREATE TABLE AS, for which the columns are not compared.
+	columnsKnown bool
+}
+
+func (t *Table) Column(name string) *Column {
+	for _, column := range t.Columns {
+		if column.Name == name {
+			return column
+		}
+	}
+	return nil
+}
+
+type Index struct {
+	Name   string
+	Table  string
+	Unique bool
+	// Key and included columns, the expressions normalized by NormalizeExpr().
+	Columns []string
+	// True for an index of a partition created by the index of the partitioned table. Such indexes
+	// are compared only if the DDLs create them.
+	Inherited bool
+}
+
+type Constraint struct {
+	Name     string
+	Table    string
+	Type     string
+	Columns  []string
+	RefTable string
+	// True for a constraint of a partition or a child table coming from the parent table. Such
+	// constraints are not compared.
+	Inherited bool
+}
+
+/*
+Schema is the set of the tables, indexes, constraints, sequences and views of a database, either as
+defined by the DDLs of the exported schema, or as present in the catalog of the target database.
+All the names are qualified with their schema, and quoted if required.
+*/
+type Schema struct {
+	Tables map[string]*Table
+	// Indexes other than the ones of the primary key and unique constraints.
+	Indexes map[string]*Index
+	// Keyed by the table and the name of the constraint.
+	Constraints map[string]*Constraint
+	Sequences   map[string]bool
+	// Kind of the view, VIEW or MATERIALIZED VIEW.
+	Views map[string]string
+
+	// Schema of the objects not qualified in the DDLs.
+	defaultSchema string
+}
+
+func NewSchema(defaultSchema string) *Schema {
+	return &Schema{
+		Tables:        make(map[string]*Table),
+		Indexes:       make(map[string]*Index),
+		Constraints:   make(map[string]*Constraint),
+		Sequences:     make(map[string]bool),
+		Views:         make(map[string]string),
+		defaultSchema: defaultSchema,
+	}
+}
+
+func QualifiedName(schema string, name string) string {
+	return queryparser.QuoteIdentifier(schema) + "." + queryparser.QuoteIdentifier(name)
+}
+
+func constraintKey(table string, name string) string {
+	return table + "." + queryparser.QuoteIdentifier(name)
+}
+
+// AddTable adds the table, with its columns known and added by AddColumn().
+func (s *Schema) AddTable(name string) *Table {
+	table := &Table{Name: name, columnsKnown: true}
+	s.Tables[name] = table
+	return table
+}
+
+func (s *Schema) AddConstraint(constraint *Constraint) {
+	s.Constraints[constraintKey(constraint.Table, constraint.Name)] = constraint
+}
+
+// Schemas returns the schemas of the objects.
+func (s *Schema) Schemas() []string {
+	var schemas []string
+	add := func(name string) {
+		schema, _ := splitQualifiedName(name)
+		if !slices.Contains(schemas, schema) {
+			schemas = append(schemas, schema)
+		}
+	}
+	for name := range s.Tables {
+		add(name)
+	}
+	for name := range s.Indexes {
+		add(name)
+	}
+	for name := range s.Sequences {
+		add(name)
+	}
+	for name := range s.Views {
+		add(name)
+	}
+	return schemas
+}
+
+// RelationName returns the qualified name of the relation, in the default schema if not qualified.
+func (s *Schema) RelationName(rv *pg_query.RangeVar) string {
+	schema := rv.Schemaname
+	if schema == "" {
+		schema = s.defaultSchema
+	}
+	return QualifiedName(schema, rv.Relname)
+}
+
+// AddDDLs adds the DDLs in their order, except for the ALTER TABLE statements which are added after
+// all the other ones. pg_dump puts the ALTER TABLE setting the default of a column to a sequence
+// with the sequence, before the table.
+func (s *Schema) AddDDLs(stmts []*pg_query.Node) {
+	for _, stmt := range stmts {
+		if stmt.GetAlterTableStmt() == nil {
+			s.AddDDL(stmt)
+		}
+	}
+	for _, stmt := range stmts {
+		if stmt.GetAlterTableStmt() != nil {
+			s.AddDDL(stmt)
+		}
+	}
+}
+
+// AddDDL adds the objects created by the DDL, and applies the changes made by the DDL to the objects
+// already added. The DDLs not about tables, indexes, constraints, sequences or views are ignored.
+func (s *Schema) AddDDL(stmt *pg_query.Node) {
+	switch {
+	case stmt.GetCreateStmt() != nil:
+		s.addCreateTable(stmt.GetCreateStmt())
+	case stmt.GetAlterTableStmt() != nil:
+		s.addAlterTable(stmt.GetAlterTableStmt())
+	case stmt.GetIndexStmt() != nil:
+		s.addIndex(stmt.GetIndexStmt())
+	case stmt.GetCreateSeqStmt() != nil:
+		s.Sequences[s.RelationName(stmt.GetCreateSeqStmt().Sequence)] = true
+	case stmt.GetViewStmt() != nil:
+		s.Views[s.RelationName(stmt.GetViewStmt().View)] = "VIEW"
+	case stmt.GetCreateTableAsStmt() != nil:
+		ctas := stmt.GetCreateTableAsStmt()
+		name := s.RelationName(ctas.Into.Rel)
+		if ctas.Objtype == pg_query.ObjectType_OBJECT_MATVIEW {
+			s.Views[name] = "MATERIALIZED VIEW"
+		} else {
+			s.Tables[name] = &Table{Name: name}
+		}
+	}
+}
+
+func (s *Schema) addCreateTable(stmt *pg_query.CreateStmt) {
+	table := s.AddTable(s.RelationName(stmt.Relation))
+	for _, parent := range stmt.InhRelations {
+		parentTable := s.Tables[s.RelationName(parent.GetRangeVar())]
+		if parentTable == nil || !parentTable.columnsKnown {
+			table.columnsKnown = false
+			continue
+		}
+		// The constraints coming from the parent are not compared, only the ones of the parent are.
+		for _, column := range parentTable.Columns {
+			copied := *column
+			table.Columns = append(table.Columns, &copied)
+		}
+	}
+	if stmt.GetOfTypename() != nil {
+		table.columnsKnown = false
+	}
+	for _, elt := range stmt.TableElts {
+		switch {
+		case elt.GetColumnDef() != nil:
+			s.addColumn(table, elt.GetColumnDef())
+		case elt.GetConstraint() != nil:
+			s.addTableConstraint(table, elt.GetConstraint(), "")
+		}
+	}
+}
+
+func (s *Schema) addColumn(table *Table, columnDef *pg_query.ColumnDef) {
+	column := table.Column(columnDef.Colname)
+	if column == nil {
+		column = &Column{Name: columnDef.Colname}
+		table.Columns = append(table.Columns, column)
+	}
+	if columnDef.TypeName != nil {
+		column.Type = FormatType(columnDef.TypeName)
+		if len(columnDef.TypeName.Names) == 1 && serialTypes[queryparser.TypeName(columnDef.TypeName)] != "" {
+			// CREATE TABLE t (id serial) is CREATE SEQUENCE t_id_seq plus an integer column defaulting to it.
+			schema, name := splitQualifiedName(table.Name)
+			sequence := QualifiedName(schema, name+"_"+column.Name+"_seq")
+			s.Sequences[sequence] = true
+			column.Type = serialTypes[queryparser.TypeName(columnDef.TypeName)]
+			column.Default = normalizeExprTextOrLog(fmt.Sprintf("nextval('%s'::regclass)", strings.ReplaceAll(sequence, "'", "''")))
+			column.NotNull = true
+		}
+	}
+	if columnDef.RawDefault != nil {
+		column.Default = normalizeExprOrLog(columnDef.RawDefault)
+	}
+	for _, elt := range columnDef.Constraints {
+		constraint := elt.GetConstraint()
+		if constraint == nil {
+			continue
+		}
+		switch constraint.Contype {
+		case pg_query.ConstrType_CONSTR_NOTNULL:
+			column.NotNull = true
+		case pg_query.ConstrType_CONSTR_IDENTITY:
+			column.NotNull = true
+			column.Identity = true
+		case pg_query.ConstrType_CONSTR_NULL:
+			column.NotNull = false
+		case pg_query.ConstrType_CONSTR_DEFAULT, pg_query.ConstrType_CONSTR_GENERATED:
+			column.Default = normalizeExprOrLog(constraint.RawExpr)
+		default:
+			s.addTableConstraint(table, constraint, column.Name)
+		}
+	}
+}
+
+// addTableConstraint adds the constraint defined on the table, or on the column if column is not empty.
+func (s *Schema) addTableConstraint(table *Table, constraint *pg_query.Constraint, column string) {
+	c := &Constraint{Name: constraint.Conname, Table: table.Name}
+	keys := constraint.Keys
+	switch constraint.Contype {
+	case pg_query.ConstrType_CONSTR_PRIMARY:
+		c.Type = PRIMARY_KEY
+	case pg_query.ConstrType_CONSTR_UNIQUE:
+		c.Type = UNIQUE
+	case pg_query.ConstrType_CONSTR_FOREIGN:
+		c.Type = FOREIGN_KEY
+		keys = constraint.FkAttrs
+		c.RefTable = s.RelationName(constraint.Pktable)
+	case pg_query.ConstrType_CONSTR_CHECK:
+		c.Type = CHECK
+		if column == "" {
+			c.Columns = getReferencedColumns(constraint.RawExpr)
+		}
+	default:
+		return
+	}
+	if column != "" {
+		c.Columns = []string{column}
+	}
+	for _, key := range keys {
+		if name := key.GetString_(); name != nil {
+			c.Columns = append(c.Columns, name.Sval)
+		}
+	}
+	if c.Type == PRIMARY_KEY {
+		for _, name := range c.Columns {
+			if column := table.Column(name); column != nil {
+				column.NotNull = true
+			}
+		}
+	}
+	if c.Name == "" {
+		c.Name = defaultConstraintName(table.Name, c.Type, c.Columns)
+	}
+	if c.Type == CHECK {
+		// Only the name and the type of the CHECK constraints are compared.
+		c.Columns = nil
+	}
+	s.AddConstraint(c)
+}
+
+// defaultConstraintName returns the name PostgreSQL gives to a constraint created without a name.
+func defaultConstraintName(table string, constraintType string, columns []string) string {
+	name := unqualifiedName(table)
+	switch constraintType {
+	case PRIMARY_KEY:
+		return name + "_pkey"
+	case UNIQUE:
+		return name + "_" + strings.Join(columns, "_") + "_key"
+	case FOREIGN_KEY:
+		return name + "_" + strings.Join(columns, "_") + "_fkey"
+	}
+	if len(columns) == 1 {
+		return name + "_" + columns[0] + "_check"
+	}
+	return name + "_check"
+}
+
+// getReferencedColumns returns the columns referred to by the expression, in the order of the references.
+func getReferencedColumns(expr *pg_query.Node) []string {
+	var columns []string
+	queryparser.Walk(expr, func(node *pg_query.Node) bool {
+		if ref := node.GetColumnRef(); ref != nil && len(ref.Fields) > 0 {
+			if name := ref.Fields[len(ref.Fields)-1].GetString_(); name != nil {
+				for _, column := range columns {
+					if column == name.Sval {
+						return true
+					}
+				}
+				columns = append(columns, name.Sval)
+			}
+		}
+		return true
+	})
+	return columns
+}
+
+func (s *Schema) addAlterTable(stmt *pg_query.AlterTableStmt) {
+	table := s.Tables[s.RelationName(stmt.Relation)]
+	if table == nil {
+		return
+	}
+	for _, elt := range stmt.Cmds {
+		cmd := elt.GetAlterTableCmd()
+		if cmd == nil {
+			continue
+		}
+		column := table.Column(cmd.Name)
+		switch cmd.Subtype {
+		case pg_query.AlterTableType_AT_AddColumn:
+			if columnDef := cmd.Def.GetColumnDef(); columnDef != nil {
+				s.addColumn(table, columnDef)
+			}
+		case pg_query.AlterTableType_AT_AddConstraint:
+			if constraint := cmd.Def.GetConstraint(); constraint != nil {
+				s.addTableConstraint(table, constraint, "")
+			}
+		case pg_query.AlterTableType_AT_DropConstraint:
+			delete(s.Constraints, constraintKey(table.Name, cmd.Name))
+		case pg_query.AlterTableType_AT_DropColumn:
+			for i, column := range table.Columns {
+				if column.Name == cmd.Name {
+					table.Columns = append(table.Columns[:i], table.Columns[i+1:]...)
+					break
+				}
+			}
+		}
+		if column == nil {
+			continue
+		}
+		switch cmd.Subtype {
+		case pg_query.AlterTableType_AT_ColumnDefault:
+			column.Default = normalizeExprOrLog(cmd.Def)
+		case pg_query.AlterTableType_AT_SetNotNull:
+			column.NotNull = true
+		case pg_query.AlterTableType_AT_AddIdentity:
+			column.NotNull = true
+			column.Identity = true
+		case pg_query.AlterTableType_AT_DropNotNull:
+			column.NotNull = false
+		case pg_query.AlterTableType_AT_AlterColumnType:
+			if columnDef := cmd.Def.GetColumnDef(); columnDef != nil && columnDef.TypeName != nil {
+				column.Type = FormatType(columnDef.TypeName)
+			}
+		}
+	}
+}
+
+func (s *Schema) addIndex(stmt *pg_query.IndexStmt) {
+	index := &Index{Name: s.IndexName(stmt), Table: s.RelationName(stmt.Relation), Unique: stmt.Unique}
+	for _, param := range append(stmt.IndexParams, stmt.IndexIncludingParams...) {
+		elem := param.GetIndexElem()
+		if elem == nil {
+			continue
+		}
+		if elem.Name != "" {
+			index.Columns = append(index.Columns, queryparser.QuoteIdentifier(elem.Name))
+		} else {
+			index.Columns = append(index.Columns, normalizeExprOrLog(elem.Expr))
+		}
+	}
+	s.Indexes[index.Name] = index
+}
+
+// IndexName returns the qualified name of the index created by the statement, the name PostgreSQL
+// gives to the index if the statement does not name it.
+func (s *Schema) IndexName(stmt *pg_query.IndexStmt) string {
+	tableName := s.RelationName(stmt.Relation)
+	name := stmt.Idxname
+	if name == "" {
+		var columns []string
+		for _, param := range stmt.IndexParams {
+			if elem := param.GetIndexElem(); elem != nil && elem.Name != "" {
+				columns = append(columns, elem.Name)
+			} else {
+				columns = append(columns, "expr")
+			}
+		}
+		name = unqualifiedName(tableName) + "_" + strings.Join(columns, "_") + "_idx"
+	}
+	// An index is always in the schema of its table.
+	schema, _ := splitQualifiedName(tableName)
+	return QualifiedName(schema, name)
+}
+
+// splitQualifiedName returns the unquoted schema and name of the object from its qualified name.
+func splitQualifiedName(qualifiedName string) (string, string) {
+	tree, err := pg_query.Parse("SELECT FROM " + qualifiedName)
+	if err != nil {
+		return "", qualifiedName
+	}
+	rv := tree.Stmts[0].Stmt.GetSelectStmt().FromClause[0].GetRangeVar()
+	return rv.Schemaname, rv.Relname
+}
+
+func unqualifiedName(qualifiedName string) string {
+	_, name := splitQualifiedName(qualifiedName)
+	return name
+}
+
+func normalizeExprTextOrLog(expr string) string {
+	text, err := NormalizeExprText(expr)
+	if err != nil {
+		log.Warnf("normalize expression %q: %v", expr, err)
+	}
+	return text
+}
+
+func normalizeExprOrLog(expr *pg_query.Node) string {
+	text, err := NormalizeExpr(expr)
+	if err != nil {
+		log.Warnf("normalize expression: %v", err)
+	}
+	return text
+}
@@ -0,0 +1,150 @@
+package schemadiff
+
+import (
+	"testing"
+
+	pg_query "github.com/pganalyze/pg_query_go/v5"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yugabyte/yb-voyager/yb-voyager/src/queryparser"
+)
+
+func parseSchema(t *testing.T, defaultSchema string, sql string) *Schema {
+	stmts, err := queryparser.Parse(sql)
+	assert.NoError(t, err)
+	schema := NewSchema(defaultSchema)
+	var nodes []*pg_query.Node
+	for _, stmt := range stmts {
+		nodes = append(nodes, stmt.Stmt)
+	}
+	schema.AddDDLs(nodes)
+	return schema
+}
+
+func TestFormatType(t *testing.T) {
+	assert := assert.New(t)
+	schema := parseSchema(t, "public", `CREATE TABLE t (
+    a int,
+    b varchar(255),
+    c character varying,
+    d numeric(10,2),
+    e timestamp(3) with time zone,
+    f timestamp,
+    g double precision,
+    h char,
+    i text[],
+    j public.citext,
+    k "MyType",
+    l bool
+);`)
+	var types []string
+	for _, column := range schema.Tables["public.t"].Columns {
+		types = append(types, column.Type)
+	}
+	assert.Equal([]string{"integer", "character varying(255)", "character varying", "numeric(10,2)",
+		"timestamp(3) with time zone", "timestamp without time zone", "double precision", "character(1)",
+		"text[]", "public.citext", `"MyType"`, "boolean"}, types)
+
+	assert.True(SameType("citext", "public.citext"))
+	assert.True(SameType(`"MyType"`, `public."MyType"`))
+	assert.False(SameType("integer", "bigint"))
+	assert.False(SameType("character varying(255)", "character varying(100)"))
+}
+
+func TestNormalizeExpr(t *testing.T) {
+	assert := assert.New(t)
+	for _, exprs := range [][2]string{
+		{"0", "(0)::numeric"},
+		{"-1", "'-1'::integer"},
+		{"'us'", "'us'::text"},
+		{"'us'::character varying", "'us'::text"},
+		{"nextval('public.orders_id_seq'::regclass)", "nextval('public.orders_id_seq'::regclass)"},
+		{"now()", "now()"},
+		{"lower(name)", "lower((name)::text)"},
+	} {
+		expected, err := NormalizeExprText(exprs[0])
+		assert.NoError(err)
+		actual, err := NormalizeExprText(exprs[1])
+		assert.NoError(err)
+		assert.Equal(expected, actual, "%s and %s", exprs[0], exprs[1])
+	}
+	expr1, _ := NormalizeExprText("now()")
+	expr2, _ := NormalizeExprText("CURRENT_DATE")
+	assert.NotEqual(expr1, expr2)
+}
+
+func TestCompare(t *testing.T) {
+	assert := assert.New(t)
+	expected := parseSchema(t, "public", `ALTER TABLE ONLY sales.customers ALTER COLUMN region SET DEFAULT 'us';
+
+CREATE TABLE sales.customers (
+    id integer NOT NULL,
+    name varchar(100),
+    region text,
+    CHECK (length(name) > 0)
+);
+
+CREATE TABLE sales.orders (
+    id serial PRIMARY KEY,
+    customer_id integer REFERENCES sales.customers(id),
+    amount numeric(10,2) DEFAULT 0 NOT NULL
+);
+
+ALTER TABLE ONLY sales.customers
+    ADD CONSTRAINT customers_pkey PRIMARY KEY (id);
+
+CREATE INDEX orders_customer_idx ON sales.orders USING btree (customer_id);
+
+CREATE INDEX customers_lower_name_idx ON sales.customers (lower(name));
+
+CREATE VIEW sales.big_orders AS SELECT * FROM sales.orders WHERE amount > 1000;
+
+CREATE TABLE events (id int);
+`)
+	assert.Equal([]string{"sales", "public"}, func() []string {
+		schemas := expected.Schemas()
+		if schemas[0] == "public" {
+			schemas[0], schemas[1] = schemas[1], schemas[0]
+		}
+		return schemas
+	}())
+
+	// The target as created from the DDLs, with a few changes.
+	actual := NewSchema("")
+	customers := actual.AddTable("sales.customers")
+	customers.Columns = []*Column{
+		{Name: "id", Type: "integer", NotNull: true},
+		{Name: "name", Type: "character varying(50)"},
+		{Name: "region", Type: "text", Default: normalizeExprTextOrLog("'us'::text")},
+	}
+	orders := actual.AddTable("sales.orders")
+	orders.Columns = []*Column{
+		{Name: "id", Type: "integer", NotNull: true, Default: normalizeExprTextOrLog("nextval('sales.orders_id_seq'::regclass)")},
+		{Name: "customer_id", Type: "integer"},
+		{Name: "amount", Type: "numeric(10,2)", NotNull: true, Default: normalizeExprTextOrLog("(0)::numeric")},
+		{Name: "note", Type: "text"},
+	}
+	actual.AddConstraint(&Constraint{Name: "customers_pkey", Table: "sales.customers", Type: PRIMARY_KEY, Columns: []string{"id"}})
+	actual.AddConstraint(&Constraint{Name: "customers_name_check", Table: "sales.customers", Type: CHECK})
+	actual.AddConstraint(&Constraint{Name: "orders_pkey", Table: "sales.orders", Type: PRIMARY_KEY, Columns: []string{"id"}})
+	actual.Indexes["sales.orders_customer_idx"] = &Index{Name: "sales.orders_customer_idx", Table: "sales.orders", Columns: []string{"customer_id"}}
+	actual.Indexes["sales.customers_lower_name_idx"] = &Index{Name: "sales.customers_lower_name_idx", Table: "sales.customers",
+		Columns: []string{normalizeExprTextOrLog("lower((name)::text)")}}
+	actual.Indexes["sales.orders_part_idx"] = &Index{Name: "sales.orders_part_idx", Table: "sales.orders", Inherited: true}
+	actual.Sequences["sales.orders_id_seq"] = true
+	actual.Sequences["sales.unused_seq"] = true
+	actual.Views["sales.big_orders"] = "MATERIALIZED VIEW"
+
+	var diffs []Diff
+	for _, diff := range Compare(expected, actual) {
+		diffs = append(diffs, *diff)
+	}
+	assert.Equal([]Diff{
+		{ObjectType: "COLUMN", ObjectName: "sales.customers.name", Status: DIFFERENT, Attribute: "type", Expected: "character varying(100)", Actual: "character varying(50)"},
+		{ObjectType: "COLUMN", ObjectName: "sales.orders.note", Status: EXTRA},
+		{ObjectType: "CONSTRAINT", ObjectName: "orders_customer_id_fkey on sales.orders", Status: MISSING},
+		{ObjectType: "SEQUENCE", ObjectName: "sales.unused_seq", Status: EXTRA},
+		{ObjectType: "TABLE", ObjectName: "public.events", Status: MISSING},
+		{ObjectType: "VIEW", ObjectName: "sales.big_orders", Status: DIFFERENT, Attribute: "kind", Expected: "VIEW", Actual: "MATERIALIZED VIEW"},
+	}, diffs)
+}
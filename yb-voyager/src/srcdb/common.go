@@ -35,6 +35,8 @@ func getExportedDataFileList(tablesMetadata map[string]*utils.TableProgressMetad
 	for key := range tablesMetadata {
 		tableMetadata := tablesMetadata[key]
 		targetTableName := strings.TrimSuffix(filepath.Base(tableMetadata.FinalFilePath), "_data.sql")
+		// The data of SQLite is exported to CSV files.
+		targetTableName = strings.TrimSuffix(targetTableName, "_data.csv")
 		if !utils.FileOrFolderExists(tableMetadata.FinalFilePath) {
 			// This can happen in case of nested tables in Oracle.
 			log.Infof("File %q does not exist. Not including table %q in the descriptor.",
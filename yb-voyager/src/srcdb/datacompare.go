@@ -0,0 +1,213 @@
+/*
+Copyright (c) YugabyteDB, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package srcdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v4"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/yugabyte/yb-voyager/yb-voyager/src/utils/sqlname"
+)
+
+var ErrChecksumNotSupported = errors.New("table checksum is not supported for this database type")
+
+// KeyRange represents the rows of a table whose key columns lie in the range (Lower, Upper].
+// A nil Lower or Upper means that the range is unbounded on that side.
+type KeyRange struct {
+	Lower []string
+	Upper []string
+}
+
+// Returns the predicate selecting the rows of the range on a PostgreSQL compatible database.
+// The key values are passed as untyped literals so that they are coerced to the column types.
+// Rows having NULL in any of the key columns are never part of a range.
+func (r *KeyRange) PGPredicate(keyColumns []string) string {
+	var conditions []string
+	for _, col := range keyColumns {
+		conditions = append(conditions, fmt.Sprintf("%s IS NOT NULL", col))
+	}
+	keyTuple := fmt.Sprintf("(%s)", strings.Join(keyColumns, ", "))
+	if r != nil && r.Lower != nil {
+		conditions = append(conditions, fmt.Sprintf("%s > %s", keyTuple, pgLiteralTuple(r.Lower)))
+	}
+	if r != nil && r.Upper != nil {
+		conditions = append(conditions, fmt.Sprintf("%s <= %s", keyTuple, pgLiteralTuple(r.Upper)))
+	}
+	return strings.Join(conditions, " AND ")
+}
+
+func pgLiteralTuple(values []string) string {
+	literals := make([]string, len(values))
+	for i, value := range values {
+		literals[i] = "'" + strings.ReplaceAll(value, "'", "''") + "'"
+	}
+	return fmt.Sprintf("(%s)", strings.Join(literals, ", "))
+}
+
+type RowHash struct {
+	Key  []string
+	Hash string
+}
+
+// GetPGTableChecksumQuery returns a query that computes the row count and an order-independent checksum
+// of the given columns of the rows matching the predicate. Every row is hashed with md5() and the first
+// 60 bits of the hashes are summed up, so that the same query can be run on PostgreSQL/YugabyteDB sources
+// and on the YugabyteDB target without requiring any ORDER BY on the (possibly huge) table.
+func GetPGTableChecksumQuery(qualifiedTableName string, columns []string, predicate string) string {
+	query := fmt.Sprintf(`SELECT COUNT(*), COALESCE(SUM(('x' || SUBSTR(MD5(ROW(%s)::text), 1, 15))::bit(60)::bigint), 0)::text FROM %s`,
+		strings.Join(columns, ", "), qualifiedTableName)
+	if predicate != "" {
+		query += " WHERE " + predicate
+	}
+	return query
+}
+
+// GetPGKeyBoundariesQuery returns a query that fetches every step-th key (in the key order) of the rows
+// matching the predicate. The returned keys split the rows into ranges of `step` rows each.
+func GetPGKeyBoundariesQuery(qualifiedTableName string, keyColumns []string, predicate string, step int64) string {
+	keyList := strings.Join(keyColumns, ", ")
+	textKeyList := strings.Join(pgTextColumns(keyColumns), ", ")
+	return fmt.Sprintf(`SELECT %s FROM (SELECT %s, ROW_NUMBER() OVER (ORDER BY %s) AS voyager_row_num FROM %s WHERE %s) AS t
+		WHERE voyager_row_num %% %d = 0 ORDER BY %s`, textKeyList, keyList, keyList, qualifiedTableName, predicate, step, keyList)
+}
+
+// GetPGRowHashesQuery returns a query that fetches the key and the md5 hash of the given columns
+// for every row matching the predicate.
+func GetPGRowHashesQuery(qualifiedTableName string, columns []string, keyColumns []string, predicate string) string {
+	return fmt.Sprintf(`SELECT %s, MD5(ROW(%s)::text) FROM %s WHERE %s`,
+		strings.Join(pgTextColumns(keyColumns), ", "), strings.Join(columns, ", "), qualifiedTableName, predicate)
+}
+
+func pgTextColumns(columns []string) []string {
+	result := make([]string, len(columns))
+	for i, col := range columns {
+		result[i] = fmt.Sprintf("%s::text", col)
+	}
+	return result
+}
+
+// Rows is satisfied by both pgx.Rows and tgtdb.Rows.
+type Rows interface {
+	Next() bool
+	Scan(dest ...interface{}) error
+	Err() error
+}
+
+func ScanKeyBoundaries(rows Rows, numKeyColumns int) ([][]string, error) {
+	var boundaries [][]string
+	for rows.Next() {
+		key := make([]string, numKeyColumns)
+		dest := make([]interface{}, numKeyColumns)
+		for i := range key {
+			dest[i] = &key[i]
+		}
+		err := rows.Scan(dest...)
+		if err != nil {
+			return nil, fmt.Errorf("scan key boundary: %w", err)
+		}
+		boundaries = append(boundaries, key)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("iterate over key boundaries: %w", rows.Err())
+	}
+	return boundaries, nil
+}
+
+func ScanRowHashes(rows Rows, numKeyColumns int) ([]*RowHash, error) {
+	var rowHashes []*RowHash
+	for rows.Next() {
+		rowHash := &RowHash{Key: make([]string, numKeyColumns)}
+		dest := make([]interface{}, numKeyColumns+1)
+		for i := range rowHash.Key {
+			dest[i] = &rowHash.Key[i]
+		}
+		dest[numKeyColumns] = &rowHash.Hash
+		err := rows.Scan(dest...)
+		if err != nil {
+			return nil, fmt.Errorf("scan row hash: %w", err)
+		}
+		rowHashes = append(rowHashes, rowHash)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("iterate over row hashes: %w", rows.Err())
+	}
+	return rowHashes, nil
+}
+
+//=============================================================
+// Implementations shared by the PostgreSQL and YugabyteDB source databases.
+// A new connection is used for every call to avoid conn busy err as multiple parallel(and time-taking) queries are possible.
+
+func pgGetTableChecksum(connUri string, tableName *sqlname.SourceName, columns []string, keyColumns []string, keyRange *KeyRange) (int64, string, error) {
+	conn, err := pgx.Connect(context.Background(), connUri)
+	if err != nil {
+		return 0, "", fmt.Errorf("connect to the source database for table checksum: %w", err)
+	}
+	defer conn.Close(context.Background())
+
+	predicate := ""
+	if len(keyColumns) > 0 {
+		predicate = keyRange.PGPredicate(keyColumns)
+	}
+	var rowCount int64
+	var checksum string
+	query := GetPGTableChecksumQuery(tableName.Qualified.MinQuoted, columns, predicate)
+	log.Infof("Querying checksum of table %q: %s", tableName, query)
+	err = conn.QueryRow(context.Background(), query).Scan(&rowCount, &checksum)
+	if err != nil {
+		return 0, "", fmt.Errorf("query %q for checksum of %q: %w", query, tableName, err)
+	}
+	return rowCount, checksum, nil
+}
+
+func pgGetTableKeyBoundaries(connUri string, tableName *sqlname.SourceName, keyColumns []string, keyRange *KeyRange, step int64) ([][]string, error) {
+	conn, err := pgx.Connect(context.Background(), connUri)
+	if err != nil {
+		return nil, fmt.Errorf("connect to the source database for key boundaries: %w", err)
+	}
+	defer conn.Close(context.Background())
+
+	query := GetPGKeyBoundariesQuery(tableName.Qualified.MinQuoted, keyColumns, keyRange.PGPredicate(keyColumns), step)
+	log.Infof("Querying key boundaries of table %q: %s", tableName, query)
+	rows, err := conn.Query(context.Background(), query)
+	if err != nil {
+		return nil, fmt.Errorf("query %q for key boundaries of %q: %w", query, tableName, err)
+	}
+	defer rows.Close()
+	return ScanKeyBoundaries(rows, len(keyColumns))
+}
+
+func pgGetTableRowHashes(connUri string, tableName *sqlname.SourceName, columns []string, keyColumns []string, keyRange *KeyRange) ([]*RowHash, error) {
+	conn, err := pgx.Connect(context.Background(), connUri)
+	if err != nil {
+		return nil, fmt.Errorf("connect to the source database for row hashes: %w", err)
+	}
+	defer conn.Close(context.Background())
+
+	query := GetPGRowHashesQuery(tableName.Qualified.MinQuoted, columns, keyColumns, keyRange.PGPredicate(keyColumns))
+	log.Infof("Querying row hashes of table %q: %s", tableName, query)
+	rows, err := conn.Query(context.Background(), query)
+	if err != nil {
+		return nil, fmt.Errorf("query %q for row hashes of %q: %w", query, tableName, err)
+	}
+	defer rows.Close()
+	return ScanRowHashes(rows, len(keyColumns))
+}
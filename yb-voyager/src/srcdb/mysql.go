@@ -22,10 +22,12 @@ import (
 	"database/sql"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/go-sql-driver/mysql"
 	"github.com/google/uuid"
 	log "github.com/sirupsen/logrus"
+	"golang.org/x/exp/slices"
 
 	"github.com/yugabyte/yb-voyager/yb-voyager/src/datafile"
 	"github.com/yugabyte/yb-voyager/yb-voyager/src/utils"
@@ -95,6 +97,21 @@ func (ms *MySQL) GetTableApproxRowCount(tableName *sqlname.SourceName) int64 {
 	return approxRowCount.Int64
 }
 
+func (ms *MySQL) GetTableSize(tableName *sqlname.SourceName) (int64, error) {
+	var size sql.NullInt64
+	query := fmt.Sprintf("SELECT data_length + index_length FROM information_schema.tables "+
+		"WHERE table_name = '%s' AND table_schema = '%s'",
+		tableName.ObjectName.Unquoted, tableName.SchemaName.Unquoted)
+	err := ms.db.QueryRow(query).Scan(&size)
+	if err != nil {
+		return 0, fmt.Errorf("query %q for size of %q: %w", query, tableName.String(), err)
+	}
+	if !size.Valid {
+		return 0, fmt.Errorf("size of %q not found", tableName.String())
+	}
+	return size.Int64, nil
+}
+
 func (ms *MySQL) GetVersion() string {
 	var version string
 	query := "SELECT VERSION()"
@@ -279,8 +296,59 @@ func (ms *MySQL) ParentTableOfPartition(table *sqlname.SourceName) string {
 	panic("not implemented")
 }
 
+/*
+ValidateTablesReadyForLiveMigration checks that the changes to the tables are captured by debezium from the binlog:
+the binlog is to be enabled with the full images of the rows, and the tables are to have a primary key for the
+changes to be applied on the target.
+*/
 func (ms *MySQL) ValidateTablesReadyForLiveMigration(tableList []*sqlname.SourceName) error {
-	panic("not implemented")
+	var logBin, binlogFormat, binlogRowImage string
+	query := "SELECT @@log_bin, @@binlog_format, @@binlog_row_image"
+	err := ms.db.QueryRow(query).Scan(&logBin, &binlogFormat, &binlogRowImage)
+	if err != nil {
+		return fmt.Errorf("error in querying(%q) source database for binlog settings: %v", query, err)
+	}
+	var settingErrs []string
+	if logBin != "1" && !strings.EqualFold(logBin, "ON") {
+		settingErrs = append(settingErrs, "binary logging is not enabled (log_bin)")
+	}
+	if !strings.EqualFold(binlogFormat, "ROW") {
+		settingErrs = append(settingErrs, fmt.Sprintf("binlog_format is %s instead of ROW", binlogFormat))
+	}
+	if !strings.EqualFold(binlogRowImage, "FULL") {
+		settingErrs = append(settingErrs, fmt.Sprintf("binlog_row_image is %s instead of FULL", binlogRowImage))
+	}
+	if len(settingErrs) > 0 {
+		return fmt.Errorf("%s\nPlease set binlog_format=ROW and binlog_row_image=FULL on the source database with binary logging enabled",
+			strings.Join(settingErrs, ", "))
+	}
+
+	nonPKTables, err := ms.GetNonPKTables()
+	if err != nil {
+		return fmt.Errorf("get tables without primary key: %w", err)
+	}
+	var tablesWithoutPK []string
+	for _, table := range tableList {
+		if slices.Contains(nonPKTables, table.Qualified.MinQuoted) {
+			tablesWithoutPK = append(tablesWithoutPK, table.Qualified.MinQuoted)
+		}
+	}
+	if len(tablesWithoutPK) > 0 {
+		return fmt.Errorf("tables %v do not have a PRIMARY KEY\nPlease add a primary key to the tables or exclude them from the migration", tablesWithoutPK)
+	}
+	return nil
+}
+
+func (ms *MySQL) GetTableChecksum(tableName *sqlname.SourceName, columns []string, keyColumns []string, keyRange *KeyRange) (int64, string, error) {
+	return 0, "", ErrChecksumNotSupported
+}
+
+func (ms *MySQL) GetTableKeyBoundaries(tableName *sqlname.SourceName, keyColumns []string, keyRange *KeyRange, step int64) ([][]string, error) {
+	return nil, ErrChecksumNotSupported
+}
+
+func (ms *MySQL) GetTableRowHashes(tableName *sqlname.SourceName, columns []string, keyColumns []string, keyRange *KeyRange) ([]*RowHash, error) {
+	return nil, ErrChecksumNotSupported
 }
 
 /*
@@ -311,10 +379,53 @@ func (ms *MySQL) GetColumnToSequenceMap(tableList []*sqlname.SourceName) map[str
 			sequenceName := fmt.Sprintf("%s_%s_seq", table.ObjectName.Unquoted, columnName)
 			columnToSequenceMap[qualifiedColumeName] = sequenceName
 		}
+		rows.Close()
 	}
 	return columnToSequenceMap
 }
 
+/*
+GetAutoIncrementLastValues returns the last values generated for the auto increment columns of the tables, keyed
+by the names of the sequences of GetColumnToSequenceMap(). The values are the ones given to the sequences if the
+rows with the highest values are deleted before the cutover.
+*/
+func (ms *MySQL) GetAutoIncrementLastValues(tableList []*sqlname.SourceName) (map[string]int64, error) {
+	// The auto increment values in information_schema are cached for a day by default since MySQL 8.0.
+	_, err := ms.db.Exec("SET SESSION information_schema_stats_expiry = 0")
+	if err != nil {
+		log.Infof("set information_schema_stats_expiry: %v", err)
+	}
+	tableAutoIncrements := make(map[string]int64)
+	for _, table := range tableList {
+		var autoIncrement sql.NullInt64
+		query := fmt.Sprintf("SELECT AUTO_INCREMENT FROM information_schema.tables WHERE table_schema = '%s' AND table_name = '%s'",
+			table.SchemaName.Unquoted, table.ObjectName.Unquoted)
+		err := ms.db.QueryRow(query).Scan(&autoIncrement)
+		if err != nil {
+			return nil, fmt.Errorf("query %q for auto increment value of %q: %w", query, table.String(), err)
+		}
+		if autoIncrement.Valid {
+			tableAutoIncrements[fmt.Sprintf("%s.%s", table.SchemaName.Unquoted, table.ObjectName.Unquoted)] = autoIncrement.Int64
+		}
+	}
+	return getAutoIncrementSequenceLastValues(ms.GetColumnToSequenceMap(tableList), tableAutoIncrements), nil
+}
+
+// getAutoIncrementSequenceLastValues maps the AUTO_INCREMENT values of the tables, keyed by <schema>.<table>, to
+// the sequences of the auto increment columns of columnToSequenceMap.
+func getAutoIncrementSequenceLastValues(columnToSequenceMap map[string]string, tableAutoIncrements map[string]int64) map[string]int64 {
+	lastValues := make(map[string]int64)
+	for column, sequenceName := range columnToSequenceMap {
+		table := column[:strings.LastIndex(column, ".")]
+		autoIncrement, ok := tableAutoIncrements[table]
+		if ok {
+			// AUTO_INCREMENT is the next value to be generated.
+			lastValues[sequenceName] = autoIncrement - 1
+		}
+	}
+	return lastValues
+}
+
 func createTLSConf(source *Source) tls.Config {
 	rootCertPool := x509.NewCertPool()
 	if source.SSLRootCert != "" {
@@ -0,0 +1,26 @@
+package srcdb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetAutoIncrementSequenceLastValues(t *testing.T) {
+	assert := assert.New(t)
+	columnToSequenceMap := map[string]string{
+		"test.orders.id":      "orders_id_seq",
+		"test.order_items.id": "order_items_id_seq",
+		"test.customers.id":   "customers_id_seq",
+	}
+	tableAutoIncrements := map[string]int64{
+		"test.orders":      101,
+		"test.order_items": 1,
+		"test.products":    5,
+	}
+	// Keyed by the sequences of column_sequence.map, the ones debezium tracks the values of.
+	assert.Equal(map[string]int64{
+		"orders_id_seq":      100,
+		"order_items_id_seq": 0,
+	}, getAutoIncrementSequenceLastValues(columnToSequenceMap, tableAutoIncrements))
+}
@@ -95,6 +95,23 @@ func (ora *Oracle) GetTableApproxRowCount(tableName *sqlname.SourceName) int64 {
 	return approxRowCount.Int64
 }
 
+// GetTableSize estimates the size of the table from its statistics, as the segments of the table are
+// visible only with the DBA views.
+func (ora *Oracle) GetTableSize(tableName *sqlname.SourceName) (int64, error) {
+	var size sql.NullInt64
+	query := fmt.Sprintf("SELECT NUM_ROWS * AVG_ROW_LEN FROM ALL_TABLES "+
+		"WHERE TABLE_NAME = '%s' and OWNER = '%s'",
+		tableName.ObjectName.Unquoted, tableName.SchemaName.Unquoted)
+	err := ora.db.QueryRow(query).Scan(&size)
+	if err != nil {
+		return 0, fmt.Errorf("query %q for size of %q: %w", query, tableName.String(), err)
+	}
+	if !size.Valid {
+		return 0, fmt.Errorf("statistics of %q not gathered", tableName.String())
+	}
+	return size.Int64, nil
+}
+
 func (ora *Oracle) GetVersion() string {
 	var version string
 	query := "SELECT BANNER FROM V$VERSION"
@@ -377,6 +394,18 @@ func (ora *Oracle) ValidateTablesReadyForLiveMigration(tableList []*sqlname.Sour
 	panic("not implemented")
 }
 
+func (ora *Oracle) GetTableChecksum(tableName *sqlname.SourceName, columns []string, keyColumns []string, keyRange *KeyRange) (int64, string, error) {
+	return 0, "", ErrChecksumNotSupported
+}
+
+func (ora *Oracle) GetTableKeyBoundaries(tableName *sqlname.SourceName, keyColumns []string, keyRange *KeyRange, step int64) ([][]string, error) {
+	return nil, ErrChecksumNotSupported
+}
+
+func (ora *Oracle) GetTableRowHashes(tableName *sqlname.SourceName, columns []string, keyColumns []string, keyRange *KeyRange) ([]*RowHash, error) {
+	return nil, ErrChecksumNotSupported
+}
+
 /*
 GetColumnToSequenceMap returns a map of column name to sequence name for all identity columns in the given list of tables.
 Note: There can be only one identity column per table in Oracle
@@ -404,6 +433,10 @@ func (ora *Oracle) GetColumnToSequenceMap(tableList []*sqlname.SourceName) map[s
 	return columnToSequenceMap
 }
 
+func (ora *Oracle) GetAutoIncrementLastValues(tableList []*sqlname.SourceName) (map[string]int64, error) {
+	return nil, nil
+}
+
 func (ora *Oracle) GetAllSequences() []string {
 	return nil
 }
@@ -0,0 +1,559 @@
+/*
+Copyright (c) YugabyteDB, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package srcdb
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/samber/lo"
+	log "github.com/sirupsen/logrus"
+	"github.com/sourcegraph/conc/pool"
+	"golang.org/x/exp/slices"
+
+	"github.com/yugabyte/yb-voyager/yb-voyager/src/datafile"
+	"github.com/yugabyte/yb-voyager/yb-voyager/src/datastore"
+	"github.com/yugabyte/yb-voyager/yb-voyager/src/utils"
+	"github.com/yugabyte/yb-voyager/yb-voyager/src/utils/sqlname"
+)
+
+// Tables bigger than this on disk are split into multiple chunks, each exported by a separate COPY into its own data file.
+const PG_NATIVE_EXPORT_CHUNK_SIZE = 256 * 1024 * 1024
+
+// TID range scans, which make the ctid based chunks efficient, are available from PG 14 onwards.
+const PG_TID_RANGE_SCAN_MIN_VERSION_NUM = 140000
+
+type pgExportChunk struct {
+	tableName *sqlname.SourceName
+	// Name of the table as recorded in the data file descriptor.
+	descriptorTableName string
+	filePath            string
+	columns             []string
+	whereClause         string
+	rowCount            int64
+	fileSize            int64
+}
+
+type pgTableExportProgress struct {
+	started       bool
+	pendingChunks int
+	rowCount      int64
+}
+
+// Counts the rows flowing through a `COPY ... TO STDOUT` in TEXT format. Every row is terminated by a newline
+// and newlines within values are escaped, so the number of newlines is the number of rows.
+type pgCopyRowCounter struct {
+	w         io.Writer
+	onNewRows func(int64)
+}
+
+func (c *pgCopyRowCounter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.onNewRows(int64(bytes.Count(p[:n], []byte{'\n'})))
+	return n, err
+}
+
+func (pg *PostgreSQL) nativeExportDataOffline(ctx context.Context, exportDir string, tableList []*sqlname.SourceName, quitChan chan bool, exportDataStart chan bool, exportSuccessChan chan bool, snapshotName string) {
+	defer utils.WaitGroup.Done()
+
+	if snapshotName == "" {
+		// Live migration passes the snapshot of the replication slot, otherwise export a snapshot
+		// and keep its transaction open till all the chunks are exported.
+		snapshotConn, exportedSnapshotName, err := pg.exportSnapshot(ctx)
+		if err != nil {
+			fmt.Printf("failed to export a snapshot of the source database: %v. For more details check '%s/logs/yb-voyager-export-data.log'.\n", err, exportDir)
+			log.Errorf("failed to export a snapshot of the source database: %v", err)
+			quitChan <- true
+			runtime.Goexit()
+		}
+		defer snapshotConn.Close(context.Background())
+		snapshotName = exportedSnapshotName
+	}
+	utils.PrintAndLog("Data export started.")
+	exportDataStart <- true
+
+	err := pg.exportDataInSnapshot(ctx, exportDir, tableList, snapshotName)
+	if err != nil {
+		fmt.Printf("failed to export data: %v. For more details check '%s/logs/yb-voyager-export-data.log'.\n", err, exportDir)
+		log.Errorf("failed to export data: %v", err)
+		quitChan <- true
+		runtime.Goexit()
+	}
+	exportSuccessChan <- true
+}
+
+func (pg *PostgreSQL) exportSnapshot(ctx context.Context) (*pgx.Conn, string, error) {
+	conn, err := pgx.Connect(ctx, pg.getConnectionUri())
+	if err != nil {
+		return nil, "", fmt.Errorf("connect to source db: %w", err)
+	}
+	var snapshotName string
+	query := "BEGIN ISOLATION LEVEL REPEATABLE READ READ ONLY"
+	_, err = conn.Exec(ctx, query)
+	if err == nil {
+		query = "SELECT pg_export_snapshot()"
+		err = conn.QueryRow(ctx, query).Scan(&snapshotName)
+	}
+	if err != nil {
+		conn.Close(context.Background())
+		return nil, "", fmt.Errorf("run query %q on source: %w", query, err)
+	}
+	log.Infof("exported snapshot %q for data export", snapshotName)
+	return conn, snapshotName, nil
+}
+
+// Returns a connection with an open transaction which sees the data as of the given exported snapshot.
+func (pg *PostgreSQL) connectInSnapshot(ctx context.Context, snapshotName string) (*pgx.Conn, error) {
+	conn, err := pgx.Connect(ctx, pg.getConnectionUri())
+	if err != nil {
+		return nil, fmt.Errorf("connect to source db: %w", err)
+	}
+	queries := []string{
+		"BEGIN ISOLATION LEVEL REPEATABLE READ READ ONLY",
+		fmt.Sprintf("SET TRANSACTION SNAPSHOT '%s'", snapshotName),
+	}
+	for _, query := range queries {
+		_, err = conn.Exec(ctx, query)
+		if err != nil {
+			conn.Close(context.Background())
+			return nil, fmt.Errorf("run query %q on source: %w", query, err)
+		}
+	}
+	return conn, nil
+}
+
+func (pg *PostgreSQL) exportDataInSnapshot(ctx context.Context, exportDir string, tableList []*sqlname.SourceName, snapshotName string) error {
+	numConnections := lo.Max([]int{pg.source.NumConnections, 1})
+	conns := make(chan *pgx.Conn, numConnections)
+	defer func() {
+		close(conns)
+		for conn := range conns {
+			conn.Close(context.Background())
+		}
+	}()
+	for i := 0; i < numConnections; i++ {
+		conn, err := pg.connectInSnapshot(ctx, snapshotName)
+		if err != nil {
+			return err
+		}
+		conns <- conn
+	}
+
+	conn := <-conns
+	chunks, setvalStmts, err := pg.planDataExport(ctx, conn, exportDir, tableList)
+	conns <- conn
+	if err != nil {
+		return err
+	}
+	// Same file and format as the sequence values restored from the pg_dump archive.
+	postdataFilePath := filepath.Join(exportDir, "data", "postdata.sql")
+	err = os.WriteFile(postdataFilePath, []byte(strings.Join(setvalStmts, "")), 0644)
+	if err != nil {
+		return fmt.Errorf("write sequence values to %q: %w", postdataFilePath, err)
+	}
+
+	log.Infof("exporting %d chunks of %d tables using %d connections", len(chunks), len(tableList), numConnections)
+	p := pool.New().WithErrors().WithContext(ctx).WithCancelOnError().WithMaxGoroutines(numConnections)
+	for _, chunk := range chunks {
+		chunk := chunk
+		p.Go(func(ctx context.Context) error {
+			conn := <-conns
+			defer func() { conns <- conn }()
+			return pg.exportChunk(ctx, conn, chunk)
+		})
+	}
+	return p.Wait()
+}
+
+// Splits the tables into chunks to be exported and returns them along with the statements restoring the sequence values.
+func (pg *PostgreSQL) planDataExport(ctx context.Context, conn *pgx.Conn, exportDir string, tableList []*sqlname.SourceName) ([]*pgExportChunk, []string, error) {
+	var serverVersionNum, blockSize int64
+	query := "SELECT current_setting('server_version_num')::int, current_setting('block_size')::int"
+	err := conn.QueryRow(ctx, query).Scan(&serverVersionNum, &blockSize)
+	if err != nil {
+		return nil, nil, fmt.Errorf("run query %q on source: %w", query, err)
+	}
+
+	pg.exportMu.Lock()
+	pg.exportProgress = make(map[string]*pgTableExportProgress)
+	pg.exportedChunks = nil
+	pg.exportMu.Unlock()
+
+	var chunks []*pgExportChunk
+	var setvalStmts []string
+	for _, tableName := range tableList {
+		var relkind string
+		var relSize int64
+		query = fmt.Sprintf("SELECT relkind::text, pg_relation_size(oid) FROM pg_class WHERE oid = '%s'::regclass", tableName.Qualified.MinQuoted)
+		err = conn.QueryRow(ctx, query).Scan(&relkind, &relSize)
+		if err != nil {
+			return nil, nil, fmt.Errorf("run query %q on source: %w", query, err)
+		}
+		switch relkind {
+		case "S":
+			setvalStmt, err := getSequenceSetvalStmt(ctx, conn, tableName)
+			if err != nil {
+				return nil, nil, err
+			}
+			setvalStmts = append(setvalStmts, setvalStmt)
+		case "p":
+			// Partitioned tables hold no data of their own, their partitions are exported separately.
+			log.Infof("skipping data export of partitioned table %q", tableName)
+			pg.exportMu.Lock()
+			pg.exportProgress[tableName.Qualified.MinQuoted] = &pgTableExportProgress{started: true}
+			pg.exportMu.Unlock()
+		default:
+			tableChunks, err := pg.planTableChunks(ctx, conn, exportDir, tableName, relSize, serverVersionNum, blockSize)
+			if err != nil {
+				return nil, nil, err
+			}
+			log.Infof("exporting table %q of size %d bytes in %d chunks", tableName, relSize, len(tableChunks))
+			pg.exportMu.Lock()
+			pg.exportProgress[tableName.Qualified.MinQuoted] = &pgTableExportProgress{pendingChunks: len(tableChunks)}
+			pg.exportMu.Unlock()
+			chunks = append(chunks, tableChunks...)
+		}
+	}
+	return chunks, setvalStmts, nil
+}
+
+func getSequenceSetvalStmt(ctx context.Context, conn *pgx.Conn, sequenceName *sqlname.SourceName) (string, error) {
+	var lastValue int64
+	var isCalled bool
+	query := fmt.Sprintf("SELECT last_value, is_called FROM %s", sequenceName.Qualified.MinQuoted)
+	err := conn.QueryRow(ctx, query).Scan(&lastValue, &isCalled)
+	if err != nil {
+		return "", fmt.Errorf("run query %q on source: %w", query, err)
+	}
+	quotedSequenceName := strings.ReplaceAll(sequenceName.Qualified.MinQuoted, "'", "''")
+	return fmt.Sprintf("SELECT pg_catalog.setval('%s', %d, %t);\n", quotedSequenceName, lastValue, isCalled), nil
+}
+
+func (pg *PostgreSQL) planTableChunks(ctx context.Context, conn *pgx.Conn, exportDir string, tableName *sqlname.SourceName,
+	relSize int64, serverVersionNum int64, blockSize int64) ([]*pgExportChunk, error) {
+
+	columns, err := getExportColumns(ctx, conn, tableName, serverVersionNum)
+	if err != nil {
+		return nil, err
+	}
+	var whereClauses []string
+	numChunks := (relSize + PG_NATIVE_EXPORT_CHUNK_SIZE - 1) / PG_NATIVE_EXPORT_CHUNK_SIZE
+	if numChunks > 1 {
+		whereClauses, err = getChunkWhereClauses(ctx, conn, tableName, int(numChunks), relSize/blockSize, serverVersionNum)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(whereClauses) == 0 {
+		whereClauses = []string{""}
+	}
+
+	descriptorTableName := tableName.Qualified.MinQuoted
+	if tableName.SchemaName.Unquoted == "public" {
+		descriptorTableName = tableName.ObjectName.MinQuoted
+	}
+	var chunks []*pgExportChunk
+	for i, whereClause := range whereClauses {
+		fileName := descriptorTableName + "_data.sql"
+		if len(whereClauses) > 1 {
+			fileName = fmt.Sprintf("%s.%d_data.sql", descriptorTableName, i+1)
+		}
+		filePath := filepath.Join(exportDir, "data", fileName)
+		if pg.source.ExportDataCompression != "" {
+			filePath += datastore.GetCompressedFileExtension(pg.source.ExportDataCompression)
+		}
+		if pg.source.ExportDataDir != "" {
+			filePath = datastore.JoinPath(pg.source.ExportDataDir, filepath.Base(filePath))
+		}
+		chunks = append(chunks, &pgExportChunk{
+			tableName:           tableName,
+			descriptorTableName: descriptorTableName,
+			filePath:            filePath,
+			columns:             columns,
+			whereClause:         whereClause,
+		})
+	}
+	return chunks, nil
+}
+
+// Generated columns are skipped as they can't be inserted into.
+func getExportColumns(ctx context.Context, conn *pgx.Conn, tableName *sqlname.SourceName, serverVersionNum int64) ([]string, error) {
+	query := fmt.Sprintf("SELECT attname FROM pg_attribute WHERE attrelid = '%s'::regclass AND attnum > 0 AND NOT attisdropped", tableName.Qualified.MinQuoted)
+	if serverVersionNum >= 120000 {
+		query += " AND attgenerated = ''"
+	}
+	query += " ORDER BY attnum"
+	rows, err := conn.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("run query %q on source: %w", query, err)
+	}
+	defer rows.Close()
+	var columns []string
+	for rows.Next() {
+		var column string
+		err = rows.Scan(&column)
+		if err != nil {
+			return nil, fmt.Errorf("scan columns of %q: %w", tableName, err)
+		}
+		columns = append(columns, pgMinQuoteIdentifier(column))
+	}
+	return columns, rows.Err()
+}
+
+// Splits the table on its primary key if it is a single integer column, otherwise on the physical location of the rows.
+// Returns nil if the table can't be split.
+func getChunkWhereClauses(ctx context.Context, conn *pgx.Conn, tableName *sqlname.SourceName, numChunks int, numPages int64, serverVersionNum int64) ([]string, error) {
+	query := fmt.Sprintf(`SELECT a.attname, a.atttypid::regtype::text FROM pg_index i
+		JOIN pg_attribute a ON a.attrelid = i.indrelid AND a.attnum = ANY(i.indkey)
+		WHERE i.indrelid = '%s'::regclass AND i.indisprimary`, tableName.Qualified.MinQuoted)
+	rows, err := conn.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("run query %q on source: %w", query, err)
+	}
+	var pkColumns, pkTypes []string
+	for rows.Next() {
+		var column, dataType string
+		err = rows.Scan(&column, &dataType)
+		if err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan primary key of %q: %w", tableName, err)
+		}
+		pkColumns = append(pkColumns, pgMinQuoteIdentifier(column))
+		pkTypes = append(pkTypes, dataType)
+	}
+	rows.Close()
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("scan primary key of %q: %w", tableName, rows.Err())
+	}
+
+	if len(pkColumns) == 1 && slices.Contains([]string{"smallint", "integer", "bigint"}, pkTypes[0]) {
+		var minValue, maxValue sql.NullInt64
+		query = fmt.Sprintf("SELECT min(%s), max(%s) FROM ONLY %s", pkColumns[0], pkColumns[0], tableName.Qualified.MinQuoted)
+		err = conn.QueryRow(ctx, query).Scan(&minValue, &maxValue)
+		if err != nil {
+			return nil, fmt.Errorf("run query %q on source: %w", query, err)
+		}
+		if !minValue.Valid {
+			return nil, nil
+		}
+		return pgIntRangeWhereClauses(pkColumns[0], minValue.Int64, maxValue.Int64, numChunks), nil
+	}
+	if serverVersionNum >= PG_TID_RANGE_SCAN_MIN_VERSION_NUM {
+		return pgCtidRangeWhereClauses(numPages, numChunks), nil
+	}
+	log.Infof("table %q has no single column integer primary key and the source doesn't support TID range scans, exporting it in a single chunk", tableName)
+	return nil, nil
+}
+
+func pgIntRangeWhereClauses(column string, minValue, maxValue int64, numChunks int) []string {
+	// Unsigned arithmetic doesn't overflow for ranges spanning the entire int64 domain.
+	span := uint64(maxValue) - uint64(minValue)
+	step := span/uint64(numChunks) + 1
+	var boundaries []string
+	for offset := step; offset <= span && offset >= step; offset += step {
+		boundaries = append(boundaries, fmt.Sprintf("%d", int64(uint64(minValue)+offset)))
+	}
+	return pgRangeWhereClauses(column, boundaries)
+}
+
+// Rows added after the pages are counted land beyond the last boundary and are covered by the last chunk.
+func pgCtidRangeWhereClauses(numPages int64, numChunks int) []string {
+	step := (numPages + int64(numChunks) - 1) / int64(numChunks)
+	var boundaries []string
+	for page := step; page > 0 && page < numPages; page += step {
+		boundaries = append(boundaries, fmt.Sprintf("'(%d,0)'", page))
+	}
+	return pgRangeWhereClauses("ctid", boundaries)
+}
+
+// The first and the last ranges are open ended so that the chunks together cover all the rows.
+func pgRangeWhereClauses(column string, boundaries []string) []string {
+	if len(boundaries) == 0 {
+		return nil
+	}
+	whereClauses := []string{fmt.Sprintf("%s < %s", column, boundaries[0])}
+	for i := 1; i < len(boundaries); i++ {
+		whereClauses = append(whereClauses, fmt.Sprintf("%s >= %s AND %s < %s", column, boundaries[i-1], column, boundaries[i]))
+	}
+	return append(whereClauses, fmt.Sprintf("%s >= %s", column, boundaries[len(boundaries)-1]))
+}
+
+func (pg *PostgreSQL) exportChunk(ctx context.Context, conn *pgx.Conn, chunk *pgExportChunk) error {
+	key := chunk.tableName.Qualified.MinQuoted
+	pg.exportMu.Lock()
+	pg.exportProgress[key].started = true
+	pg.exportMu.Unlock()
+
+	file, err := pg.createDataFile(chunk.filePath)
+	if err != nil {
+		return fmt.Errorf("create data file %q: %w", chunk.filePath, err)
+	}
+	fileSizeCounter := &byteCountingWriter{w: file}
+	var compressor io.WriteCloser
+	var w io.Writer = fileSizeCounter
+	if pg.source.ExportDataCompression != "" {
+		compressor, err = datastore.NewCompressedWriter(fileSizeCounter, pg.source.ExportDataCompression)
+		if err != nil {
+			_ = datastore.AbortWrite(file)
+			return fmt.Errorf("create data file %q: %w", chunk.filePath, err)
+		}
+		w = compressor
+	}
+	writer := bufio.NewWriter(w)
+	rowCounter := &pgCopyRowCounter{
+		w: writer,
+		onNewRows: func(n int64) {
+			pg.exportMu.Lock()
+			pg.exportProgress[key].rowCount += n
+			pg.exportMu.Unlock()
+		},
+	}
+
+	query := fmt.Sprintf("COPY (SELECT %s FROM ONLY %s", strings.Join(chunk.columns, ", "), chunk.tableName.Qualified.MinQuoted)
+	if chunk.whereClause != "" {
+		query += " WHERE " + chunk.whereClause
+	}
+	query += ") TO STDOUT"
+	log.Infof("exporting %q: %s", chunk.filePath, query)
+	commandTag, err := conn.PgConn().CopyTo(ctx, rowCounter, query)
+	if err == nil {
+		err = writer.Flush()
+	}
+	if err == nil && compressor != nil {
+		err = compressor.Close()
+	}
+	if err != nil {
+		// A partially written file is never published to the data dir.
+		_ = datastore.AbortWrite(file)
+		return fmt.Errorf("export data of %q with %q: %w", chunk.tableName, query, err)
+	}
+	err = file.Close()
+	if err != nil {
+		return fmt.Errorf("close data file %q: %w", chunk.filePath, err)
+	}
+	chunk.rowCount = commandTag.RowsAffected()
+	chunk.fileSize = fileSizeCounter.n
+	log.Infof("exported %d rows of %q to %q", chunk.rowCount, chunk.tableName, chunk.filePath)
+
+	pg.exportMu.Lock()
+	pg.exportProgress[key].pendingChunks--
+	pg.exportedChunks = append(pg.exportedChunks, chunk)
+	pg.exportMu.Unlock()
+	return nil
+}
+
+// The data files are written to the object store directly if the data dir is set, without staging them on the local disk.
+// The local files are written as tmp_<file name> and renamed once complete, so that a partially written file is never
+// mistaken for an exported one.
+func (pg *PostgreSQL) createDataFile(filePath string) (io.WriteCloser, error) {
+	if pg.source.ExportDataDir != "" {
+		return datastore.NewDataStore(pg.source.ExportDataDir).Create(filePath)
+	}
+	inProgressFilePath := filepath.Join(filepath.Dir(filePath), "tmp_"+filepath.Base(filePath))
+	file, err := os.Create(inProgressFilePath)
+	if err != nil {
+		return nil, err
+	}
+	return &inProgressDataFile{File: file, filePath: filePath}, nil
+}
+
+// inProgressDataFile is renamed to filePath when closed, and removed when aborted.
+type inProgressDataFile struct {
+	*os.File
+	filePath string
+}
+
+func (f *inProgressDataFile) Close() error {
+	err := f.File.Close()
+	if err != nil {
+		return err
+	}
+	return os.Rename(f.File.Name(), f.filePath)
+}
+
+func (f *inProgressDataFile) Abort() error {
+	_ = f.File.Close()
+	return os.Remove(f.File.Name())
+}
+
+type byteCountingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *byteCountingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// GetExportedRowCount reports the progress of the native data export of the table. The table is done once
+// all of its chunks are exported.
+func (pg *PostgreSQL) GetExportedRowCount(tableName *sqlname.SourceName) (rowCount int64, started bool, done bool) {
+	pg.exportMu.Lock()
+	defer pg.exportMu.Unlock()
+	progress, ok := pg.exportProgress[tableName.Qualified.MinQuoted]
+	if !ok {
+		return 0, false, false
+	}
+	return progress.rowCount, progress.started, progress.started && progress.pendingChunks == 0
+}
+
+func (pg *PostgreSQL) nativeExportDataPostProcessing(exportDir string, tablesProgressMetadata map[string]*utils.TableProgressMetadata) {
+	for key, tableMetadata := range tablesProgressMetadata {
+		if progress, ok := pg.exportProgress[key]; ok {
+			tableMetadata.CountLiveRows = progress.rowCount
+		}
+	}
+	slices.SortFunc(pg.exportedChunks, func(a, b *pgExportChunk) bool {
+		return a.filePath < b.filePath
+	})
+	fileEntries := make([]*datafile.FileEntry, 0)
+	exportedColumns := make(map[string][]string)
+	for _, chunk := range pg.exportedChunks {
+		filePath := filepath.Base(chunk.filePath)
+		if pg.source.ExportDataDir != "" {
+			filePath = chunk.filePath
+		}
+		fileEntries = append(fileEntries, &datafile.FileEntry{
+			FilePath:  filePath,
+			TableName: chunk.descriptorTableName,
+			RowCount:  chunk.rowCount,
+			FileSize:  chunk.fileSize,
+		})
+		exportedColumns[chunk.descriptorTableName] = chunk.columns
+	}
+	dfd := datafile.Descriptor{
+		FileFormat:                 datafile.TEXT,
+		DataFileList:               fileEntries,
+		Delimiter:                  "\t",
+		HasHeader:                  false,
+		ExportDir:                  exportDir,
+		NullString:                 `\N`,
+		TableNameToExportedColumns: exportedColumns,
+		DataDir:                    pg.source.ExportDataDir,
+	}
+	dfd.Save()
+}
@@ -0,0 +1,151 @@
+package srcdb
+
+import (
+	"bytes"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yugabyte/yb-voyager/yb-voyager/src/datafile"
+	"github.com/yugabyte/yb-voyager/yb-voyager/src/datastore"
+	"github.com/yugabyte/yb-voyager/yb-voyager/src/utils"
+	"github.com/yugabyte/yb-voyager/yb-voyager/src/utils/sqlname"
+)
+
+func TestPGIntRangeWhereClauses(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal([]string{
+		"id < 26",
+		"id >= 26 AND id < 51",
+		"id >= 51 AND id < 76",
+		"id >= 76",
+	}, pgIntRangeWhereClauses("id", 1, 100, 4))
+	assert.Nil(pgIntRangeWhereClauses("id", 5, 5, 4))
+	assert.Equal([]string{"id < 6", "id >= 6"}, pgIntRangeWhereClauses("id", 5, 6, 4))
+
+	whereClauses := pgIntRangeWhereClauses(`"Id"`, math.MinInt64, math.MaxInt64, 2)
+	assert.Equal([]string{`"Id" < 0`, `"Id" >= 0`}, whereClauses)
+}
+
+func TestPGCtidRangeWhereClauses(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal([]string{
+		"ctid < '(34,0)'",
+		"ctid >= '(34,0)' AND ctid < '(68,0)'",
+		"ctid >= '(68,0)'",
+	}, pgCtidRangeWhereClauses(100, 3))
+	assert.Nil(pgCtidRangeWhereClauses(0, 3))
+	assert.Nil(pgCtidRangeWhereClauses(1, 3))
+}
+
+func TestPGMinQuoteIdentifier(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal("col_1", pgMinQuoteIdentifier("col_1"))
+	assert.Equal(`"Col"`, pgMinQuoteIdentifier("Col"))
+	assert.Equal(`"user"`, pgMinQuoteIdentifier("user"))
+	assert.Equal(`"a""b"`, pgMinQuoteIdentifier(`a"b`))
+}
+
+func TestPGCopyRowCounter(t *testing.T) {
+	assert := assert.New(t)
+	var buf bytes.Buffer
+	var rowCount int64
+	counter := &pgCopyRowCounter{w: &buf, onNewRows: func(n int64) { rowCount += n }}
+	// The rows are split across the writes, and the newlines in the values are escaped in the text format.
+	for _, p := range []string{"1\ta\n2\t", "b", "\n", "3\tmulti\\nline\n4\t\\N\n"} {
+		n, err := counter.Write([]byte(p))
+		assert.NoError(err)
+		assert.Equal(len(p), n)
+	}
+	assert.Equal(int64(4), rowCount)
+	assert.Equal("1\ta\n2\tb\n3\tmulti\\nline\n4\t\\N\n", buf.String())
+}
+
+func TestPGCreateDataFile(t *testing.T) {
+	assert := assert.New(t)
+	pg := newPostgreSQL(&Source{})
+	filePath := filepath.Join(t.TempDir(), "orders_data.sql")
+	inProgressFilePath := filepath.Join(filepath.Dir(filePath), "tmp_orders_data.sql")
+
+	file, err := pg.createDataFile(filePath)
+	assert.NoError(err)
+	_, err = file.Write([]byte("1\ta\n"))
+	assert.NoError(err)
+	assert.True(utils.FileOrFolderExists(inProgressFilePath))
+	assert.False(utils.FileOrFolderExists(filePath))
+	assert.NoError(datastore.AbortWrite(file))
+	assert.False(utils.FileOrFolderExists(inProgressFilePath))
+	assert.False(utils.FileOrFolderExists(filePath))
+
+	file, err = pg.createDataFile(filePath)
+	assert.NoError(err)
+	_, err = file.Write([]byte("1\ta\n"))
+	assert.NoError(err)
+	assert.NoError(file.Close())
+	assert.False(utils.FileOrFolderExists(inProgressFilePath))
+	data, err := os.ReadFile(filePath)
+	assert.NoError(err)
+	assert.Equal("1\ta\n", string(data))
+}
+
+func TestPGNativeExportDataPostProcessing(t *testing.T) {
+	assert := assert.New(t)
+	sqlname.SourceDBType = sqlname.POSTGRESQL
+	exportDir := t.TempDir()
+	assert.NoError(os.MkdirAll(filepath.Join(exportDir, "metainfo"), 0755))
+	orders := sqlname.NewSourceName("public", "orders")
+	items := sqlname.NewSourceName("sales", "items")
+	chunk := func(tableName *sqlname.SourceName, descriptorTableName string, fileName string, rowCount int64) *pgExportChunk {
+		return &pgExportChunk{
+			tableName:           tableName,
+			descriptorTableName: descriptorTableName,
+			filePath:            filepath.Join(exportDir, "data", fileName),
+			columns:             []string{"id", "name"},
+			rowCount:            rowCount,
+			fileSize:            rowCount * 10,
+		}
+	}
+	pg := newPostgreSQL(&Source{})
+	pg.exportProgress = map[string]*pgTableExportProgress{
+		orders.Qualified.MinQuoted: {started: true, rowCount: 6},
+		items.Qualified.MinQuoted:  {started: true, rowCount: 2},
+	}
+	// The chunks are recorded in the order in which they are exported.
+	pg.exportedChunks = []*pgExportChunk{
+		chunk(orders, "orders", "orders.3_data.sql", 1),
+		chunk(items, "sales.items", "sales.items_data.sql", 2),
+		chunk(orders, "orders", "orders.1_data.sql", 3),
+		chunk(orders, "orders", "orders.2_data.sql", 2),
+	}
+	tablesProgressMetadata := map[string]*utils.TableProgressMetadata{
+		orders.Qualified.MinQuoted: {TableName: orders},
+		items.Qualified.MinQuoted:  {TableName: items},
+	}
+	pg.nativeExportDataPostProcessing(exportDir, tablesProgressMetadata)
+	assert.Equal(int64(6), tablesProgressMetadata[orders.Qualified.MinQuoted].CountLiveRows)
+	assert.Equal(int64(2), tablesProgressMetadata[items.Qualified.MinQuoted].CountLiveRows)
+
+	dfd := datafile.OpenDescriptor(exportDir)
+	assert.Equal(datafile.TEXT, dfd.FileFormat)
+	assert.Equal(`\N`, dfd.NullString)
+	type fileEntry struct {
+		fileName  string
+		tableName string
+		rowCount  int64
+		fileSize  int64
+	}
+	var fileEntries []fileEntry
+	for _, entry := range dfd.DataFileList {
+		fileEntries = append(fileEntries, fileEntry{filepath.Base(entry.FilePath), entry.TableName, entry.RowCount, entry.FileSize})
+	}
+	assert.Equal([]fileEntry{
+		{"orders.1_data.sql", "orders", 3, 30},
+		{"orders.2_data.sql", "orders", 2, 20},
+		{"orders.3_data.sql", "orders", 1, 10},
+		{"sales.items_data.sql", "sales.items", 2, 20},
+	}, fileEntries)
+	assert.Equal(map[string][]string{"orders": {"id", "name"}, "sales.items": {"id", "name"}}, dfd.TableNameToExportedColumns)
+}
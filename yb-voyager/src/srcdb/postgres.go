@@ -24,6 +24,7 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
+	"sync"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pglogrepl"
@@ -62,6 +63,11 @@ type PostgreSQL struct {
 	source *Source
 
 	db *pgx.Conn
+
+	// Progress of the native data export, see pg_native_export_data.go.
+	exportMu       sync.Mutex
+	exportProgress map[string]*pgTableExportProgress
+	exportedChunks []*pgExportChunk
 }
 
 func newPostgreSQL(s *Source) *PostgreSQL {
@@ -109,6 +115,18 @@ func (pg *PostgreSQL) GetTableRowCount(tableName string) int64 {
 	return rowCount
 }
 
+func (pg *PostgreSQL) GetTableChecksum(tableName *sqlname.SourceName, columns []string, keyColumns []string, keyRange *KeyRange) (int64, string, error) {
+	return pgGetTableChecksum(pg.getConnectionUri(), tableName, columns, keyColumns, keyRange)
+}
+
+func (pg *PostgreSQL) GetTableKeyBoundaries(tableName *sqlname.SourceName, keyColumns []string, keyRange *KeyRange, step int64) ([][]string, error) {
+	return pgGetTableKeyBoundaries(pg.getConnectionUri(), tableName, keyColumns, keyRange, step)
+}
+
+func (pg *PostgreSQL) GetTableRowHashes(tableName *sqlname.SourceName, columns []string, keyColumns []string, keyRange *KeyRange) ([]*RowHash, error) {
+	return pgGetTableRowHashes(pg.getConnectionUri(), tableName, columns, keyColumns, keyRange)
+}
+
 func (pg *PostgreSQL) GetTableApproxRowCount(tableName *sqlname.SourceName) int64 {
 	var approxRowCount sql.NullInt64 // handles case: value of the row is null, default for int64 is 0
 	query := fmt.Sprintf("SELECT reltuples::bigint FROM pg_class "+
@@ -124,6 +142,19 @@ func (pg *PostgreSQL) GetTableApproxRowCount(tableName *sqlname.SourceName) int6
 	return approxRowCount.Int64
 }
 
+func (pg *PostgreSQL) GetTableSize(tableName *sqlname.SourceName) (int64, error) {
+	var size sql.NullInt64
+	query := fmt.Sprintf("SELECT pg_total_relation_size('%s'::regclass)", tableName.Qualified.MinQuoted)
+	err := pg.db.QueryRow(context.Background(), query).Scan(&size)
+	if err != nil {
+		return 0, fmt.Errorf("query %q for size of %q: %w", query, tableName.String(), err)
+	}
+	if !size.Valid {
+		return 0, fmt.Errorf("size of %q not found", tableName.String())
+	}
+	return size.Int64, nil
+}
+
 func (pg *PostgreSQL) GetVersion() string {
 	var version string
 	query := "SELECT setting from pg_settings where name = 'server_version'"
@@ -266,10 +297,18 @@ func (pg *PostgreSQL) GetIndexesInfo() []utils.IndexInfo {
 }
 
 func (pg *PostgreSQL) ExportData(ctx context.Context, exportDir string, tableList []*sqlname.SourceName, quitChan chan bool, exportDataStart, exportSuccessChan chan bool, tablesColumnList map[*sqlname.SourceName][]string, snapshotName string) {
+	if pg.source.UseNativeExport {
+		pg.nativeExportDataOffline(ctx, exportDir, tableList, quitChan, exportDataStart, exportSuccessChan, snapshotName)
+		return
+	}
 	pgdumpExportDataOffline(ctx, pg.source, pg.getConnectionUriWithoutPassword(), exportDir, tableList, quitChan, exportDataStart, exportSuccessChan, snapshotName)
 }
 
 func (pg *PostgreSQL) ExportDataPostProcessing(exportDir string, tablesProgressMetadata map[string]*utils.TableProgressMetadata) {
+	if pg.source.UseNativeExport {
+		pg.nativeExportDataPostProcessing(exportDir, tablesProgressMetadata)
+		return
+	}
 	renameDataFiles(tablesProgressMetadata)
 	dfd := datafile.Descriptor{
 		FileFormat:                 datafile.TEXT,
@@ -464,6 +503,10 @@ func (pg *PostgreSQL) GetColumnToSequenceMap(tableList []*sqlname.SourceName) ma
 	return columnToSequenceMap
 }
 
+func (pg *PostgreSQL) GetAutoIncrementLastValues(tableList []*sqlname.SourceName) (map[string]int64, error) {
+	return nil, nil
+}
+
 func generateSSLQueryStringIfNotExists(s *Source) string {
 
 	if s.Uri == "" {
@@ -580,6 +623,47 @@ func (pg *PostgreSQL) GetTableToUniqueKeyColumnsMap(tableList []*sqlname.SourceN
 	return result, nil
 }
 
+const pgQueryTmplForPKCols = `SELECT n.nspname, c.relname, a.attname
+FROM pg_index i
+JOIN pg_class c ON c.oid = i.indrelid
+JOIN pg_namespace n ON n.oid = c.relnamespace
+JOIN LATERAL unnest(i.indkey) WITH ORDINALITY AS k(attnum, ord) ON true
+JOIN pg_attribute a ON a.attrelid = c.oid AND a.attnum = k.attnum
+WHERE i.indisprimary AND (n.nspname || '.' || c.relname) IN (%s)
+ORDER BY n.nspname, c.relname, k.ord;`
+
+// GetTableToPrimaryKeyColumnsMap returns the primary key columns, in key order, of the given tables keyed by `schema.table`.
+func (pg *PostgreSQL) GetTableToPrimaryKeyColumnsMap(tableList []*sqlname.SourceName) (map[string][]string, error) {
+	result := make(map[string][]string)
+	if len(tableList) == 0 {
+		return result, nil
+	}
+	qualifiedTableNames := lo.Map(tableList, func(table *sqlname.SourceName, _ int) string {
+		return fmt.Sprintf("'%s'", table.Qualified.Unquoted)
+	})
+	query := fmt.Sprintf(pgQueryTmplForPKCols, strings.Join(qualifiedTableNames, ","))
+	rows, err := pg.db.Query(context.Background(), query)
+	if err != nil {
+		return nil, fmt.Errorf("querying primary key columns: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var schemaName, tableName, colName string
+		err := rows.Scan(&schemaName, &tableName, &colName)
+		if err != nil {
+			return nil, fmt.Errorf("scanning row for primary key column name: %w", err)
+		}
+		qualifiedTableName := fmt.Sprintf("%s.%s", schemaName, tableName)
+		result[qualifiedTableName] = append(result[qualifiedTableName], colName)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("error iterating over rows for primary key columns: %w", err)
+	}
+	log.Infof("primary key columns for tables: %v", result)
+	return result, nil
+}
+
 func (pg *PostgreSQL) ClearMigrationState(migrationUUID uuid.UUID, exportDir string) error {
 	log.Infof("ClearMigrationState not implemented yet for PostgreSQL")
 	return nil
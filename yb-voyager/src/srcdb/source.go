@@ -29,6 +29,7 @@ type Source struct {
 	User                     string        `json:"user"`
 	Password                 string        `json:"password"`
 	DBName                   string        `json:"db_name"`
+	DBFilePath               string        `json:"db_file_path"`
 	CDBName                  string        `json:"cdb_name"`
 	DBSid                    string        `json:"db_sid"`
 	CDBSid                   string        `json:"cdb_sid"`
@@ -52,12 +53,16 @@ type Source struct {
 	ExcludeTableList         string        `json:"exclude_table_list"`
 	UseOrafce                utils.BoolStr `json:"use_orafce"`
 	CommentsOnObjects        utils.BoolStr `json:"comments_on_objects"`
+	UseNativeExport          utils.BoolStr `json:"use_native_export"`
 	DBVersion                string        `json:"db_version"`
 	StrExportObjectTypeList  string        `json:"str_export_object_type_list"`
 	StrExcludeObjectTypeList string        `json:"str_exclude_object_type_list"`
 
 	ExportObjectTypeList []string `json:"-"`
-	sourceDB             SourceDB `json:"-"`
+	// Object store location and compression of the data files, for the exporters writing them directly.
+	ExportDataDir         string   `json:"-"`
+	ExportDataCompression string   `json:"-"`
+	sourceDB              SourceDB `json:"-"`
 }
 
 func (s *Source) Clone() *Source {
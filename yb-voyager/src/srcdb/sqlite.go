@@ -0,0 +1,905 @@
+/*
+Copyright (c) YugabyteDB, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package srcdb
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/samber/lo"
+	log "github.com/sirupsen/logrus"
+	"github.com/sourcegraph/conc/pool"
+	"golang.org/x/exp/slices"
+
+	"github.com/yugabyte/yb-voyager/yb-voyager/src/datafile"
+	"github.com/yugabyte/yb-voyager/yb-voyager/src/utils"
+	"github.com/yugabyte/yb-voyager/yb-voyager/src/utils/sqlname"
+)
+
+// All the tables of a SQLite database file live in its "main" schema.
+const SQLITE_SCHEMA_NAME = "main"
+
+type SQLite struct {
+	source *Source
+
+	db *sql.DB
+
+	mu sync.Mutex
+	// Number of rows and the list of columns written to the data file of each exported table.
+	exportedRowCounts map[string]int64
+	exportedColumns   map[string][]string
+	// The highest values of the rowid alias columns exported as identity columns, keyed by the target table name.
+	identityLastValues map[string]*sqliteIdentityValue
+}
+
+type sqliteIdentityValue struct {
+	column    string
+	lastValue int64
+}
+
+type sqliteColumn struct {
+	name         string
+	dataType     string
+	notNull      bool
+	defaultValue sql.NullString
+	// Position of the column in the primary key, 0 if the column is not a part of it.
+	pkPosition int
+}
+
+func newSQLite(s *Source) *SQLite {
+	return &SQLite{
+		source:             s,
+		exportedRowCounts:  make(map[string]int64),
+		exportedColumns:    make(map[string][]string),
+		identityLastValues: make(map[string]*sqliteIdentityValue),
+	}
+}
+
+func (s *SQLite) Connect() error {
+	if !utils.FileOrFolderExists(s.source.DBFilePath) {
+		return fmt.Errorf("sqlite database file %q does not exist", s.source.DBFilePath)
+	}
+	db, err := sql.Open("sqlite3", fmt.Sprintf("file:%s?mode=ro", s.source.DBFilePath))
+	if err != nil {
+		return fmt.Errorf("open sqlite database %q: %w", s.source.DBFilePath, err)
+	}
+	s.db = db
+	return s.db.Ping()
+}
+
+func (s *SQLite) Disconnect() {
+	if s.db == nil {
+		log.Infof("No connection to the source database to close")
+		return
+	}
+
+	err := s.db.Close()
+	if err != nil {
+		log.Infof("Failed to close connection to the source database: %s", err)
+	}
+}
+
+func (s *SQLite) CheckRequiredToolsAreInstalled() {
+	// The schema and the data are read using the embedded sqlite library.
+}
+
+func (s *SQLite) GetTableRowCount(tableName string) int64 {
+	var rowCount int64
+	query := fmt.Sprintf("SELECT count(*) FROM %s", tableName)
+
+	log.Infof("Querying row count of table %s", tableName)
+	err := s.db.QueryRow(query).Scan(&rowCount)
+	if err != nil {
+		utils.ErrExit("Failed to query %q for row count of %q: %s", query, tableName, err)
+	}
+	log.Infof("Table %q has %v rows.", tableName, rowCount)
+	return rowCount
+}
+
+func (s *SQLite) GetTableApproxRowCount(tableName *sqlname.SourceName) int64 {
+	// SQLite does not maintain row count estimates, so the actual count is used.
+	return s.GetTableRowCount(tableName.Qualified.Quoted)
+}
+
+func (s *SQLite) GetTableSize(tableName *sqlname.SourceName) (int64, error) {
+	var size sql.NullInt64
+	query := "SELECT sum(pgsize) FROM dbstat WHERE name = ?"
+	err := s.db.QueryRow(query, tableName.ObjectName.Unquoted).Scan(&size)
+	if err != nil {
+		return 0, fmt.Errorf("query %q for size of %q: %w", query, tableName.String(), err)
+	}
+	if !size.Valid {
+		return 0, fmt.Errorf("size of %q not found", tableName.String())
+	}
+	return size.Int64, nil
+}
+
+func (s *SQLite) GetVersion() string {
+	var version string
+	query := "SELECT sqlite_version()"
+	err := s.db.QueryRow(query).Scan(&version)
+	if err != nil {
+		utils.ErrExit("run query %q on source: %s", query, err)
+	}
+	s.source.DBVersion = version
+	return version
+}
+
+func (s *SQLite) GetAllTableNamesRaw(schemaName string) ([]string, error) {
+	var tableNames []string
+	query := fmt.Sprintf("SELECT name FROM %s.sqlite_master "+
+		"WHERE type = 'table' AND name NOT LIKE 'sqlite\\_%%' ESCAPE '\\' ORDER BY name", schemaName)
+	log.Infof(`query used to GetAllTableNamesRaw(): "%s"`, query)
+
+	rows, err := s.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("error in querying source database for table names: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var tableName string
+		err = rows.Scan(&tableName)
+		if err != nil {
+			return nil, fmt.Errorf("error in scanning query rows for table names: %w", err)
+		}
+		tableNames = append(tableNames, tableName)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error in iterating query rows for table names: %w", err)
+	}
+	log.Infof("GetAllTableNamesRaw(): %s", tableNames)
+	return tableNames, nil
+}
+
+func (s *SQLite) GetAllTableNames() []*sqlname.SourceName {
+	var tableNames []*sqlname.SourceName
+	tableNamesRaw, err := s.GetAllTableNamesRaw(SQLITE_SCHEMA_NAME)
+	if err != nil {
+		utils.ErrExit("Failed to get all table names: %s", err)
+	}
+	for _, tableName := range tableNamesRaw {
+		tableNames = append(tableNames, sqlname.NewSourceName(SQLITE_SCHEMA_NAME, tableName))
+	}
+	log.Infof("GetAllTableNames(): %s", tableNames)
+	return tableNames
+}
+
+func (s *SQLite) getTableColumns(tableName *sqlname.SourceName) ([]*sqliteColumn, error) {
+	query := `SELECT name, type, "notnull", dflt_value, pk FROM pragma_table_info(?) ORDER BY cid`
+	rows, err := s.db.Query(query, tableName.ObjectName.Unquoted)
+	if err != nil {
+		return nil, fmt.Errorf("query %q for columns of %q: %w", query, tableName.String(), err)
+	}
+	defer rows.Close()
+	var columns []*sqliteColumn
+	for rows.Next() {
+		column := &sqliteColumn{}
+		err = rows.Scan(&column.name, &column.dataType, &column.notNull, &column.defaultValue, &column.pkPosition)
+		if err != nil {
+			return nil, fmt.Errorf("scan columns of %q: %w", tableName.String(), err)
+		}
+		columns = append(columns, column)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate over columns of %q: %w", tableName.String(), err)
+	}
+	return columns, nil
+}
+
+func (s *SQLite) GetTableColumns(tableName *sqlname.SourceName) ([]string, []string, []string) {
+	columns, err := s.getTableColumns(tableName)
+	if err != nil {
+		utils.ErrExit("failed to find table columns: %v", err)
+	}
+	var columnNames, dataTypes []string
+	for _, column := range columns {
+		columnNames = append(columnNames, column.name)
+		dataTypes = append(dataTypes, column.dataType)
+	}
+	return columnNames, dataTypes, nil
+}
+
+func (s *SQLite) ExportSchema(exportDir string) {
+	if !slices.Contains(s.source.ExportObjectTypeList, "TABLE") {
+		log.Infof("TABLE is not in the list of object types to export, skipping export of schema")
+		return
+	}
+	tablesDir := filepath.Join(exportDir, "schema", "tables")
+	err := os.MkdirAll(tablesDir, 0755)
+	if err != nil {
+		utils.ErrExit("create directory %q: %v", tablesDir, err)
+	}
+
+	var tablesDDL, foreignKeysDDL, indexesDDL strings.Builder
+	for _, tableName := range s.GetAllTableNames() {
+		utils.PrintAndLog("exporting the schema of table %s", SQLiteTargetTableName(tableName))
+		indexes, err := s.getIndexes(tableName)
+		if err != nil {
+			utils.ErrExit("export indexes of table %q: %v", tableName.String(), err)
+		}
+		tableDDL, err := s.getCreateTableStmt(tableName, indexes)
+		if err != nil {
+			utils.ErrExit("export schema of table %q: %v", tableName.String(), err)
+		}
+		tablesDDL.WriteString(tableDDL + "\n\n")
+
+		foreignKeys, err := s.getForeignKeyStmts(tableName)
+		if err != nil {
+			utils.ErrExit("export foreign keys of table %q: %v", tableName.String(), err)
+		}
+		for _, stmt := range foreignKeys {
+			foreignKeysDDL.WriteString(stmt + "\n\n")
+		}
+
+		for _, stmt := range getCreateIndexStmts(tableName, indexes) {
+			indexesDDL.WriteString(stmt + "\n\n")
+		}
+	}
+	tablesDDL.WriteString(foreignKeysDDL.String())
+
+	tableFilePath := filepath.Join(tablesDir, "table.sql")
+	err = os.WriteFile(tableFilePath, []byte(tablesDDL.String()), 0644)
+	if err != nil {
+		utils.ErrExit("write file %q: %v", tableFilePath, err)
+	}
+	if indexesDDL.Len() > 0 {
+		indexFilePath := filepath.Join(tablesDir, "INDEXES_table.sql")
+		err = os.WriteFile(indexFilePath, []byte(indexesDDL.String()), 0644)
+		if err != nil {
+			utils.ErrExit("write file %q: %v", indexFilePath, err)
+		}
+	}
+}
+
+func (s *SQLite) getCreateTableStmt(tableName *sqlname.SourceName, indexes []*sqliteIndex) (string, error) {
+	columns, err := s.getTableColumns(tableName)
+	if err != nil {
+		return "", err
+	}
+	identityColumn := getSQLiteRowidAliasColumn(columns, indexes)
+	var definitions []string
+	var pkColumns []*sqliteColumn
+	for _, column := range columns {
+		pgType := sqliteTypeToPG(column.dataType)
+		definition := fmt.Sprintf("%s %s", sqliteToPGIdentifier(column.name), pgType)
+		if column == identityColumn {
+			// The values of the column are restored along with the data, and the sequence of the column is
+			// set to the highest of them once the data is imported.
+			definition += " GENERATED BY DEFAULT AS IDENTITY"
+		}
+		if column.notNull {
+			definition += " NOT NULL"
+		}
+		if column.defaultValue.Valid {
+			defaultValue, ok := sqliteDefaultToPG(column.defaultValue.String, pgType)
+			if ok {
+				definition += " DEFAULT " + defaultValue
+			} else {
+				utils.PrintAndLog("WARNING: skipping the default value %s of column %s.%s which has no PostgreSQL equivalent",
+					column.defaultValue.String, SQLiteTargetTableName(tableName), column.name)
+			}
+		}
+		definitions = append(definitions, definition)
+		if column.pkPosition > 0 {
+			pkColumns = append(pkColumns, column)
+		}
+	}
+	if len(pkColumns) > 0 {
+		slices.SortFunc(pkColumns, func(a, b *sqliteColumn) bool { return a.pkPosition < b.pkPosition })
+		pkColumnNames := lo.Map(pkColumns, func(column *sqliteColumn, _ int) string {
+			return sqliteToPGIdentifier(column.name)
+		})
+		definitions = append(definitions, fmt.Sprintf("PRIMARY KEY (%s)", strings.Join(pkColumnNames, ", ")))
+	}
+
+	for _, index := range indexes {
+		if index.origin == "u" {
+			// The ordering of the columns of the index is not a part of the constraint.
+			columnNames := lo.Map(index.columns, func(column string, _ int) string { return strings.TrimSuffix(column, " DESC") })
+			definitions = append(definitions, fmt.Sprintf("UNIQUE (%s)", strings.Join(columnNames, ", ")))
+		}
+	}
+	return fmt.Sprintf("CREATE TABLE %s (\n    %s\n);", SQLiteTargetTableName(tableName),
+		strings.Join(definitions, ",\n    ")), nil
+}
+
+func (s *SQLite) getForeignKeyStmts(tableName *sqlname.SourceName) ([]string, error) {
+	query := `SELECT id, "table", "from", "to", on_update, on_delete FROM pragma_foreign_key_list(?) ORDER BY id, seq`
+	rows, err := s.db.Query(query, tableName.ObjectName.Unquoted)
+	if err != nil {
+		return nil, fmt.Errorf("query %q for foreign keys of %q: %w", query, tableName.String(), err)
+	}
+	defer rows.Close()
+
+	type foreignKey struct {
+		refTable           string
+		columns, refColumn []string
+		onUpdate, onDelete string
+	}
+	var ids []int
+	foreignKeys := make(map[int]*foreignKey)
+	for rows.Next() {
+		var id int
+		var refTable, column, onUpdate, onDelete string
+		var refColumn sql.NullString
+		err = rows.Scan(&id, &refTable, &column, &refColumn, &onUpdate, &onDelete)
+		if err != nil {
+			return nil, fmt.Errorf("scan foreign keys of %q: %w", tableName.String(), err)
+		}
+		fk, ok := foreignKeys[id]
+		if !ok {
+			fk = &foreignKey{refTable: refTable, onUpdate: onUpdate, onDelete: onDelete}
+			foreignKeys[id] = fk
+			ids = append(ids, id)
+		}
+		fk.columns = append(fk.columns, sqliteToPGIdentifier(column))
+		// The referenced columns are not recorded when the foreign key refers to the primary key of the parent table.
+		if refColumn.Valid {
+			fk.refColumn = append(fk.refColumn, sqliteToPGIdentifier(refColumn.String))
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate over foreign keys of %q: %w", tableName.String(), err)
+	}
+
+	var stmts []string
+	for _, id := range ids {
+		fk := foreignKeys[id]
+		refTable := sqliteToPGIdentifier(fk.refTable)
+		stmt := fmt.Sprintf("ALTER TABLE ONLY %s ADD FOREIGN KEY (%s) REFERENCES %s",
+			SQLiteTargetTableName(tableName), strings.Join(fk.columns, ", "), refTable)
+		if len(fk.refColumn) > 0 {
+			stmt += fmt.Sprintf("(%s)", strings.Join(fk.refColumn, ", "))
+		}
+		if fk.onUpdate != "NO ACTION" {
+			stmt += " ON UPDATE " + fk.onUpdate
+		}
+		if fk.onDelete != "NO ACTION" {
+			stmt += " ON DELETE " + fk.onDelete
+		}
+		stmts = append(stmts, stmt+";")
+	}
+	return stmts, nil
+}
+
+type sqliteIndex struct {
+	name   string
+	unique bool
+	// "c" for the indexes created by CREATE INDEX, "u" for UNIQUE constraints and "pk" for PRIMARY KEY constraints.
+	origin  string
+	partial bool
+	// Key columns of the index along with their ordering, nil if the index has expressions.
+	columns []string
+}
+
+func (s *SQLite) getIndexes(tableName *sqlname.SourceName) ([]*sqliteIndex, error) {
+	query := `SELECT name, "unique", origin, partial FROM pragma_index_list(?) ORDER BY name`
+	rows, err := s.db.Query(query, tableName.ObjectName.Unquoted)
+	if err != nil {
+		return nil, fmt.Errorf("query %q for indexes of %q: %w", query, tableName.String(), err)
+	}
+	var indexes []*sqliteIndex
+	for rows.Next() {
+		index := &sqliteIndex{}
+		err = rows.Scan(&index.name, &index.unique, &index.origin, &index.partial)
+		if err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan indexes of %q: %w", tableName.String(), err)
+		}
+		indexes = append(indexes, index)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate over indexes of %q: %w", tableName.String(), err)
+	}
+
+	query = `SELECT name, "desc" FROM pragma_index_xinfo(?) WHERE key = 1 ORDER BY seqno`
+	for _, index := range indexes {
+		columns, err := func() ([]string, error) {
+			rows, err := s.db.Query(query, index.name)
+			if err != nil {
+				return nil, fmt.Errorf("query %q for columns of index %q: %w", query, index.name, err)
+			}
+			defer rows.Close()
+			var columns []string
+			for rows.Next() {
+				var column sql.NullString
+				var desc bool
+				err = rows.Scan(&column, &desc)
+				if err != nil {
+					return nil, fmt.Errorf("scan columns of index %q: %w", index.name, err)
+				}
+				if !column.Valid {
+					// The index key is an expression.
+					return nil, nil
+				}
+				column.String = sqliteToPGIdentifier(column.String)
+				if desc {
+					column.String += " DESC"
+				}
+				columns = append(columns, column.String)
+			}
+			return columns, rows.Err()
+		}()
+		if err != nil {
+			return nil, err
+		}
+		index.columns = columns
+	}
+	return indexes, nil
+}
+
+func getCreateIndexStmts(tableName *sqlname.SourceName, indexes []*sqliteIndex) []string {
+	var stmts []string
+	for _, index := range indexes {
+		if index.origin != "c" {
+			continue
+		}
+		if index.partial || index.columns == nil {
+			utils.PrintAndLog("WARNING: skipping the export of index %s of table %s as partial and expression indexes are not supported",
+				index.name, SQLiteTargetTableName(tableName))
+			continue
+		}
+		unique := ""
+		if index.unique {
+			unique = "UNIQUE "
+		}
+		stmts = append(stmts, fmt.Sprintf("CREATE %sINDEX %s ON %s (%s);", unique, sqliteToPGIdentifier(index.name),
+			SQLiteTargetTableName(tableName), strings.Join(index.columns, ", ")))
+	}
+	return stmts
+}
+
+var sqliteTypeRegex = regexp.MustCompile(`^\s*([^(]*?)\s*(?:\((.*)\))?\s*$`)
+
+// Maps the declared type of a SQLite column to a PostgreSQL type. SQLite accepts any type name,
+// the well known ones are mapped by name and the rest by the type affinity rules of SQLite.
+func sqliteTypeToPG(declaredType string) string {
+	matches := sqliteTypeRegex.FindStringSubmatch(strings.ToUpper(declaredType))
+	if matches == nil {
+		return "text"
+	}
+	name, args := strings.Join(strings.Fields(matches[1]), " "), strings.ReplaceAll(matches[2], " ", "")
+	switch name {
+	case "":
+		// Columns without a declared type can hold values of any storage class.
+		return "text"
+	case "INTEGER", "BIGINT", "INT8", "UNSIGNED BIG INT":
+		return "bigint"
+	case "INT", "INT4", "MEDIUMINT":
+		return "integer"
+	case "SMALLINT", "TINYINT", "INT2":
+		return "smallint"
+	case "CHAR", "CHARACTER", "NCHAR", "NATIVE CHARACTER":
+		if args != "" {
+			return fmt.Sprintf("char(%s)", args)
+		}
+		return "text"
+	case "VARCHAR", "NVARCHAR", "VARYING CHARACTER", "CHARACTER VARYING":
+		if args != "" {
+			return fmt.Sprintf("varchar(%s)", args)
+		}
+		return "text"
+	case "TEXT", "CLOB":
+		return "text"
+	case "BLOB":
+		return "bytea"
+	case "REAL", "DOUBLE", "DOUBLE PRECISION", "FLOAT":
+		return "double precision"
+	case "NUMERIC", "DECIMAL":
+		if args != "" {
+			return fmt.Sprintf("numeric(%s)", args)
+		}
+		return "numeric"
+	case "BOOLEAN", "BOOL":
+		return "boolean"
+	case "DATE":
+		return "date"
+	case "DATETIME", "TIMESTAMP":
+		return "timestamp"
+	case "TIME":
+		return "time"
+	case "JSON":
+		return "jsonb"
+	case "UUID":
+		return "uuid"
+	}
+	switch {
+	case strings.Contains(name, "INT"):
+		return "bigint"
+	case strings.Contains(name, "CHAR"), strings.Contains(name, "CLOB"), strings.Contains(name, "TEXT"):
+		return "text"
+	case strings.Contains(name, "BLOB"):
+		return "bytea"
+	case strings.Contains(name, "REAL"), strings.Contains(name, "FLOA"), strings.Contains(name, "DOUB"):
+		return "double precision"
+	default:
+		return "numeric"
+	}
+}
+
+var (
+	sqliteNumericLiteralRegex = regexp.MustCompile(`^[-+]?(\d+(\.\d*)?|\.\d+)([eE][-+]?\d+)?$`)
+	sqliteStringLiteralRegex  = regexp.MustCompile(`^'([^']|'')*'$`)
+)
+
+// Translates the DEFAULT expression of a SQLite column. Returns false if the expression has no PostgreSQL equivalent.
+func sqliteDefaultToPG(defaultValue string, pgType string) (string, bool) {
+	value := strings.TrimSpace(defaultValue)
+	for len(value) > 1 && value[0] == '(' && value[len(value)-1] == ')' {
+		value = strings.TrimSpace(value[1 : len(value)-1])
+	}
+	upper := strings.ToUpper(value)
+	switch strings.ReplaceAll(upper, " ", "") {
+	case "NULL", "CURRENT_TIMESTAMP", "CURRENT_DATE", "CURRENT_TIME":
+		return upper, true
+	case "DATETIME('NOW')":
+		return "CURRENT_TIMESTAMP", true
+	case "DATE('NOW')":
+		return "CURRENT_DATE", true
+	case "TIME('NOW')":
+		return "CURRENT_TIME", true
+	}
+	if pgType == "boolean" {
+		switch upper {
+		case "0", "FALSE", "'0'", "'F'", "'FALSE'":
+			return "false", true
+		case "1", "TRUE", "'1'", "'T'", "'TRUE'":
+			return "true", true
+		}
+	}
+	if sqliteNumericLiteralRegex.MatchString(value) || sqliteStringLiteralRegex.MatchString(value) {
+		return value, true
+	}
+	return "", false
+}
+
+// SQLite identifiers are case insensitive, so they are folded to lowercase like the unquoted identifiers of PostgreSQL.
+func sqliteToPGIdentifier(name string) string {
+	return pgMinQuoteIdentifier(strings.ToLower(name))
+}
+
+// SQLiteTargetTableName returns the name of the table in the exported schema, which also names its data file
+// and its entries in the data file descriptor.
+func SQLiteTargetTableName(tableName *sqlname.SourceName) string {
+	return sqliteToPGIdentifier(tableName.ObjectName.Unquoted)
+}
+
+// getSQLiteRowidAliasColumn returns the INTEGER PRIMARY KEY column of a rowid table, whose values are generated
+// by SQLite like those of an identity column. The other primary keys, and those of the WITHOUT ROWID tables,
+// are backed by an index.
+func getSQLiteRowidAliasColumn(columns []*sqliteColumn, indexes []*sqliteIndex) *sqliteColumn {
+	pkColumns := lo.Filter(columns, func(column *sqliteColumn, _ int) bool { return column.pkPosition > 0 })
+	if len(pkColumns) != 1 || !strings.EqualFold(strings.TrimSpace(pkColumns[0].dataType), "INTEGER") {
+		return nil
+	}
+	if lo.SomeBy(indexes, func(index *sqliteIndex) bool { return index.origin == "pk" }) {
+		return nil
+	}
+	return pkColumns[0]
+}
+
+func quoteSQLiteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+func (s *SQLite) GetIndexesInfo() []utils.IndexInfo {
+	return nil
+}
+
+func (s *SQLite) ExportData(ctx context.Context, exportDir string, tableList []*sqlname.SourceName, quitChan chan bool, exportDataStart, exportSuccessChan chan bool, tablesColumnList map[*sqlname.SourceName][]string, snapshotName string) {
+	defer utils.WaitGroup.Done()
+
+	fmt.Println("Data export started.")
+	exportDataStart <- true
+
+	exportPool := pool.New().WithErrors().WithMaxGoroutines(s.source.NumConnections)
+	for _, tableName := range tableList {
+		tableName := tableName
+		exportPool.Go(func() error {
+			return s.exportTableData(ctx, exportDir, tableName, tablesColumnList[tableName])
+		})
+	}
+	err := exportPool.Wait()
+	if err != nil {
+		utils.ErrExit("Data export failed: %v", err)
+	}
+	exportSuccessChan <- true
+}
+
+// Writes the rows of the table to a CSV file in the data directory. The file is named
+// tmp_<table>_data.csv while it is being written and renamed to <table>_data.csv once complete.
+func (s *SQLite) exportTableData(ctx context.Context, exportDir string, tableName *sqlname.SourceName, columnList []string) error {
+	columns, err := s.getTableColumns(tableName)
+	if err != nil {
+		return err
+	}
+	indexes, err := s.getIndexes(tableName)
+	if err != nil {
+		return err
+	}
+	identityColumn := getSQLiteRowidAliasColumn(columns, indexes)
+	if len(columnList) > 0 && !(len(columnList) == 1 && columnList[0] == "*") {
+		columns = lo.Filter(columns, func(column *sqliteColumn, _ int) bool {
+			return slices.Contains(columnList, column.name)
+		})
+	}
+	// -1 if the table has no identity column, or if it is not exported.
+	identityColumnIdx := slices.Index(columns, identityColumn)
+	pgTypes := lo.Map(columns, func(column *sqliteColumn, _ int) string { return sqliteTypeToPG(column.dataType) })
+	selectList := lo.Map(columns, func(column *sqliteColumn, _ int) string { return quoteSQLiteIdentifier(column.name) })
+	exportedColumns := lo.Map(columns, func(column *sqliteColumn, _ int) string { return sqliteToPGIdentifier(column.name) })
+
+	query := fmt.Sprintf("SELECT %s FROM %s", strings.Join(selectList, ", "), tableName.Qualified.Quoted)
+	log.Infof("exporting data of table %q using query %q", tableName.String(), query)
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("query %q: %w", query, err)
+	}
+	defer rows.Close()
+
+	targetTableName := SQLiteTargetTableName(tableName)
+	inProgressFilePath := filepath.Join(exportDir, "data", "tmp_"+targetTableName+"_data.csv")
+	finalFilePath := filepath.Join(exportDir, "data", targetTableName+"_data.csv")
+	file, err := os.Create(inProgressFilePath)
+	if err != nil {
+		return fmt.Errorf("create %q: %w", inProgressFilePath, err)
+	}
+	defer file.Close()
+	writer := bufio.NewWriter(file)
+
+	_, err = writer.WriteString(strings.Join(exportedColumns, ",") + "\n")
+	if err != nil {
+		return fmt.Errorf("write header to %q: %w", inProgressFilePath, err)
+	}
+	values := make([]any, len(columns))
+	valuePtrs := make([]any, len(columns))
+	for i := range values {
+		valuePtrs[i] = &values[i]
+	}
+	fields := make([]string, len(columns))
+	var rowCount int64
+	var identityValue *sqliteIdentityValue
+	for rows.Next() {
+		err = rows.Scan(valuePtrs...)
+		if err != nil {
+			return fmt.Errorf("scan row of %q: %w", tableName.String(), err)
+		}
+		if identityColumnIdx >= 0 {
+			value, ok := values[identityColumnIdx].(int64)
+			if ok && (identityValue == nil || value > identityValue.lastValue) {
+				identityValue = &sqliteIdentityValue{column: strings.ToLower(identityColumn.name), lastValue: value}
+			}
+		}
+		for i, value := range values {
+			if value == nil {
+				fields[i] = utils.YB_VOYAGER_NULL_STRING
+			} else {
+				fields[i] = `"` + strings.ReplaceAll(formatSQLiteValue(value, pgTypes[i]), `"`, `""`) + `"`
+			}
+		}
+		_, err = writer.WriteString(strings.Join(fields, ",") + "\n")
+		if err != nil {
+			return fmt.Errorf("write row to %q: %w", inProgressFilePath, err)
+		}
+		rowCount++
+	}
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("iterate over rows of %q: %w", tableName.String(), err)
+	}
+	err = writer.Flush()
+	if err != nil {
+		return fmt.Errorf("flush %q: %w", inProgressFilePath, err)
+	}
+	err = file.Close()
+	if err != nil {
+		return fmt.Errorf("close %q: %w", inProgressFilePath, err)
+	}
+	err = os.Rename(inProgressFilePath, finalFilePath)
+	if err != nil {
+		return fmt.Errorf("rename %q to %q: %w", inProgressFilePath, finalFilePath, err)
+	}
+	log.Infof("exported %d rows of table %q to %q", rowCount, tableName.String(), finalFilePath)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.exportedRowCounts[tableName.Qualified.MinQuoted] = rowCount
+	s.exportedColumns[targetTableName] = exportedColumns
+	if identityValue != nil {
+		s.identityLastValues[targetTableName] = identityValue
+	}
+	return nil
+}
+
+// Formats a value read from SQLite in the input format of the PostgreSQL type of its column.
+// The sqlite3 driver returns the values of BOOLEAN and DATE/DATETIME/TIMESTAMP columns as bool and time.Time.
+func formatSQLiteValue(value any, pgType string) string {
+	switch v := value.(type) {
+	case []byte:
+		if pgType == "bytea" {
+			return `\x` + hex.EncodeToString(v)
+		}
+		return string(v)
+	case string:
+		if pgType == "bytea" {
+			return `\x` + hex.EncodeToString([]byte(v))
+		}
+		return v
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case time.Time:
+		if pgType == "date" {
+			return v.Format(time.DateOnly)
+		}
+		return v.Format(time.DateTime + ".999999999")
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func (s *SQLite) ExportDataPostProcessing(exportDir string, tablesProgressMetadata map[string]*utils.TableProgressMetadata) {
+	// The rows are counted while they are written, values spanning multiple lines make the line count of the file inexact.
+	for key, tableMetadata := range tablesProgressMetadata {
+		if rowCount, ok := s.exportedRowCounts[key]; ok {
+			tableMetadata.CountLiveRows = rowCount
+		}
+	}
+	dfd := datafile.Descriptor{
+		FileFormat:                 datafile.CSV,
+		Delimiter:                  ",",
+		HasHeader:                  true,
+		ExportDir:                  exportDir,
+		NullString:                 utils.YB_VOYAGER_NULL_STRING,
+		DataFileList:               getExportedDataFileList(tablesProgressMetadata),
+		TableNameToExportedColumns: s.exportedColumns,
+	}
+	dfd.Save()
+
+	// Same file and format as the sequence values restored from the pg_dump archive.
+	postdataFilePath := filepath.Join(exportDir, "data", "postdata.sql")
+	err := os.WriteFile(postdataFilePath, []byte(s.getIdentitySetvalStmts()), 0644)
+	if err != nil {
+		utils.ErrExit("write the identity column values to %q: %v", postdataFilePath, err)
+	}
+}
+
+// getIdentitySetvalStmts returns the statements setting the sequences of the identity columns to the highest
+// exported values, so that the rows inserted after the migration get the next values.
+func (s *SQLite) getIdentitySetvalStmts() string {
+	tableNames := lo.Keys(s.identityLastValues)
+	slices.Sort(tableNames)
+	var stmts strings.Builder
+	for _, tableName := range tableNames {
+		identityValue := s.identityLastValues[tableName]
+		// The table name is parsed as an identifier, and the column name is taken as is.
+		stmts.WriteString(fmt.Sprintf("SELECT pg_catalog.setval(pg_catalog.pg_get_serial_sequence('%s', '%s'), %d, true);\n",
+			strings.ReplaceAll(tableName, "'", "''"), strings.ReplaceAll(identityValue.column, "'", "''"), identityValue.lastValue))
+	}
+	return stmts.String()
+}
+
+func (s *SQLite) GetCharset() (string, error) {
+	var encoding string
+	query := "PRAGMA encoding"
+	err := s.db.QueryRow(query).Scan(&encoding)
+	if err != nil {
+		return "", fmt.Errorf("run query %q on source: %w", query, err)
+	}
+	return encoding, nil
+}
+
+func (s *SQLite) FilterUnsupportedTables(tableList []*sqlname.SourceName, useDebezium bool) ([]*sqlname.SourceName, []*sqlname.SourceName) {
+	return tableList, nil
+}
+
+func (s *SQLite) FilterEmptyTables(tableList []*sqlname.SourceName) ([]*sqlname.SourceName, []*sqlname.SourceName) {
+	var nonEmptyTableList, emptyTableList []*sqlname.SourceName
+	for _, tableName := range tableList {
+		query := fmt.Sprintf("SELECT 1 FROM %s LIMIT 1", tableName.Qualified.Quoted)
+		if IsTableEmpty(s.db, query) {
+			emptyTableList = append(emptyTableList, tableName)
+		} else {
+			nonEmptyTableList = append(nonEmptyTableList, tableName)
+		}
+	}
+	return nonEmptyTableList, emptyTableList
+}
+
+func (s *SQLite) GetColumnsWithSupportedTypes(tableList []*sqlname.SourceName, useDebezium bool, _ bool) (map[*sqlname.SourceName][]string, []string) {
+	tableColumnMap := make(map[*sqlname.SourceName][]string)
+	for _, tableName := range tableList {
+		tableColumnMap[tableName] = []string{"*"}
+	}
+	return tableColumnMap, nil
+}
+
+func (s *SQLite) ParentTableOfPartition(table *sqlname.SourceName) string {
+	return ""
+}
+
+func (s *SQLite) GetColumnToSequenceMap(tableList []*sqlname.SourceName) map[string]string {
+	return nil
+}
+
+func (s *SQLite) GetAutoIncrementLastValues(tableList []*sqlname.SourceName) (map[string]int64, error) {
+	return nil, nil
+}
+
+func (s *SQLite) GetAllSequences() []string {
+	return nil
+}
+
+func (s *SQLite) GetServers() []string {
+	return []string{s.source.DBFilePath}
+}
+
+func (s *SQLite) GetPartitions(tableName *sqlname.SourceName) []*sqlname.SourceName {
+	return nil
+}
+
+func (s *SQLite) GetTableToUniqueKeyColumnsMap(tableList []*sqlname.SourceName) (map[string][]string, error) {
+	return nil, nil
+}
+
+func (s *SQLite) ClearMigrationState(migrationUUID uuid.UUID, exportDir string) error {
+	return nil
+}
+
+func (s *SQLite) GetNonPKTables() ([]string, error) {
+	var nonPKTables []string
+	for _, tableName := range s.GetAllTableNames() {
+		columns, err := s.getTableColumns(tableName)
+		if err != nil {
+			return nil, err
+		}
+		if !lo.SomeBy(columns, func(column *sqliteColumn) bool { return column.pkPosition > 0 }) {
+			nonPKTables = append(nonPKTables, tableName.Qualified.MinQuoted)
+		}
+	}
+	return nonPKTables, nil
+}
+
+func (s *SQLite) ValidateTablesReadyForLiveMigration(tableList []*sqlname.SourceName) error {
+	return fmt.Errorf("live migration is not supported for sqlite")
+}
+
+func (s *SQLite) GetTableChecksum(tableName *sqlname.SourceName, columns []string, keyColumns []string, keyRange *KeyRange) (int64, string, error) {
+	return 0, "", ErrChecksumNotSupported
+}
+
+func (s *SQLite) GetTableKeyBoundaries(tableName *sqlname.SourceName, keyColumns []string, keyRange *KeyRange, step int64) ([][]string, error) {
+	return nil, ErrChecksumNotSupported
+}
+
+func (s *SQLite) GetTableRowHashes(tableName *sqlname.SourceName, columns []string, keyColumns []string, keyRange *KeyRange) ([]*RowHash, error) {
+	return nil, ErrChecksumNotSupported
+}
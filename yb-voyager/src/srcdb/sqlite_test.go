@@ -0,0 +1,190 @@
+package srcdb
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yugabyte/yb-voyager/yb-voyager/src/datafile"
+	"github.com/yugabyte/yb-voyager/yb-voyager/src/utils"
+	"github.com/yugabyte/yb-voyager/yb-voyager/src/utils/sqlname"
+)
+
+func createTestSQLiteDB(t *testing.T) string {
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	stmts := []string{
+		`CREATE TABLE Customers (id INTEGER PRIMARY KEY, name VARCHAR(100) NOT NULL, email TEXT UNIQUE, active BOOLEAN DEFAULT 1, created DATETIME DEFAULT CURRENT_TIMESTAMP)`,
+		`CREATE TABLE orders (id INTEGER PRIMARY KEY, customer_id INTEGER REFERENCES Customers(id) ON DELETE CASCADE, amount DECIMAL(10, 2), notes, photo BLOB, UNIQUE (customer_id, amount DESC))`,
+		`CREATE INDEX orders_customer_idx ON orders (customer_id, amount DESC)`,
+		`CREATE INDEX orders_notes_idx ON orders (lower(notes))`,
+		// Not a rowid alias, the INT primary key is backed by an index.
+		`CREATE TABLE "Order Items" (order_id INT PRIMARY KEY, qty INTEGER)`,
+		`INSERT INTO Customers (id, name, email, active, created) VALUES (1, 'Alice', 'alice@example.com', 1, '2023-01-02 03:04:05')`,
+		`INSERT INTO Customers (id, name, email, active, created) VALUES (2, 'Bob "B"', NULL, 0, '2023-02-03 04:05:06')`,
+		`INSERT INTO orders VALUES (10, 1, 12.5, 'multi` + "\n" + `line', x'0102ff')`,
+		`INSERT INTO "Order Items" VALUES (10, 3)`,
+	}
+	for _, stmt := range stmts {
+		_, err = db.Exec(stmt)
+		if err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+	return dbPath
+}
+
+func TestSQLiteTypeToPG(t *testing.T) {
+	assert := assert.New(t)
+	tests := map[string]string{
+		"":                 "text",
+		"INTEGER":          "bigint",
+		"int":              "integer",
+		"tinyint":          "smallint",
+		"VARCHAR(255)":     "varchar(255)",
+		"nvarchar":         "text",
+		"CHARACTER(20)":    "char(20)",
+		"DECIMAL(10, 2)":   "numeric(10,2)",
+		"double precision": "double precision",
+		"BOOLEAN":          "boolean",
+		"DATETIME":         "timestamp",
+		"BLOB":             "bytea",
+		"UNSIGNED BIG INT": "bigint",
+		"BIGSERIAL":        "numeric",
+		"STRING":           "numeric",
+		"LONGTEXT":         "text",
+		"FLOAT8":           "double precision",
+	}
+	for declaredType, pgType := range tests {
+		assert.Equal(pgType, sqliteTypeToPG(declaredType), declaredType)
+	}
+}
+
+func TestSQLiteDefaultToPG(t *testing.T) {
+	assert := assert.New(t)
+	tests := []struct {
+		defaultValue string
+		pgType       string
+		expected     string
+		ok           bool
+	}{
+		{"0", "boolean", "false", true},
+		{"'abc'", "text", "'abc'", true},
+		{"-1.5", "double precision", "-1.5", true},
+		{"current_timestamp", "timestamp", "CURRENT_TIMESTAMP", true},
+		{"(datetime('now'))", "timestamp", "CURRENT_TIMESTAMP", true},
+		{"(strftime('%s','now'))", "bigint", "", false},
+	}
+	for _, test := range tests {
+		defaultValue, ok := sqliteDefaultToPG(test.defaultValue, test.pgType)
+		assert.Equal(test.ok, ok, test.defaultValue)
+		assert.Equal(test.expected, defaultValue, test.defaultValue)
+	}
+}
+
+func TestSQLiteExportSchemaAndData(t *testing.T) {
+	assert := assert.New(t)
+	sqlname.SourceDBType = sqlname.SQLITE
+	s := newSQLite(&Source{DBType: "sqlite", DBFilePath: createTestSQLiteDB(t), ExportObjectTypeList: []string{"TABLE"}, NumConnections: 2})
+	err := s.Connect()
+	assert.NoError(err)
+	defer s.Disconnect()
+
+	tableList := s.GetAllTableNames()
+	assert.Equal([]string{"main.customers", "main.order items", "main.orders"}, lo.Map(tableList, func(tableName *sqlname.SourceName, _ int) string {
+		return tableName.String()
+	}))
+	columns, dataTypes, _ := s.GetTableColumns(tableList[2])
+	assert.Equal([]string{"id", "customer_id", "amount", "notes", "photo"}, columns)
+	assert.Equal([]string{"INTEGER", "INTEGER", "DECIMAL(10, 2)", "", "BLOB"}, dataTypes)
+
+	exportDir := t.TempDir()
+	s.ExportSchema(exportDir)
+	tableDDL, err := os.ReadFile(filepath.Join(exportDir, "schema", "tables", "table.sql"))
+	assert.NoError(err)
+	assert.Equal(`CREATE TABLE customers (
+    id bigint GENERATED BY DEFAULT AS IDENTITY,
+    name varchar(100) NOT NULL,
+    email text,
+    active boolean DEFAULT true,
+    created timestamp DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    UNIQUE (email)
+);
+
+CREATE TABLE "order items" (
+    order_id integer,
+    qty bigint,
+    PRIMARY KEY (order_id)
+);
+
+CREATE TABLE orders (
+    id bigint GENERATED BY DEFAULT AS IDENTITY,
+    customer_id bigint,
+    amount numeric(10,2),
+    notes text,
+    photo bytea,
+    PRIMARY KEY (id),
+    UNIQUE (customer_id, amount)
+);
+
+ALTER TABLE ONLY orders ADD FOREIGN KEY (customer_id) REFERENCES customers(id) ON DELETE CASCADE;
+
+`, string(tableDDL))
+	indexDDL, err := os.ReadFile(filepath.Join(exportDir, "schema", "tables", "INDEXES_table.sql"))
+	assert.NoError(err)
+	assert.Equal("CREATE INDEX orders_customer_idx ON orders (customer_id, amount DESC);\n\n", string(indexDDL))
+
+	assert.NoError(os.MkdirAll(filepath.Join(exportDir, "data"), 0755))
+	assert.NoError(os.MkdirAll(filepath.Join(exportDir, "metainfo"), 0755))
+	for _, tableName := range tableList {
+		assert.NoError(s.exportTableData(context.Background(), exportDir, tableName, []string{"*"}))
+	}
+	customersData, err := os.ReadFile(filepath.Join(exportDir, "data", "customers_data.csv"))
+	assert.NoError(err)
+	assert.Equal(`id,name,email,active,created
+"1","Alice","alice@example.com","true","2023-01-02 03:04:05"
+"2","Bob ""B""",__YBV_NULL__,"false","2023-02-03 04:05:06"
+`, string(customersData))
+	ordersData, err := os.ReadFile(filepath.Join(exportDir, "data", "orders_data.csv"))
+	assert.NoError(err)
+	assert.Equal("id,customer_id,amount,notes,photo\n\"10\",\"1\",\"12.5\",\"multi\nline\",\"\\x0102ff\"\n", string(ordersData))
+
+	tablesProgressMetadata := make(map[string]*utils.TableProgressMetadata)
+	for _, tableName := range tableList {
+		tablesProgressMetadata[tableName.Qualified.MinQuoted] = &utils.TableProgressMetadata{
+			TableName:     tableName,
+			FinalFilePath: filepath.Join(exportDir, "data", SQLiteTargetTableName(tableName)+"_data.csv"),
+		}
+	}
+	s.ExportDataPostProcessing(exportDir, tablesProgressMetadata)
+	dfd := datafile.OpenDescriptor(exportDir)
+	assert.Equal(datafile.CSV, dfd.FileFormat)
+	assert.True(dfd.HasHeader)
+	assert.Equal(utils.YB_VOYAGER_NULL_STRING, dfd.NullString)
+	assert.Equal([]string{"id", "customer_id", "amount", "notes", "photo"}, dfd.TableNameToExportedColumns["orders"])
+	rowCounts := make(map[string]int64)
+	for _, fileEntry := range dfd.DataFileList {
+		rowCounts[fileEntry.TableName] = fileEntry.RowCount
+	}
+	assert.Equal(map[string]int64{"customers": 2, `"order items"`: 1, "orders": 1}, rowCounts)
+	assert.ElementsMatch([]string{"customers_data.csv", `"order items"_data.csv`, "orders_data.csv"}, lo.Map(dfd.DataFileList, func(fileEntry *datafile.FileEntry, _ int) string {
+		return filepath.Base(fileEntry.FilePath)
+	}))
+
+	// The identity columns continue from the highest exported values.
+	postdata, err := os.ReadFile(filepath.Join(exportDir, "data", "postdata.sql"))
+	assert.NoError(err)
+	assert.Equal(`SELECT pg_catalog.setval(pg_catalog.pg_get_serial_sequence('customers', 'id'), 2, true);
+SELECT pg_catalog.setval(pg_catalog.pg_get_serial_sequence('orders', 'id'), 10, true);
+`, string(postdata))
+}
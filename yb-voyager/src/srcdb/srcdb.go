@@ -31,6 +31,7 @@ type SourceDB interface {
 	Disconnect()
 	GetTableRowCount(tableName string) int64
 	GetTableApproxRowCount(tableName *sqlname.SourceName) int64
+	GetTableSize(tableName *sqlname.SourceName) (int64, error)
 	CheckRequiredToolsAreInstalled()
 	GetVersion() string
 	GetAllTableNames() []*sqlname.SourceName
@@ -46,6 +47,7 @@ type SourceDB interface {
 	GetTableColumns(tableName *sqlname.SourceName) ([]string, []string, []string)
 	ParentTableOfPartition(table *sqlname.SourceName) string
 	GetColumnToSequenceMap(tableList []*sqlname.SourceName) map[string]string
+	GetAutoIncrementLastValues(tableList []*sqlname.SourceName) (map[string]int64, error)
 	GetAllSequences() []string
 	GetServers() []string
 	GetPartitions(table *sqlname.SourceName) []*sqlname.SourceName
@@ -53,6 +55,9 @@ type SourceDB interface {
 	ClearMigrationState(migrationUUID uuid.UUID, exportDir string) error
 	GetNonPKTables() ([]string, error)
 	ValidateTablesReadyForLiveMigration(tableList []*sqlname.SourceName) error
+	GetTableChecksum(tableName *sqlname.SourceName, columns []string, keyColumns []string, keyRange *KeyRange) (int64, string, error)
+	GetTableKeyBoundaries(tableName *sqlname.SourceName, keyColumns []string, keyRange *KeyRange, step int64) ([][]string, error)
+	GetTableRowHashes(tableName *sqlname.SourceName, columns []string, keyColumns []string, keyRange *KeyRange) ([]*RowHash, error)
 }
 
 func newSourceDB(source *Source) SourceDB {
@@ -65,6 +70,8 @@ func newSourceDB(source *Source) SourceDB {
 		return newMySQL(source)
 	case "oracle":
 		return newOracle(source)
+	case "sqlite":
+		return newSQLite(source)
 	default:
 		panic(fmt.Sprintf("unknown source database type %q", source.DBType))
 	}
@@ -19,11 +19,13 @@ import (
 	"os"
 	"os/exec"
 	"path"
+	"regexp"
 	"strings"
 
 	log "github.com/sirupsen/logrus"
 
 	"github.com/yugabyte/yb-voyager/yb-voyager/src/utils"
+	"github.com/yugabyte/yb-voyager/yb-voyager/src/utils/sqlname"
 )
 
 func checkTools(tools ...string) {
@@ -51,3 +53,13 @@ func findAllExecutablesInPath(executableName string) ([]string, error) {
 	}
 	return result, nil
 }
+
+var pgUnquotedIdentifierRegex = regexp.MustCompile(`^[a-z_][a-z0-9_$]*$`)
+
+// Quotes the PostgreSQL identifier only if it would otherwise be case folded or parsed as a keyword.
+func pgMinQuoteIdentifier(name string) string {
+	if pgUnquotedIdentifierRegex.MatchString(name) && !sqlname.IsReservedKeywordPG(name) {
+		return name
+	}
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
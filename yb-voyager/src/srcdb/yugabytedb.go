@@ -100,6 +100,19 @@ func (yb *YugabyteDB) GetTableApproxRowCount(tableName *sqlname.SourceName) int6
 	return approxRowCount.Int64
 }
 
+func (yb *YugabyteDB) GetTableSize(tableName *sqlname.SourceName) (int64, error) {
+	var size sql.NullInt64
+	query := fmt.Sprintf("SELECT pg_total_relation_size('%s'::regclass)", tableName.Qualified.MinQuoted)
+	err := yb.conn.QueryRow(context.Background(), query).Scan(&size)
+	if err != nil {
+		return 0, fmt.Errorf("query %q for size of %q: %w", query, tableName.String(), err)
+	}
+	if !size.Valid {
+		return 0, fmt.Errorf("size of %q not found", tableName.String())
+	}
+	return size.Int64, nil
+}
+
 func (yb *YugabyteDB) GetVersion() string {
 	var version string
 	query := "SELECT setting from pg_settings where name = 'server_version'"
@@ -243,6 +256,18 @@ func (yb *YugabyteDB) ValidateTablesReadyForLiveMigration(tableList []*sqlname.S
 	panic("not implemented")
 }
 
+func (yb *YugabyteDB) GetTableChecksum(tableName *sqlname.SourceName, columns []string, keyColumns []string, keyRange *KeyRange) (int64, string, error) {
+	return pgGetTableChecksum(yb.getConnectionUri(), tableName, columns, keyColumns, keyRange)
+}
+
+func (yb *YugabyteDB) GetTableKeyBoundaries(tableName *sqlname.SourceName, keyColumns []string, keyRange *KeyRange, step int64) ([][]string, error) {
+	return pgGetTableKeyBoundaries(yb.getConnectionUri(), tableName, keyColumns, keyRange, step)
+}
+
+func (yb *YugabyteDB) GetTableRowHashes(tableName *sqlname.SourceName, columns []string, keyColumns []string, keyRange *KeyRange) ([]*RowHash, error) {
+	return pgGetTableRowHashes(yb.getConnectionUri(), tableName, columns, keyColumns, keyRange)
+}
+
 func (yb *YugabyteDB) GetIndexesInfo() []utils.IndexInfo {
 	return nil
 }
@@ -412,6 +437,10 @@ func (yb *YugabyteDB) GetColumnToSequenceMap(tableList []*sqlname.SourceName) ma
 	return columnToSequenceMap
 }
 
+func (yb *YugabyteDB) GetAutoIncrementLastValues(tableList []*sqlname.SourceName) (map[string]int64, error) {
+	return nil, nil
+}
+
 func (yb *YugabyteDB) GetServers() []string {
 	var ybServers []string
 
@@ -216,6 +216,11 @@ func (tdb *TargetOracleDB) ImportBatch(batch Batch, args *ImportBatchArgs, expor
 	return rowsAffected, err
 }
 
+func (tdb *TargetOracleDB) ImportBatchIsolatingErrors(batch Batch, args *ImportBatchArgs, records []string, maxRejectedRecords int64,
+	recordRejectedRecords func([]*RejectedRecord) error) (int64, error) {
+	return 0, fmt.Errorf("isolating the rejected records of a batch is not supported for Oracle")
+}
+
 func (tdb *TargetOracleDB) WithConn(fn func(*sql.Conn) (bool, error)) error {
 	var err error
 	retry := true
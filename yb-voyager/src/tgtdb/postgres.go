@@ -406,6 +406,18 @@ func (pg *TargetPostgreSQL) importBatch(conn *pgx.Conn, batch Batch, args *Impor
 	return res.RowsAffected(), err
 }
 
+func (pg *TargetPostgreSQL) ImportBatchIsolatingErrors(batch Batch, args *ImportBatchArgs, records []string, maxRejectedRecords int64,
+	recordRejectedRecords func([]*RejectedRecord) error) (int64, error) {
+	var rowsAffected int64
+	var err error
+	copyFn := func(conn *pgx.Conn) (bool, error) {
+		rowsAffected, err = importBatchIsolatingErrors(pg, conn, batch, args, records, maxRejectedRecords, recordRejectedRecords)
+		return false, err // Retries are implemented in the caller.
+	}
+	err = pg.connPool.WithConn(copyFn)
+	return rowsAffected, err
+}
+
 func (pg *TargetPostgreSQL) IfRequiredQuoteColumnNames(tableName string, columns []string) ([]string, error) {
 	result := make([]string, len(columns))
 	// FAST PATH.
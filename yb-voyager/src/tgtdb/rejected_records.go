@@ -0,0 +1,195 @@
+/*
+Copyright (c) YugabyteDB, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package tgtdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	log "github.com/sirupsen/logrus"
+)
+
+var ErrTooManyRejectedRecords = errors.New("number of rejected records exceeds the limit")
+
+type RejectedRecord struct {
+	Record string
+	Error  string
+}
+
+// batchImportTracker is implemented by the targets recording the imported batches in the voyager schema.
+type batchImportTracker interface {
+	setTargetSchema(conn *pgx.Conn)
+	isBatchAlreadyImported(tx pgx.Tx, batch Batch) (bool, int64, error)
+	recordEntryInDB(tx pgx.Tx, batch Batch, rowsAffected int64) error
+}
+
+// Imports the records of the batch in a single transaction, skipping the records rejected by the database.
+// The rejected records are passed to recordRejectedRecords before the transaction is committed, so that
+// they are never lost if the import is interrupted after the commit.
+func importBatchIsolatingErrors(tdb batchImportTracker, conn *pgx.Conn, batch Batch, args *ImportBatchArgs, records []string,
+	maxRejectedRecords int64, recordRejectedRecords func([]*RejectedRecord) error) (rowsAffected int64, err error) {
+
+	tdb.setTargetSchema(conn)
+
+	// NOTE: DO NOT DEFINE A NEW err VARIABLE IN THIS FUNCTION. ELSE, IT WILL MASK THE err FROM RETURN LIST.
+	ctx := context.Background()
+	var tx pgx.Tx
+	tx, err = conn.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return 0, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() {
+		var err2 error
+		if err != nil {
+			err2 = tx.Rollback(ctx)
+			if err2 != nil {
+				rowsAffected = 0
+				err = fmt.Errorf("rollback txn: %w (while processing %s)", err2, err)
+			}
+		} else {
+			err2 = tx.Commit(ctx)
+			if err2 != nil {
+				rowsAffected = 0
+				err = fmt.Errorf("commit txn: %w", err2)
+			}
+		}
+	}()
+
+	var alreadyImported bool
+	alreadyImported, rowsAffected, err = tdb.isBatchAlreadyImported(tx, batch)
+	if err != nil {
+		return 0, err
+	}
+	if alreadyImported {
+		return rowsAffected, nil
+	}
+
+	// The records are streamed without the header and in a single transaction.
+	copyArgs := *args
+	copyArgs.HasHeader = false
+	copyCommand := copyArgs.GetPGCopyStatement()
+	log.Infof("Importing %q isolating rejected records using COPY command: [%s]", batch.GetFilePath(), copyCommand)
+	var rejectedRecords []*RejectedRecord
+	rowsAffected, rejectedRecords, err = copyRecordsIsolatingErrors(&txRecordsCopier{tx: tx, copyCommand: copyCommand}, records, maxRejectedRecords)
+	if err != nil {
+		return 0, err
+	}
+	log.Infof("%d records of %q are rejected", len(rejectedRecords), batch.GetFilePath())
+
+	err = tdb.recordEntryInDB(tx, batch, rowsAffected)
+	if err != nil {
+		return 0, fmt.Errorf("record entry in DB for batch %q: %w", batch.GetFilePath(), err)
+	}
+	err = recordRejectedRecords(rejectedRecords)
+	if err != nil {
+		return 0, fmt.Errorf("record rejected records of batch %q: %w", batch.GetFilePath(), err)
+	}
+	return rowsAffected, nil
+}
+
+// recordsCopier copies the records in a transaction, and runs the savepoint statements of that transaction.
+type recordsCopier interface {
+	Exec(stmt string) error
+	CopyFrom(records []string) (int64, error)
+}
+
+type txRecordsCopier struct {
+	tx          pgx.Tx
+	copyCommand string
+}
+
+func (c *txRecordsCopier) Exec(stmt string) error {
+	_, err := c.tx.Exec(context.Background(), stmt)
+	return err
+}
+
+func (c *txRecordsCopier) CopyFrom(records []string) (int64, error) {
+	input := strings.NewReader(strings.Join(records, "\n") + "\n")
+	res, err := c.tx.Conn().PgConn().CopyFrom(context.Background(), input, c.copyCommand)
+	return res.RowsAffected(), err
+}
+
+// Copies the records in the transaction, isolating the records rejected by the database by bisecting.
+// Every chunk of records is copied under a savepoint. If the COPY of a chunk fails due to the data,
+// the savepoint is rolled back and the chunk is split into two halves which are copied again, until the
+// offending records are found. Any other error (e.g. connection failure) is returned as is.
+func copyRecordsIsolatingErrors(copier recordsCopier, records []string, maxRejectedRecords int64) (int64, []*RejectedRecord, error) {
+	var rowsAffected int64
+	var rejectedRecords []*RejectedRecord
+	var copyChunk func(chunk []string) error
+	copyChunk = func(chunk []string) error {
+		err := copier.Exec("SAVEPOINT voyager_copy_chunk")
+		if err != nil {
+			return fmt.Errorf("create savepoint: %w", err)
+		}
+		n, err := copier.CopyFrom(chunk)
+		if err == nil {
+			rowsAffected += n
+			err = copier.Exec("RELEASE SAVEPOINT voyager_copy_chunk")
+			if err != nil {
+				return fmt.Errorf("release savepoint: %w", err)
+			}
+			return nil
+		}
+		if !isDataError(err) {
+			return err
+		}
+		err2 := copier.Exec("ROLLBACK TO SAVEPOINT voyager_copy_chunk")
+		if err2 != nil {
+			return fmt.Errorf("rollback to savepoint: %w (while processing %s)", err2, err)
+		}
+		if len(chunk) == 1 {
+			log.Infof("record rejected: %s", err)
+			rejectedRecords = append(rejectedRecords, &RejectedRecord{Record: chunk[0], Error: err.Error()})
+			if int64(len(rejectedRecords)) > maxRejectedRecords {
+				return ErrTooManyRejectedRecords
+			}
+			return nil
+		}
+		mid := len(chunk) / 2
+		err = copyChunk(chunk[:mid])
+		if err != nil {
+			return err
+		}
+		return copyChunk(chunk[mid:])
+	}
+	err := copyChunk(records)
+	if err != nil {
+		return 0, nil, err
+	}
+	return rowsAffected, rejectedRecords, nil
+}
+
+// Returns true if the error is reported by the database while processing the data of the COPY,
+// as opposed to the errors due to connectivity, resource exhaustion or transaction conflicts.
+func isDataError(err error) bool {
+	var pgerr *pgconn.PgError
+	if !errors.As(err, &pgerr) {
+		return false
+	}
+	switch pgerr.Code[:2] {
+	case "08", // connection exception
+		"40", // transaction rollback
+		"53", // insufficient resources
+		"57": // operator intervention
+		return false
+	}
+	return true
+}
@@ -0,0 +1,140 @@
+package tgtdb
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgconn"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeRecordsCopier fails the COPY of a chunk with the error of the first record of the chunk found in errs.
+type fakeRecordsCopier struct {
+	errs  map[string]error
+	stmts []string
+}
+
+func (c *fakeRecordsCopier) Exec(stmt string) error {
+	c.stmts = append(c.stmts, stmt)
+	return nil
+}
+
+func (c *fakeRecordsCopier) CopyFrom(records []string) (int64, error) {
+	c.stmts = append(c.stmts, "COPY "+strings.Join(records, ","))
+	for _, record := range records {
+		if err, ok := c.errs[record]; ok {
+			return 0, err
+		}
+	}
+	return int64(len(records)), nil
+}
+
+func TestCopyRecordsIsolatingErrors(t *testing.T) {
+	assert := assert.New(t)
+	invalidInput := &pgconn.PgError{Code: "22P02", Message: "invalid input syntax for type integer"}
+	uniqueViolation := &pgconn.PgError{Code: "23505", Message: "duplicate key value violates unique constraint"}
+	connFailure := &pgconn.PgError{Code: "08006", Message: "connection failure"}
+	testCases := []struct {
+		name               string
+		records            []string
+		errs               map[string]error
+		maxRejectedRecords int64
+		rowsAffected       int64
+		rejected           []string
+		err                error
+	}{
+		{
+			name:               "no rejected records",
+			records:            []string{"1", "2", "3"},
+			maxRejectedRecords: 0,
+			rowsAffected:       3,
+		},
+		{
+			name:               "rejected records isolated by bisecting",
+			records:            []string{"1", "2", "x", "4", "5", "6", "1"},
+			errs:               map[string]error{"x": invalidInput, "6": uniqueViolation},
+			maxRejectedRecords: 2,
+			rowsAffected:       5,
+			rejected:           []string{"x", "6"},
+		},
+		{
+			name:               "more rejected records than the limit",
+			records:            []string{"x", "2", "y", "4"},
+			errs:               map[string]error{"x": invalidInput, "y": invalidInput},
+			maxRejectedRecords: 1,
+			err:                ErrTooManyRejectedRecords,
+		},
+		{
+			name:               "connection failure is not a data error",
+			records:            []string{"1", "2", "3", "4"},
+			errs:               map[string]error{"3": connFailure},
+			maxRejectedRecords: 10,
+			err:                connFailure,
+		},
+		{
+			name:               "error not reported by the database",
+			records:            []string{"1", "2"},
+			errs:               map[string]error{"1": errors.New("unexpected EOF")},
+			maxRejectedRecords: 10,
+			err:                errors.New("unexpected EOF"),
+		},
+	}
+	for _, tc := range testCases {
+		copier := &fakeRecordsCopier{errs: tc.errs}
+		rowsAffected, rejectedRecords, err := copyRecordsIsolatingErrors(copier, tc.records, tc.maxRejectedRecords)
+		if tc.err != nil {
+			assert.Equal(tc.err, err, tc.name)
+			continue
+		}
+		assert.NoError(err, tc.name)
+		assert.Equal(tc.rowsAffected, rowsAffected, tc.name)
+		var rejected []string
+		for _, rejectedRecord := range rejectedRecords {
+			rejected = append(rejected, rejectedRecord.Record)
+			assert.Equal(tc.errs[rejectedRecord.Record].Error(), rejectedRecord.Error, tc.name)
+		}
+		assert.Equal(tc.rejected, rejected, tc.name)
+	}
+}
+
+func TestCopyRecordsIsolatingErrorsSavepoints(t *testing.T) {
+	assert := assert.New(t)
+	copier := &fakeRecordsCopier{errs: map[string]error{"x": &pgconn.PgError{Code: "22P02"}}}
+	rowsAffected, rejectedRecords, err := copyRecordsIsolatingErrors(copier, []string{"1", "x", "3"}, 1)
+	assert.NoError(err)
+	assert.Equal(int64(2), rowsAffected)
+	assert.Len(rejectedRecords, 1)
+	assert.Equal([]string{
+		"SAVEPOINT voyager_copy_chunk", "COPY 1,x,3", "ROLLBACK TO SAVEPOINT voyager_copy_chunk",
+		"SAVEPOINT voyager_copy_chunk", "COPY 1", "RELEASE SAVEPOINT voyager_copy_chunk",
+		"SAVEPOINT voyager_copy_chunk", "COPY x,3", "ROLLBACK TO SAVEPOINT voyager_copy_chunk",
+		"SAVEPOINT voyager_copy_chunk", "COPY x", "ROLLBACK TO SAVEPOINT voyager_copy_chunk",
+		"SAVEPOINT voyager_copy_chunk", "COPY 3", "RELEASE SAVEPOINT voyager_copy_chunk",
+	}, copier.stmts)
+}
+
+func TestIsDataError(t *testing.T) {
+	assert := assert.New(t)
+	testCases := []struct {
+		err         error
+		isDataError bool
+	}{
+		{&pgconn.PgError{Code: "22P02"}, true}, // invalid text representation
+		{&pgconn.PgError{Code: "22001"}, true}, // string data right truncation
+		{&pgconn.PgError{Code: "23505"}, true}, // unique violation
+		{&pgconn.PgError{Code: "23503"}, true}, // foreign key violation
+		{&pgconn.PgError{Code: "08006"}, false},
+		{&pgconn.PgError{Code: "40001"}, false},
+		{&pgconn.PgError{Code: "53200"}, false},
+		{&pgconn.PgError{Code: "57014"}, false},
+		{errors.New("conn closed"), false},
+	}
+	for _, tc := range testCases {
+		assert.Equal(tc.isDataError, isDataError(tc.err), "%v", tc.err)
+	}
+	// The error is found when wrapped.
+	wrapped := fmt.Errorf("copy: %w", &pgconn.PgError{Code: "22P02"})
+	assert.True(isDataError(wrapped))
+}
@@ -60,7 +60,9 @@ var YBValueConverterSuite = map[string]ConverterFn{
 			return columnValue, fmt.Errorf("parsing epoch milliseconds: %v", err)
 		}
 		epochSecs := epochMilliSecs / 1000
-		timestamp := time.Unix(epochSecs, 0).UTC().Format(time.DateTime)
+		epochNanos := (epochMilliSecs % 1000) * 1000000
+		// The milliseconds, of DATETIME(3) of MySQL for example, are omitted if zero.
+		timestamp := time.Unix(epochSecs, epochNanos).UTC().Format(time.DateTime + ".999")
 		return quoteValueIfRequired(timestamp, formatIfRequired, dbzmSchema)
 	},
 	"io.debezium.time.MicroTimestamp": func(columnValue string, formatIfRequired bool, dbzmSchema *schemareg.ColumnSchema) (string, error) {
@@ -84,6 +86,17 @@ var YBValueConverterSuite = map[string]ConverterFn{
 		return quoteValueIfRequired(timestamp, formatIfRequired, dbzmSchema)
 	},
 	"io.debezium.time.ZonedTimestamp": quoteValueIfRequired,
+	// YEAR of MySQL, as the number of the year.
+	"io.debezium.time.Year": func(columnValue string, _ bool, _ *schemareg.ColumnSchema) (string, error) {
+		return columnValue, nil
+	},
+	// SET of MySQL, as the comma separated list of the values of the set.
+	"io.debezium.data.EnumSet": func(columnValue string, formatIfRequired bool, dbzmSchema *schemareg.ColumnSchema) (string, error) {
+		if formatIfRequired {
+			columnValue = strings.ReplaceAll(columnValue, "'", "''")
+		}
+		return quoteValueIfRequired(columnValue, formatIfRequired, dbzmSchema)
+	},
 	"io.debezium.time.Time": func(columnValue string, formatIfRequired bool, dbzmSchema *schemareg.ColumnSchema) (string, error) {
 		epochMilliSecs, err := strconv.ParseInt(columnValue, 10, 64)
 		if err != nil {
@@ -0,0 +1,33 @@
+package tgtdbsuite
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestYBValueConverterSuiteMySQLTypes(t *testing.T) {
+	assert := assert.New(t)
+	testCases := []struct {
+		schemaName       string
+		value            string
+		formatIfRequired bool
+		expected         string
+	}{
+		{"io.debezium.time.Timestamp", "1700000000000", false, "2023-11-14 22:13:20"},
+		{"io.debezium.time.Timestamp", "1700000000000", true, "'2023-11-14 22:13:20'"},
+		// DATETIME(3) of MySQL.
+		{"io.debezium.time.Timestamp", "1700000000123", false, "2023-11-14 22:13:20.123"},
+		{"io.debezium.time.Timestamp", "1700000000120", true, "'2023-11-14 22:13:20.12'"},
+		{"io.debezium.time.Year", "2024", false, "2024"},
+		{"io.debezium.time.Year", "2024", true, "2024"},
+		{"io.debezium.data.EnumSet", "a,b", true, "'a,b'"},
+		{"io.debezium.data.EnumSet", "it's,b", false, "it's,b"},
+		{"io.debezium.data.EnumSet", "it's,b", true, "'it''s,b'"},
+	}
+	for _, tc := range testCases {
+		value, err := YBValueConverterSuite[tc.schemaName](tc.value, tc.formatIfRequired, nil)
+		assert.NoError(err)
+		assert.Equal(tc.expected, value, "%s: %s", tc.schemaName, tc.value)
+	}
+}
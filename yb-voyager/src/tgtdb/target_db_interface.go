@@ -37,6 +37,11 @@ type TargetDB interface {
 	GetNonEmptyTables(tableNames []string) []string
 	IsNonRetryableCopyError(err error) bool
 	ImportBatch(batch Batch, args *ImportBatchArgs, exportDir string, tableSchema map[string]map[string]string) (int64, error)
+	// Imports the given records of the batch, skipping the records rejected by the database. The rejected
+	// records are passed to recordRejectedRecords before the batch is committed.
+	// Returns ErrTooManyRejectedRecords if more than maxRejectedRecords records are rejected.
+	ImportBatchIsolatingErrors(batch Batch, args *ImportBatchArgs, records []string, maxRejectedRecords int64,
+		recordRejectedRecords func([]*RejectedRecord) error) (int64, error)
 	IfRequiredQuoteColumnNames(tableName string, columns []string) ([]string, error)
 	ExecuteBatch(migrationUUID uuid.UUID, batch *EventBatch) error
 	GetDebeziumValueConverterSuite() map[string]tgtdbsuite.ConverterFn
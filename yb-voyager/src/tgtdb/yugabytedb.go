@@ -434,6 +434,18 @@ func (yb *TargetYugabyteDB) importBatch(conn *pgx.Conn, batch Batch, args *Impor
 	return res.RowsAffected(), err
 }
 
+func (yb *TargetYugabyteDB) ImportBatchIsolatingErrors(batch Batch, args *ImportBatchArgs, records []string, maxRejectedRecords int64,
+	recordRejectedRecords func([]*RejectedRecord) error) (int64, error) {
+	var rowsAffected int64
+	var err error
+	copyFn := func(conn *pgx.Conn) (bool, error) {
+		rowsAffected, err = importBatchIsolatingErrors(yb, conn, batch, args, records, maxRejectedRecords, recordRejectedRecords)
+		return false, err // Retries are implemented in the caller.
+	}
+	err = yb.connPool.WithConn(copyFn)
+	return rowsAffected, err
+}
+
 func (yb *TargetYugabyteDB) IfRequiredQuoteColumnNames(tableName string, columns []string) ([]string, error) {
 	result := make([]string, len(columns))
 	// FAST PATH.
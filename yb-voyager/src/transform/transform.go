@@ -0,0 +1,371 @@
+/*
+Copyright (c) YugabyteDB, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Package transform implements the row and column transformations applied to the data while it is
+// being imported: masking the PII columns, normalizing values and dropping columns.
+package transform
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+const (
+	// Replaces the value with the hex encoded SHA-256 hash of the salt and the value.
+	HASH = "hash"
+	// Replaces every character of the value with '*', retaining the length of the value.
+	REDACT = "redact"
+	// Replaces the value with the given constant value.
+	CONSTANT = "constant"
+	// Replaces the matches of the regular expression in the value with the replacement.
+	REGEX_REPLACE = "regex_replace"
+	// Removes the column from the imported data.
+	DROP = "drop"
+)
+
+var actions = []string{HASH, REDACT, CONSTANT, REGEX_REPLACE, DROP}
+
+// Rule is a transformation of a column of a table, as specified in the transformations file:
+//
+//	{
+//	  "rules": [
+//	    {"table": "public.users", "column": "email", "action": "hash", "salt": "s3cret"},
+//	    {"table": "users", "column": "ssn", "action": "redact"},
+//	    {"table": "users", "column": "country", "action": "constant", "value": "IN"},
+//	    {"table": "users", "column": "phone", "action": "regex_replace", "pattern": "[0-9]", "replacement": "#"},
+//	    {"table": "users", "column": "notes", "action": "drop"}
+//	  ]
+//	}
+//
+// The table name can be qualified with the schema name. As in SQL, the unquoted table and column
+// names are case insensitive. NULL values are left as they are, except by the `constant` action.
+type Rule struct {
+	Table       string  `json:"table"`
+	Column      string  `json:"column"`
+	Action      string  `json:"action"`
+	Salt        string  `json:"salt,omitempty"`
+	Value       *string `json:"value,omitempty"`
+	Pattern     string  `json:"pattern,omitempty"`
+	Replacement string  `json:"replacement,omitempty"`
+
+	schemaName identifier
+	tableName  identifier
+	columnName identifier
+	re         *regexp.Regexp
+}
+
+type Config struct {
+	Rules []*Rule `json:"rules"`
+}
+
+type identifier struct {
+	name   string
+	quoted bool
+}
+
+func parseIdentifier(name string) identifier {
+	if len(name) >= 2 && strings.HasPrefix(name, `"`) && strings.HasSuffix(name, `"`) {
+		return identifier{name: name[1 : len(name)-1], quoted: true}
+	}
+	return identifier{name: name}
+}
+
+// Matches the name of a table or a column in the data. The names in the data are taken as is.
+func (id identifier) matches(name string) bool {
+	name = parseIdentifier(name).name
+	if id.quoted {
+		return id.name == name
+	}
+	return strings.EqualFold(id.name, name)
+}
+
+// splitTableName splits the optionally schema qualified table name. The dot inside a quoted name is retained.
+func splitTableName(tableName string) (string, string) {
+	inQuotes := false
+	for i := 0; i < len(tableName); i++ {
+		switch tableName[i] {
+		case '"':
+			inQuotes = !inQuotes
+		case '.':
+			if !inQuotes {
+				return tableName[:i], tableName[i+1:]
+			}
+		}
+	}
+	return "", tableName
+}
+
+func (r *Rule) init() error {
+	if r.Table == "" || r.Column == "" {
+		return fmt.Errorf("table and column are required")
+	}
+	schemaName, tableName := splitTableName(r.Table)
+	r.schemaName, r.tableName, r.columnName = parseIdentifier(schemaName), parseIdentifier(tableName), parseIdentifier(r.Column)
+	switch r.Action {
+	case HASH, REDACT, DROP:
+	case CONSTANT:
+		if r.Value == nil {
+			return fmt.Errorf("value is required for the %q action", CONSTANT)
+		}
+	case REGEX_REPLACE:
+		if r.Pattern == "" {
+			return fmt.Errorf("pattern is required for the %q action", REGEX_REPLACE)
+		}
+		var err error
+		r.re, err = regexp.Compile(r.Pattern)
+		if err != nil {
+			return fmt.Errorf("invalid pattern %q: %w", r.Pattern, err)
+		}
+	default:
+		return fmt.Errorf("unknown action %q. Supported actions are: %s", r.Action, strings.Join(actions, ", "))
+	}
+	return nil
+}
+
+func (r *Rule) matchesTable(schemaName, tableName string) bool {
+	if !r.tableName.matches(tableName) {
+		return false
+	}
+	// An unqualified name on either side matches the table in any schema.
+	return r.schemaName.name == "" || schemaName == "" || r.schemaName.matches(schemaName)
+}
+
+func (r *Rule) apply(value *string) *string {
+	if r.Action == CONSTANT {
+		return r.Value
+	}
+	if value == nil {
+		return nil
+	}
+	var result string
+	switch r.Action {
+	case HASH:
+		sum := sha256.Sum256([]byte(r.Salt + *value))
+		result = hex.EncodeToString(sum[:])
+	case REDACT:
+		result = strings.Repeat("*", utf8.RuneCountInString(*value))
+	case REGEX_REPLACE:
+		result = r.re.ReplaceAllString(*value, r.Replacement)
+	}
+	return &result
+}
+
+//============================================================================
+
+type Transformer struct {
+	rules []*Rule
+
+	mu         sync.Mutex
+	tableCache map[string]*TableTransformer
+}
+
+func NewTransformer(config *Config) (*Transformer, error) {
+	for i, rule := range config.Rules {
+		err := rule.init()
+		if err != nil {
+			return nil, fmt.Errorf("rule %d (table %q, column %q): %w", i+1, rule.Table, rule.Column, err)
+		}
+	}
+	return &Transformer{rules: config.Rules, tableCache: map[string]*TableTransformer{}}, nil
+}
+
+func NewTransformerFromFile(filePath string) (*Transformer, error) {
+	bytes, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("read transformations file: %w", err)
+	}
+	config := &Config{}
+	decoder := json.NewDecoder(strings.NewReader(string(bytes)))
+	decoder.DisallowUnknownFields()
+	err = decoder.Decode(config)
+	if err != nil {
+		return nil, fmt.Errorf("parse transformations file %q: %w", filePath, err)
+	}
+	transformer, err := NewTransformer(config)
+	if err != nil {
+		return nil, fmt.Errorf("transformations file %q: %w", filePath, err)
+	}
+	return transformer, nil
+}
+
+// ForTable returns the transformations of the optionally schema qualified table.
+// It returns nil if there are none, which is safe to use.
+func (t *Transformer) ForTable(tableName string) *TableTransformer {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	tt, ok := t.tableCache[tableName]
+	if ok {
+		return tt
+	}
+	schemaName, objectName := splitTableName(tableName)
+	var rules []*Rule
+	for _, rule := range t.rules {
+		if rule.matchesTable(schemaName, objectName) {
+			rules = append(rules, rule)
+		}
+	}
+	if len(rules) > 0 {
+		tt = &TableTransformer{tableName: tableName, rules: rules}
+	}
+	t.tableCache[tableName] = tt
+	return tt
+}
+
+//============================================================================
+
+type TableTransformer struct {
+	tableName string
+	rules     []*Rule
+}
+
+func (tt *TableTransformer) columnRules(columnName string) []*Rule {
+	var result []*Rule
+	for _, rule := range tt.rules {
+		if rule.columnName.matches(columnName) {
+			result = append(result, rule)
+		}
+	}
+	return result
+}
+
+func isDropped(rules []*Rule) bool {
+	for _, rule := range rules {
+		if rule.Action == DROP {
+			return true
+		}
+	}
+	return false
+}
+
+// ForColumns prepares the transformation of the rows having the given columns.
+func (tt *TableTransformer) ForColumns(columnNames []string) (*RowTransformer, error) {
+	if tt == nil {
+		return nil, nil
+	}
+	if len(columnNames) == 0 {
+		return nil, fmt.Errorf("column names of table %q are required to apply the transformations", tt.tableName)
+	}
+	rt := &RowTransformer{columnRules: make([][]*Rule, len(columnNames))}
+	for i, columnName := range columnNames {
+		rt.columnRules[i] = tt.columnRules(columnName)
+		if isDropped(rt.columnRules[i]) {
+			rt.numDropped++
+		} else {
+			rt.columnNames = append(rt.columnNames, columnName)
+		}
+	}
+	return rt, nil
+}
+
+// TransformMap transforms the column values of a change event in place.
+// Dropping the columns of the key of the event is not allowed.
+func (tt *TableTransformer) TransformMap(m map[string]*string, isKey bool) error {
+	if tt == nil {
+		return nil
+	}
+	for column, value := range m {
+		rules := tt.columnRules(column)
+		if isDropped(rules) {
+			if isKey {
+				return fmt.Errorf("column %q of table %q can't be dropped as it is part of the key", column, tt.tableName)
+			}
+			delete(m, column)
+			continue
+		}
+		for _, rule := range rules {
+			value = rule.apply(value)
+		}
+		m[column] = value
+	}
+	return nil
+}
+
+// TransformsColumn returns true if the values of the column are changed by the transformations.
+func (tt *TableTransformer) TransformsColumn(columnName string) bool {
+	if tt == nil {
+		return false
+	}
+	rules := tt.columnRules(columnName)
+	return len(rules) > 0 && !isDropped(rules)
+}
+
+// DropsAllColumns returns true if all the columns of the map are dropped, e.g. if an update event
+// changes only the dropped columns.
+func (tt *TableTransformer) DropsAllColumns(m map[string]*string) bool {
+	if tt == nil || len(m) == 0 {
+		return false
+	}
+	for column := range m {
+		if !isDropped(tt.columnRules(column)) {
+			return false
+		}
+	}
+	return true
+}
+
+//============================================================================
+
+// RowTransformer transforms the rows of a table having a fixed set of columns.
+type RowTransformer struct {
+	columnRules [][]*Rule
+	columnNames []string
+	numDropped  int
+}
+
+// ColumnNames returns the names of the columns of the transformed rows.
+func (rt *RowTransformer) ColumnNames() []string {
+	return rt.columnNames
+}
+
+// DropColumns removes the dropped columns from a list of the values, e.g. a header of a data file.
+func (rt *RowTransformer) DropColumns(values []string) []string {
+	if rt.numDropped == 0 {
+		return values
+	}
+	var result []string
+	for i, value := range values {
+		if i >= len(rt.columnRules) || !isDropped(rt.columnRules[i]) {
+			result = append(result, value)
+		}
+	}
+	return result
+}
+
+func (rt *RowTransformer) TransformRow(values []*string) ([]*string, error) {
+	if len(values) != len(rt.columnRules) {
+		return nil, fmt.Errorf("row has %d values, expected %d", len(values), len(rt.columnRules))
+	}
+	result := make([]*string, 0, len(values)-rt.numDropped)
+	for i, value := range values {
+		if isDropped(rt.columnRules[i]) {
+			continue
+		}
+		for _, rule := range rt.columnRules[i] {
+			value = rule.apply(value)
+		}
+		result = append(result, value)
+	}
+	return result, nil
+}
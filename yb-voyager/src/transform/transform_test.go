@@ -0,0 +1,99 @@
+package transform
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func str(s string) *string { return &s }
+
+func TestRowTransformer(t *testing.T) {
+	assert := assert.New(t)
+	transformer, err := NewTransformer(&Config{Rules: []*Rule{
+		{Table: "public.users", Column: "email", Action: HASH, Salt: "salt"},
+		{Table: "users", Column: "SSN", Action: REDACT},
+		{Table: "users", Column: "country", Action: CONSTANT, Value: str("IN")},
+		{Table: "users", Column: "phone", Action: REGEX_REPLACE, Pattern: "[0-9]", Replacement: "#"},
+		{Table: "users", Column: `"Notes"`, Action: DROP},
+		{Table: "other.users", Column: "id", Action: REDACT},
+	}})
+	assert.NoError(err)
+
+	assert.Nil(transformer.ForTable("public.orders"))
+	rt, err := transformer.ForTable("public.users").ForColumns([]string{"id", "email", "ssn", "country", "phone", `"Notes"`})
+	assert.NoError(err)
+	assert.Equal([]string{"id", "email", "ssn", "country", "phone"}, rt.ColumnNames())
+	assert.Equal([]string{"id", "email", "ssn", "country", "phone"}, rt.DropColumns([]string{"id", "email", "ssn", "country", "phone", "Notes"}))
+
+	values, err := rt.TransformRow([]*string{str("1"), str("a@b.com"), str("123-45-6789"), nil, str("+91 98450"), str("note")})
+	assert.NoError(err)
+	assert.Len(values, 5)
+	assert.Equal("1", *values[0])
+	assert.Len(*values[1], 64)
+	assert.Equal("***********", *values[2])
+	assert.Equal("IN", *values[3])
+	assert.Equal("+## #####", *values[4])
+
+	// Hashing is deterministic, so that the snapshot and the changes are transformed alike.
+	again, err := rt.TransformRow([]*string{str("2"), str("a@b.com"), nil, nil, nil, nil})
+	assert.NoError(err)
+	assert.Equal(*values[1], *again[1])
+	assert.Nil(again[2])
+	assert.Equal("IN", *again[3])
+
+	_, err = rt.TransformRow([]*string{str("1")})
+	assert.Error(err)
+	_, err = transformer.ForTable("users").ForColumns(nil)
+	assert.ErrorContains(err, "column names")
+}
+
+func TestTransformMap(t *testing.T) {
+	assert := assert.New(t)
+	transformer, err := NewTransformer(&Config{Rules: []*Rule{
+		{Table: "users", Column: "email", Action: HASH},
+		{Table: "users", Column: "notes", Action: DROP},
+	}})
+	assert.NoError(err)
+	tt := transformer.ForTable("public.users")
+
+	rt, err := tt.ForColumns([]string{"id", "email", "notes"})
+	assert.NoError(err)
+	row, err := rt.TransformRow([]*string{str("1"), str("a@b.com"), str("x")})
+	assert.NoError(err)
+
+	fields := map[string]*string{"id": str("1"), "EMAIL": str("a@b.com"), "notes": str("x")}
+	assert.NoError(tt.TransformMap(fields, false))
+	assert.Equal(map[string]*string{"id": str("1"), "EMAIL": row[1]}, fields)
+
+	assert.True(tt.DropsAllColumns(map[string]*string{"notes": nil}))
+	assert.False(tt.DropsAllColumns(map[string]*string{"notes": nil, "id": nil}))
+	assert.ErrorContains(tt.TransformMap(map[string]*string{"notes": str("x")}, true), "part of the key")
+
+	var noTransformations *TableTransformer
+	assert.NoError(noTransformations.TransformMap(fields, true))
+}
+
+func TestNewTransformerFromFile(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	filePath := filepath.Join(dir, "transformations.json")
+	assert.NoError(os.WriteFile(filePath, []byte(`{"rules": [{"table": "users", "column": "email", "action": "mask"}]}`), 0644))
+	_, err := NewTransformerFromFile(filePath)
+	assert.ErrorContains(err, `unknown action "mask"`)
+
+	assert.NoError(os.WriteFile(filePath, []byte(`{"rules": [{"table": "users", "column": "phone", "action": "regex_replace", "pattern": "("}]}`), 0644))
+	_, err = NewTransformerFromFile(filePath)
+	assert.ErrorContains(err, "invalid pattern")
+
+	assert.NoError(os.WriteFile(filePath, []byte(`{"rules": [{"table": "users", "column": "country", "action": "constant"}]}`), 0644))
+	_, err = NewTransformerFromFile(filePath)
+	assert.ErrorContains(err, "value is required")
+
+	assert.NoError(os.WriteFile(filePath, []byte(`{"rules": [{"table": "users", "column": "country", "action": "constant", "value": "IN"}]}`), 0644))
+	transformer, err := NewTransformerFromFile(filePath)
+	assert.NoError(err)
+	assert.NotNil(transformer.ForTable("users"))
+}
@@ -27,6 +27,7 @@ import (
 	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/container"
 	"gocloud.dev/blob"
 	"gocloud.dev/blob/azureblob"
+	"gocloud.dev/gcerrors"
 
 	"github.com/yugabyte/yb-voyager/yb-voyager/src/utils"
 )
@@ -136,24 +137,32 @@ func ListAllObjects(dataDirUrl string) ([]string, error) {
 	return keys, nil
 }
 
-func GetHeadObject(objectURL string) (*blob.Attributes, error) {
+// opens the container of the blob in the url as a bucket and returns it along with the key of the blob.
+func openBucket(objectURL string) (*blob.Bucket, string, error) {
 	serviceName, containerName, key, err := splitObjectPath(objectURL)
 	if err != nil {
-		return nil, fmt.Errorf("splitting object path of %q: %w", objectURL, err)
+		return nil, "", fmt.Errorf("splitting object path of %q: %w", objectURL, err)
 	}
 	url := fmt.Sprintf("https://%s/%s", serviceName, containerName)
 	containerClient, err := createContainerClient(url)
 	if err != nil {
-		return nil, fmt.Errorf("creating container client for %q: %w", url, err)
+		return nil, "", fmt.Errorf("creating container client for %q: %w", url, err)
 	}
-	ctx := context.Background()
+	bucket, err := azureblob.OpenBucket(context.Background(), containerClient, nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("opening bucket for %q: %w", url, err)
+	}
+	return bucket, key, nil
+}
+
+func GetHeadObject(objectURL string) (*blob.Attributes, error) {
 	// using OpenBucket API to get the attributes of the blob in the container
-	bucket, err := azureblob.OpenBucket(ctx, containerClient, nil)
+	bucket, key, err := openBucket(objectURL)
 	if err != nil {
-		return nil, fmt.Errorf("opening bucket for %q: %w", url, err)
+		return nil, err
 	}
 	defer bucket.Close()
-	blobAttributes, err := bucket.Attributes(ctx, key)
+	blobAttributes, err := bucket.Attributes(context.Background(), key)
 	if err != nil {
 		return nil, fmt.Errorf("getting attributes of %q: %w", objectURL, err)
 	}
@@ -161,16 +170,102 @@ func GetHeadObject(objectURL string) (*blob.Attributes, error) {
 }
 
 func NewObjectReader(objectURL string) (io.ReadCloser, error) {
+	return NewObjectRangeReader(objectURL, 0)
+}
+
+// NewObjectRangeReader returns a reader for the blob starting at the given byte offset.
+func NewObjectRangeReader(objectURL string, offset int64) (io.ReadCloser, error) {
 	createClientIfNotExists(objectURL)
 	_, containerName, key, err := splitObjectPath(objectURL)
 	if err != nil {
 		return nil, fmt.Errorf("splitting object path of %q: %w", objectURL, err)
 	}
 	ctx := context.Background()
-	get, err := client.DownloadStream(ctx, containerName, key, nil)
+	// A zero Count reads till the end of the blob.
+	options := &azblob.DownloadStreamOptions{Range: azblob.HTTPRange{Offset: offset}}
+	get, err := client.DownloadStream(ctx, containerName, key, options)
 	if err != nil {
 		return nil, fmt.Errorf("create download stream for %q: %w", objectURL, err)
 	}
 	retryReader := get.NewRetryReader(ctx, &azblob.RetryReaderOptions{MaxRetries: 10})
 	return retryReader, nil
 }
+
+// NewObjectWriter returns a writer that uploads the blob. The blob becomes visible only after the writer is closed.
+func NewObjectWriter(objectURL string) (io.WriteCloser, error) {
+	bucket, key, err := openBucket(objectURL)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	w, err := bucket.NewWriter(ctx, key, nil)
+	if err != nil {
+		cancel()
+		bucket.Close()
+		return nil, fmt.Errorf("create writer for %q: %w", objectURL, err)
+	}
+	return &blobWriter{Writer: w, bucket: bucket, cancel: cancel}, nil
+}
+
+// blobWriter owns the bucket opened for the upload, which is closed along with the writer.
+type blobWriter struct {
+	*blob.Writer
+	bucket *blob.Bucket
+	cancel context.CancelFunc
+}
+
+func (w *blobWriter) Close() error {
+	defer w.cancel()
+	err := w.Writer.Close()
+	bucketErr := w.bucket.Close()
+	if err != nil {
+		return err
+	}
+	return bucketErr
+}
+
+// Abort cancels the upload, the blocks staged so far are never committed.
+func (w *blobWriter) Abort() error {
+	w.cancel()
+	_ = w.Writer.Close()
+	return w.bucket.Close()
+}
+
+// DeleteObject deletes the blob. It is not an error if the blob doesn't exist.
+func DeleteObject(objectURL string) error {
+	bucket, key, err := openBucket(objectURL)
+	if err != nil {
+		return err
+	}
+	defer bucket.Close()
+	err = bucket.Delete(context.Background(), key)
+	if err != nil && gcerrors.Code(err) != gcerrors.NotFound {
+		return fmt.Errorf("delete %q: %w", objectURL, err)
+	}
+	return nil
+}
+
+// RenameObject copies the blob to the new name and deletes the old one, as azure blob storage has no rename operation.
+func RenameObject(oldObjectURL, newObjectURL string) error {
+	bucket, oldKey, err := openBucket(oldObjectURL)
+	if err != nil {
+		return err
+	}
+	defer bucket.Close()
+	_, _, newKey, err := splitObjectPath(newObjectURL)
+	if err != nil {
+		return fmt.Errorf("splitting object path of %q: %w", newObjectURL, err)
+	}
+	if !strings.HasPrefix(newObjectURL, strings.TrimSuffix(oldObjectURL, oldKey)) {
+		return fmt.Errorf("rename across containers is not supported")
+	}
+	err = bucket.Copy(context.Background(), newKey, oldKey, nil)
+	if err != nil {
+		return fmt.Errorf("copy %q to %q: %w", oldObjectURL, newObjectURL, err)
+	}
+	err = bucket.Delete(context.Background(), oldKey)
+	if err != nil {
+		return fmt.Errorf("delete %q: %w", oldObjectURL, err)
+	}
+	return nil
+}
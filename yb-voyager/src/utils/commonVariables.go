@@ -74,13 +74,19 @@ var mysqlSchemaObjectList = []string{"TABLE", "PARTITION", "INDEX", "VIEW", /*"G
 	"TRIGGER", "FUNCTION", "PROCEDURE"}
 var mysqlSchemaObjectListForExport = []string{"TABLE", "VIEW", "TRIGGER", "FUNCTION", "PROCEDURE"}
 
+// In SQLITE, INDEX are exported along with TABLE
+var sqliteSchemaObjectList = []string{"TABLE", "INDEX"}
+var sqliteSchemaObjectListForExport = []string{"TABLE"}
+
 var WaitGroup sync.WaitGroup
 var WaitChannel = make(chan int)
 
 // report.json format
 type Report struct {
-	Summary Summary `json:"summary"`
-	Issues  []Issue `json:"issues"`
+	Summary      Summary       `json:"summary"`
+	Issues       []Issue       `json:"issues"`
+	WaivedIssues []WaivedIssue `json:"waivedIssues,omitempty"`
+	AppliedFixes []AppliedFix  `json:"appliedFixes,omitempty"`
 }
 
 type Summary struct {
@@ -105,10 +111,29 @@ type Issue struct {
 	Reason       string `json:"reason"`
 	SqlStatement string `json:"sqlStatement,omitempty"`
 	FilePath     string `json:"filePath"`
+	Line         int    `json:"line,omitempty"`
+	Column       int    `json:"column,omitempty"`
 	Suggestion   string `json:"suggestion"`
 	GH           string `json:"GH"`
 }
 
+// Issue which is not reported as an error as it is waived in the waivers file given to analyze-schema
+type WaivedIssue struct {
+	Issue
+	Justification string `json:"justification"`
+	Expiry        string `json:"expiry,omitempty"`
+}
+
+// Change made to a schema file by analyze-schema --apply-fixes
+type AppliedFix struct {
+	ObjectType     string `json:"objectType"`
+	ObjectName     string `json:"objectName"`
+	Description    string `json:"description"`
+	FilePath       string `json:"filePath"`
+	Line           int    `json:"line,omitempty"`
+	BackupFilePath string `json:"backupFilePath"`
+}
+
 type Segment struct {
 	Num      int
 	FilePath string
@@ -17,6 +17,7 @@ package gcs
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/url"
@@ -116,14 +117,85 @@ func GetObjAttrs(object string) (*storage.ObjectAttrs, error) {
 }
 
 func NewObjectReader(object string) (io.ReadCloser, error) {
+	return NewObjectRangeReader(object, 0)
+}
+
+// NewObjectRangeReader returns a reader for the object starting at the given byte offset.
+func NewObjectRangeReader(object string, offset int64) (io.ReadCloser, error) {
 	createClientIfNotExists()
 	bucketName, keyName, err := splitObjectPath(object)
 	if err != nil {
 		return nil, fmt.Errorf("split object path of %q: %w", object, err)
 	}
-	r, err := client.Bucket(bucketName).Object(keyName).NewReader(context.Background())
+	r, err := client.Bucket(bucketName).Object(keyName).NewRangeReader(context.Background(), offset, -1)
 	if err != nil {
 		return nil, fmt.Errorf("get reader for %q: %w", object, err)
 	}
 	return r, nil
 }
+
+// NewObjectWriter returns a writer that uploads the object. The object becomes visible only after the writer is closed.
+func NewObjectWriter(object string) (io.WriteCloser, error) {
+	createClientIfNotExists()
+	bucketName, keyName, err := splitObjectPath(object)
+	if err != nil {
+		return nil, fmt.Errorf("split object path of %q: %w", object, err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &objectWriter{Writer: client.Bucket(bucketName).Object(keyName).NewWriter(ctx), cancel: cancel}, nil
+}
+
+type objectWriter struct {
+	*storage.Writer
+	cancel context.CancelFunc
+}
+
+func (w *objectWriter) Close() error {
+	defer w.cancel()
+	return w.Writer.Close()
+}
+
+// Abort cancels the upload, a canceled upload never creates the object.
+func (w *objectWriter) Abort() error {
+	w.cancel()
+	_ = w.Writer.Close()
+	return nil
+}
+
+// DeleteObject deletes the object. It is not an error if the object doesn't exist.
+func DeleteObject(object string) error {
+	createClientIfNotExists()
+	bucketName, keyName, err := splitObjectPath(object)
+	if err != nil {
+		return fmt.Errorf("split object path of %q: %w", object, err)
+	}
+	err = client.Bucket(bucketName).Object(keyName).Delete(context.Background())
+	if err != nil && !errors.Is(err, storage.ErrObjectNotExist) {
+		return fmt.Errorf("delete %q: %w", object, err)
+	}
+	return nil
+}
+
+// RenameObject copies the object to the new name and deletes the old one, as gcs has no rename operation.
+func RenameObject(oldObject, newObject string) error {
+	createClientIfNotExists()
+	oldBucketName, oldKey, err := splitObjectPath(oldObject)
+	if err != nil {
+		return fmt.Errorf("split object path of %q: %w", oldObject, err)
+	}
+	newBucketName, newKey, err := splitObjectPath(newObject)
+	if err != nil {
+		return fmt.Errorf("split object path of %q: %w", newObject, err)
+	}
+	src := client.Bucket(oldBucketName).Object(oldKey)
+	dst := client.Bucket(newBucketName).Object(newKey)
+	_, err = dst.CopierFrom(src).Run(context.Background())
+	if err != nil {
+		return fmt.Errorf("copy %q to %q: %w", oldObject, newObject, err)
+	}
+	err = src.Delete(context.Background())
+	if err != nil {
+		return fmt.Errorf("delete %q: %w", oldObject, err)
+	}
+	return nil
+}
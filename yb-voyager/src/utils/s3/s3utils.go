@@ -17,42 +17,147 @@ package s3
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/url"
+	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"gocloud.dev/blob"
 	"gocloud.dev/blob/s3blob"
+	"gocloud.dev/gcerrors"
 
 	"github.com/yugabyte/yb-voyager/yb-voyager/src/utils"
 )
 
-var client *s3.Client
+// Config holds the connection settings of the buckets hosted outside AWS, e.g. on S3-compatible
+// stores like MinIO or Ceph. They are given as query parameters of the data dir URL:
+//
+//	s3://bucket/path?endpoint=http://minio:9000&use_path_style=true&profile=minio&region=us-east-1
+type Config struct {
+	Endpoint     string
+	Region       string
+	Profile      string
+	UsePathStyle bool
+}
+
+const (
+	ENDPOINT_PARAM       = "endpoint"
+	REGION_PARAM         = "region"
+	PROFILE_PARAM        = "profile"
+	USE_PATH_STYLE_PARAM = "use_path_style"
+
+	// Region used for the custom endpoints when none is configured. S3-compatible stores
+	// generally ignore the region, but the request signature requires one.
+	DEFAULT_CUSTOM_ENDPOINT_REGION = "us-east-1"
+)
+
+var (
+	clientsMutex  sync.Mutex
+	defaultClient *s3.Client
+	bucketConfigs = map[string]*Config{}
+	bucketClients = map[string]*s3.Client{}
+)
+
+// ParseDataDirURL returns the data dir URL without the query parameters, along with the
+// connection settings given by them. The settings are registered for the bucket of the URL,
+// so that all the subsequent operations on the objects of the bucket use them.
+func ParseDataDirURL(dataDir string) (string, *Config, error) {
+	u, err := url.Parse(dataDir)
+	if err != nil {
+		return "", nil, err
+	}
+	if u.Host == "" {
+		return "", nil, fmt.Errorf("missing bucket in s3 url %v", dataDir)
+	}
+	if u.RawQuery == "" {
+		return dataDir, nil, nil
+	}
+	cfg := &Config{}
+	for param, values := range u.Query() {
+		value := values[len(values)-1]
+		switch param {
+		case ENDPOINT_PARAM:
+			endpoint, err := url.Parse(value)
+			if err != nil || endpoint.Scheme == "" || endpoint.Host == "" {
+				return "", nil, fmt.Errorf("invalid %s %q in s3 url %v", ENDPOINT_PARAM, value, dataDir)
+			}
+			cfg.Endpoint = value
+		case REGION_PARAM:
+			cfg.Region = value
+		case PROFILE_PARAM:
+			cfg.Profile = value
+		case USE_PATH_STYLE_PARAM:
+			cfg.UsePathStyle, err = strconv.ParseBool(value)
+			if err != nil {
+				return "", nil, fmt.Errorf("invalid %s %q in s3 url %v", USE_PATH_STYLE_PARAM, value, dataDir)
+			}
+		default:
+			return "", nil, fmt.Errorf("unknown parameter %q in s3 url %v. Supported parameters are: %s, %s, %s, %s",
+				param, dataDir, ENDPOINT_PARAM, REGION_PARAM, PROFILE_PARAM, USE_PATH_STYLE_PARAM)
+		}
+	}
+	RegisterBucketConfig(u.Host, cfg)
+	u.RawQuery = ""
+	return u.String(), cfg, nil
+}
+
+// RegisterBucketConfig sets the connection settings to be used for the objects of the bucket.
+func RegisterBucketConfig(bucket string, cfg *Config) {
+	clientsMutex.Lock()
+	defer clientsMutex.Unlock()
+	bucketConfigs[bucket] = cfg
+	delete(bucketClients, bucket)
+}
+
+func getClient(bucket string) *s3.Client {
+	clientsMutex.Lock()
+	defer clientsMutex.Unlock()
+	cfg, ok := bucketConfigs[bucket]
+	if !ok {
+		if defaultClient == nil {
+			defaultClient = newClient(&Config{})
+		}
+		return defaultClient
+	}
+	if bucketClients[bucket] == nil {
+		bucketClients[bucket] = newClient(cfg)
+	}
+	return bucketClients[bucket]
+}
 
-func createClientIfNotExists() {
-	if client != nil {
-		return
+func newClient(cfg *Config) *s3.Client {
+	var opts []func(*config.LoadOptions) error
+	if cfg.Profile != "" {
+		opts = append(opts, config.WithSharedConfigProfile(cfg.Profile))
+	}
+	region := cfg.Region
+	if region == "" && cfg.Endpoint != "" {
+		region = DEFAULT_CUSTOM_ENDPOINT_REGION
+	}
+	if region != "" {
+		opts = append(opts, config.WithRegion(region))
 	}
-	cfg, err := config.LoadDefaultConfig(context.Background())
+	awsCfg, err := config.LoadDefaultConfig(context.Background(), opts...)
 	if err != nil {
 		utils.ErrExit("load s3 config: %w", err)
 	}
-	client = s3.NewFromConfig(cfg)
+	return s3.NewFromConfig(awsCfg, func(o *s3.Options) {
+		o.UsePathStyle = cfg.UsePathStyle
+		if cfg.Endpoint != "" {
+			o.EndpointResolver = s3.EndpointResolverFromURL(cfg.Endpoint)
+		}
+	})
 }
 
 func ValidateObjectURL(datadir string) error {
-	u, err := url.Parse(datadir)
-	if err != nil {
-		return err
-	}
-	bucket := u.Host
-	if bucket == "" {
-		return fmt.Errorf("missing bucket in s3 url %v", datadir)
-	}
-	return nil
+	_, _, err := ParseDataDirURL(datadir)
+	return err
 }
 
 func splitObjectPath(objectPath string) (string, string, error) {
@@ -72,12 +177,12 @@ func splitObjectPath(objectPath string) (string, string, error) {
 }
 
 func ListAllObjects(dataDir string) ([]string, error) {
-	createClientIfNotExists()
 	dataDirUrl, err := url.Parse(dataDir)
 	if err != nil {
 		return nil, fmt.Errorf("parsing the object of %q: %w", dataDir, err)
 	}
 	bucket := dataDirUrl.Host
+	client := getClient(bucket)
 	prefix := ""
 	if dataDirUrl.Path != "" {
 		prefix = dataDirUrl.Path[1:] //remove initial "/"
@@ -111,11 +216,11 @@ func ListAllObjects(dataDir string) ([]string, error) {
 }
 
 func GetHeadObject(object string) (*s3.HeadObjectOutput, error) {
-	createClientIfNotExists()
 	bucket, key, err := splitObjectPath(object)
 	if err != nil {
 		return nil, err
 	}
+	client := getClient(bucket)
 	headObj := s3.HeadObjectInput{
 		Bucket: aws.String(bucket),
 		Key:    aws.String(key),
@@ -128,14 +233,110 @@ func GetHeadObject(object string) (*s3.HeadObjectOutput, error) {
 }
 
 func NewObjectReader(object string) (io.ReadCloser, error) {
-	createClientIfNotExists()
+	return NewObjectRangeReader(object, 0)
+}
+
+// NewObjectRangeReader returns a reader for the object starting at the given byte offset.
+// The object is fetched using an HTTP range request.
+func NewObjectRangeReader(object string, offset int64) (io.ReadCloser, error) {
 	bucketName, keyName, err := splitObjectPath(object)
 	if err != nil {
 		return nil, err
 	}
-	bucket, err := s3blob.OpenBucketV2(context.Background(), client, bucketName, nil)
+	bucket, err := s3blob.OpenBucketV2(context.Background(), getClient(bucketName), bucketName, nil)
 	if err != nil {
 		utils.ErrExit("open bucket: %w", err)
 	}
-	return bucket.NewReader(context.Background(), keyName, nil)
+	return bucket.NewRangeReader(context.Background(), keyName, offset, -1, nil)
+}
+
+func NewObjectWriter(object string) (io.WriteCloser, error) {
+	bucketName, keyName, err := splitObjectPath(object)
+	if err != nil {
+		return nil, err
+	}
+	bucket, err := s3blob.OpenBucketV2(context.Background(), getClient(bucketName), bucketName, nil)
+	if err != nil {
+		return nil, fmt.Errorf("open bucket %q: %w", bucketName, err)
+	}
+	// The object is uploaded in parts as it is written and becomes visible only after the writer is closed.
+	ctx, cancel := context.WithCancel(context.Background())
+	w, err := bucket.NewWriter(ctx, keyName, nil)
+	if err != nil {
+		cancel()
+		bucket.Close()
+		return nil, fmt.Errorf("create writer for %q: %w", object, err)
+	}
+	return &objectWriter{Writer: w, bucket: bucket, cancel: cancel}, nil
+}
+
+// objectWriter closes the bucket it was opened from when the upload is completed or aborted.
+type objectWriter struct {
+	*blob.Writer
+	bucket *blob.Bucket
+	cancel context.CancelFunc
+}
+
+func (w *objectWriter) Close() error {
+	defer w.cancel()
+	err := w.Writer.Close()
+	bucketErr := w.bucket.Close()
+	if err != nil {
+		return err
+	}
+	return bucketErr
+}
+
+// Abort discards the parts uploaded so far instead of publishing the object.
+func (w *objectWriter) Abort() error {
+	w.cancel()
+	_ = w.Writer.Close()
+	return w.bucket.Close()
+}
+
+// DeleteObject deletes the object. It is not an error if the object doesn't exist.
+func DeleteObject(object string) error {
+	bucketName, keyName, err := splitObjectPath(object)
+	if err != nil {
+		return err
+	}
+	bucket, err := s3blob.OpenBucketV2(context.Background(), getClient(bucketName), bucketName, nil)
+	if err != nil {
+		return fmt.Errorf("open bucket %q: %w", bucketName, err)
+	}
+	defer bucket.Close()
+	err = bucket.Delete(context.Background(), keyName)
+	if err != nil && gcerrors.Code(err) != gcerrors.NotFound {
+		return fmt.Errorf("delete %q: %w", object, err)
+	}
+	return nil
+}
+
+// RenameObject copies the object to the new key and deletes the old one, as s3 has no rename operation.
+func RenameObject(oldObject, newObject string) error {
+	bucketName, oldKey, err := splitObjectPath(oldObject)
+	if err != nil {
+		return err
+	}
+	newBucketName, newKey, err := splitObjectPath(newObject)
+	if err != nil {
+		return err
+	}
+	if bucketName != newBucketName {
+		return errors.New("rename across buckets is not supported")
+	}
+	bucket, err := s3blob.OpenBucketV2(context.Background(), getClient(bucketName), bucketName, nil)
+	if err != nil {
+		return fmt.Errorf("open bucket %q: %w", bucketName, err)
+	}
+	defer bucket.Close()
+	err = bucket.Copy(context.Background(), newKey, oldKey, nil)
+	if err != nil {
+		return fmt.Errorf("copy %q to %q: %w", oldObject, newObject, err)
+	}
+	err = bucket.Delete(context.Background(), oldKey)
+	if err != nil {
+		return fmt.Errorf("delete %q: %w", oldObject, err)
+	}
+	return nil
 }
@@ -0,0 +1,191 @@
+package s3
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeS3Server is a minimal S3-compatible store, like MinIO, serving path-style requests.
+type fakeS3Server struct {
+	mu      sync.Mutex
+	objects map[string][]byte // "bucket/key" -> content
+	// Access keys seen in the request signatures.
+	accessKeys map[string]bool
+}
+
+func newFakeS3Server(t *testing.T) (*fakeS3Server, *httptest.Server) {
+	fake := &fakeS3Server{objects: map[string][]byte{}, accessKeys: map[string]bool{}}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	return fake, server
+}
+
+func (f *fakeS3Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	auth := r.Header.Get("Authorization")
+	if i := strings.Index(auth, "Credential="); i >= 0 {
+		f.accessKeys[strings.SplitN(auth[i+len("Credential="):], "/", 2)[0]] = true
+	}
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	objectName := bucket + "/" + key
+	switch {
+	case r.Method == http.MethodGet && key == "":
+		f.listObjects(w, bucket, r.URL.Query().Get("prefix"))
+	case r.Method == http.MethodPut && r.Header.Get("X-Amz-Copy-Source") != "":
+		src := strings.TrimPrefix(r.Header.Get("X-Amz-Copy-Source"), "/")
+		content, ok := f.objects[src]
+		if !ok {
+			http.Error(w, "NoSuchKey", http.StatusNotFound)
+			return
+		}
+		f.objects[objectName] = content
+		fmt.Fprintf(w, `<CopyObjectResult><ETag>"etag"</ETag><LastModified>%s</LastModified></CopyObjectResult>`,
+			time.Now().UTC().Format(time.RFC3339))
+	case r.Method == http.MethodPut:
+		content, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		f.objects[objectName] = content
+		w.Header().Set("ETag", `"etag"`)
+	case r.Method == http.MethodDelete:
+		delete(f.objects, objectName)
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodGet || r.Method == http.MethodHead:
+		content, ok := f.objects[objectName]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			if r.Method == http.MethodGet {
+				fmt.Fprint(w, `<Error><Code>NoSuchKey</Code></Error>`)
+			}
+			return
+		}
+		w.Header().Set("Last-Modified", time.Now().UTC().Format(http.TimeFormat))
+		w.Header().Set("ETag", `"etag"`)
+		status := http.StatusOK
+		var start int
+		if _, err := fmt.Sscanf(r.Header.Get("Range"), "bytes=%d-", &start); err == nil {
+			w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, len(content)-1, len(content)))
+			status = http.StatusPartialContent
+			content = content[start:]
+		}
+		w.Header().Set("Content-Length", fmt.Sprint(len(content)))
+		w.WriteHeader(status)
+		if r.Method == http.MethodGet {
+			_, _ = w.Write(content)
+		}
+	default:
+		http.Error(w, "unsupported request", http.StatusNotImplemented)
+	}
+}
+
+func (f *fakeS3Server) listObjects(w http.ResponseWriter, bucket string, prefix string) {
+	type content struct {
+		Key  string
+		Size int
+	}
+	result := struct {
+		XMLName     xml.Name `xml:"ListBucketResult"`
+		Name        string
+		IsTruncated bool
+		Contents    []content
+	}{Name: bucket}
+	for name, data := range f.objects {
+		key := strings.TrimPrefix(name, bucket+"/")
+		if key != name && strings.HasPrefix(key, prefix) {
+			result.Contents = append(result.Contents, content{Key: key, Size: len(data)})
+		}
+	}
+	sort.Slice(result.Contents, func(i, j int) bool { return result.Contents[i].Key < result.Contents[j].Key })
+	_ = xml.NewEncoder(w).Encode(result)
+}
+
+func TestParseDataDirURL(t *testing.T) {
+	assert := assert.New(t)
+	dataDir, cfg, err := ParseDataDirURL("s3://bucket/path")
+	assert.NoError(err)
+	assert.Equal("s3://bucket/path", dataDir)
+	assert.Nil(cfg)
+
+	dataDir, cfg, err = ParseDataDirURL("s3://onprem-bucket/path?endpoint=http://minio:9000&use_path_style=true&profile=minio")
+	assert.NoError(err)
+	assert.Equal("s3://onprem-bucket/path", dataDir)
+	assert.Equal(&Config{Endpoint: "http://minio:9000", UsePathStyle: true, Profile: "minio"}, cfg)
+
+	_, _, err = ParseDataDirURL("s3://bucket/path?use_path_style=yes")
+	assert.ErrorContains(err, "use_path_style")
+	_, _, err = ParseDataDirURL("s3://bucket/path?endpoint=minio:9000")
+	assert.ErrorContains(err, "endpoint")
+	_, _, err = ParseDataDirURL("s3://bucket/path?secret=x")
+	assert.ErrorContains(err, "unknown parameter")
+	_, _, err = ParseDataDirURL("s3:///path")
+	assert.ErrorContains(err, "missing bucket")
+}
+
+func TestS3CompatibleStore(t *testing.T) {
+	assert := assert.New(t)
+	fake, server := newFakeS3Server(t)
+
+	credentialsFile := filepath.Join(t.TempDir(), "credentials")
+	assert.NoError(os.WriteFile(credentialsFile, []byte("[minio]\naws_access_key_id = minio-key\naws_secret_access_key = minio-secret\n"), 0600))
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", credentialsFile)
+	t.Setenv("AWS_CONFIG_FILE", filepath.Join(t.TempDir(), "config"))
+	t.Setenv("AWS_ACCESS_KEY_ID", "")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "")
+	t.Setenv("AWS_PROFILE", "")
+
+	dataDir, _, err := ParseDataDirURL(fmt.Sprintf("s3://staging/export?endpoint=%s&use_path_style=true&profile=minio", server.URL))
+	assert.NoError(err)
+
+	w, err := NewObjectWriter(dataDir + "/orders_data.sql.tmp")
+	assert.NoError(err)
+	_, err = io.WriteString(w, "1\tone\n")
+	assert.NoError(err)
+	assert.NoError(w.Close())
+	assert.NoError(RenameObject(dataDir+"/orders_data.sql.tmp", dataDir+"/orders_data.sql"))
+
+	objectNames, err := ListAllObjects(dataDir)
+	assert.NoError(err)
+	assert.Equal([]string{"orders_data.sql"}, objectNames)
+
+	headObject, err := GetHeadObject(dataDir + "/orders_data.sql")
+	assert.NoError(err)
+	assert.Equal(int64(len("1\tone\n")), headObject.ContentLength)
+
+	r, err := NewObjectReader(dataDir + "/orders_data.sql")
+	assert.NoError(err)
+	content, err := io.ReadAll(r)
+	assert.NoError(err)
+	assert.NoError(r.Close())
+	assert.Equal("1\tone\n", string(content))
+
+	r, err = NewObjectRangeReader(dataDir+"/orders_data.sql", 2)
+	assert.NoError(err)
+	content, err = io.ReadAll(r)
+	assert.NoError(err)
+	assert.NoError(r.Close())
+	assert.Equal("one\n", string(content))
+
+	assert.NoError(DeleteObject(dataDir + "/orders_data.sql"))
+	assert.NoError(DeleteObject(dataDir + "/orders_data.sql"))
+	objectNames, err = ListAllObjects(dataDir)
+	assert.NoError(err)
+	assert.Empty(objectNames)
+
+	// All the requests were path-style and signed with the credentials of the profile.
+	assert.Equal(map[string]bool{"minio-key": true}, fake.accessKeys)
+}
@@ -27,6 +27,7 @@ const (
 	POSTGRESQL = "postgresql"
 	ORACLE     = "oracle"
 	MYSQL      = "mysql"
+	SQLITE     = "sqlite"
 )
 
 var (
@@ -165,7 +166,7 @@ func quote(s string, dbType string) string {
 		return s
 	}
 	switch dbType {
-	case POSTGRESQL, YUGABYTEDB:
+	case POSTGRESQL, YUGABYTEDB, SQLITE:
 		return `"` + strings.ToLower(s) + `"`
 	case MYSQL:
 		return s // TODO - learn the semantics of quoting in MySQL.
@@ -181,7 +182,7 @@ func unquote(s string, dbType string) string {
 		return s[1 : len(s)-1]
 	}
 	switch dbType {
-	case POSTGRESQL, YUGABYTEDB:
+	case POSTGRESQL, YUGABYTEDB, SQLITE:
 		return strings.ToLower(s)
 	case MYSQL:
 		return s
@@ -209,7 +210,7 @@ func SetDifference(a, b []*SourceName) []*SourceName {
 func minQuote(objectName, sourceDBType string) string {
 	objectName = unquote(objectName, sourceDBType)
 	switch sourceDBType {
-	case YUGABYTEDB, POSTGRESQL:
+	case YUGABYTEDB, POSTGRESQL, SQLITE:
 		if IsAllLowercase(objectName) && !IsReservedKeywordPG(objectName) {
 			return objectName
 		} else {
@@ -252,7 +253,7 @@ func IsCaseSensitive(s string, sourceDbType string) bool {
 		return !IsAllUppercase(s)
 	case POSTGRESQL:
 		return !IsAllLowercase(s)
-	case MYSQL:
+	case MYSQL, SQLITE:
 		return false
 	}
 	panic("invalid source db type")
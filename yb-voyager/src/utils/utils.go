@@ -125,6 +125,8 @@ func GetSchemaObjectList(sourceDBType string) []string {
 		requiredList = postgresSchemaObjectList
 	case "mysql":
 		requiredList = mysqlSchemaObjectList
+	case "sqlite":
+		requiredList = sqliteSchemaObjectList
 	default:
 		ErrExit("Unsupported %q source db type\n", sourceDBType)
 	}
@@ -141,6 +143,8 @@ func GetExportSchemaObjectList(sourceDBType string) []string {
 		requiredList = postgresSchemaObjectListForExport
 	case "mysql":
 		requiredList = mysqlSchemaObjectListForExport
+	case "sqlite":
+		requiredList = sqliteSchemaObjectListForExport
 	default:
 		ErrExit("Unsupported %q source db type\n", sourceDBType)
 	}